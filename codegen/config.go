@@ -19,6 +19,7 @@ import (
 	"os"
 
 	"github.com/corestoreio/csfw/codegen/tableToStruct/tpl"
+	"github.com/corestoreio/csfw/locale"
 	"github.com/corestoreio/csfw/util"
 )
 
@@ -327,14 +328,20 @@ var ConfigMaterializationEntityType = TableToStruct{
 }
 
 // ConfigLocalization temporary integration because missing feature in https://github.com/golang/text
+// Only the EnabledLocale get generated, i18n.LookupNumberLocale and
+// i18n.LookupDateLocale report all other locales as not found. All
+// locale.AllowedLocales are enabled, add further locales of your stores and
+// run codegen/localization again. An empty EnabledCurrency generates all
+// ISO 4217 currencies in legal tender.
 var ConfigLocalization = struct {
-	Package       string
-	OutputFile    string
-	EnabledLocale util.StringSlice
+	Package         string
+	OutputFile      string
+	EnabledLocale   util.StringSlice
+	EnabledCurrency util.StringSlice
 }{
-	Package:       "i18n_test",
-	OutputFile:    BasePath.AppendDir("i18n", "generated_translation_test").String(),
-	EnabledLocale: util.StringSlice{"en", "fr", "de", "de_CH", "nl", "ca_FR"},
+	Package:       "i18n",
+	OutputFile:    BasePath.AppendDir("i18n", "locale_generated").String(),
+	EnabledLocale: append(util.StringSlice{"en", "fr", "de", "nl", "ca_FR"}, locale.AllowedLocales...),
 }
 
 var (
@@ -15,24 +15,25 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"os"
-
-	"text/template"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/corestoreio/csfw/codegen"
 	"github.com/corestoreio/csfw/codegen/localization/gen"
+	"golang.org/x/text/currency"
 	"golang.org/x/text/unicode/cldr"
 )
 
-func main() {
-
-	fmt.Println("TODO refactor")
-	os.Exit(-1)
+// numberSystem only the latin digits are supported by the i18n package.
+const numberSystem = "latn"
 
+func main() {
 	gen.Init()
 
 	// Read the CLDR zip file. Autodownloading if file not found
@@ -41,32 +42,30 @@ func main() {
 
 	d := &cldr.Decoder{}
 	d.SetDirFilter("main", "supplemental")
-	d.SetSectionFilter("localeDisplayNames", "numbers")
+	d.SetSectionFilter("numbers", "plurals", "dates")
 	data, err := d.DecodeZip(r)
 	codegen.LogFatal(err)
 
-	curW := &bytes.Buffer{}
-	for _, loc := range data.Locales() {
+	plurals := newPluralRules(data.Supplemental())
 
+	var locales []numberLocale
+	var dates []dateLocale
+	for _, loc := range data.Locales() {
 		if false == codegen.ConfigLocalization.EnabledLocale.Include(loc) {
 			continue
 		}
-
 		ldml, err := data.LDML(loc)
 		codegen.LogFatal(err)
 		fmt.Fprintf(os.Stdout, "Generating: %s\n", loc)
-
-		curB := curBuilder{
-			w:      curW,
-			locale: loc,
-			data:   ldml,
-		}
-		curB.generate()
+		locales = append(locales, newNumberLocale(loc, ldml, plurals))
+		dates = append(dates, newDateLocale(loc, ldml))
 	}
 
 	tplData := map[string]interface{}{
-		"Package":       codegen.ConfigLocalization.Package,
-		"CurrencyDicts": curW.String(),
+		"Package":     codegen.ConfigLocalization.Package,
+		"CLDRVersion": cldr.Version,
+		"Locales":     locales,
+		"Dates":       dates,
 	}
 
 	formatted, err := codegen.GenerateCode(codegen.ConfigLocalization.Package, tplCode, tplData, nil)
@@ -78,55 +77,416 @@ func main() {
 	codegen.LogFatal(ioutil.WriteFile(codegen.ConfigLocalization.OutputFile, formatted, 0600))
 }
 
-type curBuilder struct {
-	w      io.Writer
-	locale string
-	data   *cldr.LDML
+// numberLocale contains the Go literals of an i18n.NumberLocale.
+type numberLocale struct {
+	Locale     string
+	Symbols    string
+	Decimal    string
+	Percent    string
+	Scientific string
+	Currency   string
+	Accounting string
+	Compact    []string
+	Plural     []string
+	Currencies []string
 }
 
-func (b *curBuilder) generate() {
+func newNumberLocale(loc string, ldml *cldr.LDML, plurals map[string][]string) numberLocale {
+	nl := numberLocale{
+		Locale:  loc,
+		Symbols: symbols(ldml.Numbers),
+		Plural:  plurals[loc],
+	}
+	if nl.Plural == nil {
+		nl.Plural = plurals[strings.Split(loc, "_")[0]]
+	}
 
-	var nameData = bytes.Buffer{}
-	var nameIDX []uint16
-	for _, cur := range b.data.Numbers.Currencies.Currency {
-		var d string
-		if len(cur.DisplayName) > 0 {
-			d = cur.DisplayName[0].Data()
+	for _, df := range ldml.Numbers.DecimalFormats {
+		if df.NumberSystem != numberSystem {
+			continue
+		}
+		for _, dfl := range df.DecimalFormatLength {
+			for _, f := range dfl.DecimalFormat {
+				for _, p := range f.Pattern {
+					switch dfl.Type {
+					case "":
+						nl.Decimal = strconv.Quote(p.Data())
+					case "short":
+						if _, err := strconv.ParseInt(p.Type, 10, 64); err != nil {
+							continue // does not fit into CompactPattern.Type
+						}
+						nl.Compact = append(nl.Compact, fmt.Sprintf("{Type: %s, Count: %q, Pattern: %q}", p.Type, p.Count, p.Data()))
+					}
+				}
+			}
+		}
+	}
+	for _, pf := range ldml.Numbers.PercentFormats {
+		if pf.NumberSystem != numberSystem {
+			continue
+		}
+		for _, pfl := range pf.PercentFormatLength {
+			for _, f := range pfl.PercentFormat {
+				for _, p := range f.Pattern {
+					nl.Percent = strconv.Quote(p.Data())
+				}
+			}
+		}
+	}
+	for _, sf := range ldml.Numbers.ScientificFormats {
+		if sf.NumberSystem != numberSystem {
+			continue
+		}
+		for _, sfl := range sf.ScientificFormatLength {
+			for _, f := range sfl.ScientificFormat {
+				for _, p := range f.Pattern {
+					nl.Scientific = strconv.Quote(p.Data())
+				}
+			}
 		}
-		nameData.WriteString(d)
-		nameIDX = append(nameIDX, uint16(nameData.Len()))
 	}
-	nameIDX = append(nameIDX, uint16(nameData.Len()))
+	for _, cf := range ldml.Numbers.CurrencyFormats {
+		if cf.NumberSystem != numberSystem {
+			continue
+		}
+		for _, cfl := range cf.CurrencyFormatLength {
+			if cfl.Type != "" {
+				continue // short currency formats are not supported
+			}
+			for _, f := range cfl.CurrencyFormat {
+				for _, p := range f.Pattern {
+					switch f.Type {
+					case "standard":
+						nl.Currency = strconv.Quote(p.Data())
+					case "accounting":
+						nl.Accounting = strconv.Quote(p.Data())
+					}
+				}
+			}
+		}
+	}
+	if nl.Accounting == "" {
+		nl.Accounting = nl.Currency
+	}
+	if ldml.Numbers.Currencies != nil {
+		for _, cur := range ldml.Numbers.Currencies.Currency {
+			if currencyEnabled(cur.Type) {
+				nl.Currencies = append(nl.Currencies, currencyLocale(cur.Type, cur.Symbol, cur.DisplayName))
+			}
+		}
+	}
+	return nl
+}
+
+// legalTender contains the ISO codes of all currencies in legal tender.
+var legalTender = func() map[string]bool {
+	lt := make(map[string]bool)
+	for _, ru := range currency.AllRegionsWithUnit() {
+		lt[ru.Unit.String()] = true
+	}
+	return lt
+}()
 
-	var codeData = bytes.Buffer{}
-	for _, cur := range b.data.Numbers.Currencies.Currency {
-		if len(cur.Type) != 3 {
-			panic(fmt.Errorf("Expecting 3 character long currency code: %v\n", cur))
+// currencyEnabled reports if the currency gets generated. Without
+// configured currencies all currencies in legal tender get generated.
+func currencyEnabled(iso string) bool {
+	if len(codegen.ConfigLocalization.EnabledCurrency) == 0 {
+		return legalTender[iso]
+	}
+	return codegen.ConfigLocalization.EnabledCurrency.Include(iso)
+}
+
+// symbols generates the i18n.Symbols literal.
+func symbols(n *cldr.Numbers) string {
+	for _, s := range n.Symbols {
+		if s.NumberSystem != numberSystem {
+			continue
+		}
+		first := func(cs ...*cldr.Common) string {
+			if len(cs) == 0 {
+				return `0`
+			}
+			// take the last rune which is not a bidi mark because the minus
+			// and percent sign can be wrapped in left-to-right marks
+			d := cs[0].Data()
+			for len(d) > 0 {
+				r, size := utf8.DecodeLastRuneInString(d)
+				if !unicode.Is(unicode.Cf, r) {
+					return strconv.QuoteRune(r)
+				}
+				d = d[:len(d)-size]
+			}
+			return `0`
 		}
-		codeData.WriteString(cur.Type)
+		nan := "NaN"
+		if len(s.Nan) > 0 {
+			nan = s.Nan[0].Data()
+		}
+		return fmt.Sprintf(
+			"Symbols{Decimal: %s, Group: %s, List: %s, PercentSign: %s, CurrencySign: '¤', PlusSign: %s, MinusSign: %s, Exponential: %s, SuperscriptingExponent: %s, PerMille: %s, Infinity: %s, Nan: []byte(%q)}",
+			first(commons(s.Decimal)...), first(commons(s.Group)...), first(commons(s.List)...),
+			first(commons(s.PercentSign)...), first(commons(s.PlusSign)...), first(commons(s.MinusSign)...),
+			first(commons(s.Exponential)...), first(s.SuperscriptingExponent...), first(commons(s.PerMille)...),
+			first(commons(s.Infinity)...), nan,
+		)
 	}
+	return "Symbols{}"
+}
 
-	var symbolData = bytes.Buffer{}
-	var symbolIDX []uint16
-	for _, cur := range b.data.Numbers.Currencies.Currency {
-		var d string
-		if len(cur.Symbol) > 0 {
-			d = cur.Symbol[0].Data()
+// commons extracts the embedded cldr.Common of the symbol elements.
+func commons(elems interface{}) []*cldr.Common {
+	var cs []*cldr.Common
+	switch es := elems.(type) {
+	case []*struct {
+		cldr.Common
+		NumberSystem string `xml:"numberSystem,attr"`
+	}:
+		for _, e := range es {
+			cs = append(cs, &e.Common)
 		}
-		symbolData.WriteString(d)
-		symbolIDX = append(symbolIDX, uint16(symbolData.Len()))
 	}
-	symbolIDX = append(symbolIDX, uint16(symbolData.Len()))
+	return cs
+}
 
-	tplData := map[string]interface{}{
-		"Locale":      b.locale,
-		"Codes3":      codeData.String(),
-		"NamesData":   nameData.String(),
-		"NamesIDX":    nameIDX,
-		"SymbolsData": symbolData.String(),
-		"SymbolsIDX":  symbolIDX,
+// currencyLocale generates a map entry of i18n.CurrencyLocale.
+func currencyLocale(iso string, symbols []*cldr.Common, names []*struct {
+	cldr.Common
+	Count string `xml:"count,attr"`
+}) string {
+	var symbol, displayName string
+	for _, s := range symbols {
+		if s.Alt == "" {
+			symbol = s.Data()
+			break
+		}
 	}
+	var counts []string
+	for _, n := range names {
+		if n.Count == "" {
+			displayName = n.Data()
+			continue
+		}
+		counts = append(counts, fmt.Sprintf("%q: %q", n.Count, n.Data()))
+	}
+	sort.Strings(counts)
+	return fmt.Sprintf("%q: {Symbol: %q, DisplayName: %q, Names: map[string]string{%s}}", iso, symbol, displayName, strings.Join(counts, ", "))
+}
 
-	err := template.Must(template.New("tpl_code_unit").Parse(tplCodeUnit)).Execute(b.w, tplData)
-	codegen.LogFatal(err)
+// newPluralRules returns the cardinal plural rules per locale as map
+// entries. The samples after @ get removed.
+func newPluralRules(sd *cldr.SupplementalData) map[string][]string {
+	rules := make(map[string][]string)
+	for _, p := range sd.Plurals {
+		if p.Type != "" && p.Type != "cardinal" {
+			continue
+		}
+		for _, pr := range p.PluralRules {
+			var entries []string
+			for _, r := range pr.PluralRule {
+				rule := r.Data()
+				if i := strings.IndexByte(rule, '@'); i >= 0 {
+					rule = rule[:i]
+				}
+				if rule = strings.TrimSpace(rule); rule != "" {
+					entries = append(entries, fmt.Sprintf("%q: %q", r.Count, rule))
+				}
+			}
+			for _, loc := range strings.Fields(pr.Locales) {
+				rules[loc] = entries
+			}
+		}
+	}
+	return rules
+}
+
+// calendarType only the gregorian calendar is supported by the i18n package.
+const calendarType = "gregorian"
+
+// dateLocale contains the Go literals of an i18n.DateLocale.
+type dateLocale struct {
+	Locale         string
+	Months         string
+	MonthsAbbr     string
+	Days           string
+	DaysAbbr       string
+	AM             string
+	PM             string
+	DateFormats    string
+	TimeFormats    string
+	DateTimeFormat string
+	Relative       []string
+}
+
+// ldmlDays maps the CLDR day types to the index in i18n.DateLocale.Days.
+var ldmlDays = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+
+// formatLengths maps the CLDR format lengths to the i18n constants.
+var formatLengths = map[string]int{"full": 0, "long": 1, "medium": 2, "short": 3}
+
+func newDateLocale(loc string, ldml *cldr.LDML) dateLocale {
+	dl := dateLocale{
+		Locale:         loc,
+		AM:             `""`,
+		PM:             `""`,
+		DateTimeFormat: `"{1} {0}"`,
+	}
+	var months, monthsAbbr [12]string
+	var days, daysAbbr [7]string
+	var dateFormats, timeFormats [4]string
+
+	if ldml.Dates == nil || ldml.Dates.Calendars == nil {
+		return dl
+	}
+	for _, cal := range ldml.Dates.Calendars.Calendar {
+		if cal.Type != calendarType {
+			continue
+		}
+		if cal.Months != nil {
+			for _, mc := range cal.Months.MonthContext {
+				if mc.Type != "format" {
+					continue
+				}
+				for _, mw := range mc.MonthWidth {
+					for _, m := range mw.Month {
+						i, err := strconv.Atoi(m.Type)
+						if err != nil || i < 1 || i > 12 || m.Yeartype != "" {
+							continue
+						}
+						switch mw.Type {
+						case "wide":
+							months[i-1] = m.Data()
+						case "abbreviated":
+							monthsAbbr[i-1] = m.Data()
+						}
+					}
+				}
+			}
+		}
+		if cal.Days != nil {
+			for _, dc := range cal.Days.DayContext {
+				if dc.Type != "format" {
+					continue
+				}
+				for _, dw := range dc.DayWidth {
+					for _, d := range dw.Day {
+						i, ok := ldmlDays[d.Type]
+						if !ok {
+							continue
+						}
+						switch dw.Type {
+						case "wide":
+							days[i] = d.Data()
+						case "abbreviated":
+							daysAbbr[i] = d.Data()
+						}
+					}
+				}
+			}
+		}
+		if cal.DayPeriods != nil {
+			for _, dpc := range cal.DayPeriods.DayPeriodContext {
+				for _, dpw := range dpc.DayPeriodWidth {
+					if dpc.Type != "format" || dpw.Type != "abbreviated" {
+						continue
+					}
+					for _, dp := range dpw.DayPeriod {
+						switch {
+						case dp.Type == "am" && dp.Alt == "":
+							dl.AM = strconv.Quote(dp.Data())
+						case dp.Type == "pm" && dp.Alt == "":
+							dl.PM = strconv.Quote(dp.Data())
+						}
+					}
+				}
+			}
+		}
+		if cal.DateFormats != nil {
+			for _, dfl := range cal.DateFormats.DateFormatLength {
+				for _, df := range dfl.DateFormat {
+					for _, p := range df.Pattern {
+						if i, ok := formatLengths[dfl.Type]; ok && p.Alt == "" {
+							dateFormats[i] = p.Data()
+						}
+					}
+				}
+			}
+		}
+		if cal.TimeFormats != nil {
+			for _, tfl := range cal.TimeFormats.TimeFormatLength {
+				for _, tf := range tfl.TimeFormat {
+					for _, p := range tf.Pattern {
+						if i, ok := formatLengths[tfl.Type]; ok && p.Alt == "" {
+							timeFormats[i] = p.Data()
+						}
+					}
+				}
+			}
+		}
+		if cal.DateTimeFormats != nil {
+			for _, dtfl := range cal.DateTimeFormats.DateTimeFormatLength {
+				if dtfl.Type != "medium" {
+					continue
+				}
+				for _, dtf := range dtfl.DateTimeFormat {
+					for _, p := range dtf.Pattern {
+						dl.DateTimeFormat = strconv.Quote(p.Data())
+					}
+				}
+			}
+		}
+	}
+
+	dl.Months = stringArray(months[:])
+	dl.MonthsAbbr = stringArray(monthsAbbr[:])
+	dl.Days = stringArray(days[:])
+	dl.DaysAbbr = stringArray(daysAbbr[:])
+	dl.DateFormats = stringArray(dateFormats[:])
+	dl.TimeFormats = stringArray(timeFormats[:])
+	dl.Relative = relativeTimes(ldml)
+	return dl
+}
+
+// relativeTimes generates the map entries of i18n.RelativeTime for the units
+// second to year.
+func relativeTimes(ldml *cldr.LDML) []string {
+	if ldml.Dates.Fields == nil {
+		return nil
+	}
+	var entries []string
+	for _, f := range ldml.Dates.Fields.Field {
+		switch f.Type {
+		case "second", "minute", "hour", "day", "week", "month", "year":
+		default:
+			continue
+		}
+		var future, past []string
+		for _, rt := range f.RelativeTime {
+			for _, p := range rt.RelativeTimePattern {
+				e := fmt.Sprintf("%q: %q", p.Count, p.Data())
+				switch rt.Type {
+				case "future":
+					future = append(future, e)
+				case "past":
+					past = append(past, e)
+				}
+			}
+		}
+		if len(future) == 0 && len(past) == 0 {
+			continue
+		}
+		sort.Strings(future)
+		sort.Strings(past)
+		entries = append(entries, fmt.Sprintf("%q: {Future: map[string]string{%s}, Past: map[string]string{%s}}",
+			f.Type, strings.Join(future, ", "), strings.Join(past, ", ")))
+	}
+	return entries
+}
+
+// stringArray generates a Go array literal like [7]string{"a", "b"}.
+func stringArray(ss []string) string {
+	qs := make([]string, len(ss))
+	for i, s := range ss {
+		qs[i] = strconv.Quote(s)
+	}
+	return fmt.Sprintf("[%d]string{%s}", len(ss), strings.Join(qs, ", "))
 }
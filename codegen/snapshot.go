@@ -0,0 +1,299 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/juju/errgo"
+)
+
+// EnvSnapshot is the name of the environment variable which contains the
+// path to a JSON schema snapshot. The snapshot gets used if no DSN has been
+// set.
+const EnvSnapshot = "CS_SNAPSHOT"
+
+// SnapshotFile default schema snapshot of a Magento 2 database. The Magento
+// 1.9 snapshot is in the same folder.
+var SnapshotFile = filepath.Join(string(BasePath), "testdata", "magento-2.0.0-schema.json")
+
+// ReadSnapshotFile reads the JSON schema snapshot from the path in the
+// environment variable CS_SNAPSHOT or if empty from SnapshotFile.
+func ReadSnapshotFile() (*csdb.Snapshot, error) {
+	path := os.Getenv(EnvSnapshot)
+	if path == "" {
+		path = SnapshotFile
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	defer f.Close()
+	s, err := csdb.ReadSnapshot(f)
+	if err != nil {
+		return nil, errgo.Notef(err, "Snapshot %q", path)
+	}
+	return s, nil
+}
+
+// GetTablesSnapshot returns all tables from a snapshot like GetTables does
+// from a database. The optional sql argument can be a LIKE pattern or a
+// SELECT on information_schema whose WHERE clause contains only
+// TABLE_SCHEMA = DATABASE(), TABLE_NAME [NOT] LIKE, TABLE_NAME [NOT] IN, AND,
+// OR and parentheses.
+func GetTablesSnapshot(s *csdb.Snapshot, sql ...string) ([]string, error) {
+	match := func(string) bool { return true }
+	if len(sql) > 0 && sql[0] != "" {
+		if false == dbr.Stmt.IsSelect(sql[0]) {
+			match = likeMatcher(sql[0]).MatchString
+		} else {
+			p, err := newWhereParser(sql[0])
+			if err != nil {
+				return nil, errgo.Mask(err)
+			}
+			if match, err = p.parse(); err != nil {
+				return nil, errgo.Notef(err, "Query: %s", sql[0])
+			}
+		}
+	}
+
+	tableNames := make([]string, 0, 200)
+	for _, t := range s.Tables {
+		if match(t.Name) {
+			tableNames = append(tableNames, t.Name)
+		}
+	}
+	return tableNames, nil
+}
+
+// GetEavValueTablesSnapshot same as GetEavValueTables but reads the tables
+// from a snapshot. A snapshot contains no data so the value_table_prefix of
+// the eav_entity_type table cannot be considered.
+func GetEavValueTablesSnapshot(s *csdb.Snapshot, entityTypeCodes []string) (TypeCodeValueTable, error) {
+	typeCodeTables := make(TypeCodeValueTable, len(entityTypeCodes))
+
+	for _, typeCode := range entityTypeCodes {
+		vtp := typeCode + TableNameSeparator + TableEntityTypeSuffix + TableNameSeparator // e.g. catalog_product_entity_
+
+		tableNames, err := GetTablesSnapshot(s, strings.Replace(vtp, "_", `\_`, -1)+`%`)
+		if err != nil {
+			return nil, errgo.Mask(err)
+		}
+
+		if _, ok := typeCodeTables[typeCode]; !ok {
+			typeCodeTables[typeCode] = make(map[string]string, len(tableNames))
+		}
+		for _, t := range tableNames {
+			if valueSuffix := t[len(vtp):]; TableEntityTypeValueSuffixes.contains(valueSuffix) {
+				typeCodeTables[typeCode][t] = valueSuffix
+			}
+		}
+	}
+	return typeCodeTables, nil
+}
+
+// GetColumnsSnapshot returns all columns of a table from a snapshot. The same
+// columns as in GetColumns gets discarded.
+func GetColumnsSnapshot(s *csdb.Snapshot, table string) (Columns, error) {
+	for _, t := range s.Tables {
+		if t.Name != table {
+			continue
+		}
+		cols := make(Columns, 0, len(t.Columns))
+		for _, c := range t.Columns {
+			if isIgnoredColumn(table, c.Field.String) {
+				continue
+			}
+			cols = append(cols, column{Column: c})
+		}
+		return cols, nil
+	}
+	return nil, errgo.Newf("Table %q not found in snapshot", table)
+}
+
+// likeMatcher converts a MySQL LIKE pattern into an anchored regular
+// expression. A backslash escapes the wildcards % and _.
+func likeMatcher(pattern string) *regexp.Regexp {
+	var buf = make([]byte, 0, len(pattern)+10)
+	buf = append(buf, '^')
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			buf = append(buf, regexp.QuoteMeta(pattern[i:i+1])...)
+		case c == '%':
+			buf = append(buf, ".*"...)
+		case c == '_':
+			buf = append(buf, '.')
+		default:
+			buf = append(buf, regexp.QuoteMeta(pattern[i:i+1])...)
+		}
+	}
+	buf = append(buf, '$')
+	return regexp.MustCompile("(?s)" + string(buf))
+}
+
+var whereTokens = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|[A-Za-z_.]+(?:\(\))?|[(),=]`)
+
+// whereParser evaluates the WHERE clause of the information_schema queries
+// in ConfigTableToStruct against a table name.
+type whereParser struct {
+	tokens []string
+	pos    int
+}
+
+func newWhereParser(query string) (*whereParser, error) {
+	where := strings.Index(strings.ToUpper(query), "WHERE")
+	if where < 0 {
+		return nil, errgo.Newf("Missing WHERE in query: %s", query)
+	}
+	clause := query[where+len("WHERE"):]
+	if gb := strings.Index(strings.ToUpper(clause), "GROUP BY"); gb >= 0 {
+		clause = clause[:gb]
+	}
+	return &whereParser{tokens: whereTokens.FindAllString(strings.TrimRight(clause, "; \t\n"), -1)}, nil
+}
+
+func (p *whereParser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	p.pos++
+	return p.tokens[p.pos-1]
+}
+
+func (p *whereParser) accept(tok string) bool {
+	if p.pos < len(p.tokens) && strings.EqualFold(p.tokens[p.pos], tok) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *whereParser) parse() (func(string) bool, error) {
+	m, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = errgo.Newf("Unexpected %q", p.tokens[p.pos])
+	}
+	return m, err
+}
+
+func (p *whereParser) or() (func(string) bool, error) {
+	left, err := p.and()
+	for err == nil && p.accept("OR") {
+		var right func(string) bool
+		if right, err = p.and(); err == nil {
+			l := left
+			left = func(t string) bool { return l(t) || right(t) }
+		}
+	}
+	return left, err
+}
+
+func (p *whereParser) and() (func(string) bool, error) {
+	left, err := p.term()
+	for err == nil && p.accept("AND") {
+		var right func(string) bool
+		if right, err = p.term(); err == nil {
+			l := left
+			left = func(t string) bool { return l(t) && right(t) }
+		}
+	}
+	return left, err
+}
+
+func (p *whereParser) term() (func(string) bool, error) {
+	switch {
+	case p.accept("("):
+		m, err := p.or()
+		if err == nil && !p.accept(")") {
+			err = errgo.New("Missing closing parenthesis")
+		}
+		return m, err
+	case p.accept("TABLE_SCHEMA"):
+		if !p.accept("=") || !p.accept("DATABASE()") {
+			return nil, errgo.New("Only TABLE_SCHEMA = DATABASE() is supported")
+		}
+		return func(string) bool { return true }, nil
+	case p.accept("TABLE_NAME"):
+		not := p.accept("NOT")
+		var m func(string) bool
+		switch {
+		case p.accept("LIKE"):
+			lit, err := p.literal()
+			if err != nil {
+				return nil, errgo.Mask(err)
+			}
+			m = likeMatcher(lit).MatchString
+		case p.accept("IN"):
+			names, err := p.literalList()
+			if err != nil {
+				return nil, errgo.Mask(err)
+			}
+			m = func(t string) bool { return isInList(names, t) }
+		default:
+			return nil, errgo.New("Only TABLE_NAME [NOT] LIKE and [NOT] IN are supported")
+		}
+		if not {
+			return func(t string) bool { return !m(t) }, nil
+		}
+		return m, nil
+	}
+	return nil, errgo.Newf("Unsupported condition at %q", p.next())
+}
+
+// literal returns the content of a quoted string. Backslashes are kept for
+// the LIKE escapes.
+func (p *whereParser) literal() (string, error) {
+	tok := p.next()
+	if len(tok) < 2 || tok[0] != '\'' {
+		return "", errgo.Newf("Expecting a string but got %q", tok)
+	}
+	return strings.Replace(tok[1:len(tok)-1], "''", "'", -1), nil
+}
+
+func (p *whereParser) literalList() ([]string, error) {
+	if !p.accept("(") {
+		return nil, errgo.New("Expecting a list")
+	}
+	var list []string
+	for {
+		lit, err := p.literal()
+		if err != nil {
+			return nil, errgo.Mask(err)
+		}
+		list = append(list, lit)
+		if p.accept(")") {
+			return list, nil
+		}
+		if !p.accept(",") {
+			return nil, errgo.New("Expecting a comma in list")
+		}
+	}
+}
+
+func isInList(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
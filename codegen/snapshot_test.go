@@ -0,0 +1,115 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustReadSnapshot(t *testing.T, version string) *csdb.Snapshot {
+	f, err := os.Open(filepath.Join("..", "testdata", version+"-schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	s, err := csdb.ReadSnapshot(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestGetTablesSnapshot(t *testing.T) {
+	s := mustReadSnapshot(t, "magento-2.0.0")
+
+	all, err := GetTablesSnapshot(s)
+	assert.NoError(t, err)
+	assert.Len(t, all, len(s.Tables))
+
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{"{{tableprefix}}admin_user", []string{"admin_user"}},
+		{"{{tableprefix}}store%", []string{"store", "store_group", "store_website"}},
+		{"{{tableprefix}}directory\\_country%", []string{"directory_country", "directory_country_format", "directory_country_region", "directory_country_region_name"}},
+		{ConfigTableToStruct["store"].SQLQuery, []string{"store", "store_group", "store_website"}},
+		{ConfigTableToStruct["authorization"].SQLQuery, []string{"authorization_role", "authorization_rule"}},
+		{ConfigTableToStruct["eav"].GenericsWhiteList, []string{"eav_entity_type"}},
+		{`SELECT TABLE_NAME FROM information_schema.COLUMNS WHERE TABLE_NAME NOT IN ('store') AND
+			(TABLE_NAME LIKE 'store%' OR TABLE_NAME = 'x') GROUP BY TABLE_NAME;`, nil},
+	}
+	for i, test := range tests {
+		have, err := GetTablesSnapshot(s, ReplaceTablePrefix(test.sql))
+		if test.want == nil {
+			assert.Error(t, err, "Index %d", i)
+			continue
+		}
+		assert.NoError(t, err, "Index %d", i)
+		assert.Exactly(t, test.want, have, "Index %d", i)
+	}
+
+	catalog, err := GetTablesSnapshot(s, ReplaceTablePrefix(ConfigTableToStruct["catalog"].SQLQuery))
+	assert.NoError(t, err)
+	assert.Contains(t, catalog, "catalog_product_entity")
+	assert.Contains(t, catalog, "catalog_product_entity_varchar")
+	for _, table := range catalog {
+		assert.NotContains(t, table, "bundle")
+		assert.NotContains(t, table, "_flat_")
+	}
+	assert.NotContains(t, catalog, "catalogsearch_fulltext_scope", "catalog\\_ must not match catalogsearch")
+}
+
+func TestGetEavValueTablesSnapshot(t *testing.T) {
+	s := mustReadSnapshot(t, "magento-1.9.2.1")
+	tcvt, err := GetEavValueTablesSnapshot(s, []string{"catalog_category", "customer"})
+	assert.NoError(t, err)
+	assert.Exactly(t, TypeCodeValueTable{
+		"catalog_category": {
+			"catalog_category_entity_datetime": "datetime",
+			"catalog_category_entity_decimal":  "decimal",
+			"catalog_category_entity_int":      "int",
+			"catalog_category_entity_text":     "text",
+			"catalog_category_entity_varchar":  "varchar",
+		},
+		"customer": {
+			"customer_entity_datetime": "datetime",
+			"customer_entity_decimal":  "decimal",
+			"customer_entity_int":      "int",
+			"customer_entity_text":     "text",
+			"customer_entity_varchar":  "varchar",
+		},
+	}, tcvt)
+}
+
+func TestGetColumnsSnapshot(t *testing.T) {
+	s := mustReadSnapshot(t, "magento-1.9.2.1")
+
+	cols, err := GetColumnsSnapshot(s, "catalog_product_entity")
+	assert.NoError(t, err)
+	assert.False(t, cols.GetByName("entity_type_id").Field.Valid, "entity_type_id must be ignored")
+	assert.Exactly(t, []string{"entity_id"}, cols.GetFieldNames(true))
+	assert.NoError(t, cols.MapSQLToGoDBRType())
+	assert.Exactly(t, "EntityID", cols.GetByName("entity_id").GoName)
+	assert.Exactly(t, "int64", cols.GetByName("entity_id").GoType)
+
+	_, err = GetColumnsSnapshot(s, "not_existent")
+	assert.EqualError(t, err, `Table "not_existent" not found in snapshot`)
+}
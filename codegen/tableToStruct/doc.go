@@ -12,5 +12,15 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// package main generates Go structs, slices and function receivers from SQL tables.
+/*
+package main generates Go structs, slices and function receivers from SQL tables.
+
+The tables and columns get read from the database in the environment
+variable CS_DSN. If CS_DSN is not set the generator reads the JSON schema
+snapshot from the file in the environment variable CS_SNAPSHOT, by default
+testdata/magento-2.0.0-schema.json. The snapshots in testdata get created
+with csdb.ReadSnapshotSQL from the mysqldump schema files. A snapshot
+contains no data so the value_table_prefix of eav_entity_type cannot be
+considered.
+*/
 package main
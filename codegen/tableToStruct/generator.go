@@ -32,11 +32,12 @@ import (
 	"github.com/corestoreio/csfw/storage/dbr"
 	"github.com/corestoreio/csfw/util"
 	"github.com/corestoreio/csfw/util/log"
+	"github.com/juju/errgo"
 )
 
 type generator struct {
 	tts             codegen.TableToStruct
-	dbrConn         *dbr.Connection
+	schema          schema
 	outfile         *os.File
 	tables          []string         // all available tables for which we should at least generate a type definition
 	whiteListTables util.StringSlice // table name in this slice is allowed for generic functions
@@ -48,11 +49,11 @@ type generator struct {
 	isMagento2         bool
 }
 
-func newGenerator(tts codegen.TableToStruct, dbrConn *dbr.Connection, wg *sync.WaitGroup) *generator {
+func newGenerator(tts codegen.TableToStruct, s schema, wg *sync.WaitGroup) *generator {
 	wg.Add(1)
 	return &generator{
 		tts:                tts,
-		dbrConn:            dbrConn,
+		schema:             s,
 		wg:                 wg,
 		existingMethodSets: newDuplicateChecker(),
 	}
@@ -145,11 +146,11 @@ func (g *generator) appendToFile(tpl string, data interface{}, addFM template.Fu
 func (g *generator) initTables() {
 	defer log.WhenDone(PkgLog).Info("Stats", "Package", g.tts.Package, "Step", "InitTables")
 	var err error
-	g.tables, err = codegen.GetTables(g.dbrConn.NewSession(), codegen.ReplaceTablePrefix(g.tts.SQLQuery))
+	g.tables, err = g.schema.tables(codegen.ReplaceTablePrefix(g.tts.SQLQuery))
 	codegen.LogFatal(err)
 
 	if len(g.tts.EntityTypeCodes) > 0 && g.tts.EntityTypeCodes[0] != "" {
-		g.eavValueTables, err = codegen.GetEavValueTables(g.dbrConn, g.tts.EntityTypeCodes)
+		g.eavValueTables, err = g.schema.eavValueTables(g.tts.EntityTypeCodes)
 		codegen.LogFatal(err)
 
 		for _, vTables := range g.eavValueTables {
@@ -170,7 +171,7 @@ func (g *generator) initTables() {
 		return
 	}
 
-	g.whiteListTables, err = codegen.GetTables(g.dbrConn.NewSession(), codegen.ReplaceTablePrefix(g.tts.GenericsWhiteList))
+	g.whiteListTables, err = g.schema.tables(codegen.ReplaceTablePrefix(g.tts.GenericsWhiteList))
 	codegen.LogFatal(err)
 }
 
@@ -202,6 +203,23 @@ func (g *generator) runHeader() {
 
 func (g *generator) runTable() {
 	defer log.WhenDone(PkgLog).Info("Stats", "Package", g.tts.Package, "Step", "RunTable")
+
+	for _, table := range g.tables {
+		code, err := g.tableCode(table)
+		if err != nil {
+			fmt.Printf("\n%s\n", code)
+			codegen.LogFatal(err)
+		}
+		if _, err := g.outfile.Write(code); err != nil {
+			codegen.LogFatal(err)
+		}
+		codegen.LogFatal(g.outfile.Sync()) // flush immediately to disk to prevent a race condition
+	}
+}
+
+// tableCode renders the type definitions and the generic functions of a
+// table and returns the formatted code.
+func (g *generator) tableCode(table string) ([]byte, error) {
 	type OneTable struct {
 		Package          string
 		Tick             string
@@ -216,45 +234,47 @@ func (g *generator) runTable() {
 		FindByPk         string
 	}
 
-	for _, table := range g.tables {
-
-		columns, err := codegen.GetColumns(g.dbrConn.DB, table)
-		codegen.LogFatal(err)
-		codegen.LogFatal(columns.MapSQLToGoDBRType())
-
-		name := g.getConsistentName(table)
-		data := OneTable{
-			Package:   g.tts.Package,
-			Tick:      "`",
-			Name:      name,
-			TableName: g.getMagento2TableName(table), // original table name!
-			Struct:    TypePrefix + name,             // getTableConstantName
-			Slice:     TypePrefix + name + "Slice",   // getTableConstantName
-			Table:     table,
-			GoColumns: columns,
-			Columns:   columns.CopyToCSDB(),
-		}
+	columns, err := g.schema.columns(table)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	if err := columns.MapSQLToGoDBRType(); err != nil {
+		return nil, errgo.Mask(err)
+	}
 
-		if data.Columns.PrimaryKeys().Len() > 0 {
-			data.FindByPk = "FindBy" + util.UnderscoreCamelize(data.Columns.PrimaryKeys().JoinFields("_"))
-		}
+	name := g.getConsistentName(table)
+	data := OneTable{
+		Package:   g.tts.Package,
+		Tick:      "`",
+		Name:      name,
+		TableName: g.getMagento2TableName(table), // original table name!
+		Struct:    TypePrefix + name,             // getTableConstantName
+		Slice:     TypePrefix + name + "Slice",   // getTableConstantName
+		Table:     table,
+		GoColumns: columns,
+		Columns:   columns.CopyToCSDB(),
+	}
 
-		tplFuncs := template.FuncMap{
-			"typePrefix": func(name string) string {
-				// if the method already exists in package then add the prefix parent
-				// to avoid duplicate function names.
-				search := data.Slice + name
-				if g.existingMethodSets.has(search) {
-					return MethodRecvPrefix + name
-				}
-				return name
-			},
-			"findBy":  findBy,
-			"dbrType": dbrType,
-		}
+	if data.Columns.PrimaryKeys().Len() > 0 {
+		data.FindByPk = "FindBy" + util.UnderscoreCamelize(data.Columns.PrimaryKeys().JoinFields("_"))
+	}
 
-		g.appendToFile(g.getGenericTemplate(table), data, tplFuncs)
+	tplFuncs := template.FuncMap{
+		"typePrefix": func(name string) string {
+			// if the method already exists in package then add the prefix parent
+			// to avoid duplicate function names.
+			search := data.Slice + name
+			if g.existingMethodSets.has(search) {
+				return MethodRecvPrefix + name
+			}
+			return name
+		},
+		"findBy":    findBy,
+		"dbrType":   dbrType,
+		"dbrColumn": dbrColumn,
 	}
+
+	return codegen.GenerateCode(g.tts.Package, g.getGenericTemplate(table), data, tplFuncs)
 }
 
 func (g *generator) getGenericTemplate(tableName string) string {
@@ -0,0 +1,108 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/corestoreio/csfw/codegen"
+	"github.com/corestoreio/csfw/codegen/tableToStruct/tpl"
+	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/corestoreio/csfw/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var tableWebsite = csdb.NewTable(
+	"store_website",
+	csdb.Column{Field: dbr.NewNullString("website_id"), Type: dbr.NewNullString("smallint(5) unsigned"), Null: dbr.NewNullString("NO"), Key: dbr.NewNullString("PRI"), Default: dbr.NewNullString(nil), Extra: dbr.NewNullString("auto_increment")},
+	csdb.Column{Field: dbr.NewNullString("code"), Type: dbr.NewNullString("varchar(32)"), Null: dbr.NewNullString("YES"), Key: dbr.NewNullString("UNI"), Default: dbr.NewNullString(nil), Extra: dbr.NewNullString("")},
+	csdb.Column{Field: dbr.NewNullString("sort_order"), Type: dbr.NewNullString("smallint(5) unsigned"), Null: dbr.NewNullString("NO"), Key: dbr.NewNullString(""), Default: dbr.NewNullString("0"), Extra: dbr.NewNullString("")},
+	csdb.Column{Field: dbr.NewNullString("is_default"), Type: dbr.NewNullString("smallint(5) unsigned"), Null: dbr.NewNullString("YES"), Key: dbr.NewNullString(""), Default: dbr.NewNullString("0"), Extra: dbr.NewNullString("")},
+)
+
+func newTestGenerator(existingMethods ...string) *generator {
+	s := &csdb.Snapshot{
+		Version: csdb.SnapshotVersion,
+		Tables:  []csdb.SnapshotTable{{Name: tableWebsite.Name, Columns: tableWebsite.Columns}},
+	}
+	g := newGenerator(codegen.TableToStruct{
+		Package:           "store",
+		GenericsFunctions: tpl.OptSQL,
+	}, snapshotSchema{s}, &sync.WaitGroup{})
+	g.whiteListTables = util.StringSlice{tableWebsite.Name}
+	for _, m := range existingMethods {
+		g.existingMethodSets.add(m)
+	}
+	return g
+}
+
+func TestGeneratorTableCode(t *testing.T) {
+	code, err := newTestGenerator().tableCode(tableWebsite.Name)
+	assert.NoError(t, err, "%s", code)
+
+	assert.Contains(t, string(code), `// WebsiteColumns contains the typed columns of DB table store_website
+// prefixed with the alias csdb.MainTable to be used in WHERE and ORDER BY.
+// Generated via tableToStruct.
+var WebsiteColumns = struct {
+	WebsiteID dbr.ColumnInt64
+	Code      dbr.ColumnString
+	SortOrder dbr.ColumnInt64
+	IsDefault dbr.ColumnBool
+}{
+	WebsiteID: dbr.NewColumnInt64(csdb.MainTable, "website_id"),
+	Code:      dbr.NewColumnString(csdb.MainTable, "code"),
+	SortOrder: dbr.NewColumnInt64(csdb.MainTable, "sort_order"),
+	IsDefault: dbr.NewColumnBool(csdb.MainTable, "is_default"),
+}
+`)
+
+	assert.Contains(t, string(code), `// SQLSelect fills this slice with data from the database.
+// Generated via tableToStruct.
+func (s *TableWebsiteSlice) SQLSelect(dbrSess dbr.SessionRunner, cbs ...dbr.SelectCb) (int, error) {
+	return csdb.LoadSlice(dbrSess, TableCollection, TableIndexWebsite, &(*s), cbs...)
+}
+
+// SelectWhere fills this slice with all rows matching the
+// conditions. Use the typed columns of WebsiteColumns to create them.
+// Generated via tableToStruct.
+func (s *TableWebsiteSlice) SelectWhere(dbrSess dbr.SessionRunner, conds ...dbr.ConditionArg) (int, error) {
+	return s.SQLSelect(dbrSess, func(sb *dbr.SelectBuilder) *dbr.SelectBuilder {
+		return sb.Where(conds...)
+	})
+}
+`)
+	assert.NotContains(t, string(code), "FindBy", "Only the SQL generics have been enabled")
+}
+
+func TestGeneratorTableCodeExistingMethod(t *testing.T) {
+	code, err := newTestGenerator("TableWebsiteSliceSQLSelect").tableCode(tableWebsite.Name)
+	assert.NoError(t, err, "%s", code)
+	assert.Contains(t, string(code), "func (s *TableWebsiteSlice) parentSQLSelect(dbrSess dbr.SessionRunner, cbs ...dbr.SelectCb) (int, error) {")
+	assert.Contains(t, string(code), "func (s *TableWebsiteSlice) SelectWhere(dbrSess dbr.SessionRunner, conds ...dbr.ConditionArg) (int, error) {\n\treturn s.SQLSelect(", "SelectWhere must use the SQLSelect of the package")
+}
+
+func TestGeneratorTableCodeNotWhiteListed(t *testing.T) {
+	g := newTestGenerator()
+	g.whiteListTables = nil
+	code, err := g.tableCode(tableWebsite.Name)
+	assert.NoError(t, err, "%s", code)
+	assert.Contains(t, string(code), "var WebsiteColumns = struct {")
+	assert.NotContains(t, string(code), "SelectWhere")
+
+	_, err = g.tableCode("not_existent")
+	assert.EqualError(t, err, `Table "not_existent" not found in snapshot`)
+}
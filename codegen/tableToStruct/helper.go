@@ -23,7 +23,6 @@ import (
 	"github.com/corestoreio/csfw/codegen"
 	"github.com/corestoreio/csfw/codegen/tableToStruct/codecgen"
 	"github.com/corestoreio/csfw/storage/csdb"
-	"github.com/corestoreio/csfw/storage/dbr"
 	"github.com/corestoreio/csfw/util"
 	"github.com/corestoreio/csfw/util/log"
 )
@@ -105,9 +104,9 @@ func isDuplicate(sl []string, st string) bool {
 	return false
 }
 
-func detectMagentoVersion(dbrSess dbr.SessionRunner) (MageOne, MageTwo bool) {
+func detectMagentoVersion(s schema) (MageOne, MageTwo bool) {
 	defer log.WhenDone(PkgLog).Info("Stats", "Package", "DetectMagentoVersion")
-	allTables, err := codegen.GetTables(dbrSess)
+	allTables, err := s.tables()
 	codegen.LogFatal(err)
 	MageOne, MageTwo = util.MagentoVersion(codegen.TablePrefix, allTables)
 
@@ -143,3 +142,22 @@ func dbrType(c csdb.Column) string {
 	}
 	return ""
 }
+
+// dbrColumn is a template function used in runTable() and returns the name
+// of the typed dbr column, e.g. ColumnInt64 for dbr.ColumnInt64.
+func dbrColumn(c csdb.Column) string {
+	switch {
+	// order of the c.Is* functions matters ... :-|
+	case c.IsBool():
+		return "ColumnBool"
+	case c.IsString():
+		return "ColumnString"
+	case c.IsMoney(), c.IsFloat():
+		return "ColumnFloat64"
+	case c.IsInt():
+		return "ColumnInt64"
+	case c.IsDate():
+		return "ColumnTime"
+	}
+	return "Column"
+}
@@ -18,7 +18,6 @@ import (
 	"sync"
 
 	"github.com/corestoreio/csfw/codegen"
-	"github.com/corestoreio/csfw/storage/csdb"
 	"github.com/corestoreio/csfw/util/log"
 )
 
@@ -35,15 +34,15 @@ func init() {
 
 func main() {
 	defer log.WhenDone(PkgLog).Info("Stats")
-	dbc, err := csdb.Connect()
+	s, closeSchema, err := newSchema()
 	codegen.LogFatal(err)
-	defer dbc.Close()
+	defer closeSchema()
 	var wg sync.WaitGroup
 
-	mageV1, mageV2 := detectMagentoVersion(dbc.NewSession())
+	mageV1, mageV2 := detectMagentoVersion(s)
 
 	for _, tStruct := range codegen.ConfigTableToStruct {
-		go newGenerator(tStruct, dbc, &wg).setMagentoVersion(mageV1, mageV2).run()
+		go newGenerator(tStruct, s, &wg).setMagentoVersion(mageV1, mageV2).run()
 	}
 
 	wg.Wait()
@@ -0,0 +1,83 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/corestoreio/csfw/codegen"
+	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/juju/errgo"
+)
+
+// schema provides the tables and columns for the generator either from a
+// database connection or from a schema snapshot.
+type schema interface {
+	tables(sql ...string) ([]string, error)
+	columns(table string) (codegen.Columns, error)
+	eavValueTables(entityTypeCodes []string) (codegen.TypeCodeValueTable, error)
+}
+
+// dbSchema reads the schema from the database behind CS_DSN.
+type dbSchema struct {
+	*dbr.Connection
+}
+
+func (s dbSchema) tables(sql ...string) ([]string, error) {
+	return codegen.GetTables(s.NewSession(), sql...)
+}
+
+func (s dbSchema) columns(table string) (codegen.Columns, error) {
+	return codegen.GetColumns(s.DB, table)
+}
+
+func (s dbSchema) eavValueTables(entityTypeCodes []string) (codegen.TypeCodeValueTable, error) {
+	return codegen.GetEavValueTables(s.Connection, entityTypeCodes)
+}
+
+// snapshotSchema reads the schema from a JSON snapshot when no DSN has been
+// set.
+type snapshotSchema struct {
+	*csdb.Snapshot
+}
+
+func (s snapshotSchema) tables(sql ...string) ([]string, error) {
+	return codegen.GetTablesSnapshot(s.Snapshot, sql...)
+}
+
+func (s snapshotSchema) columns(table string) (codegen.Columns, error) {
+	return codegen.GetColumnsSnapshot(s.Snapshot, table)
+}
+
+func (s snapshotSchema) eavValueTables(entityTypeCodes []string) (codegen.TypeCodeValueTable, error) {
+	return codegen.GetEavValueTablesSnapshot(s.Snapshot, entityTypeCodes)
+}
+
+// newSchema connects to the database if the DSN has been set otherwise it
+// loads the snapshot from codegen.ReadSnapshotFile. The returned function
+// closes the connection.
+func newSchema() (schema, func() error, error) {
+	if _, err := csdb.GetDSN(); err != nil {
+		s, err := codegen.ReadSnapshotFile()
+		if err != nil {
+			return nil, nil, errgo.Mask(err)
+		}
+		return snapshotSchema{s}, func() error { return nil }, nil
+	}
+	dbc, err := csdb.Connect()
+	if err != nil {
+		return nil, nil, errgo.Mask(err)
+	}
+	return dbSchema{dbc}, dbc.Close, nil
+}
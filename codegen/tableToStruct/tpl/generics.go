@@ -26,6 +26,15 @@ type {{.Slice}} []*{{.Struct}}
 type {{.Struct}} struct {
 {{ range .GoColumns }}{{.GoName}} {{.GoType}} {{ $.Tick }}db:"{{.Field.String}}" json:",omitempty"{{ $.Tick }} {{.Comment}}
 {{ end }} }
+
+// {{.Name}}Columns contains the typed columns of DB table {{ .TableName }}
+// prefixed with the alias csdb.MainTable to be used in WHERE and ORDER BY.
+// Generated via tableToStruct.
+var {{.Name}}Columns = struct {
+{{ range .GoColumns }}{{.GoName}} dbr.{{ dbrColumn .Column }}
+{{ end }} }{
+{{ range .GoColumns }}{{.GoName}}: dbr.New{{ dbrColumn .Column }}(csdb.MainTable, "{{.Field.String}}"),
+{{ end }} }
 `
 
 // Generics defines the available templates
@@ -48,6 +57,15 @@ func (s *{{.Slice}}) {{ typePrefix "SQLSelect" }}(dbrSess dbr.SessionRunner, cbs
 	return csdb.LoadSlice(dbrSess, TableCollection, TableIndex{{.Name}}, &(*s), cbs...)
 }
 
+// {{ typePrefix "SelectWhere" }} fills this slice with all rows matching the
+// conditions. Use the typed columns of {{.Name}}Columns to create them.
+// Generated via tableToStruct.
+func (s *{{.Slice}}) {{ typePrefix "SelectWhere" }}(dbrSess dbr.SessionRunner, conds ...dbr.ConditionArg) (int, error) {
+	return s.SQLSelect(dbrSess, func(sb *dbr.SelectBuilder) *dbr.SelectBuilder {
+		return sb.Where(conds...)
+	})
+}
+
 // {{ typePrefix "SQLInsert" }} inserts all records into the database @todo.
 // Generated via tableToStruct.
 func (s *{{.Slice}}) {{ typePrefix "SQLInsert" }}(dbrSess dbr.SessionRunner, cbs ...dbr.InsertCb) (int, error) {
@@ -72,7 +90,7 @@ const FindBy = `
 // Generated via tableToStruct.
 var ErrIDNotFound{{.Slice}} = csdb.NewError("ID not found in {{.Slice}}")
 
-{{if (.FindByPk) ne ""}}
+{{if ne .FindByPk ""}}
 // {{ typePrefix .FindByPk }} searches the primary keys and returns a
 // *{{.Struct}} if found or an error.
 // Generated via tableToStruct.
@@ -46,4 +46,5 @@ func init(){
     {{ range $k,$v := .Tables }} csdb.AddTableByName(TableIndex{{.Name}}, "{{.TableName}}"),
     {{ end }} )
     // Don't forget to call TableCollection.ReInit(...) in your code to load the column definitions.
-}`
+}
+`
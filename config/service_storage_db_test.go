@@ -22,7 +22,7 @@ import (
 	"strings"
 
 	"github.com/corestoreio/csfw/config"
-	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
 	"github.com/corestoreio/csfw/store/scope"
 	"github.com/corestoreio/csfw/util"
 	"github.com/stretchr/testify/assert"
@@ -33,7 +33,7 @@ func TestDBStorageOneStmt(t *testing.T) {
 	defer debugLogBuf.Reset()
 	defer infoLogBuf.Reset()
 
-	dbc := csdb.MustConnectTest()
+	dbc := csdbtest.MustConnectTest("TestDBStorageOneStmt")
 	defer func() { assert.NoError(t, dbc.Close()) }()
 
 	sdb := config.MustNewDBStorage(dbc.DB).Start()
@@ -81,7 +81,7 @@ func TestDBStorageMultipleStmt(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Test skipped in short mode")
 	}
-	dbc := csdb.MustConnectTest()
+	dbc := csdbtest.MustConnectTest("TestDBStorageMultipleStmt")
 	defer func() { assert.NoError(t, dbc.Close()) }()
 
 	sdb := config.MustNewDBStorage(dbc.DB)
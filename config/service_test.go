@@ -19,12 +19,12 @@ import (
 
 	"github.com/corestoreio/csfw/config"
 	"github.com/corestoreio/csfw/config/element"
-	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
 	"github.com/stretchr/testify/assert"
 )
 
 func init() {
-	dbc := csdb.MustConnectTest()
+	dbc := csdbtest.MustConnectTest("config_service_init")
 	if err := config.TableCollection.Init(dbc.NewSession()); err != nil {
 		panic(err)
 	}
@@ -94,7 +94,7 @@ func TestApplyCoreConfigData(t *testing.T) {
 	defer debugLogBuf.Reset()
 	defer infoLogBuf.Reset()
 
-	dbc := csdb.MustConnectTest()
+	dbc := csdbtest.MustConnectTest("TestApplyCoreConfigData")
 	defer func() { assert.NoError(t, dbc.Close()) }()
 	sess := dbc.NewSession(nil) // nil tricks the NewSession ;-)
 
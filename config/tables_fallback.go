@@ -26,11 +26,13 @@ import (
 
 	"github.com/corestoreio/csfw/storage/csdb"
 	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/corestoreio/csfw/storage/money"
 )
 
 var (
 	_ = (*sort.IntSlice)(nil)
 	_ = (*time.Time)(nil)
+	_ = (*money.Money)(nil)
 )
 
 // TableIndex... is the index to a table. These constants are guaranteed
@@ -61,3 +63,20 @@ type TableCoreConfigData struct {
 	Path     string         `db:"path" json:",omitempty"`      // path varchar(255) NOT NULL  DEFAULT 'general'
 	Value    dbr.NullString `db:"value" json:",omitempty"`     // value text NULL
 }
+
+// CoreConfigDataColumns contains the typed columns of DB table core_config_data
+// prefixed with the alias csdb.MainTable to be used in WHERE and ORDER BY.
+// Generated via tableToStruct.
+var CoreConfigDataColumns = struct {
+	ConfigID dbr.ColumnInt64
+	Scope    dbr.ColumnString
+	ScopeID  dbr.ColumnInt64
+	Path     dbr.ColumnString
+	Value    dbr.ColumnString
+}{
+	ConfigID: dbr.NewColumnInt64(csdb.MainTable, "config_id"),
+	Scope:    dbr.NewColumnString(csdb.MainTable, "scope"),
+	ScopeID:  dbr.NewColumnInt64(csdb.MainTable, "scope_id"),
+	Path:     dbr.NewColumnString(csdb.MainTable, "path"),
+	Value:    dbr.NewColumnString(csdb.MainTable, "value"),
+}
@@ -0,0 +1,71 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Address defines a postal address of a customer or an order.
+type Address struct {
+	Prefix, Firstname, Middlename, Lastname, Suffix string
+	Company                                         string
+	// Street contains up to four lines.
+	Street []string
+	City   string
+	// Region contains the name of a region. If empty the formatter and the
+	// validator use the RegionID to look up the region.
+	Region   string
+	RegionID int64
+	Postcode string
+	// CountryID 2-letter ISO 3166-1 code like DE.
+	CountryID string
+	Telephone string
+	Fax       string
+	VatID     string
+}
+
+// addressStreetLines maximum number of street lines available as template
+// variables street1 to street4.
+const addressStreetLines = 4
+
+// vars returns the template variables of the address like in Magento.
+// street contains all street lines separated by a comma.
+func (a Address) vars() map[string]string {
+	v := map[string]string{
+		"prefix":     a.Prefix,
+		"firstname":  a.Firstname,
+		"middlename": a.Middlename,
+		"lastname":   a.Lastname,
+		"suffix":     a.Suffix,
+		"company":    a.Company,
+		"street":     strings.Join(a.Street, ", "),
+		"city":       a.City,
+		"region":     a.Region,
+		"postcode":   a.Postcode,
+		"country_id": a.CountryID,
+		"telephone":  a.Telephone,
+		"fax":        a.Fax,
+		"vat_id":     a.VatID,
+	}
+	if a.RegionID > 0 {
+		v["region_id"] = strconv.FormatInt(a.RegionID, 10)
+	}
+	for i := 0; i < addressStreetLines && i < len(a.Street); i++ {
+		v["street"+strconv.Itoa(i+1)] = a.Street[i]
+	}
+	return v
+}
@@ -0,0 +1,219 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory
+
+import (
+	"html"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/util"
+	"github.com/juju/errgo"
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// Address format types, equal to the field names of the configuration
+// group customer/address_templates.
+const (
+	AddressFormatText    = "text"
+	AddressFormatOneline = "oneline"
+	AddressFormatHTML    = "html"
+	AddressFormatPDF     = "pdf"
+)
+
+// addressTemplateDefaults contains the default values of the configuration
+// paths customer/address_templates/*.
+var addressTemplateDefaults = map[string]string{
+	AddressFormatText: `{{depend prefix}}{{var prefix}} {{/depend}}{{var firstname}} {{depend middlename}}{{var middlename}} {{/depend}}{{var lastname}}{{depend suffix}} {{var suffix}}{{/depend}}
+{{depend company}}{{var company}}{{/depend}}
+{{if street1}}{{var street1}}
+{{/if}}
+{{depend street2}}{{var street2}}{{/depend}}
+{{depend street3}}{{var street3}}{{/depend}}
+{{depend street4}}{{var street4}}{{/depend}}
+{{if city}}{{var city}},  {{/if}}{{if region}}{{var region}}, {{/if}}{{if postcode}}{{var postcode}}{{/if}}
+{{var country}}
+T: {{var telephone}}
+{{depend fax}}F: {{var fax}}{{/depend}}
+{{depend vat_id}}VAT: {{var vat_id}}{{/depend}}`,
+	AddressFormatOneline: `{{depend prefix}}{{var prefix}} {{/depend}}{{var firstname}} {{depend middlename}}{{var middlename}} {{/depend}}{{var lastname}}{{depend suffix}} {{var suffix}}{{/depend}}, {{var street}}, {{var city}}, {{var region}} {{var postcode}}, {{var country}}`,
+	AddressFormatHTML: `{{depend prefix}}{{var prefix}} {{/depend}}{{var firstname}} {{depend middlename}}{{var middlename}} {{/depend}}{{var lastname}}{{depend suffix}} {{var suffix}}{{/depend}}{{depend firstname}}<br/>{{/depend}}
+{{depend company}}{{var company}}<br />{{/depend}}
+{{if street1}}{{var street1}}<br />{{/if}}
+{{depend street2}}{{var street2}}<br />{{/depend}}
+{{depend street3}}{{var street3}}<br />{{/depend}}
+{{depend street4}}{{var street4}}<br />{{/depend}}
+{{if city}}{{var city}},  {{/if}}{{if region}}{{var region}}, {{/if}}{{if postcode}}{{var postcode}}{{/if}}<br/>
+{{var country}}<br/>
+{{depend telephone}}T: {{var telephone}}{{/depend}}
+{{depend fax}}<br/>F: {{var fax}}{{/depend}}
+{{depend vat_id}}<br/>VAT: {{var vat_id}}{{/depend}}`,
+	AddressFormatPDF: `{{depend prefix}}{{var prefix}} {{/depend}}{{var firstname}} {{depend middlename}}{{var middlename}} {{/depend}}{{var lastname}}{{depend suffix}} {{var suffix}}{{/depend}}|
+{{depend company}}{{var company}}|{{/depend}}
+{{if street1}}{{var street1}}
+{{/if}}
+{{depend street2}}{{var street2}}|{{/depend}}
+{{depend street3}}{{var street3}}|{{/depend}}
+{{depend street4}}{{var street4}}|{{/depend}}
+{{if city}}{{var city}},|{{/if}}
+{{if region}}{{var region}}, {{/if}}{{if postcode}}{{var postcode}}{{/if}}|
+{{var country}}|
+{{depend telephone}}T: {{var telephone}}{{/depend}}|
+{{depend fax}}<br/>F: {{var fax}}{{/depend}}|
+{{depend vat_id}}<br/>VAT: {{var vat_id}}{{/depend}}|`,
+}
+
+// AddressFormatOption can be used as an argument in NewAddressFormatter to
+// configure the formatter.
+type AddressFormatOption func(*AddressFormatter)
+
+// WithAddressTemplate sets the template of a format type for a country.
+// An empty countryID sets the template for all countries without an own
+// template.
+func WithAddressTemplate(countryID, formatType, tpl string) AddressFormatOption {
+	return func(af *AddressFormatter) {
+		at, err := ParseAddressTemplate(tpl)
+		if err != nil {
+			af.lastErrs = append(af.lastErrs, errgo.Notef(err, "Country %q type %q", countryID, formatType))
+			return
+		}
+		af.templates[addressTemplateKey{strings.ToUpper(countryID), formatType}] = at
+	}
+}
+
+// WithAddressConfig reads the templates of all countries from the
+// configuration paths customer/address_templates/* of a scope. Empty or
+// missing values keep the default templates.
+func WithAddressConfig(sg config.ScopedGetter) AddressFormatOption {
+	return func(af *AddressFormatter) {
+		for ft := range addressTemplateDefaults {
+			tpl, err := sg.String("customer/address_templates/" + ft)
+			if err != nil && config.NotKeyNotFoundError(err) {
+				af.lastErrs = append(af.lastErrs, errgo.Notef(err, "Path customer/address_templates/%s", ft))
+				continue
+			}
+			if tpl != "" {
+				WithAddressTemplate("", ft, tpl)(af)
+			}
+		}
+	}
+}
+
+// WithAddressRegions sets the regions to look up the region name by the
+// RegionID of an address.
+func WithAddressRegions(r *Regions) AddressFormatOption {
+	return func(af *AddressFormatter) {
+		af.Regions = r
+	}
+}
+
+// WithAddressLocale sets the language of the country names. Default
+// English.
+func WithAddressLocale(t language.Tag) AddressFormatOption {
+	return func(af *AddressFormatter) {
+		af.countryNamer = display.Regions(t)
+	}
+}
+
+type addressTemplateKey struct {
+	countryID, formatType string
+}
+
+// AddressFormatter renders postal addresses with the template of the
+// country of the address and the format type. Countries without an own
+// template use the default template of the format type. The variable
+// country contains the localized name of the country. AddressFormatter is
+// safe for concurrent use after creation.
+type AddressFormatter struct {
+	// lastErrs a collector. While setting options, errors may occur and will
+	// be accumulated here for later output in the NewAddressFormatter()
+	// function.
+	lastErrs []error
+
+	// Regions optional to look up the region name by its ID.
+	Regions *Regions
+
+	mu           sync.RWMutex
+	templates    map[addressTemplateKey]*AddressTemplate
+	countryNamer display.Namer
+}
+
+// NewAddressFormatter creates a new address formatter with the Magento
+// default templates.
+func NewAddressFormatter(opts ...AddressFormatOption) (*AddressFormatter, error) {
+	af := &AddressFormatter{
+		templates:    make(map[addressTemplateKey]*AddressTemplate),
+		countryNamer: display.Regions(language.English),
+	}
+	for ft, tpl := range addressTemplateDefaults {
+		af.templates[addressTemplateKey{"", ft}] = MustParseAddressTemplate(tpl)
+	}
+	af.Option(opts...)
+	if af.lastErrs != nil {
+		return nil, af
+	}
+	return af, nil
+}
+
+// Error implements the error interface. Returns a string where each error has
+// been separated by a line break.
+func (af *AddressFormatter) Error() string {
+	return util.Errors(af.lastErrs...)
+}
+
+// Option applies optional arguments to the formatter.
+func (af *AddressFormatter) Option(opts ...AddressFormatOption) *AddressFormatter {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+	for _, o := range opts {
+		if o != nil {
+			o(af)
+		}
+	}
+	return af
+}
+
+// Format writes the address in the format type, e.g. AddressFormatText.
+// The HTML format escapes the values of the address.
+func (af *AddressFormatter) Format(w io.Writer, formatType string, a Address) (int, error) {
+	af.mu.RLock()
+	defer af.mu.RUnlock()
+
+	at, ok := af.templates[addressTemplateKey{strings.ToUpper(a.CountryID), formatType}]
+	if !ok {
+		if at, ok = af.templates[addressTemplateKey{"", formatType}]; !ok {
+			return 0, errgo.Newf("Address format type %q not found", formatType)
+		}
+	}
+
+	vars := a.vars()
+	if vars["region"] == "" && a.RegionID > 0 && af.Regions != nil {
+		if rg, ok := af.Regions.ByID(a.RegionID); ok {
+			vars["region"] = rg.Name
+		}
+	}
+	if r, err := language.ParseRegion(a.CountryID); err == nil {
+		vars["country"] = af.countryNamer.Name(r)
+	}
+
+	var escape func(string) string
+	if formatType == AddressFormatHTML {
+		escape = html.EscapeString
+	}
+	return io.WriteString(w, at.Execute(vars, escape))
+}
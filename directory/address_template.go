@@ -0,0 +1,169 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+// AddressTemplate contains a parsed address template in the syntax of the
+// Magento configuration customer/address_templates. Supported directives:
+//
+//	{{var name}}                      value of the variable
+//	{{depend name}}...{{/depend}}     content only if name is not empty
+//	{{if name}}...{{else}}...{{/if}}  content depending on name
+//
+// Directives can be nested.
+type AddressTemplate struct {
+	nodes []addressNode
+}
+
+const (
+	addressNodeText = iota
+	addressNodeVar
+	addressNodeCond
+)
+
+type addressNode struct {
+	kind int
+	// text contains the text or the name of the variable.
+	text     string
+	body     []addressNode
+	elseBody []addressNode
+}
+
+// ParseAddressTemplate parses a Magento address template.
+func ParseAddressTemplate(tpl string) (*AddressTemplate, error) {
+	p := &addressParser{src: tpl}
+	nodes, end, err := p.parse()
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	if end != "" {
+		return nil, errgo.Newf("Unexpected {{%s}} at offset %d", end, p.pos)
+	}
+	return &AddressTemplate{nodes: nodes}, nil
+}
+
+// MustParseAddressTemplate same as ParseAddressTemplate but panics on error.
+func MustParseAddressTemplate(tpl string) *AddressTemplate {
+	at, err := ParseAddressTemplate(tpl)
+	if err != nil {
+		panic(err)
+	}
+	return at
+}
+
+// Execute renders the template with the variables. Each value gets passed
+// through the escape function, if not nil.
+func (at *AddressTemplate) Execute(vars map[string]string, escape func(string) string) string {
+	var buf bytes.Buffer
+	executeAddressNodes(&buf, at.nodes, vars, escape)
+	return buf.String()
+}
+
+func executeAddressNodes(buf *bytes.Buffer, nodes []addressNode, vars map[string]string, escape func(string) string) {
+	for _, n := range nodes {
+		switch n.kind {
+		case addressNodeText:
+			buf.WriteString(n.text)
+		case addressNodeVar:
+			v := vars[n.text]
+			if escape != nil {
+				v = escape(v)
+			}
+			buf.WriteString(v)
+		case addressNodeCond:
+			if vars[n.text] != "" {
+				executeAddressNodes(buf, n.body, vars, escape)
+			} else {
+				executeAddressNodes(buf, n.elseBody, vars, escape)
+			}
+		}
+	}
+}
+
+type addressParser struct {
+	src string
+	pos int
+}
+
+// parse parses nodes until the end of the source or a closing directive
+// like /if, /depend or else, which gets returned.
+func (p *addressParser) parse() (nodes []addressNode, end string, err error) {
+	for p.pos < len(p.src) {
+		i := strings.Index(p.src[p.pos:], "{{")
+		if i < 0 {
+			nodes = append(nodes, addressNode{kind: addressNodeText, text: p.src[p.pos:]})
+			p.pos = len(p.src)
+			break
+		}
+		if i > 0 {
+			nodes = append(nodes, addressNode{kind: addressNodeText, text: p.src[p.pos : p.pos+i]})
+		}
+		start := p.pos + i
+		j := strings.Index(p.src[start:], "}}")
+		if j < 0 {
+			return nil, "", errgo.Newf("Unclosed directive at offset %d", start)
+		}
+		p.pos = start + j + 2
+		fields := strings.Fields(p.src[start+2 : start+j])
+		if len(fields) == 0 {
+			return nil, "", errgo.Newf("Empty directive at offset %d", start)
+		}
+
+		switch dir := fields[0]; dir {
+		case "/if", "/depend", "else":
+			return nodes, dir, nil
+		case "var", "depend", "if":
+			if len(fields) != 2 {
+				return nil, "", errgo.Newf("Directive %q at offset %d expects one variable", dir, start)
+			}
+			if dir == "var" {
+				nodes = append(nodes, addressNode{kind: addressNodeVar, text: fields[1]})
+				continue
+			}
+			n, err := p.parseCond(dir, fields[1], start)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, n)
+		default:
+			return nil, "", errgo.Newf("Unknown directive %q at offset %d", dir, start)
+		}
+	}
+	return nodes, "", nil
+}
+
+func (p *addressParser) parseCond(dir, name string, start int) (addressNode, error) {
+	n := addressNode{kind: addressNodeCond, text: name}
+	var end string
+	var err error
+	if n.body, end, err = p.parse(); err != nil {
+		return n, err
+	}
+	if end == "else" && dir == "if" {
+		if n.elseBody, end, err = p.parse(); err != nil {
+			return n, err
+		}
+	}
+	if end != "/"+dir {
+		return n, errgo.Newf("Directive %q at offset %d not closed with {{/%s}}", dir, start, dir)
+	}
+	return n, nil
+}
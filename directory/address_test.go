@@ -0,0 +1,162 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/directory"
+	"github.com/corestoreio/csfw/store/scope"
+	"github.com/juju/errgo"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+var testAddress = directory.Address{
+	Firstname: "Hans",
+	Lastname:  "Müller",
+	Company:   "Müller & Söhne",
+	Street:    []string{"Hauptstraße 1", "Hinterhaus"},
+	City:      "München",
+	RegionID:  82,
+	Postcode:  "80331",
+	CountryID: "DE",
+	Telephone: "089 123456",
+}
+
+var testRegions = directory.NewRegions(
+	directory.Region{ID: 12, CountryID: "US", Code: "CA", Name: "California"},
+	directory.Region{ID: 82, CountryID: "DE", Code: "BAY", Name: "Bayern"},
+)
+
+func TestAddressTemplate(t *testing.T) {
+	tests := []struct {
+		tpl     string
+		vars    map[string]string
+		want    string
+		wantErr string
+	}{
+		{"{{var a}}-{{var b}}", map[string]string{"a": "1"}, "1-", ""},
+		{"{{depend a}}A{{var a}}{{/depend}}|{{depend b}}B{{/depend}}", map[string]string{"a": "1"}, "A1|", ""},
+		{"{{if a}}{{if b}}ab{{else}}a{{/if}}{{else}}none{{/if}}", map[string]string{"a": "1"}, "a", ""},
+		{"{{if a}}x{{else}}none{{/if}}", nil, "none", ""},
+		{"{{ var  a }}", map[string]string{"a": "<b>"}, "<b>", ""},
+		{"{{var}}", nil, "", `Directive "var" at offset 0 expects one variable`},
+		{"{{if a}}x", nil, "", `Directive "if" at offset 0 not closed with {{/if}}`},
+		{"{{depend a}}x{{else}}y{{/depend}}", nil, "", `Directive "depend" at offset 0 not closed with {{/depend}}`},
+		{"x{{/if}}", nil, "", "Unexpected {{/if}} at offset 8"},
+		{"{{for a}}", nil, "", `Unknown directive "for" at offset 0`},
+		{"{{var a", nil, "", "Unclosed directive at offset 0"},
+	}
+	for i, test := range tests {
+		at, err := directory.ParseAddressTemplate(test.tpl)
+		if test.wantErr != "" {
+			assert.EqualError(t, err, test.wantErr, "Index %d", i)
+			continue
+		}
+		assert.NoError(t, err, "Index %d", i)
+		assert.Exactly(t, test.want, at.Execute(test.vars, nil), "Index %d", i)
+	}
+}
+
+func TestAddressFormatter(t *testing.T) {
+	af, err := directory.NewAddressFormatter(
+		directory.WithAddressRegions(testRegions),
+		directory.WithAddressLocale(language.German),
+		directory.WithAddressTemplate("de", directory.AddressFormatText, "{{var firstname}} {{var lastname}}\n{{var street1}}\n{{var postcode}} {{var city}}\n{{var country}}"),
+	)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		formatType string
+		a          directory.Address
+		want       string
+	}{
+		{directory.AddressFormatText, testAddress, "Hans Müller\nHauptstraße 1\n80331 München\nDeutschland"},
+		{directory.AddressFormatOneline, testAddress, "Hans Müller, Hauptstraße 1, Hinterhaus, München, Bayern 80331, Deutschland"},
+		{directory.AddressFormatHTML, testAddress, "Hans Müller<br/>\nMüller &amp; Söhne<br />\nHauptstraße 1<br />\nHinterhaus<br />\n\n\nMünchen,  Bayern, 80331<br/>\nDeutschland<br/>\nT: 089 123456\n\n"},
+		{directory.AddressFormatOneline, directory.Address{Prefix: "Mr.", Firstname: "John", Lastname: "Doe", Street: []string{"1 Main St"}, City: "Los Angeles", Region: "CA", Postcode: "90001", CountryID: "US"},
+			"Mr. John Doe, 1 Main St, Los Angeles, CA 90001, Vereinigte Staaten"},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		_, err := af.Format(&buf, test.formatType, test.a)
+		assert.NoError(t, err, "Index %d", i)
+		assert.Exactly(t, test.want, buf.String(), "Index %d", i)
+	}
+
+	var buf bytes.Buffer
+	_, err = af.Format(&buf, "xml", testAddress)
+	assert.EqualError(t, err, `Address format type "xml" not found`)
+}
+
+func TestAddressFormatterConfig(t *testing.T) {
+	sg := config.NewMockGetter(config.WithMockValues(config.MockPV{
+		scope.StrStores.FQPathInt64(1, "customer/address_templates/oneline"): "{{var lastname}}, {{var country_id}}",
+	})).NewScoped(1, 1, 1)
+
+	af, err := directory.NewAddressFormatter(directory.WithAddressConfig(sg))
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	_, err = af.Format(&buf, directory.AddressFormatOneline, testAddress)
+	assert.NoError(t, err)
+	assert.Exactly(t, "Müller, DE", buf.String())
+
+	af, err = directory.NewAddressFormatter(directory.WithAddressTemplate("", directory.AddressFormatPDF, "{{if x}}"))
+	assert.Nil(t, af)
+	assert.Contains(t, err.Error(), `Directive "if" at offset 0 not closed with {{/if}}`)
+}
+
+func TestAddressValidator(t *testing.T) {
+	sg := config.NewMockGetter(config.WithMockValues(config.MockPV{
+		directory.Backend.GeneralCountryAllow.FQPathInt64(scope.StrDefault, 0):                "DE,US,GB,CH",
+		directory.Backend.GeneralRegionStateRequired.FQPathInt64(scope.StrDefault, 0):         "US",
+		directory.Backend.GeneralCountryOptionalZipCountries.FQPathInt64(scope.StrDefault, 0): "GB",
+	})).NewScoped(0, 0, 0)
+	av := directory.NewAddressValidator(sg, testRegions)
+	assert.NoError(t, av.SetPostcodePattern("ch", `^[1-9][0-9]{3}$`))
+	assert.Error(t, av.SetPostcodePattern("ch", `[`))
+
+	tests := []struct {
+		a       directory.Address
+		wantErr error
+	}{
+		{testAddress, nil},
+		{directory.Address{CountryID: "DE", Region: "bay", Postcode: "80331"}, nil},
+		{directory.Address{CountryID: "US", Region: "California", Postcode: "90001-1234"}, nil},
+		{directory.Address{CountryID: "GB", Postcode: ""}, nil},
+		{directory.Address{CountryID: "GB", Postcode: "SW1A 1AA"}, nil},
+		{directory.Address{CountryID: "CH", Postcode: "8001"}, nil},
+		{directory.Address{CountryID: "", Postcode: "80331"}, directory.ErrAddressCountry},
+		{directory.Address{CountryID: "FR", Postcode: "75001"}, directory.ErrAddressCountry},
+		{directory.Address{CountryID: "US", Postcode: "90001"}, directory.ErrAddressRegion},
+		{directory.Address{CountryID: "US", RegionID: 82, Postcode: "90001"}, directory.ErrAddressRegion},
+		{directory.Address{CountryID: "DE", Region: "Tirol", Postcode: "80331"}, directory.ErrAddressRegion},
+		{directory.Address{CountryID: "DE"}, directory.ErrAddressPostcode},
+		{directory.Address{CountryID: "DE", Postcode: "8033"}, directory.ErrAddressPostcode},
+		{directory.Address{CountryID: "GB", Postcode: "12345"}, directory.ErrAddressPostcode},
+		{directory.Address{CountryID: "CH", Postcode: "0800"}, directory.ErrAddressPostcode},
+	}
+	for i, test := range tests {
+		err := av.Validate(test.a)
+		if test.wantErr == nil {
+			assert.NoError(t, err, "Index %d", i)
+			continue
+		}
+		assert.True(t, errgo.Cause(err) == test.wantErr, "Index %d => %v", i, err)
+	}
+}
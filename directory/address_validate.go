@@ -0,0 +1,183 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/juju/errgo"
+)
+
+var (
+	// ErrAddressCountry gets returned when the country of an address is
+	// empty or not allowed in general/country/allow.
+	ErrAddressCountry = errors.New("Address country invalid")
+	// ErrAddressRegion gets returned when the region is required but empty
+	// or when the region does not belong to the country.
+	ErrAddressRegion = errors.New("Address region invalid")
+	// ErrAddressPostcode gets returned when the postcode is required but
+	// empty or does not match the pattern of the country.
+	ErrAddressPostcode = errors.New("Address postcode invalid")
+)
+
+// postcodePatterns contains the postcode formats of the countries from the
+// Magento file Directory/etc/zip_codes.xml.
+var postcodePatterns = map[string]string{
+	"AT": `^[0-9]{4}$`,
+	"AU": `^[0-9]{4}$`,
+	"BE": `^[0-9]{4}$`,
+	"BR": `^[0-9]{5}-?[0-9]{3}$`,
+	"CA": `^[a-zA-Z][0-9][a-zA-Z]\s?[0-9][a-zA-Z][0-9]$`,
+	"CH": `^[0-9]{4}$`,
+	"CZ": `^[0-9]{3}\s?[0-9]{2}$`,
+	"DE": `^[0-9]{5}$`,
+	"DK": `^[0-9]{4}$`,
+	"ES": `^[0-9]{5}$`,
+	"FI": `^[0-9]{5}$`,
+	"FR": `^[0-9]{5}$`,
+	"GB": `^[a-zA-Z]{1,2}[0-9][0-9a-zA-Z]?\s?[0-9][a-zA-Z]{2}$`,
+	"IT": `^[0-9]{5}$`,
+	"JP": `^[0-9]{3}-?[0-9]{4}$`,
+	"LI": `^[0-9]{4}$`,
+	"LU": `^[0-9]{4}$`,
+	"NL": `^[0-9]{4}\s?[a-zA-Z]{2}$`,
+	"NO": `^[0-9]{4}$`,
+	"NZ": `^[0-9]{4}$`,
+	"PL": `^[0-9]{2}-[0-9]{3}$`,
+	"PT": `^[0-9]{4}-?[0-9]{3}$`,
+	"SE": `^[0-9]{3}\s?[0-9]{2}$`,
+	"US": `^[0-9]{5}(-?[0-9]{4})?$`,
+}
+
+// AddressValidator validates postal addresses with the configuration of a
+// scope:
+//   - general/country/allow contains the country,
+//   - general/region/state_required requires a region,
+//   - general/country/optional_zip_countries makes the postcode optional.
+//
+// Postcodes must match the pattern of their country, countries without a
+// pattern accept any postcode. AddressValidator is safe for concurrent use.
+type AddressValidator struct {
+	// Config reads the configuration values of a scope.
+	Config config.ScopedGetter
+	// Backend contains the configuration paths. Default the global Backend.
+	Backend *PkgBackend
+	// Regions optional to check that a region belongs to the country. If a
+	// country has regions, the region of an address must be one of them.
+	Regions *Regions
+
+	mu        sync.RWMutex
+	postcodes map[string]*regexp.Regexp
+}
+
+// NewAddressValidator creates a new validator for the configuration of a
+// scope. Regions can be nil.
+func NewAddressValidator(sg config.ScopedGetter, r *Regions) *AddressValidator {
+	av := &AddressValidator{
+		Config:    sg,
+		Backend:   Backend,
+		Regions:   r,
+		postcodes: make(map[string]*regexp.Regexp, len(postcodePatterns)),
+	}
+	for c, p := range postcodePatterns {
+		av.postcodes[c] = regexp.MustCompile(p)
+	}
+	return av
+}
+
+// SetPostcodePattern sets or replaces the postcode pattern of a country. An
+// empty pattern removes the check.
+func (av *AddressValidator) SetPostcodePattern(countryID, pattern string) error {
+	countryID = strings.ToUpper(countryID)
+	av.mu.Lock()
+	defer av.mu.Unlock()
+	if pattern == "" {
+		delete(av.postcodes, countryID)
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return errgo.Notef(err, "Country %q", countryID)
+	}
+	av.postcodes[countryID] = re
+	return nil
+}
+
+// Validate checks the country, the region and the postcode of an address
+// and returns the first error. Check the error with errgo.Cause against
+// ErrAddressCountry, ErrAddressRegion or ErrAddressPostcode.
+func (av *AddressValidator) Validate(a Address) error {
+	cID := strings.ToUpper(a.CountryID)
+	if cID == "" || false == containsCountry(av.Backend.GeneralCountryAllow.Get(av.Config), cID) {
+		return errgo.WithCausef(nil, ErrAddressCountry, "%s: %q", ErrAddressCountry, a.CountryID)
+	}
+	if err := av.validateRegion(cID, a); err != nil {
+		return err
+	}
+	return av.validatePostcode(cID, a.Postcode)
+}
+
+func (av *AddressValidator) validateRegion(cID string, a Address) error {
+	if a.RegionID == 0 && a.Region == "" {
+		if containsCountry(av.Backend.GeneralRegionStateRequired.Get(av.Config), cID) {
+			return errgo.WithCausef(nil, ErrAddressRegion, "%s: required for country %q", ErrAddressRegion, cID)
+		}
+		return nil
+	}
+	if av.Regions == nil || false == av.Regions.HasCountry(cID) {
+		return nil
+	}
+	if a.RegionID > 0 {
+		if rg, ok := av.Regions.ByID(a.RegionID); ok && rg.CountryID == cID {
+			return nil
+		}
+		return errgo.WithCausef(nil, ErrAddressRegion, "%s: ID %d not found in country %q", ErrAddressRegion, a.RegionID, cID)
+	}
+	if _, ok := av.Regions.ByCode(cID, a.Region); !ok {
+		return errgo.WithCausef(nil, ErrAddressRegion, "%s: %q not found in country %q", ErrAddressRegion, a.Region, cID)
+	}
+	return nil
+}
+
+func (av *AddressValidator) validatePostcode(cID, postcode string) error {
+	postcode = strings.TrimSpace(postcode)
+	if postcode == "" {
+		if containsCountry(av.Backend.GeneralCountryOptionalZipCountries.Get(av.Config), cID) {
+			return nil
+		}
+		return errgo.WithCausef(nil, ErrAddressPostcode, "%s: required for country %q", ErrAddressPostcode, cID)
+	}
+	av.mu.RLock()
+	re, ok := av.postcodes[cID]
+	av.mu.RUnlock()
+	if ok && false == re.MatchString(postcode) {
+		return errgo.WithCausef(nil, ErrAddressPostcode, "%s: %q for country %q", ErrAddressPostcode, postcode, cID)
+	}
+	return nil
+}
+
+// containsCountry returns true if the country codes contain the code.
+func containsCountry(codes []string, code string) bool {
+	for _, c := range codes {
+		if strings.EqualFold(strings.TrimSpace(c), code) {
+			return true
+		}
+	}
+	return false
+}
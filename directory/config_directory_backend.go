@@ -161,7 +161,7 @@ type PkgBackend struct {
 	// GeneralLocaleWeightUnit => Weight Unit.
 	// Path: general/locale/weight_unit
 	// SourceModel: Otnegam\Directory\Model\Config\Source\WeightUnit
-	GeneralLocaleWeightUnit model.Str
+	GeneralLocaleWeightUnit ConfigWeightUnit
 }
 
 // NewBackend initializes the global Path variable. See init()
@@ -195,7 +195,7 @@ func (pp *PkgBackend) init(cfgStruct element.SectionSlice) *PkgBackend {
 	pp.GeneralLocaleWeekend = model.NewStringCSV(`general/locale/weekend`, model.WithConfigStructure(cfgStruct))
 	pp.GeneralRegionStateRequired = model.NewStringCSV(`general/region/state_required`, model.WithConfigStructure(cfgStruct))
 	pp.GeneralRegionDisplayAll = model.NewBool(`general/region/display_all`, model.WithConfigStructure(cfgStruct))
-	pp.GeneralLocaleWeightUnit = model.NewStr(`general/locale/weight_unit`, model.WithConfigStructure(cfgStruct))
+	pp.GeneralLocaleWeightUnit = NewConfigWeightUnit(`general/locale/weight_unit`, model.WithConfigStructure(cfgStruct))
 
 	return pp
 }
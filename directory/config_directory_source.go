@@ -30,6 +30,8 @@ type PkgSource struct {
 	Currency source.Slice
 	// Country should contain all countries
 	Country source.Slice
+	// WeightUnit contains the units lbs and kgs.
+	WeightUnit source.Slice
 }
 
 // Sources global slice containing available sources to be used in
@@ -45,6 +47,7 @@ func InitSources(dbrSess dbr.SessionRunner) error {
 
 	initSourceCurrency(dbrSess, o)
 	initSourceCountry(dbrSess, o)
+	initSourceWeightUnit(o)
 
 	Sources = o
 	return nil
@@ -83,3 +86,12 @@ func initSourceCountry(dbrsess dbr.SessionRunner, o *PkgSource) error {
 	Backend.GeneralCountryDestinations.Source = o.Country
 	return nil
 }
+
+// initSourceWeightUnit sets the available units of weight.
+func initSourceWeightUnit(o *PkgSource) {
+	Backend.Lock()
+	defer Backend.Unlock()
+
+	o.WeightUnit = source.NewByString(WeightUnitLbs, "lbs", WeightUnitKgs, "kgs")
+	Backend.GeneralLocaleWeightUnit.Source = o.WeightUnit
+}
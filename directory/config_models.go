@@ -48,3 +48,61 @@ func (p ConfigCurrency) Get(sg config.ScopedGetter) (Currency, error) {
 func (p ConfigCurrency) Write(w config.Writer, v Currency, s scope.Scope, id int64) error {
 	return p.Str.Write(w, v.String(), s, id)
 }
+
+// ConfigWeightUnit unit of weight type for the configuration path
+// general/locale/weight_unit with the values lbs or kgs.
+type ConfigWeightUnit struct {
+	model.Str
+}
+
+// NewConfigWeightUnit creates a new unit of weight configuration type.
+func NewConfigWeightUnit(path string, opts ...model.Option) ConfigWeightUnit {
+	return ConfigWeightUnit{
+		Str: model.NewStr(path, opts...),
+	}
+}
+
+// Get returns the configured unit of weight of a scope. Invalid values
+// return an error with the cause ErrUnitUnknown.
+func (p ConfigWeightUnit) Get(sg config.ScopedGetter) (Unit, error) {
+	u, err := ParseUnit(p.Str.Get(sg))
+	if err != nil {
+		return Unit{}, errgo.Mask(err, errgo.Any)
+	}
+	if false == u.IsWeight() {
+		return Unit{}, errgo.WithCausef(nil, ErrUnitUnknown, "%s: %q is not a unit of weight", ErrUnitUnknown, u)
+	}
+	return u, nil
+}
+
+// Weight creates a weight in the configured unit of a scope, e.g. for a
+// product weight stored without unit.
+func (p ConfigWeightUnit) Weight(sg config.ScopedGetter, v float64) (Measure, error) {
+	u, err := p.Get(sg)
+	if err != nil {
+		return Measure{}, errgo.Mask(err, errgo.Any)
+	}
+	return NewMeasure(v, u), nil
+}
+
+// Dimension creates a length in the unit which belongs to the configured
+// unit of weight of a scope: inch for lbs and centimeter for kgs.
+func (p ConfigWeightUnit) Dimension(sg config.ScopedGetter, v float64) (Measure, error) {
+	u, err := p.Get(sg)
+	if err != nil {
+		return Measure{}, errgo.Mask(err, errgo.Any)
+	}
+	return NewMeasure(v, u.DimensionUnit()), nil
+}
+
+// Write writes a unit of weight as lbs or kgs to the configuration storage.
+func (p ConfigWeightUnit) Write(w config.Writer, u Unit, s scope.Scope, id int64) error {
+	v := WeightUnitKgs
+	switch {
+	case u == Pound:
+		v = WeightUnitLbs
+	case u != Kilogram:
+		return errgo.WithCausef(nil, ErrUnitUnknown, "%s: %q cannot be stored, only %s or %s", ErrUnitUnknown, u, Pound, Kilogram)
+	}
+	return p.Str.Write(w, v, s, id)
+}
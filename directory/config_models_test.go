@@ -20,6 +20,7 @@ import (
 	"github.com/corestoreio/csfw/config"
 	"github.com/corestoreio/csfw/directory"
 	"github.com/corestoreio/csfw/store/scope"
+	"github.com/juju/errgo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -55,3 +56,36 @@ func TestNewConfigCurrencyWrite(t *testing.T) {
 	assert.Exactly(t, directory.Backend.CurrencyOptionsBase.FQPathInt64(scope.StrWebsites, 33), w.ArgPath)
 	assert.Exactly(t, "EUR", w.ArgValue)
 }
+
+func TestConfigWeightUnit(t *testing.T) {
+	t.Parallel()
+	cw := directory.Backend.GeneralLocaleWeightUnit
+
+	cr := config.NewMockGetter(
+		config.WithMockValues(config.MockPV{
+			cw.FQPathInt64(scope.StrWebsites, 1): "kgs",
+			cw.FQPathInt64(scope.StrStores, 3):   "cm",
+		}),
+	)
+
+	u, err := cw.Get(cr.NewScoped(0, 0, 0))
+	assert.NoError(t, err)
+	assert.Exactly(t, directory.Pound, u, "default lbs")
+
+	w, err := cw.Weight(cr.NewScoped(1, 0, 0), 2.5)
+	assert.NoError(t, err)
+	assert.Exactly(t, directory.NewMeasure(2.5, directory.Kilogram), w)
+
+	d, err := cw.Dimension(cr.NewScoped(0, 0, 0), 10)
+	assert.NoError(t, err)
+	assert.Exactly(t, directory.NewMeasure(10, directory.Inch), d)
+
+	_, err = cw.Weight(cr.NewScoped(1, 1, 3), 1)
+	assert.True(t, errgo.Cause(err) == directory.ErrUnitUnknown, "%v", err)
+
+	mw := new(config.MockWrite)
+	assert.NoError(t, cw.Write(mw, directory.Kilogram, scope.StoreID, 2))
+	assert.Exactly(t, cw.FQPathInt64(scope.StrStores, 2), mw.ArgPath)
+	assert.Exactly(t, "kgs", mw.ArgValue)
+	assert.Error(t, cw.Write(mw, directory.Ounce, scope.StoreID, 2))
+}
@@ -0,0 +1,279 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/corestoreio/csfw/storage/money"
+	"github.com/juju/errgo"
+	"golang.org/x/text/currency"
+)
+
+// TableCurrencyRate is the name of the table which stores the exchange
+// rates.
+const TableCurrencyRate = "directory_currency_rate"
+
+// rateDecimals defines the scale of the column rate decimal(24,12).
+const rateDecimals = 12
+
+var (
+	// ErrRateNotFound gets returned when no exchange rate between two
+	// currencies can be found.
+	ErrRateNotFound = errors.New("Currency rate not found")
+	// ErrRateInvalid gets returned when a rate cannot be parsed or is not
+	// greater than zero.
+	ErrRateInvalid = errors.New("Currency rate invalid")
+	// ErrCurrencyMissing gets returned by Convert when the Valuta field of
+	// the money has not been set.
+	ErrCurrencyMissing = errors.New("Money has no currency")
+)
+
+// CurrencyRate defines the exchange rate between two currencies. One unit of
+// From costs Rate units of To.
+type CurrencyRate struct {
+	From currency.Unit
+	To   currency.Unit
+	Rate *big.Rat
+}
+
+// NewCurrencyRate creates a new rate from two 3-letter ISO 4217 currency
+// codes and a decimal number like "1.1327".
+func NewCurrencyRate(from, to, rate string) (CurrencyRate, error) {
+	var cr CurrencyRate
+	var err error
+	if cr.From, err = currency.ParseISO(from); err != nil {
+		return cr, errgo.Notef(err, "Currency from: %q", from)
+	}
+	if cr.To, err = currency.ParseISO(to); err != nil {
+		return cr, errgo.Notef(err, "Currency to: %q", to)
+	}
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return cr, errgo.WithCausef(nil, ErrRateInvalid, "%s: %s %s %q", ErrRateInvalid, from, to, rate)
+	}
+	cr.Rate = r
+	return cr, nil
+}
+
+// MustNewCurrencyRate same as NewCurrencyRate() but panics on error.
+func MustNewCurrencyRate(from, to, rate string) CurrencyRate {
+	cr, err := NewCurrencyRate(from, to, rate)
+	if err != nil {
+		panic(err)
+	}
+	return cr
+}
+
+// String returns the rate with twelve decimals, like the database column.
+func (cr CurrencyRate) String() string {
+	return cr.From.String() + "/" + cr.To.String() + " " + cr.Rate.FloatString(rateDecimals)
+}
+
+// ratePair is the key of the Rates map.
+type ratePair struct {
+	from, to currency.Unit
+}
+
+// tableCurrencyRate represents one row of the table directory_currency_rate.
+type tableCurrencyRate struct {
+	CurrencyFrom string `db:"currency_from"`
+	CurrencyTo   string `db:"currency_to"`
+	Rate         string `db:"rate"`
+}
+
+// Rates contains the exchange rates and converts money between currencies.
+// Rates is safe for concurrent use.
+type Rates struct {
+	mu sync.RWMutex
+	m  map[ratePair]*big.Rat
+}
+
+// NewRates creates a new rate collection.
+func NewRates(crs ...CurrencyRate) *Rates {
+	r := &Rates{
+		m: make(map[ratePair]*big.Rat),
+	}
+	r.Set(crs...)
+	return r
+}
+
+// Set adds the rates or replaces existing ones.
+func (r *Rates) Set(crs ...CurrencyRate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cr := range crs {
+		r.m[ratePair{cr.From, cr.To}] = new(big.Rat).Set(cr.Rate)
+	}
+}
+
+// Slice returns all rates sorted by the currency codes.
+func (r *Rates) Slice() []CurrencyRate {
+	r.mu.RLock()
+	crs := make([]CurrencyRate, 0, len(r.m))
+	for p, rate := range r.m {
+		crs = append(crs, CurrencyRate{From: p.from, To: p.to, Rate: new(big.Rat).Set(rate)})
+	}
+	r.mu.RUnlock()
+	sort.Sort(currencyRateSlice(crs))
+	return crs
+}
+
+// Rate returns the exchange rate from one currency into another. If no
+// direct rate exists the inverse rate or a cross rate through a third
+// currency gets calculated. Magento stores only the rates from the base
+// currencies into the allowed currencies.
+func (r *Rates) Rate(from, to currency.Unit) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if rate, ok := r.lookup(from, to); ok {
+		return rate, nil
+	}
+	for _, cr := range r.sorted() {
+		for _, via := range [...]currency.Unit{cr.From, cr.To} {
+			if via == from || via == to {
+				continue
+			}
+			r1, ok1 := r.lookup(from, via)
+			r2, ok2 := r.lookup(via, to)
+			if ok1 && ok2 {
+				return r1.Mul(r1, r2), nil
+			}
+		}
+	}
+	return nil, errgo.WithCausef(nil, ErrRateNotFound, "%s: %s => %s", ErrRateNotFound, from, to)
+}
+
+// lookup returns a copy of the direct or the inverse rate.
+func (r *Rates) lookup(from, to currency.Unit) (*big.Rat, bool) {
+	if rate, ok := r.m[ratePair{from, to}]; ok {
+		return new(big.Rat).Set(rate), true
+	}
+	if rate, ok := r.m[ratePair{to, from}]; ok {
+		return new(big.Rat).Inv(rate), true
+	}
+	return nil, false
+}
+
+// sorted returns the rates without copies for a stable iteration order.
+func (r *Rates) sorted() []CurrencyRate {
+	crs := make([]CurrencyRate, 0, len(r.m))
+	for p, rate := range r.m {
+		crs = append(crs, CurrencyRate{From: p.from, To: p.to, Rate: rate})
+	}
+	sort.Sort(currencyRateSlice(crs))
+	return crs
+}
+
+// Convert converts the money into another currency. The result gets rounded
+// half away from zero to the fraction digits of the target currency, e.g.
+// two for EUR and zero for JPY. The Valuta field must be set. Missing rates
+// return an error with the cause ErrRateNotFound.
+func (r *Rates) Convert(m money.Money, to currency.Unit) (money.Money, error) {
+	if m.Valuta == (currency.Unit{}) {
+		return m, ErrCurrencyMissing
+	}
+	rate, err := r.Rate(m.Valuta, to)
+	if err != nil {
+		return m, errgo.Mask(err, errgo.Any)
+	}
+	return convert(m, rate, to), nil
+}
+
+// convert multiplies the raw value of the money with the rate. The raw
+// value has Precision() decimals which may be more than the fraction digits
+// of the currency. Large results switch to the arbitrary-precision
+// representation of the money.
+func convert(m money.Money, rate *big.Rat, to currency.Unit) money.Money {
+	scale, _ := currency.Standard.Rounding(to)
+	if prec := m.Precision(); scale > prec {
+		scale = prec
+	}
+	shift := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(m.Precision()-scale)), nil)
+
+	v := new(big.Rat).SetInt(m.Big())
+	v.Mul(v, rate)
+	v.Quo(v, new(big.Rat).SetInt(shift))
+
+	i := roundHalfUp(v)
+	i.Mul(i, shift)
+	m = m.SetBig(i)
+	m.Valuta = to
+	return m
+}
+
+// roundHalfUp rounds half away from zero to an integer.
+func roundHalfUp(v *big.Rat) *big.Int {
+	q, rem := new(big.Int).QuoRem(v.Num(), v.Denom(), new(big.Int))
+	rem.Abs(rem).Lsh(rem, 1)
+	if rem.Cmp(v.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(v.Sign())))
+	}
+	return q
+}
+
+// Load reads all rows of the table directory_currency_rate and replaces
+// the existing rates with the same currencies. Rows with unknown currency
+// codes return an error.
+func (r *Rates) Load(dbrSess dbr.SessionRunner) error {
+	var rows []*tableCurrencyRate
+	if _, err := dbrSess.Select("currency_from", "currency_to", "rate").From(TableCurrencyRate).LoadStructs(&rows); err != nil {
+		return errgo.Mask(err)
+	}
+	crs := make([]CurrencyRate, len(rows))
+	for i, row := range rows {
+		cr, err := NewCurrencyRate(row.CurrencyFrom, row.CurrencyTo, row.Rate)
+		if err != nil {
+			return errgo.Mask(err, errgo.Any)
+		}
+		crs[i] = cr
+	}
+	r.Set(crs...)
+	return nil
+}
+
+// Save writes all rates into the table directory_currency_rate. Existing
+// rows get updated.
+func (r *Rates) Save(dbrSess dbr.SessionRunner) error {
+	crs := r.Slice()
+	if len(crs) == 0 {
+		return nil
+	}
+	ib := dbrSess.InsertInto(TableCurrencyRate).Columns("currency_from", "currency_to", "rate")
+	for _, cr := range crs {
+		ib.Values(cr.From.String(), cr.To.String(), cr.Rate.FloatString(rateDecimals))
+	}
+	_, err := ib.OnDuplicateKey("rate").Exec()
+	return errgo.Mask(err)
+}
+
+type currencyRateSlice []CurrencyRate
+
+func (s currencyRateSlice) Len() int      { return len(s) }
+func (s currencyRateSlice) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s currencyRateSlice) Less(i, j int) bool {
+	if f1, f2 := s[i].From.String(), s[j].From.String(); f1 != f2 {
+		return f1 < f2
+	}
+	return s[i].To.String() < s[j].To.String()
+}
@@ -0,0 +1,156 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+// RateProvider fetches the current exchange rates from an external source.
+// The name of a provider gets configured in currency/import/service.
+type RateProvider interface {
+	FetchRates() ([]CurrencyRate, error)
+}
+
+// RateProviderFunc is an adapter to use a function as a RateProvider.
+type RateProviderFunc func() ([]CurrencyRate, error)
+
+// FetchRates calls f().
+func (f RateProviderFunc) FetchRates() ([]CurrencyRate, error) {
+	return f()
+}
+
+// FixedRates is a RateProvider which returns always the same rates. Useful
+// for currencies with a fixed exchange rate or for testing.
+type FixedRates []CurrencyRate
+
+// FetchRates returns a copy of the rates.
+func (fr FixedRates) FetchRates() ([]CurrencyRate, error) {
+	crs := make([]CurrencyRate, len(fr))
+	copy(crs, fr)
+	return crs, nil
+}
+
+// ECBDailyURL points to the daily reference rates of the European Central
+// Bank. All rates are based on EUR.
+const ECBDailyURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+// ECBRates is a RateProvider which reads the XML format of the European
+// Central Bank.
+type ECBRates struct {
+	// URL can be an http:// or https:// URL or the path to a local file.
+	URL string
+	// Client used to fetch the URL. Default http.DefaultClient.
+	Client *http.Client
+}
+
+// NewECBRates creates a new ECB provider. An empty url uses ECBDailyURL.
+func NewECBRates(url string) *ECBRates {
+	if url == "" {
+		url = ECBDailyURL
+	}
+	return &ECBRates{
+		URL: url,
+	}
+}
+
+// FetchRates downloads or reads the XML document and returns the EUR based
+// rates.
+func (e *ECBRates) FetchRates() ([]CurrencyRate, error) {
+	if !strings.HasPrefix(e.URL, "http://") && !strings.HasPrefix(e.URL, "https://") {
+		f, err := os.Open(e.URL)
+		if err != nil {
+			return nil, errgo.Mask(err)
+		}
+		defer f.Close()
+		return ParseECB(f)
+	}
+
+	c := e.Client
+	if c == nil {
+		c = http.DefaultClient
+	}
+	resp, err := c.Get(e.URL)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errgo.Newf("Fetching %s failed with status: %s", e.URL, resp.Status)
+	}
+	return ParseECB(resp.Body)
+}
+
+// ecbEnvelope represents the document of the ECB. The namespaces get
+// ignored.
+//
+//	<gesmes:Envelope>
+//		<Cube>
+//			<Cube time='2016-04-15'>
+//				<Cube currency='USD' rate='1.1268'/>
+type ecbEnvelope struct {
+	Cube struct {
+		Days []struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string `xml:"currency,attr"`
+				Rate     string `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+// ParseECB parses the XML format of the European Central Bank. If the
+// document contains several days, only the rates of the latest day get
+// returned.
+func ParseECB(r io.Reader) ([]CurrencyRate, error) {
+	var env ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&env); err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	latest := -1
+	var latestTime time.Time
+	for i, d := range env.Cube.Days {
+		t, err := time.Parse("2006-01-02", d.Time)
+		if err != nil {
+			return nil, errgo.Notef(err, "ECB time %q", d.Time)
+		}
+		if latest < 0 || t.After(latestTime) {
+			latest, latestTime = i, t
+		}
+	}
+	if latest < 0 {
+		return nil, errgo.New("ECB document contains no rates")
+	}
+
+	day := env.Cube.Days[latest]
+	crs := make([]CurrencyRate, len(day.Rates))
+	for i, rt := range day.Rates {
+		cr, err := NewCurrencyRate("EUR", rt.Currency, rt.Rate)
+		if err != nil {
+			return nil, errgo.Mask(err)
+		}
+		crs[i] = cr
+	}
+	return crs, nil
+}
@@ -0,0 +1,276 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/corestoreio/csfw/store/scope"
+	"github.com/corestoreio/csfw/util"
+	"github.com/go-gomail/gomail"
+	"github.com/juju/errgo"
+)
+
+// Import frequencies as stored in currency/import/frequency. The scheduler
+// runs like the Magento cron job at the configured time every day, every
+// Monday or on the first day of a month.
+const (
+	FrequencyDaily   = "D"
+	FrequencyWeekly  = "W"
+	FrequencyMonthly = "M"
+)
+
+// RateMailer sends the error email of a failed import. *email.Service
+// implements this interface.
+type RateMailer interface {
+	Send(sc scope.Scope, id int64, m *gomail.Message) error
+}
+
+// RateOption can be used as an argument in NewRateService to configure the
+// service.
+type RateOption func(*RateService)
+
+// WithRateProvider adds a provider. The name must match the value in the
+// configuration path currency/import/service.
+func WithRateProvider(name string, p RateProvider) RateOption {
+	return func(s *RateService) {
+		if p == nil {
+			s.lastErrs = append(s.lastErrs, fmt.Errorf("RateProvider %q cannot be nil", name))
+			return
+		}
+		s.providers[name] = p
+	}
+}
+
+// WithRateConfig sets the configuration in the default scope. Default
+// config.DefaultService.
+func WithRateConfig(sg config.ScopedGetter) RateOption {
+	return func(s *RateService) {
+		s.Config = sg
+	}
+}
+
+// WithRateDB sets the database session to store the imported rates in the
+// table directory_currency_rate.
+func WithRateDB(dbrSess dbr.SessionRunner) RateOption {
+	return func(s *RateService) {
+		s.DB = dbrSess
+	}
+}
+
+// WithRateMailer sets the mailer for the error email.
+func WithRateMailer(m RateMailer) RateOption {
+	return func(s *RateService) {
+		s.Mailer = m
+	}
+}
+
+// RateService imports the exchange rates from the configured RateProvider,
+// stores them in the database and converts money. The scheduler honours the
+// configuration paths below currency/import.
+type RateService struct {
+	// lastErrs a collector. While setting options, errors may occur and will
+	// be accumulated here for later output in the NewRateService() function.
+	lastErrs []error
+
+	*Rates
+
+	// Config reads the configuration values of currency/import.
+	Config config.ScopedGetter
+	// Backend contains the configuration paths. Default the global Backend.
+	Backend *PkgBackend
+	// DB optional session to save the imported rates.
+	DB dbr.SessionRunner
+	// Mailer optional to send the error email.
+	Mailer RateMailer
+
+	providers map[string]RateProvider
+	now       func() time.Time
+
+	mu   sync.Mutex
+	stop chan struct{}
+}
+
+var _ error = (*RateService)(nil)
+
+// NewRateService creates a new rate service. The providers "ecb" with the
+// daily ECB rates and "fixed" without any rates are always available.
+func NewRateService(opts ...RateOption) (*RateService, error) {
+	s := &RateService{
+		Rates:   NewRates(),
+		Backend: Backend,
+		providers: map[string]RateProvider{
+			"ecb":   NewECBRates(ECBDailyURL),
+			"fixed": FixedRates(nil),
+		},
+		now: time.Now,
+	}
+	s.Option(opts...)
+	if s.Config == nil {
+		s.Config = config.DefaultService.NewScoped(0, 0, 0)
+	}
+	if s.lastErrs != nil {
+		return nil, s
+	}
+	return s, nil
+}
+
+// Error implements the error interface. Returns a string where each error has
+// been separated by a line break.
+func (s *RateService) Error() string {
+	return util.Errors(s.lastErrs...)
+}
+
+// Option applies optional arguments to the service.
+func (s *RateService) Option(opts ...RateOption) *RateService {
+	for _, o := range opts {
+		if o != nil {
+			o(s)
+		}
+	}
+	return s
+}
+
+// Import fetches the rates from the configured provider, adds them to the
+// Rates and saves them in the database if a session has been set. On error
+// the error email gets sent.
+func (s *RateService) Import() error {
+	err := s.importRates()
+	if err != nil {
+		if mErr := s.sendError(err); mErr != nil && PkgLog.IsDebug() {
+			PkgLog.Debug("directory.RateService.Import.sendError", "err", mErr, "importErr", err)
+		}
+	}
+	return err
+}
+
+func (s *RateService) importRates() error {
+	name := s.Backend.CurrencyImportService.Get(s.Config)
+	p, ok := s.providers[name]
+	if !ok {
+		return errgo.Newf("RateProvider %q not found", name)
+	}
+	crs, err := p.FetchRates()
+	if err != nil {
+		return errgo.Notef(err, "RateProvider %q", name)
+	}
+	s.Set(crs...)
+	if s.DB == nil {
+		return nil
+	}
+	return errgo.Mask(s.Save(s.DB))
+}
+
+// sendError sends the error to the recipient in currency/import/error_email.
+func (s *RateService) sendError(importErr error) error {
+	to := s.Backend.CurrencyImportErrorEmail.Get(s.Config)
+	if s.Mailer == nil || to == "" {
+		return nil
+	}
+	m := gomail.NewMessage()
+	if ident := s.Backend.CurrencyImportErrorEmailIdentity.Get(s.Config); ident != "" {
+		if from, err := s.Config.String("trans_email", "ident_"+ident, "email"); err == nil && from != "" {
+			m.SetHeader("From", from)
+		}
+	}
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", "Currency Rate Import Errors")
+	m.SetBody("text/plain", importErr.Error())
+	return s.Mailer.Send(scope.DefaultID, 0, m)
+}
+
+// Next returns the time of the next import after t. The time of the day
+// gets read from currency/import/time in the format "HH,MM,SS".
+func (s *RateService) Next(t time.Time) time.Time {
+	h, m, sec := parseImportTime(s.Backend.CurrencyImportTime.Get(s.Config))
+	n := time.Date(t.Year(), t.Month(), t.Day(), h, m, sec, 0, t.Location())
+
+	switch s.Backend.CurrencyImportFrequency.Get(s.Config) {
+	case FrequencyWeekly:
+		n = n.AddDate(0, 0, (int(time.Monday)-int(n.Weekday())+7)%7)
+		if !n.After(t) {
+			n = n.AddDate(0, 0, 7)
+		}
+	case FrequencyMonthly:
+		n = n.AddDate(0, 0, 1-n.Day())
+		if !n.After(t) {
+			n = n.AddDate(0, 1, 0)
+		}
+	default:
+		if !n.After(t) {
+			n = n.AddDate(0, 0, 1)
+		}
+	}
+	return n
+}
+
+// parseImportTime parses the Magento time field "HH,MM,SS". Invalid parts
+// are zero.
+func parseImportTime(v string) (h, m, s int) {
+	parts := strings.Split(v, ",")
+	ints := make([]int, 3)
+	for i := 0; i < len(parts) && i < 3; i++ {
+		ints[i], _ = strconv.Atoi(strings.TrimSpace(parts[i]))
+	}
+	return ints[0], ints[1], ints[2]
+}
+
+// Start runs the scheduler in a goroutine until Stop gets called. At the
+// time returned by Next the rates get imported if currency/import/enabled
+// is true. Errors get logged.
+func (s *RateService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	go s.run(s.stop)
+}
+
+// Stop terminates the scheduler.
+func (s *RateService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+func (s *RateService) run(stop <-chan struct{}) {
+	for {
+		now := s.now()
+		t := time.NewTimer(s.Next(now).Sub(now))
+		select {
+		case <-stop:
+			t.Stop()
+			return
+		case <-t.C:
+			if !s.Backend.CurrencyImportEnabled.Get(s.Config) {
+				continue
+			}
+			if err := s.Import(); err != nil && PkgLog.IsDebug() {
+				PkgLog.Debug("directory.RateService.run.Import", "err", err)
+			}
+		}
+	}
+}
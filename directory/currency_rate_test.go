@@ -0,0 +1,261 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/directory"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/corestoreio/csfw/storage/money"
+	"github.com/corestoreio/csfw/store/scope"
+	"github.com/go-gomail/gomail"
+	"github.com/juju/errgo"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/currency"
+)
+
+var ecbFile = filepath.Join("testdata", "eurofxref-daily.xml")
+
+func TestNewCurrencyRate(t *testing.T) {
+	cr, err := directory.NewCurrencyRate("EUR", "USD", "1.1285")
+	assert.NoError(t, err)
+	assert.Exactly(t, "EUR/USD 1.128500000000", cr.String())
+
+	_, err = directory.NewCurrencyRate("EUR", "XYZ", "1.1285")
+	assert.Error(t, err)
+	_, err = directory.NewCurrencyRate("EUR", "USD", "0")
+	assert.True(t, errgo.Cause(err) == directory.ErrRateInvalid, "Error: %s", err)
+	_, err = directory.NewCurrencyRate("EUR", "USD", "x")
+	assert.True(t, errgo.Cause(err) == directory.ErrRateInvalid, "Error: %s", err)
+}
+
+func TestRatesRate(t *testing.T) {
+	r := directory.NewRates(
+		directory.MustNewCurrencyRate("EUR", "USD", "1.25"),
+		directory.MustNewCurrencyRate("EUR", "CHF", "1.10"),
+	)
+	tests := []struct {
+		from, to currency.Unit
+		want     string
+	}{
+		{currency.EUR, currency.EUR, "1.0000"},
+		{currency.EUR, currency.USD, "1.2500"},
+		{currency.USD, currency.EUR, "0.8000"},
+		{currency.USD, currency.CHF, "0.8800"},
+		{currency.CHF, currency.USD, "1.1364"},
+	}
+	for _, test := range tests {
+		rate, err := r.Rate(test.from, test.to)
+		assert.NoError(t, err)
+		assert.Exactly(t, test.want, rate.FloatString(4), "%s => %s", test.from, test.to)
+	}
+
+	_, err := r.Rate(currency.EUR, currency.JPY)
+	assert.True(t, errgo.Cause(err) == directory.ErrRateNotFound, "Error: %s", err)
+}
+
+func TestRatesConvert(t *testing.T) {
+	r := directory.NewRates(
+		directory.MustNewCurrencyRate("EUR", "USD", "1.1285"),
+		directory.MustNewCurrencyRate("EUR", "JPY", "122.51"),
+		directory.MustNewCurrencyRate("EUR", "CHF", "1.0903"),
+	)
+	tests := []struct {
+		from    currency.Unit
+		amount  float64
+		to      currency.Unit
+		wantRaw int64
+	}{
+		{currency.EUR, 10, currency.USD, 112900},      // 11.285 rounds up
+		{currency.EUR, -10, currency.USD, -112900},    // -11.285 rounds away from zero
+		{currency.EUR, 10, currency.JPY, 12250000},    // 1225.1 has no decimals
+		{currency.EUR, 0.04, currency.JPY, 50000},     // 4.9004
+		{currency.USD, 11.29, currency.EUR, 100000},   // 10.0044
+		{currency.USD, 100, currency.CHF, 966100},     // 96.61497 cross rate
+		{currency.EUR, 12.3456, currency.EUR, 123500}, // same currency gets rounded
+	}
+	for _, test := range tests {
+		m := money.New().Setf(test.amount)
+		m.Valuta = test.from
+		have, err := r.Convert(m, test.to)
+		assert.NoError(t, err)
+		assert.Exactly(t, test.wantRaw, have.Raw(), "%v %s => %s", test.amount, test.from, test.to)
+		assert.Exactly(t, test.to, have.Valuta)
+	}
+
+	_, err := r.Convert(money.New().Setf(1), currency.USD)
+	assert.EqualError(t, err, directory.ErrCurrencyMissing.Error())
+
+	m := money.New().Setf(1)
+	m.Valuta = currency.GBP
+	_, err = r.Convert(m, currency.USD)
+	assert.True(t, errgo.Cause(err) == directory.ErrRateNotFound, "%v", err)
+
+	m = money.New().Set(1 << 62)
+	m.Valuta = currency.EUR
+	m, err = r.Convert(m, currency.JPY)
+	assert.NoError(t, err)
+	assert.True(t, m.IsBig())
+	assert.Exactly(t, "56497765411753929.0000", string(m.Ftoa())) // 461168601842738.7904 * 122.51
+}
+
+func TestParseECB(t *testing.T) {
+	crs, err := directory.NewECBRates(ecbFile).FetchRates()
+	assert.NoError(t, err)
+	assert.Len(t, crs, 4)
+	assert.Exactly(t, "EUR/USD 1.128500000000", crs[0].String())
+	assert.Exactly(t, "EUR/GBP 0.794980000000", crs[3].String())
+
+	_, err = directory.NewECBRates(filepath.Join("testdata", "not_found.xml")).FetchRates()
+	assert.Error(t, err)
+}
+
+func TestECBRatesHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/eurofxref-daily.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, ecbFile)
+	}))
+	defer srv.Close()
+
+	crs, err := directory.NewECBRates(srv.URL + "/eurofxref-daily.xml").FetchRates()
+	assert.NoError(t, err)
+	assert.Len(t, crs, 4)
+
+	_, err = directory.NewECBRates(srv.URL + "/missing.xml").FetchRates()
+	assert.Contains(t, err.Error(), "404 Not Found")
+}
+
+func newMockSession(t *testing.T) (*dbr.Session, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	cxn, err := dbr.NewConnection(dbr.SetDB(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cxn.NewSession(), mock
+}
+
+func TestRatesLoadSave(t *testing.T) {
+	dbrSess, dbMock := newMockSession(t)
+
+	dbMock.ExpectQuery("SELECT currency_from, currency_to, rate FROM `directory_currency_rate`").
+		WillReturnRows(sqlmock.NewRows([]string{"currency_from", "currency_to", "rate"}).
+			AddRow("EUR", "USD", "1.128500000000").
+			AddRow("EUR", "CHF", "1.090300000000"))
+	dbMock.ExpectExec("INSERT INTO directory_currency_rate \\(`currency_from`,`currency_to`,`rate`\\) VALUES \\('EUR','CHF','1.090300000000'\\),\\('EUR','USD','1.130000000000'\\) ON DUPLICATE KEY UPDATE `rate`=VALUES\\(`rate`\\)").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	r := directory.NewRates()
+	assert.NoError(t, r.Load(dbrSess))
+	assert.Len(t, r.Slice(), 2)
+
+	r.Set(directory.MustNewCurrencyRate("EUR", "USD", "1.13"))
+	assert.NoError(t, r.Save(dbrSess))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+type mockMailer struct {
+	msgs []*gomail.Message
+}
+
+func (mm *mockMailer) Send(_ scope.Scope, _ int64, m *gomail.Message) error {
+	mm.msgs = append(mm.msgs, m)
+	return nil
+}
+
+func newRateConfig(pv config.MockPV) config.ScopedGetter {
+	return config.NewMockGetter(config.WithMockValues(pv)).NewScoped(0, 0, 0)
+}
+
+func TestRateServiceImport(t *testing.T) {
+	mm := new(mockMailer)
+	s, err := directory.NewRateService(
+		directory.WithRateConfig(newRateConfig(config.MockPV{
+			directory.Backend.CurrencyImportService.FQPathInt64(scope.StrDefault, 0):            "ecbTest",
+			directory.Backend.CurrencyImportErrorEmail.FQPathInt64(scope.StrDefault, 0):         "admin@example.com",
+			directory.Backend.CurrencyImportErrorEmailIdentity.FQPathInt64(scope.StrDefault, 0): "general",
+			"default/0/trans_email/ident_general/email":                                         "shop@example.com",
+		})),
+		directory.WithRateProvider("ecbTest", directory.NewECBRates(ecbFile)),
+		directory.WithRateMailer(mm),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Import())
+	assert.Len(t, s.Slice(), 4)
+	assert.Len(t, mm.msgs, 0)
+
+	m := money.New().Setf(100)
+	m.Valuta = currency.USD
+	m, err = s.Convert(m, currency.GBP)
+	assert.NoError(t, err)
+	assert.Exactly(t, int64(704500), m.Raw()) // 70.4457
+
+	s.Option(directory.WithRateProvider("ecbTest", directory.RateProviderFunc(func() ([]directory.CurrencyRate, error) {
+		return nil, errors.New("Service unavailable")
+	})))
+	err = s.Import()
+	assert.Contains(t, err.Error(), "Service unavailable")
+	if assert.Len(t, mm.msgs, 1) {
+		assert.Exactly(t, []string{"admin@example.com"}, mm.msgs[0].GetHeader("To"))
+		assert.Exactly(t, []string{"shop@example.com"}, mm.msgs[0].GetHeader("From"))
+	}
+}
+
+func TestNewRateServiceError(t *testing.T) {
+	s, err := directory.NewRateService(directory.WithRateProvider("nil", nil))
+	assert.Nil(t, s)
+	assert.EqualError(t, err, `RateProvider "nil" cannot be nil`)
+
+	s, err = directory.NewRateService(directory.WithRateConfig(newRateConfig(config.MockPV{
+		directory.Backend.CurrencyImportService.FQPathInt64(scope.StrDefault, 0): "webservicex",
+	})))
+	assert.NoError(t, err)
+	assert.EqualError(t, s.Import(), `RateProvider "webservicex" not found`)
+}
+
+func TestRateServiceNext(t *testing.T) {
+	now := time.Date(2016, 4, 15, 10, 30, 0, 0, time.UTC) // Friday
+	tests := []struct {
+		frequency, time string
+		want            time.Time
+	}{
+		{"D", "11,00,00", time.Date(2016, 4, 15, 11, 0, 0, 0, time.UTC)},
+		{"D", "09,15,00", time.Date(2016, 4, 16, 9, 15, 0, 0, time.UTC)},
+		{"", "", time.Date(2016, 4, 16, 0, 0, 0, 0, time.UTC)},
+		{"W", "00,00,00", time.Date(2016, 4, 18, 0, 0, 0, 0, time.UTC)},
+		{"M", "02,00,00", time.Date(2016, 5, 1, 2, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		s, err := directory.NewRateService(directory.WithRateConfig(newRateConfig(config.MockPV{
+			directory.Backend.CurrencyImportFrequency.FQPathInt64(scope.StrDefault, 0): test.frequency,
+			directory.Backend.CurrencyImportTime.FQPathInt64(scope.StrDefault, 0):      test.time,
+		})))
+		assert.NoError(t, err)
+		assert.Exactly(t, test.want, s.Next(now), "Frequency %q Time %q", test.frequency, test.time)
+	}
+}
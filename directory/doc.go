@@ -14,4 +14,16 @@
 
 // Package directory provides features for currencies, currency rates,
 // conversion of prices to a specified currency format, countries and regions.
+//
+// Postal addresses get rendered with the AddressFormatter, which uses the
+// Magento template syntax of customer/address_templates and supports an own
+// template per country. The AddressValidator checks region and postcode
+// against the configuration paths below general/country and general/region.
+// Regions get loaded from the table directory_country_region.
+//
+// A Measure holds a weight or a length in a Unit like Kilogram or Inch,
+// converts between units and formats through i18n.Number. The configured
+// unit of a store comes from Backend.GeneralLocaleWeightUnit:
+//	w, err := directory.Backend.GeneralLocaleWeightUnit.Weight(sg, 2.5) // 2.5 lb
+//	kg, err := w.Convert(directory.Kilogram)
 package directory
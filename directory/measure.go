@@ -0,0 +1,167 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/juju/errgo"
+)
+
+var (
+	// ErrUnitUnknown gets returned when a unit symbol cannot be parsed.
+	ErrUnitUnknown = errors.New("Unknown unit of measure")
+	// ErrUnitIncompatible gets returned when converting between a weight
+	// and a length unit.
+	ErrUnitIncompatible = errors.New("Incompatible units of measure")
+)
+
+// Values of the configuration path general/locale/weight_unit.
+const (
+	WeightUnitLbs = "lbs"
+	WeightUnitKgs = "kgs"
+)
+
+const (
+	unitKindWeight = iota + 1
+	unitKindLength
+)
+
+// Unit defines a unit of measure for weights or lengths. The zero value is
+// not a valid unit.
+type Unit struct {
+	symbol string
+	kind   int
+	// factor converts into the base unit kilogram or meter.
+	factor float64
+}
+
+// Units of measure for weights and dimensions. The factors of the imperial
+// units are the exact international definitions.
+var (
+	Kilogram   = Unit{symbol: "kg", kind: unitKindWeight, factor: 1}
+	Gram       = Unit{symbol: "g", kind: unitKindWeight, factor: 0.001}
+	Pound      = Unit{symbol: "lb", kind: unitKindWeight, factor: 0.45359237}
+	Ounce      = Unit{symbol: "oz", kind: unitKindWeight, factor: 0.45359237 / 16}
+	Meter      = Unit{symbol: "m", kind: unitKindLength, factor: 1}
+	Centimeter = Unit{symbol: "cm", kind: unitKindLength, factor: 0.01}
+	Millimeter = Unit{symbol: "mm", kind: unitKindLength, factor: 0.001}
+	Inch       = Unit{symbol: "in", kind: unitKindLength, factor: 0.0254}
+	Foot       = Unit{symbol: "ft", kind: unitKindLength, factor: 0.3048}
+)
+
+// ParseUnit parses a unit symbol like kg, lb, oz or cm. The values lbs and
+// kgs of the configuration path general/locale/weight_unit are accepted
+// too. Case insensitive.
+func ParseUnit(s string) (Unit, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "kg", WeightUnitKgs:
+		return Kilogram, nil
+	case "g":
+		return Gram, nil
+	case "lb", WeightUnitLbs:
+		return Pound, nil
+	case "oz":
+		return Ounce, nil
+	case "m":
+		return Meter, nil
+	case "cm":
+		return Centimeter, nil
+	case "mm":
+		return Millimeter, nil
+	case "in":
+		return Inch, nil
+	case "ft":
+		return Foot, nil
+	}
+	return Unit{}, errgo.WithCausef(nil, ErrUnitUnknown, "%s: %q", ErrUnitUnknown, s)
+}
+
+// String returns the symbol of the unit, e.g. kg.
+func (u Unit) String() string {
+	return u.symbol
+}
+
+// IsWeight returns true for the units of weight.
+func (u Unit) IsWeight() bool {
+	return u.kind == unitKindWeight
+}
+
+// IsLength returns true for the units of length.
+func (u Unit) IsLength() bool {
+	return u.kind == unitKindLength
+}
+
+// DimensionUnit returns the unit of length which belongs to a unit of
+// weight like in Magento: inch for the imperial and centimeter for the
+// metric units.
+func (u Unit) DimensionUnit() Unit {
+	if u == Pound || u == Ounce {
+		return Inch
+	}
+	return Centimeter
+}
+
+// Measure defines an amount in a unit, e.g. the weight of a product.
+type Measure struct {
+	Value float64
+	Unit  Unit
+}
+
+// NewMeasure creates a new measure.
+func NewMeasure(v float64, u Unit) Measure {
+	return Measure{Value: v, Unit: u}
+}
+
+// Convert converts the measure into another unit of the same kind.
+func (m Measure) Convert(u Unit) (Measure, error) {
+	if m.Unit.kind == 0 || m.Unit.kind != u.kind {
+		return m, errgo.WithCausef(nil, ErrUnitIncompatible, "%s: %q => %q", ErrUnitIncompatible, m.Unit, u)
+	}
+	if m.Unit == u {
+		return m, nil
+	}
+	return Measure{Value: m.Value * m.Unit.factor / u.factor, Unit: u}, nil
+}
+
+// Add adds a measure of the same kind and returns the sum in the unit of m.
+func (m Measure) Add(m2 Measure) (Measure, error) {
+	c, err := m2.Convert(m.Unit)
+	if err != nil {
+		return m, errgo.Mask(err, errgo.Any)
+	}
+	m.Value += c.Value
+	return m, nil
+}
+
+// Fmt writes the value formatted by the localized number format followed
+// by a space and the unit symbol, e.g. "1.234,50 kg".
+func (m Measure) Fmt(w io.Writer, n *i18n.Number) (int, error) {
+	nw, err := n.FmtFloat64(w, m.Value)
+	if err != nil {
+		return nw, errgo.Mask(err)
+	}
+	nu, err := io.WriteString(w, " "+m.Unit.symbol)
+	return nw + nu, errgo.Mask(err)
+}
+
+// String returns the value and the unit without localization, e.g. 2.5 kg.
+func (m Measure) String() string {
+	return strconv.FormatFloat(m.Value, 'f', -1, 64) + " " + m.Unit.symbol
+}
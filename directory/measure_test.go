@@ -0,0 +1,109 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/corestoreio/csfw/directory"
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/juju/errgo"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestParseUnit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    directory.Unit
+		wantErr error
+	}{
+		{"kgs", directory.Kilogram, nil},
+		{"KG", directory.Kilogram, nil},
+		{" lbs ", directory.Pound, nil},
+		{"lb", directory.Pound, nil},
+		{"oz", directory.Ounce, nil},
+		{"g", directory.Gram, nil},
+		{"cm", directory.Centimeter, nil},
+		{"in", directory.Inch, nil},
+		{"stone", directory.Unit{}, directory.ErrUnitUnknown},
+		{"", directory.Unit{}, directory.ErrUnitUnknown},
+	}
+	for i, test := range tests {
+		u, err := directory.ParseUnit(test.in)
+		assert.True(t, errgo.Cause(err) == test.wantErr, "Index %d => %v", i, err)
+		assert.Exactly(t, test.want, u, "Index %d", i)
+	}
+	assert.True(t, directory.Ounce.IsWeight())
+	assert.True(t, directory.Foot.IsLength())
+	assert.Exactly(t, directory.Inch, directory.Pound.DimensionUnit())
+	assert.Exactly(t, directory.Centimeter, directory.Gram.DimensionUnit())
+}
+
+func TestMeasureConvert(t *testing.T) {
+	tests := []struct {
+		m    directory.Measure
+		to   directory.Unit
+		want float64
+	}{
+		{directory.NewMeasure(1, directory.Kilogram), directory.Pound, 2.20462262},
+		{directory.NewMeasure(1, directory.Pound), directory.Ounce, 16},
+		{directory.NewMeasure(500, directory.Gram), directory.Kilogram, 0.5},
+		{directory.NewMeasure(8, directory.Ounce), directory.Gram, 226.796185},
+		{directory.NewMeasure(12, directory.Inch), directory.Foot, 1},
+		{directory.NewMeasure(1, directory.Inch), directory.Centimeter, 2.54},
+		{directory.NewMeasure(3, directory.Meter), directory.Meter, 3},
+	}
+	for i, test := range tests {
+		c, err := test.m.Convert(test.to)
+		assert.NoError(t, err, "Index %d", i)
+		assert.InDelta(t, test.want, c.Value, 1e-6, "Index %d", i)
+		assert.Exactly(t, test.to, c.Unit, "Index %d", i)
+	}
+
+	_, err := directory.NewMeasure(1, directory.Kilogram).Convert(directory.Inch)
+	assert.True(t, errgo.Cause(err) == directory.ErrUnitIncompatible, "%v", err)
+	_, err = directory.Measure{Value: 1}.Convert(directory.Gram)
+	assert.True(t, errgo.Cause(err) == directory.ErrUnitIncompatible, "%v", err)
+}
+
+func TestMeasureAdd(t *testing.T) {
+	m, err := directory.NewMeasure(1, directory.Kilogram).Add(directory.NewMeasure(500, directory.Gram))
+	assert.NoError(t, err)
+	assert.Exactly(t, "1.5 kg", m.String())
+
+	_, err = m.Add(directory.NewMeasure(1, directory.Meter))
+	assert.True(t, errgo.Cause(err) == directory.ErrUnitIncompatible, "%v", err)
+}
+
+func TestMeasureFmt(t *testing.T) {
+	tests := []struct {
+		tag  string
+		m    directory.Measure
+		want string
+	}{
+		{"de-DE", directory.NewMeasure(1234.5, directory.Kilogram), "1.234,500 kg"},
+		{"en-US", directory.NewMeasure(2.25, directory.Pound), "2.250 lb"},
+		{"fr", directory.NewMeasure(12, directory.Centimeter), "12,000 cm"},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		n := i18n.NumberLocaleByTag(language.MustParse(test.tag)).NewNumber()
+		_, err := test.m.Fmt(&buf, n)
+		assert.NoError(t, err, "Index %d", i)
+		assert.Exactly(t, test.want, buf.String(), "Index %d", i)
+	}
+}
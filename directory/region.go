@@ -0,0 +1,172 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/juju/errgo"
+)
+
+// Tables which store the regions of a country and their localized names.
+const (
+	TableCountryRegion     = "directory_country_region"
+	TableCountryRegionName = "directory_country_region_name"
+)
+
+// Region defines a state or province of a country.
+type Region struct {
+	ID int64
+	// CountryID 2-letter ISO 3166-1 code like US.
+	CountryID string
+	// Code like CA for California.
+	Code string
+	// Name contains the default or the localized name.
+	Name string
+}
+
+// tableCountryRegion represents one row of the table
+// directory_country_region.
+type tableCountryRegion struct {
+	RegionID    int64  `db:"region_id"`
+	CountryID   string `db:"country_id"`
+	Code        string `db:"code"`
+	DefaultName string `db:"default_name"`
+}
+
+// tableCountryRegionName represents one row of the table
+// directory_country_region_name.
+type tableCountryRegionName struct {
+	RegionID int64  `db:"region_id"`
+	Name     string `db:"name"`
+}
+
+// Regions contains the regions of all countries. Regions is safe for
+// concurrent use.
+type Regions struct {
+	mu sync.RWMutex
+	m  map[int64]Region
+}
+
+// NewRegions creates a new region collection.
+func NewRegions(rs ...Region) *Regions {
+	r := &Regions{
+		m: make(map[int64]Region),
+	}
+	r.Set(rs...)
+	return r
+}
+
+// Set adds the regions or replaces existing ones with the same ID.
+func (r *Regions) Set(rs ...Region) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rg := range rs {
+		rg.CountryID = strings.ToUpper(rg.CountryID)
+		r.m[rg.ID] = rg
+	}
+}
+
+// ByID returns a region by its ID. The bool reports if the region exists.
+func (r *Regions) ByID(id int64) (Region, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rg, ok := r.m[id]
+	return rg, ok
+}
+
+// ByCode returns the region of a country by its code or by its name, both
+// case insensitive. The bool reports if the region exists.
+func (r *Regions) ByCode(countryID, code string) (Region, bool) {
+	for _, rg := range r.Country(countryID) {
+		if strings.EqualFold(rg.Code, code) || strings.EqualFold(rg.Name, code) {
+			return rg, true
+		}
+	}
+	return Region{}, false
+}
+
+// Country returns all regions of a country sorted by name.
+func (r *Regions) Country(countryID string) []Region {
+	countryID = strings.ToUpper(countryID)
+	r.mu.RLock()
+	var rs []Region
+	for _, rg := range r.m {
+		if rg.CountryID == countryID {
+			rs = append(rs, rg)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Sort(regionSlice(rs))
+	return rs
+}
+
+// HasCountry returns true if at least one region of the country exists.
+func (r *Regions) HasCountry(countryID string) bool {
+	countryID = strings.ToUpper(countryID)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, rg := range r.m {
+		if rg.CountryID == countryID {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads all rows of the table directory_country_region with their
+// default names. If the locale, e.g. de_DE, is not empty, the names get
+// replaced by the translations of the table directory_country_region_name.
+func (r *Regions) Load(dbrSess dbr.SessionRunner, locale string) error {
+	var rows []*tableCountryRegion
+	if _, err := dbrSess.Select("region_id", "country_id", "code", "default_name").From(TableCountryRegion).LoadStructs(&rows); err != nil {
+		return errgo.Mask(err)
+	}
+	names := make(map[int64]string)
+	if locale != "" {
+		var nameRows []*tableCountryRegionName
+		if _, err := dbrSess.Select("region_id", "name").From(TableCountryRegionName).
+			Where(dbr.ConditionRaw("locale = ?", locale)).LoadStructs(&nameRows); err != nil {
+			return errgo.Notef(err, "Locale %q", locale)
+		}
+		for _, nr := range nameRows {
+			names[nr.RegionID] = nr.Name
+		}
+	}
+
+	rs := make([]Region, len(rows))
+	for i, row := range rows {
+		rs[i] = Region{ID: row.RegionID, CountryID: row.CountryID, Code: row.Code, Name: row.DefaultName}
+		if n, ok := names[row.RegionID]; ok && n != "" {
+			rs[i].Name = n
+		}
+	}
+	r.Set(rs...)
+	return nil
+}
+
+type regionSlice []Region
+
+func (s regionSlice) Len() int      { return len(s) }
+func (s regionSlice) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s regionSlice) Less(i, j int) bool {
+	if s[i].Name != s[j].Name {
+		return s[i].Name < s[j].Name
+	}
+	return s[i].ID < s[j].ID
+}
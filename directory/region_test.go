@@ -0,0 +1,59 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/csfw/directory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegionsLoad(t *testing.T) {
+	dbrSess, dbMock := newMockSession(t)
+
+	dbMock.ExpectQuery("SELECT region_id, country_id, code, default_name FROM `directory_country_region`").
+		WillReturnRows(sqlmock.NewRows([]string{"region_id", "country_id", "code", "default_name"}).
+			AddRow(12, "US", "CA", "California").
+			AddRow(43, "US", "NY", "New York").
+			AddRow(82, "DE", "BAY", "Bavaria").
+			AddRow(88, "DE", "BER", "Berlin"))
+	dbMock.ExpectQuery("SELECT region_id, name FROM `directory_country_region_name` WHERE \\(locale = 'de_DE'\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"region_id", "name"}).
+			AddRow(82, "Bayern"))
+
+	r := directory.NewRegions()
+	assert.NoError(t, r.Load(dbrSess, "de_DE"))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	assert.Exactly(t, []directory.Region{
+		{ID: 82, CountryID: "DE", Code: "BAY", Name: "Bayern"},
+		{ID: 88, CountryID: "DE", Code: "BER", Name: "Berlin"},
+	}, r.Country("de"))
+
+	rg, ok := r.ByID(12)
+	assert.True(t, ok)
+	assert.Exactly(t, "California", rg.Name)
+
+	rg, ok = r.ByCode("US", "new york")
+	assert.True(t, ok)
+	assert.Exactly(t, int64(43), rg.ID)
+	_, ok = r.ByCode("DE", "CA")
+	assert.False(t, ok)
+
+	assert.True(t, r.HasCountry("us"))
+	assert.False(t, r.HasCountry("CH"))
+}
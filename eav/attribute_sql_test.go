@@ -19,7 +19,7 @@ import (
 
 	"github.com/corestoreio/csfw/codegen"
 	"github.com/corestoreio/csfw/eav"
-	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
 	"github.com/corestoreio/csfw/util/diff"
 	"github.com/corestoreio/csfw/util/sqlbeautifier"
 	"github.com/stretchr/testify/assert"
@@ -28,7 +28,7 @@ import (
 var testWantGetAttributeSelectSql string = "Please specify a build tag: mage1 or mage2\n$ go test -tags mageX -v ."
 
 func TestGetAttributeSelectSql(t *testing.T) {
-	dbc := csdb.MustConnectTest()
+	dbc := csdbtest.MustConnectTest("TestGetAttributeSelectSql")
 	defer dbc.Close()
 
 	dbrSess := dbc.NewSession()
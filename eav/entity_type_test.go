@@ -18,7 +18,7 @@ import (
 	"testing"
 
 	"github.com/corestoreio/csfw/eav"
-	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
 	"github.com/corestoreio/csfw/storage/dbr"
 	"github.com/stretchr/testify/assert"
 )
@@ -46,7 +46,7 @@ var (
 )
 
 func init() {
-	dbc := csdb.MustConnectTest()
+	dbc := csdbtest.MustConnectTest("eav_entity_type_init")
 	defer dbc.Close()
 	if err := eav.TableCollection.Init(dbc.NewSession()); err != nil {
 		panic(err)
@@ -54,7 +54,7 @@ func init() {
 }
 
 func TestEntityType(t *testing.T) {
-	dbc := csdb.MustConnectTest()
+	dbc := csdbtest.MustConnectTest("TestEntityType")
 	defer dbc.Close()
 	dbrSess := dbc.NewSession()
 
@@ -75,7 +75,7 @@ func TestEntityType(t *testing.T) {
 }
 
 func TestEntityTypeSliceGetByCode(t *testing.T) {
-	dbc := csdb.MustConnectTest()
+	dbc := csdbtest.MustConnectTest("TestEntityTypeSliceGetByCode")
 	defer dbc.Close()
 	dbrSess := dbc.NewSession()
 
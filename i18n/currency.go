@@ -17,6 +17,7 @@ package i18n
 import (
 	"bytes"
 	"io"
+	"math/big"
 	"sync"
 
 	"github.com/juju/errgo"
@@ -253,6 +254,22 @@ func (c *Currency) FmtNumber(w io.Writer, sign int, intgr int64, prec int, frac
 	return c.flushBuf(w)
 }
 
+// FmtBigNumber formats a number like FmtNumber but the integer part can
+// exceed an int64. Thread safe.
+func (c *Currency) FmtBigNumber(w io.Writer, sign int, intgr *big.Int, prec int, frac int64) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clearBuf()
+
+	if _, err := c.Number.FmtBigNumber(&c.buf, sign, intgr, prec, frac); err != nil {
+		if PkgLog.IsDebug() {
+			PkgLog.Debug("i18n.Currency.FmtBigNumber.FmtBigNumber", "err", err, "buffer", string(c.buf), "sign", sign, "i", intgr, "prec", prec, "frac", frac)
+		}
+		return 0, errgo.Mask(err)
+	}
+	return c.flushBuf(w)
+}
+
 // FmtInt64 formats an integer according to the currency format pattern.
 // Thread safe
 func (c *Currency) FmtInt64(w io.Writer, i int64) (int, error) {
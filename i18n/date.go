@@ -0,0 +1,354 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/corestoreio/csfw/util/php"
+	"github.com/juju/errgo"
+	"golang.org/x/text/language"
+)
+
+// Lengths of the CLDR date and time formats.
+const (
+	DateFull = iota
+	DateLong
+	DateMedium
+	DateShort
+)
+
+// Units of the relative time formats.
+const (
+	UnitSecond = "second"
+	UnitMinute = "minute"
+	UnitHour   = "hour"
+	UnitDay    = "day"
+	UnitWeek   = "week"
+	UnitMonth  = "month"
+	UnitYear   = "year"
+)
+
+// ErrDateLength gets returned when a format length is not one of DateFull,
+// DateLong, DateMedium or DateShort.
+var ErrDateLength = errors.New("Invalid date format length")
+
+// DateLocale contains the CLDR names and formats of the gregorian calendar
+// of a locale. The data gets generated by the program in
+// codegen/localization and registered with RegisterDateLocale.
+type DateLocale struct {
+	// Locale like de_CH, en or fr.
+	Locale string
+	// Months, MonthsAbbr, Days and DaysAbbr contain the names in the format
+	// context. Days start with Sunday.
+	Months, MonthsAbbr [12]string
+	Days, DaysAbbr     [7]string
+	AM, PM             string
+	// DateFormats and TimeFormats contain the ICU patterns in the order
+	// DateFull, DateLong, DateMedium and DateShort.
+	DateFormats, TimeFormats [4]string
+	// DateTimeFormat combines the time {0} with the date {1}, e.g.
+	// "{1}, {0}".
+	DateTimeFormat string
+	// Relative contains the relative time patterns per unit, e.g. UnitDay.
+	Relative map[string]RelativeTime
+}
+
+// RelativeTime contains the patterns of a unit per plural category, e.g.
+// "one": "in {0} day" and "other": "{0} days ago".
+type RelativeTime struct {
+	Future, Past map[string]string
+}
+
+var dateLocales = struct {
+	sync.RWMutex
+	m map[string]DateLocale
+}{
+	m: make(map[string]DateLocale),
+}
+
+// defaultDateLocale gets used when no locale has been registered.
+var defaultDateLocale = DateLocale{
+	Locale:         LocaleDefault,
+	Months:         php.EnglishDateNames.Months,
+	MonthsAbbr:     php.EnglishDateNames.MonthsAbbr,
+	Days:           php.EnglishDateNames.Days,
+	DaysAbbr:       php.EnglishDateNames.DaysAbbr,
+	AM:             php.EnglishDateNames.AM,
+	PM:             php.EnglishDateNames.PM,
+	DateFormats:    [4]string{"EEEE, MMMM d, y", "MMMM d, y", "MMM d, y", "M/d/yy"},
+	TimeFormats:    [4]string{"h:mm:ss a zzzz", "h:mm:ss a z", "h:mm:ss a", "h:mm a"},
+	DateTimeFormat: "{1}, {0}",
+}
+
+// RegisterDateLocale adds or replaces the calendar data of locales. Thread
+// safe.
+func RegisterDateLocale(dls ...DateLocale) {
+	dateLocales.Lock()
+	defer dateLocales.Unlock()
+	for _, dl := range dls {
+		dateLocales.m[dl.Locale] = dl
+	}
+}
+
+// DateLocaleByTag returns the calendar data of the best matching locale. The
+// search works like NumberLocaleByTag and falls back to LocaleDefault, use
+// LookupDateLocale to detect locales without registered data. Thread safe.
+func DateLocaleByTag(t language.Tag) DateLocale {
+	if dl, ok := LookupDateLocale(t); ok {
+		return dl
+	}
+	if dl, ok := LookupDateLocale(language.Make(LocaleDefault)); ok {
+		return dl
+	}
+	return defaultDateLocale
+}
+
+// LookupDateLocale returns the calendar data of the best matching locale
+// like DateLocaleByTag but without falling back to LocaleDefault. The bool
+// reports if data for the language of the tag has been registered. Thread
+// safe.
+func LookupDateLocale(t language.Tag) (DateLocale, bool) {
+	dateLocales.RLock()
+	defer dateLocales.RUnlock()
+	for _, k := range localeKeys(t) {
+		if dl, ok := dateLocales.m[k]; ok {
+			return dl, true
+		}
+	}
+	return DateLocale{}, false
+}
+
+// NewDate creates a date formatter for the locale.
+func (dl DateLocale) NewDate(opts ...DateOptions) *Date {
+	return NewDate(append([]DateOptions{SetDateLocale(dl)}, opts...)...)
+}
+
+// DateOptions applies options to the Date type and returns the previous
+// option.
+type DateOptions func(*Date) DateOptions
+
+// SetDateLocale sets the names, formats and plural rules of a locale.
+func SetDateLocale(dl DateLocale) DateOptions {
+	return func(d *Date) DateOptions {
+		previous := d.dl
+		d.dl = dl
+		d.names = php.DateNames{
+			Months:     dl.Months,
+			MonthsAbbr: dl.MonthsAbbr,
+			Days:       dl.Days,
+			DaysAbbr:   dl.DaysAbbr,
+			AM:         dl.AM,
+			PM:         dl.PM,
+		}
+		d.plural = PluralRules{}
+		if tag, err := GetLocaleTag(dl.Locale); err == nil {
+			if nl, ok := LookupNumberLocale(tag); ok {
+				d.plural = nl.plural
+			}
+		}
+		if base, _ := language.Make(dl.Locale).Base(); base.String() != "en" {
+			d.names.Ordinal = noOrdinal // the PHP letter S is English only
+		}
+		return SetDateLocale(previous)
+	}
+}
+
+// SetDateLocation sets the time zone in which the times get formatted, e.g.
+// the store time zone from general/locale/timezone. Default UTC.
+func SetDateLocation(loc *time.Location) DateOptions {
+	return func(d *Date) DateOptions {
+		previous := d.loc
+		if loc == nil {
+			loc = time.UTC
+		}
+		d.loc = loc
+		return SetDateLocation(previous)
+	}
+}
+
+func noOrdinal(int) string { return "" }
+
+// Date formats times with the localized names and formats of a locale in a
+// time zone. Thread safe.
+type Date struct {
+	mu     sync.RWMutex
+	dl     DateLocale
+	names  php.DateNames
+	plural PluralRules
+	loc    *time.Location
+}
+
+// NewDate creates a new date formatter. Default locale is en_US in UTC.
+func NewDate(opts ...DateOptions) *Date {
+	d := &Date{}
+	d.DSetOptions(SetDateLocale(defaultDateLocale), SetDateLocation(time.UTC))
+	d.DSetOptions(opts...)
+	return d
+}
+
+// DSetOptions applies options and returns the last applied previous option.
+func (d *Date) DSetOptions(opts ...DateOptions) (previous DateOptions) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, o := range opts {
+		if o != nil {
+			previous = o(d)
+		}
+	}
+	return
+}
+
+// Location returns the time zone of the formatter.
+func (d *Date) Location() *time.Location {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.loc
+}
+
+// FmtPHP formats the time with a PHP date() format and the localized names,
+// e.g. "l, j. F Y" for "Montag, 8. August 2005".
+func (d *Date) FmtPHP(w io.Writer, t time.Time, phpFormat string) (int, error) {
+	return d.fmt(w, t, php.ParseDate(phpFormat))
+}
+
+// FmtICU formats the time with an ICU pattern, as used in Magento, and the
+// localized names.
+func (d *Date) FmtICU(w io.Writer, t time.Time, pattern string) (int, error) {
+	return d.fmt(w, t, php.ParseICU(pattern))
+}
+
+// FmtDate formats the date part with the CLDR format of the length, e.g.
+// DateMedium.
+func (d *Date) FmtDate(w io.Writer, t time.Time, length int) (int, error) {
+	return d.FmtDateTime(w, t, length, -1)
+}
+
+// FmtTime formats the time part with the CLDR format of the length.
+func (d *Date) FmtTime(w io.Writer, t time.Time, length int) (int, error) {
+	return d.FmtDateTime(w, t, -1, length)
+}
+
+// FmtDateTime formats date and time with the CLDR formats of the lengths and
+// combines them with the DateTimeFormat. A negative length omits the part.
+func (d *Date) FmtDateTime(w io.Writer, t time.Time, dateLength, timeLength int) (int, error) {
+	if dateLength > DateShort || timeLength > DateShort || (dateLength < 0 && timeLength < 0) {
+		return 0, errgo.WithCausef(nil, ErrDateLength, "%s: date %d time %d", ErrDateLength, dateLength, timeLength)
+	}
+	d.mu.RLock()
+	var datePattern, timePattern string
+	if dateLength >= 0 {
+		datePattern = d.dl.DateFormats[dateLength]
+	}
+	if timeLength >= 0 {
+		timePattern = d.dl.TimeFormats[timeLength]
+	}
+	dtf := d.dl.DateTimeFormat
+	d.mu.RUnlock()
+
+	switch {
+	case timePattern == "":
+		return d.FmtICU(w, t, datePattern)
+	case datePattern == "":
+		return d.FmtICU(w, t, timePattern)
+	}
+	if dtf == "" {
+		dtf = "{1} {0}"
+	}
+	var b []byte
+	for dtf != "" {
+		i := strings.IndexByte(dtf, '{')
+		if i < 0 || i+3 > len(dtf) || dtf[i+2] != '}' {
+			i = len(dtf)
+		}
+		// text between the placeholders can contain quoted literals
+		b = php.ParseICU(dtf[:i]).AppendFormat(b, t, nil)
+		if i == len(dtf) {
+			break
+		}
+		switch dtf[i+1] {
+		case '0':
+			b = d.appendFormat(b, t, php.ParseICU(timePattern))
+		case '1':
+			b = d.appendFormat(b, t, php.ParseICU(datePattern))
+		default:
+			b = append(b, dtf[i:i+3]...)
+		}
+		dtf = dtf[i+3:]
+	}
+	return w.Write(b)
+}
+
+// FmtRelative formats the difference between t and now, e.g. "in 3 days" or
+// "2 hours ago". Differences of 30 days are a month and of 365 days a year.
+// The amount gets truncated.
+func (d *Date) FmtRelative(w io.Writer, t, now time.Time) (int, error) {
+	diff := t.Sub(now)
+	future := diff >= 0
+	if !future {
+		diff = -diff
+	}
+
+	var unit string
+	var count int64
+	switch days := int64(diff / (24 * time.Hour)); {
+	case diff < time.Minute:
+		unit, count = UnitSecond, int64(diff/time.Second)
+	case diff < time.Hour:
+		unit, count = UnitMinute, int64(diff/time.Minute)
+	case diff < 24*time.Hour:
+		unit, count = UnitHour, int64(diff/time.Hour)
+	case days < 7:
+		unit, count = UnitDay, days
+	case days < 30:
+		unit, count = UnitWeek, days/7
+	case days < 365:
+		unit, count = UnitMonth, days/30
+	default:
+		unit, count = UnitYear, days/365
+	}
+
+	d.mu.RLock()
+	rt, ok := d.dl.Relative[unit]
+	pr := d.plural
+	d.mu.RUnlock()
+	if !ok {
+		return 0, errgo.Newf("Relative time unit %q not found in locale %q", unit, d.dl.Locale)
+	}
+	patterns := rt.Past
+	if future {
+		patterns = rt.Future
+	}
+	p, ok := patterns[pr.CategoryInt64(count)]
+	if !ok {
+		p = patterns[PluralOther]
+	}
+	return w.Write([]byte(strings.Replace(p, "{0}", strconv.FormatInt(count, 10), -1)))
+}
+
+func (d *Date) fmt(w io.Writer, t time.Time, df php.DateFormat) (int, error) {
+	return w.Write(d.appendFormat(nil, t, df))
+}
+
+func (d *Date) appendFormat(b []byte, t time.Time, df php.DateFormat) []byte {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return df.AppendFormat(b, t.In(d.loc), &d.names)
+}
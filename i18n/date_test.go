@@ -0,0 +1,140 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/juju/errgo"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+var testDateTime = time.Date(2005, 8, 1, 13, 12, 46, 0, time.UTC)
+
+func newTestDate(tag string) *i18n.Date {
+	return i18n.DateLocaleByTag(language.MustParse(tag)).NewDate(
+		i18n.SetDateLocation(time.FixedZone("CEST", 2*3600)),
+	)
+}
+
+func TestDateFmtPHPAndICU(t *testing.T) {
+	tests := []struct {
+		tag  string
+		fmt  func(d *i18n.Date, buf *bytes.Buffer) (int, error)
+		want string
+	}{
+		{"en", func(d *i18n.Date, buf *bytes.Buffer) (int, error) {
+			return d.FmtPHP(buf, testDateTime, `l jS \of F Y h:i A`)
+		}, "Monday 1st of August 2005 03:12 PM"},
+		{"de-DE", func(d *i18n.Date, buf *bytes.Buffer) (int, error) {
+			return d.FmtPHP(buf, testDateTime, `l, jS F Y, D M`)
+		}, "Montag, 1 August 2005, Mo. Aug."},
+		{"fr", func(d *i18n.Date, buf *bytes.Buffer) (int, error) {
+			return d.FmtICU(buf, testDateTime, "EEEE d MMMM y 'à' HH:mm")
+		}, "lundi 1 août 2005 à 15:12"},
+		{"nl", func(d *i18n.Date, buf *bytes.Buffer) (int, error) {
+			return d.FmtICU(buf, testDateTime, "EEE d MMM y h a")
+		}, "ma 1 aug 2005 3 p.m."},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		_, err := test.fmt(newTestDate(test.tag), &buf)
+		assert.NoError(t, err, "Index %d", i)
+		assert.Exactly(t, test.want, buf.String(), "Index %d", i)
+	}
+}
+
+func TestDateFmtDateTime(t *testing.T) {
+	tests := []struct {
+		tag                    string
+		dateLength, timeLength int
+		want                   string
+	}{
+		{"en-US", i18n.DateFull, -1, "Monday, August 1, 2005"},
+		{"en-US", i18n.DateMedium, i18n.DateShort, "Aug 1, 2005, 3:12\u202fPM"},
+		{"en-US", -1, i18n.DateLong, "3:12:46\u202fPM CEST"},
+		{"de-CH", i18n.DateLong, i18n.DateMedium, "1. August 2005, 15:12:46"},
+		{"de", i18n.DateShort, -1, "01.08.05"},
+		{"fr-FR", i18n.DateLong, i18n.DateShort, "1 août 2005, 15:12"},
+		{"ca-FR", i18n.DateLong, -1, "1 d’agost de 2005"},
+		{"nl", i18n.DateShort, i18n.DateShort, "01-08-2005 15:12"},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		_, err := newTestDate(test.tag).FmtDateTime(&buf, testDateTime, test.dateLength, test.timeLength)
+		assert.NoError(t, err, "Index %d", i)
+		assert.Exactly(t, test.want, buf.String(), "Index %d", i)
+	}
+
+	var buf bytes.Buffer
+	_, err := i18n.NewDate().FmtDate(&buf, testDateTime, 4)
+	assert.True(t, errgo.Cause(err) == i18n.ErrDateLength, "%v", err)
+	_, err = i18n.NewDate().FmtDateTime(&buf, testDateTime, -1, -1)
+	assert.True(t, errgo.Cause(err) == i18n.ErrDateLength, "%v", err)
+
+	buf.Reset()
+	_, err = i18n.NewDate().FmtTime(&buf, testDateTime, i18n.DateShort)
+	assert.NoError(t, err)
+	assert.Exactly(t, "1:12 PM", buf.String(), "default location UTC")
+}
+
+func TestDateFmtRelative(t *testing.T) {
+	now := testDateTime
+	tests := []struct {
+		tag  string
+		t    time.Time
+		want string
+	}{
+		{"en", now.Add(30 * time.Second), "in 30 seconds"},
+		{"en", now.Add(-time.Minute), "1 minute ago"},
+		{"en", now.Add(-150 * time.Minute), "2 hours ago"},
+		{"en", now.AddDate(0, 0, 1), "in 1 day"},
+		{"en", now.AddDate(0, 0, 15), "in 2 weeks"},
+		{"en", now.AddDate(0, -3, 0), "3 months ago"},
+		{"en", now.AddDate(-1, 0, 0), "1 year ago"},
+		{"de", now.AddDate(0, 0, -3), "vor 3 Tagen"},
+		{"de-CH", now.AddDate(0, 0, 1), "in 1 Tag"},
+		{"fr", now.Add(-90 * time.Second), "il y a 1 minute"},
+		{"fr", now.AddDate(2, 0, 0), "dans 2 ans"},
+		{"nl", now.Add(-5 * time.Hour), "5 uur geleden"},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		_, err := newTestDate(test.tag).FmtRelative(&buf, test.t, now)
+		assert.NoError(t, err, "Index %d", i)
+		assert.Exactly(t, test.want, buf.String(), "Index %d", i)
+	}
+
+	var buf bytes.Buffer
+	_, err := i18n.NewDate(i18n.SetDateLocale(i18n.DateLocale{Locale: "xx"})).FmtRelative(&buf, now, now)
+	assert.EqualError(t, err, `Relative time unit "second" not found in locale "xx"`)
+}
+
+func TestDateLocaleByTag(t *testing.T) {
+	assert.Exactly(t, "de_CH", i18n.DateLocaleByTag(language.MustParse("de-CH")).Locale)
+	assert.Exactly(t, "de_AT", i18n.DateLocaleByTag(language.MustParse("de-AT")).Locale)
+	assert.Exactly(t, "de", i18n.DateLocaleByTag(language.MustParse("de-LU")).Locale)
+	assert.Exactly(t, "en_US", i18n.DateLocaleByTag(language.MustParse("eo")).Locale)
+
+	_, ok := i18n.LookupDateLocale(language.MustParse("eo"))
+	assert.False(t, ok)
+	dl, ok := i18n.LookupDateLocale(language.MustParse("de-LU"))
+	assert.True(t, ok)
+	assert.Exactly(t, "de", dl.Locale)
+}
@@ -75,6 +75,73 @@ despite the format is #,##0.00 ¤.
 @todo: CashDigits and CashRounding are currently not implemented.
 @todo: something like https://github.com/maximilien/i18n4go
 
+Number parsing
+
+Number and Currency implement ParseNumber(s string) (sign int, i int64, prec int, frac int64, err error)
+as the inverse of FmtNumber. The decimal and grouping separator of the format
+decide how an input gets read, so "1.234" is 1234 in German and 1.234 in
+English. Minus signs, plus signs and parentheses for negative numbers in
+accounting formats are supported. A currency formatter accepts the currency
+sign or the 3-letter ISO code on both sides of the number.
+
+Locale registry
+
+The program in codegen/localization generates the CLDR symbols, patterns,
+plural rules, currency names and calendar data of the enabled locales into
+the file locale_generated.go. NumberLocaleByTag returns the data of the best
+matching locale, de-AT falls back to de and unknown languages to
+LocaleDefault, and creates ready to use formatters. LookupNumberLocale and
+LookupDateLocale report languages without registered data instead of falling
+back:
+
+	nl := i18n.NumberLocaleByTag(language.MustParse("de-CH"))
+	nl.NewNumber().FmtFloat64(w, 1234.56)         // 1'234.560
+	nl.NewPercent().FmtFloat64(w, 0.25)           // 25%
+	nl.NewScientific().FmtInt64(w, 1234)          // 1.234E3
+	nl.NewNumber().FmtCompact(w, 1234567)         // 1.2 Mio.
+	nl.NewCurrency(currency.CHF).FmtInt64(w, 12)  // CHF 12.00
+	nl.CurrencyName(currency.CHF, "2")            // Schweizer Franken
+
+Percent and per mille patterns multiply the number by 100 or 1000. The
+compact format rounds to two significant digits and chooses the pattern by
+the plural category of the rounded number. PluralRules implements the CLDR
+language plural rules and is also usable on its own. Further locales can be
+added with RegisterNumberLocale.
+
+Translation
+
+A Translator loads the Magento CSV files found in i18n/<locale>.csv of each
+module and theme or gettext PO files. A theme overwrites a module and a
+more specific locale like de_CH overwrites de:
+
+	tr := i18n.NewTranslator(i18n.SetTranslatorMissing(func(locale, id string) {
+		// log the untranslated text
+	}))
+	err := tr.LoadFiles(i18n.PriorityModule, modulePattern) // e.g. app/code/Vendor/Module/i18n/de_DE.csv
+	err = tr.LoadFiles(i18n.PriorityTheme, themePattern)
+	tr.T("de_CH", "Hello %1", "Gopher")  // Hallo Gopher
+	tr.TN("de_CH", "%1 item(s)", 2)      // 2 Artikel
+
+Placeholders %1, %2 ... get replaced by the arguments. Plural forms select
+the text by the CLDR plural category of the number. In CSV files they are
+written as {one}%1 Artikel|{other}%1 Artikel. The package locale provides a
+middleware which binds the Translator to the locale of the requested store.
+
+Dates
+
+A Date formats times with the CLDR month and day names and the date and
+time formats of a locale. Magento stores ICU patterns, PHP code uses date()
+formats, both are supported:
+
+	d := i18n.DateLocaleByTag(language.MustParse("de-DE")).NewDate(i18n.SetDateLocation(loc))
+	d.FmtDateTime(w, t, i18n.DateLong, i18n.DateShort) // 8. August 2005, 15:12
+	d.FmtPHP(w, t, "l, j. F Y")                        // Montag, 8. August 2005
+	d.FmtICU(w, t, "EEE d. MMM")                       // Mo. 8. Aug.
+	d.FmtRelative(w, t, time.Now())                    // vor 3 Tagen
+
+The time gets converted into the location of the Date before formatting.
+The package locale creates a Date with the locale and time zone of a scope.
+
 Currency Format
 
 The currency symbol ¤ specifies where the currency sign will be placed.
@@ -20,7 +20,9 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"math/big"
 	"strconv"
+	"strings"
 
 	"unicode/utf8"
 
@@ -71,10 +73,19 @@ type (
 		// does know to add a leading zero. If prec is shorter than the length of
 		// frac then prec will be adjusted to the frac length.
 		FmtNumber(w io.Writer, sign int, i int64, prec int, frac int64) (int, error)
+		// FmtBigNumber formats like FmtNumber but i can exceed an int64.
+		FmtBigNumber(w io.Writer, sign int, i *big.Int, prec int, frac int64) (int, error)
 		// FmtInt formats an integer according to the format pattern.
 		FmtInt64(w io.Writer, i int64) (int, error)
 		// FmtFloat64 formats a float value, does internal maybe incorrect rounding.
 		FmtFloat64(w io.Writer, f float64) (int, error)
+		// ParseNumber parses a localized number and is the inverse of
+		// FmtNumber. The return values have the same meaning as the arguments
+		// of FmtNumber.
+		ParseNumber(s string) (sign int, i int64, prec int, frac int64, err error)
+		// ParseBigNumber parses like ParseNumber but the integer part can
+		// exceed an int64.
+		ParseBigNumber(s string) (sign int, i *big.Int, prec int, frac int64, err error)
 	}
 
 	Number struct {
@@ -93,6 +104,10 @@ type (
 		// format if different and fracValid is true
 		frac      CurrencyFractions
 		fracValid bool
+		// compact contains the short decimal formats for FmtCompact and
+		// plural selects the pattern.
+		compact []CompactPattern
+		plural  PluralRules
 	}
 
 	// NumberOptions applies options to the Number struct. To read more
@@ -163,11 +178,18 @@ func SetNumberFormat(f string, s ...Symbols) NumberOptions {
 			minusSign: n.sym.MinusSign,
 			decimal:   n.sym.Decimal,
 			group:     n.sym.Group,
+			percent:   n.sym.PercentSign,
+			perMille:  n.sym.PerMille,
+			exp:       n.sym.Exponential,
 			prefix:    make([]byte, formatBufferSize),
 			suffix:    make([]byte, formatBufferSize),
 		}
 		n.fneg = n.fo // copy default format
 		n.fneg.pattern = negFmt
+		// own buffers otherwise parsing the negative format overwrites the
+		// prefix and suffix of the positive format.
+		n.fneg.prefix = make([]byte, formatBufferSize)
+		n.fneg.suffix = make([]byte, formatBufferSize)
 		n.fneg.isNegative = true
 		if len(s) == 1 {
 			return SetNumberFormat(previousF, previousS)
@@ -263,6 +285,16 @@ func (no *Number) FmtNumber(w io.Writer, sign int, intgr int64, prec int, frac i
 		wrote += utf8.EncodeRune(no.buf, usedFmt.plusSign)
 	}
 
+	if usedFmt.shift > 0 {
+		intgr, prec, frac = shiftNumber(sign, intgr, prec, frac, usedFmt.shift)
+	}
+	if usedFmt.scientific {
+		if intgr < 0 {
+			intgr = -intgr
+		}
+		return no.fmtScientific(w, &usedFmt, wrote, sign, strconv.FormatInt(intgr, 10), prec, frac)
+	}
+
 	if no.fracValid {
 		// currency has different precision than in the format. e.g.: japanese Yen.
 		usedFmt.precision = no.frac.Digits
@@ -282,91 +314,118 @@ func (no *Number) FmtNumber(w io.Writer, sign int, intgr int64, prec int, frac i
 	if usedFmt.isNegative && usedFmt.minusSign == 0 && intgr < 0 {
 		intgr = -intgr
 	}
+	return no.fmtDigits(w, &usedFmt, wrote, sign, strconv.FormatInt(intgr, 10), frac)
+}
+
+// FmtBigNumber formats a number like FmtNumber but the integer part can
+// exceed an int64. Returns the number bytes written or an error. Thread
+// safe.
+func (no *Number) FmtBigNumber(w io.Writer, sign int, intgr *big.Int, prec int, frac int64) (int, error) {
+	if intgr.IsInt64() {
+		return no.FmtNumber(w, sign, intgr.Int64(), prec, frac)
+	}
+
+	no.mu.Lock()
+	defer no.mu.Unlock()
+	no.clearBuf()
+
+	if prec < intLen(frac) {
+		return 0, ErrPrecIsTooShort
+	}
+	sign = intgr.Sign()
+
+	usedFmt, err := no.GetFormat(sign < 0)
+	if err != nil {
+		if PkgLog.IsDebug() {
+			PkgLog.Debug("i18n.Number.FmtBigNumber.GetFormat", "err", err, "format", usedFmt.String())
+		}
+		return 0, errgo.Mask(err)
+	}
 
-	// generate integer part string
-	var intStr string = "0"
-	if intgr != 0 {
-		intStr = strconv.FormatInt(intgr, 10) // maybe convert to byte ...
-		if usedFmt.group > 0 {                // add thousand separator if required
-			if intgr < 0 {
+	var wrote int
+	if sign > 0 && usedFmt.plusSign > 0 {
+		wrote += utf8.EncodeRune(no.buf, usedFmt.plusSign)
+	}
+
+	abs := new(big.Int).Abs(intgr)
+	if usedFmt.shift > 0 {
+		prec, frac = shiftBigNumber(abs, prec, frac, usedFmt.shift)
+	}
+	if usedFmt.scientific {
+		return no.fmtScientific(w, &usedFmt, wrote, sign, abs.String(), prec, frac)
+	}
+
+	if no.fracValid {
+		usedFmt.precision = no.frac.Digits
+	}
+
+	frac = usedFmt.adjustFracToPrec(frac, prec)
+
+	if usedFmt.precision == 0 {
+		abs.Add(abs, big.NewInt(frac)) // round away from zero
+	}
+
+	intStr := abs.String()
+	if sign < 0 && (false == usedFmt.isNegative || usedFmt.minusSign > 0) {
+		intStr = string(minusSign) + intStr
+	}
+	return no.fmtDigits(w, &usedFmt, wrote, sign, intStr, frac)
+}
+
+// fmtDigits writes the decimal integer intStr, which can start with a minus
+// sign, with the group separators, the fractional part and the affixes of
+// the format f. wrote contains the bytes already written into the buffer.
+// Must be protected by a mutex.
+func (no *Number) fmtDigits(w io.Writer, f *format, wrote int, sign int, intStr string, frac int64) (int, error) {
+	if intStr != "0" {
+		if f.group > 0 { // add thousand separator if required
+			neg := intStr[0] == '-'
+			if neg {
 				intStr = intStr[1:] // skip the minus sign
 			}
-			gc := string(usedFmt.group)
+			gc := string(f.group)
 			for i := len(intStr); i > 3; {
 				i -= 3
 				intStr = intStr[:i] + gc + intStr[i:]
 			}
-			if intgr < 0 {
+			if neg {
 				intStr = string(minusSign) + intStr // add minus sign back
 			}
 		}
-	} else if false == usedFmt.isNegative && intgr == 0 && frac != 0 && sign < 0 {
-		// no negative format available, number can be -0.000345 but negative
+	} else if (false == f.isNegative || f.minusSign > 0) && frac != 0 && sign < 0 {
+		// no negative format available or the negative format contains a
+		// minus sign, number can be -0.000345 but negative
 		intStr = string(minusSign) + "0" // this minusSign will be replace at the end
 	}
 
-	// no fractional part, we can leave now
-	if usedFmt.precision == 0 {
-
-		if lp := len(usedFmt.prefix); lp > 0 {
-			no.buf = append(no.buf[:wrote], usedFmt.prefix...)
-			wrote += lp
-			no.buf = no.buf[:numberBufferSize] // revert back to old size
-		}
-
-		no.buf = append(no.buf[:wrote], intStr...)
-		no.buf = no.buf[:numberBufferSize] // revert back to old size
-		wrote += len(intStr)
-
-		if ls := len(usedFmt.suffix); ls > 0 {
-			no.buf = append(no.buf[:wrote], usedFmt.suffix...)
-			wrote += ls
-			no.buf = no.buf[:numberBufferSize] // revert back to old size
-		}
+	buf := append(no.buf[:wrote], f.prefix...)
+	buf = append(buf, intStr...)
 
-		return w.Write(no.buf[:wrote])
+	// no fractional part, we can leave now
+	if f.precision == 0 {
+		buf = append(buf, f.suffix...)
+		return w.Write(buf)
 	}
 
 	// generate fractional part, round frac if it's to large to fit into prec
 	fracStr := strconv.FormatInt(frac, 10)
 
 	// may need padding
-	if len(fracStr) < usedFmt.precision {
-		fracStr = "000000000000000"[:usedFmt.precision-len(fracStr)] + fracStr
-	}
-
-	if lp := len(usedFmt.prefix); lp > 0 {
-		no.buf = append(no.buf[:wrote], usedFmt.prefix...)
-		wrote += lp
-		no.buf = no.buf[:numberBufferSize] // revert back to old size
+	if len(fracStr) < f.precision {
+		fracStr = "000000000000000"[:f.precision-len(fracStr)] + fracStr
 	}
 
-	no.buf = append(no.buf[:wrote], intStr...)
-	wrote += len(intStr)
-	no.buf = no.buf[:numberBufferSize] // revert back to old size
-
-	// write decimal separator
-	wrote += utf8.EncodeRune(no.buf[wrote:], usedFmt.decimal)
-	no.buf = append(no.buf[:wrote], fracStr...)
-	wrote += len(fracStr)
-	no.buf = no.buf[:numberBufferSize] // revert back to old size
-
-	// write suffix
-	if ls := len(usedFmt.suffix); ls > 0 {
-		no.buf = append(no.buf[:wrote], usedFmt.suffix...)
-		wrote += ls
-		no.buf = no.buf[:numberBufferSize] // revert back to old size
-	}
+	buf = appendRune(buf, f.decimal)
+	buf = append(buf, fracStr...)
+	buf = append(buf, f.suffix...)
 
 	// if we have a minus sign replace the minus with the format sign
-	if usedFmt.minusSign > 0 {
+	if f.minusSign > 0 {
 		var mBuf [4]byte
-		mWritten := utf8.EncodeRune(mBuf[:], usedFmt.minusSign)
-		wrote += mWritten - 1 // check why we need here a -1 and trim does not work
-		no.buf = bytes.Replace(no.buf[:wrote], minusSign, mBuf[:mWritten], 1)
+		mWritten := utf8.EncodeRune(mBuf[:], f.minusSign)
+		return w.Write(bytes.Replace(buf, minusSign, mBuf[:mWritten], 1))
 	}
-
-	return w.Write(no.buf[:wrote])
+	return w.Write(buf)
 }
 
 // FmtInt64 formats an integer according to the format pattern.
@@ -427,8 +486,12 @@ func (no *Number) FmtFloat64(w io.Writer, f float64) (int, error) {
 		return 0, errgo.Mask(err)
 	}
 
+	// percent and per mille formats multiply the number later in FmtNumber,
+	// so more fractional digits must be kept.
+	prec := usedFmt.precision + usedFmt.shift
+
 	// to test the next lines: http://play.golang.org/p/L0ykFv3G4B
-	precPow10 := math.Pow10(usedFmt.precision)
+	precPow10 := math.Pow10(prec)
 
 	var modf float64
 	if f > 0 {
@@ -442,9 +505,12 @@ func (no *Number) FmtFloat64(w io.Writer, f float64) (int, error) {
 		fracf = -fracf
 	}
 
-	fracI := int64(util.Round(fracf*precPow10, 0, usedFmt.precision))
+	fracI := int64(util.Round(fracf*precPow10, 0, prec))
 
-	return no.FmtNumber(w, sign, int64(intgr), intLen(fracI), fracI)
+	if l := intLen(fracI); l > prec {
+		prec = l // rounding carry, e.g. 0.95 with precision 0
+	}
+	return no.FmtNumber(w, sign, int64(intgr), prec, fracI)
 }
 
 func (no *Number) clearBuf() {
@@ -469,6 +535,16 @@ type format struct {
 	minusSign  rune
 	decimal    rune
 	group      rune
+	percent    rune
+	perMille   rune
+	exp        rune
+	// shift moves the decimal point to the right, 2 for percent and 3 for
+	// per mille formats.
+	shift int
+	// scientific is true if the pattern contains an exponent like #E0 and
+	// expDigits contains the minimum amount of exponent digits.
+	scientific bool
+	expDigits  int
 	prefix     []byte
 	suffix     []byte
 }
@@ -546,7 +622,24 @@ func (f *format) parse() error {
 	suffixStart, precStart := false, false
 	hasGroup, hasPlus, hasMinus := false, false, false
 	precCount := 0
+	// a pattern without digits like $%^ contains only literals
+	hasDigits := strings.ContainsAny(string(f.pattern), "#0")
 	for _, c := range f.pattern {
+		switch {
+		case c == '%' && hasDigits:
+			f.shift, c = 2, f.percent
+		case c == '‰' && hasDigits:
+			f.shift, c = 3, f.perMille
+		case c == 'E' && suffixStart:
+			f.scientific = true
+			continue
+		case c == '0' && f.scientific:
+			f.expDigits++
+			continue
+		case c == '+' && f.scientific:
+			continue // always show the sign of the exponent is not supported
+		}
+
 		switch c {
 		case '+':
 			hasPlus = true
@@ -606,3 +699,120 @@ func intLen(n int64) int {
 func isInt(f float64) bool {
 	return int64(math.Floor(f)) == int64(math.Ceil(f))
 }
+
+// shiftNumber multiplies a number by 10^n, e.g. for the percent format.
+// Returns the new integer, precision and fractional part.
+func shiftNumber(sign int, intgr int64, prec int, frac int64, n int) (int64, int, int64) {
+	if intgr < 0 {
+		intgr = -intgr
+	}
+	p := pow10i(n)
+	if prec >= n {
+		d := pow10i(prec - n)
+		intgr = intgr*p + frac/d
+		frac %= d
+		prec -= n
+	} else {
+		intgr = intgr*p + frac*pow10i(n-prec)
+		frac, prec = 0, 0
+	}
+	if sign < 0 {
+		intgr = -intgr
+	}
+	return intgr, prec, frac
+}
+
+// shiftBigNumber multiplies a positive number by 10^n like shiftNumber. The
+// integer part gets modified. Returns the new precision and fractional part.
+func shiftBigNumber(intgr *big.Int, prec int, frac int64, n int) (int, int64) {
+	intgr.Mul(intgr, big.NewInt(pow10i(n)))
+	if prec >= n {
+		d := pow10i(prec - n)
+		intgr.Add(intgr, big.NewInt(frac/d))
+		return prec - n, frac % d
+	}
+	intgr.Add(intgr, big.NewInt(frac*pow10i(n-prec)))
+	return 0, 0
+}
+
+// fmtScientific writes a number with one integer digit and an exponent,
+// e.g. 1.234E3. If the format has no fractional digits all significant
+// digits get written otherwise the mantissa gets rounded half up. wrote
+// contains the bytes already written into the buffer. Must be protected by
+// a mutex. intStr contains the digits of the absolute integer part.
+func (no *Number) fmtScientific(w io.Writer, f *format, wrote int, sign int, intStr string, prec int, frac int64) (int, error) {
+	if intStr == "0" {
+		intStr = ""
+	}
+	fracStr := strconv.FormatInt(frac, 10)
+	if frac == 0 {
+		fracStr = ""
+	}
+	for len(fracStr) > 0 && len(fracStr) < prec {
+		fracStr = "0" + fracStr
+	}
+
+	digits := strings.TrimLeft(intStr+fracStr, "0")
+	exp := len(intStr) - 1 - (len(intStr) + len(fracStr) - len(digits))
+	if intStr == "" {
+		exp = -1 - (len(fracStr) - len(digits))
+	}
+	digits = strings.TrimRight(digits, "0")
+	if digits == "" {
+		digits, exp = "0", 0
+	}
+
+	if max := f.precision + 1; f.precision > 0 && len(digits) > max {
+		roundUp := digits[max] >= '5'
+		digits = digits[:max]
+		if roundUp {
+			m, _ := strconv.ParseInt(digits, 10, 64)
+			digits = strconv.FormatInt(m+1, 10)
+			if len(digits) > max {
+				digits = digits[:max]
+				exp++
+			}
+		}
+		digits = strings.TrimRight(digits, "0")
+	}
+
+	buf := append(no.buf[:wrote], f.prefix...)
+	if sign < 0 {
+		if f.minusSign > 0 {
+			buf = appendRune(buf, f.minusSign)
+		} else {
+			buf = append(buf, minusSign...)
+		}
+	}
+	buf = append(buf, digits[0])
+	if len(digits) > 1 {
+		buf = appendRune(buf, f.decimal)
+		buf = append(buf, digits[1:]...)
+	}
+	buf = appendRune(buf, f.exp)
+	if exp < 0 {
+		buf = append(buf, minusSign...)
+		exp = -exp
+	}
+	expStr := strconv.Itoa(exp)
+	for i := len(expStr); i < f.expDigits; i++ {
+		buf = append(buf, '0')
+	}
+	buf = append(buf, expStr...)
+	buf = append(buf, f.suffix...)
+	return w.Write(buf)
+}
+
+func appendRune(buf []byte, r rune) []byte {
+	var rb [utf8.UTFMax]byte
+	return append(buf, rb[:utf8.EncodeRune(rb[:], r)]...)
+}
+
+// pow10i returns 10^n as an integer.
+func pow10i(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
@@ -0,0 +1,161 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CompactPattern defines a short decimal format of CLDR, e.g. 0K for
+// thousands in English or 0 Mio'.' for millions in German.
+type CompactPattern struct {
+	// Type is the power of ten the pattern starts with, e.g. 1000.
+	Type int64
+	// Count is the plural category, mostly PluralOne or PluralOther.
+	Count string
+	// Pattern contains the zeros which get replaced by the number. The
+	// amount of zeros defines the amount of integer digits. Text in single
+	// quotes is a literal. The pattern "0" disables the compact format.
+	Pattern string
+}
+
+// SetNumberCompact sets the short decimal formats for FmtCompact. The
+// plural rules select the pattern by the plural category of the number.
+func SetNumberCompact(pr PluralRules, cps ...CompactPattern) NumberOptions {
+	return func(n *Number) NumberOptions {
+		prevPR, prevCPS := n.plural, n.compact
+		n.plural, n.compact = pr, cps
+		return SetNumberCompact(prevPR, prevCPS...)
+	}
+}
+
+// FmtCompact formats an integer in the short compact form like 1.2K or
+// 12 Mio. The number gets rounded to two significant digits, but all integer
+// digits are kept: 1234 => 1.2K, 12345 => 12K and 123456 => 123K. Numbers
+// smaller than the smallest pattern or without patterns get formatted with
+// FmtInt64. Thread safe.
+func (no *Number) FmtCompact(w io.Writer, i int64) (int, error) {
+	no.mu.RLock()
+	cps, pr, dec := no.compact, no.plural, no.fo.decimal
+	no.mu.RUnlock()
+
+	abs := i
+	if abs < 0 {
+		abs = -abs
+	}
+
+	typ, zeros := compactType(cps, abs)
+	if zeros == 0 {
+		return no.FmtInt64(w, i)
+	}
+	intgr, tenth := compactRound(abs, typ, zeros)
+	if intgr >= pow10i(zeros) {
+		// rounding moved the number into the next pattern: 999999 => 1M
+		typ, zeros = compactType(cps, intgr*(typ/pow10i(zeros-1)))
+		if zeros == 0 {
+			return no.FmtInt64(w, i)
+		}
+		intgr, tenth = compactRound(abs, typ, zeros)
+	}
+
+	number := strconv.FormatInt(intgr, 10)
+	if tenth > 0 {
+		number += "." + strconv.FormatInt(tenth, 10)
+	}
+	pattern := compactPattern(cps, typ, pr.Category(number))
+	if tenth > 0 && dec > 0 {
+		number = strings.Replace(number, ".", string(dec), 1)
+	}
+	if i < 0 {
+		number = string(minusSign) + number
+	}
+	return w.Write(renderCompact(pattern, number))
+}
+
+// compactType returns the largest type smaller or equal abs and the amount
+// of zeros of its pattern. Zeros is 0 if no compact format can be applied.
+func compactType(cps []CompactPattern, abs int64) (typ int64, zeros int) {
+	for _, cp := range cps {
+		if cp.Type <= abs && cp.Type > typ {
+			typ = cp.Type
+		}
+	}
+	p := compactPattern(cps, typ, PluralOther)
+	if typ == 0 || p == "" || p == "0" {
+		return 0, 0
+	}
+	return typ, strings.Count(p, "0")
+}
+
+// compactRound divides abs and rounds half up to two significant digits.
+func compactRound(abs, typ int64, zeros int) (intgr, tenth int64) {
+	div := typ / pow10i(zeros-1)
+	if div < 1 {
+		div = 1
+	}
+	q, r := abs/div, abs%div
+	if q < 10 && div > 1 {
+		t := q*10 + (r*10+div/2)/div
+		return t / 10, t % 10
+	}
+	if r*2 >= div && div > 1 {
+		q++
+	}
+	return q, 0
+}
+
+// compactPattern returns the pattern for the type and plural category and
+// falls back to PluralOther.
+func compactPattern(cps []CompactPattern, typ int64, count string) string {
+	var other string
+	for _, cp := range cps {
+		if cp.Type != typ {
+			continue
+		}
+		if cp.Count == count {
+			return cp.Pattern
+		}
+		if cp.Count == PluralOther || cp.Count == "" {
+			other = cp.Pattern
+		}
+	}
+	return other
+}
+
+// renderCompact replaces the zeros in the pattern with the number and
+// removes the quotes of literals.
+func renderCompact(pattern, number string) []byte {
+	buf := make([]byte, 0, len(pattern)+len(number))
+	written, quoted := false, false
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\'' && i+1 < len(pattern) && pattern[i+1] == '\'':
+			buf = append(buf, c)
+			i++
+		case c == '\'':
+			quoted = !quoted
+		case c == '0' && false == quoted:
+			if false == written {
+				buf = append(buf, number...)
+				written = true
+			}
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
@@ -0,0 +1,214 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"sync"
+
+	"github.com/juju/errgo"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+)
+
+// NumberLocale contains the CLDR number symbols, formats, plural rules and
+// currency names of a locale. The data gets generated by the program in
+// codegen/localization and registered with RegisterNumberLocale.
+type NumberLocale struct {
+	// Locale like de_CH, en or fr.
+	Locale string
+	// Symbols of the latn numbering system.
+	Symbols Symbols
+	// Decimal, Percent, Scientific, Currency and Accounting contain the
+	// standard patterns, e.g. #,##0.###, #,##0%, #E0, ¤#,##0.00 and
+	// ¤#,##0.00;(¤#,##0.00).
+	Decimal    string
+	Percent    string
+	Scientific string
+	Currency   string
+	Accounting string
+	// Compact contains the short decimal formats like 0K.
+	Compact []CompactPattern
+	// Plural contains the plural rules per category, e.g.
+	// "one": "i = 1 and v = 0".
+	Plural map[string]string
+	// Currencies contains the names and symbols. Key is the 3-letter ISO
+	// 4217 code.
+	Currencies map[string]CurrencyLocale
+
+	plural PluralRules
+}
+
+// CurrencyLocale contains the localized names of a currency.
+type CurrencyLocale struct {
+	Symbol      string
+	DisplayName string
+	// Names contains the long names per plural category, e.g.
+	// "one": "US dollar" and "other": "US dollars".
+	Names map[string]string
+}
+
+var numberLocales = struct {
+	sync.RWMutex
+	m map[string]NumberLocale
+}{
+	m: make(map[string]NumberLocale),
+}
+
+// defaultNumberLocale gets used when no locale has been registered.
+var defaultNumberLocale = NumberLocale{
+	Locale:     LocaleDefault,
+	Symbols:    defaultSymbols,
+	Decimal:    DefaultNumberFormat,
+	Percent:    "#,##0%",
+	Scientific: "#E0",
+	Currency:   DefaultCurrencyFormat,
+	Accounting: DefaultCurrencyFormat,
+}
+
+// RegisterNumberLocale adds or replaces the number data of locales. Returns
+// an error if the plural rules cannot be parsed. Thread safe.
+func RegisterNumberLocale(nls ...NumberLocale) error {
+	numberLocales.Lock()
+	defer numberLocales.Unlock()
+	for _, nl := range nls {
+		pr, err := NewPluralRules(nl.Plural)
+		if err != nil {
+			return errgo.Notef(err, "Locale %q", nl.Locale)
+		}
+		nl.plural = pr
+		numberLocales.m[nl.Locale] = nl
+	}
+	return nil
+}
+
+// NumberLocaleByTag returns the number data of the best matching locale.
+// For de-CH the locale de_CH gets searched and then de. If nothing can be
+// found the data of LocaleDefault or the package defaults get returned, use
+// LookupNumberLocale to detect locales without registered data. Thread safe.
+func NumberLocaleByTag(t language.Tag) NumberLocale {
+	if nl, ok := LookupNumberLocale(t); ok {
+		return nl
+	}
+	if nl, ok := LookupNumberLocale(language.Make(LocaleDefault)); ok {
+		return nl
+	}
+	return defaultNumberLocale
+}
+
+// LookupNumberLocale returns the number data of the best matching locale
+// like NumberLocaleByTag but without falling back to LocaleDefault. The bool
+// reports if data for the language of the tag has been registered. Thread
+// safe.
+func LookupNumberLocale(t language.Tag) (NumberLocale, bool) {
+	numberLocales.RLock()
+	defer numberLocales.RUnlock()
+	for _, k := range localeKeys(t) {
+		if nl, ok := numberLocales.m[k]; ok {
+			return nl, true
+		}
+	}
+	return NumberLocale{}, false
+}
+
+// localeKeys returns the registry keys to search for a tag in the order
+// base_script_region, base_region, base_script and base.
+func localeKeys(t language.Tag) []string {
+	var keys []string
+	b, _ := t.Base()
+	s, sc := t.Script()
+	r, rc := t.Region()
+	if rc == language.Exact {
+		if sc == language.Exact {
+			keys = append(keys, b.String()+LocaleSeparator+s.String()+LocaleSeparator+r.String())
+		}
+		keys = append(keys, b.String()+LocaleSeparator+r.String())
+	}
+	if sc == language.Exact {
+		keys = append(keys, b.String()+LocaleSeparator+s.String())
+	}
+	return append(keys, b.String())
+}
+
+// NewNumber creates a decimal number formatter including the compact
+// formats.
+func (nl NumberLocale) NewNumber(opts ...NumberOptions) *Number {
+	return NewNumber(append([]NumberOptions{
+		SetNumberFormat(nl.Decimal, nl.Symbols),
+		SetNumberCompact(nl.plural, nl.Compact...),
+	}, opts...)...)
+}
+
+// NewPercent creates a percent formatter. 0.25 gets formatted as 25%.
+func (nl NumberLocale) NewPercent(opts ...NumberOptions) *Number {
+	return NewNumber(append([]NumberOptions{SetNumberFormat(nl.Percent, nl.Symbols)}, opts...)...)
+}
+
+// NewScientific creates a formatter for the scientific notation like 1.2E3.
+func (nl NumberLocale) NewScientific(opts ...NumberOptions) *Number {
+	return NewNumber(append([]NumberOptions{SetNumberFormat(nl.Scientific, nl.Symbols)}, opts...)...)
+}
+
+// NewCurrency creates a currency formatter with the localized symbol and the
+// fraction digits of the currency.
+func (nl NumberLocale) NewCurrency(u currency.Unit, opts ...CurrencyOptions) *Currency {
+	return nl.newCurrency(nl.Currency, u, opts)
+}
+
+// NewAccounting creates a currency formatter with the accounting format,
+// which uses in many locales parentheses for negative amounts.
+func (nl NumberLocale) NewAccounting(u currency.Unit, opts ...CurrencyOptions) *Currency {
+	return nl.newCurrency(nl.Accounting, u, opts)
+}
+
+func (nl NumberLocale) newCurrency(f string, u currency.Unit, opts []CurrencyOptions) *Currency {
+	sign := nl.Currencies[u.String()].Symbol
+	if sign == "" {
+		sign = u.String()
+	}
+	scale, inc := currency.Standard.Rounding(u)
+	cashScale, cashInc := currency.Cash.Rounding(u)
+	return NewCurrency(append([]CurrencyOptions{
+		SetCurrencyISO(u.String()),
+		SetCurrencySign([]byte(sign)),
+		SetCurrencyFormat(f, nl.Symbols),
+		SetCurrencyFraction(scale, inc, cashScale, cashInc),
+	}, opts...)...)
+}
+
+// PluralCategory returns the plural category of a number formatted with a
+// dot as decimal separator. See PluralRules.Category.
+func (nl NumberLocale) PluralCategory(number string) string {
+	return nl.plural.Category(number)
+}
+
+// CurrencyName returns the long name of a currency in the plural form of
+// the number, e.g. "1 US dollar" and "2 US dollars". Falls back to the
+// display name and then to the ISO code.
+func (nl NumberLocale) CurrencyName(u currency.Unit, number string) string {
+	cl, ok := nl.Currencies[u.String()]
+	if !ok {
+		return u.String()
+	}
+	if n, ok := cl.Names[nl.plural.Category(number)]; ok {
+		return n
+	}
+	if n, ok := cl.Names[PluralOther]; ok {
+		return n
+	}
+	if cl.DisplayName != "" {
+		return cl.DisplayName
+	}
+	return u.String()
+}
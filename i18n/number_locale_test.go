@@ -0,0 +1,114 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/corestoreio/csfw/locale"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+)
+
+func TestNumberLocaleByTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"de-CH", "de_CH"},
+		{"de-AT", "de_AT"},
+		{"de-LU", "de"},
+		{"de", "de"},
+		{"fr-CA", "fr_CA"},
+		{"fr-BE", "fr"},
+		{"en-US", "en_US"},
+		{"eo", "en_US"},
+	}
+	for _, test := range tests {
+		assert.Exactly(t, test.want, i18n.NumberLocaleByTag(language.MustParse(test.tag)).Locale, "Tag %q", test.tag)
+	}
+}
+
+func TestLookupNumberLocale(t *testing.T) {
+	nl, ok := i18n.LookupNumberLocale(language.MustParse("de-LU"))
+	assert.True(t, ok)
+	assert.Exactly(t, "de", nl.Locale)
+
+	nl, ok = i18n.LookupNumberLocale(language.MustParse("eo"))
+	assert.False(t, ok)
+	assert.Exactly(t, "", nl.Locale)
+}
+
+func TestLookupLocaleAllowedLocales(t *testing.T) {
+	for _, l := range locale.AllowedLocales {
+		tag, err := i18n.GetLocaleTag(l)
+		if !assert.NoError(t, err, "Locale %s", l) {
+			continue
+		}
+		nl, ok := i18n.LookupNumberLocale(tag)
+		assert.True(t, ok, "Missing number data for locale %s", l)
+		assert.NotEmpty(t, nl.Currencies, "Missing currencies for locale %s", l)
+		_, ok = i18n.LookupDateLocale(tag)
+		assert.True(t, ok, "Missing date data for locale %s", l)
+	}
+}
+
+func TestNumberLocaleFormats(t *testing.T) {
+	en := i18n.NumberLocaleByTag(language.English)
+	de := i18n.NumberLocaleByTag(language.German)
+
+	tests := []struct {
+		fmt  func(buf *bytes.Buffer) (int, error)
+		want string
+	}{
+		{func(buf *bytes.Buffer) (int, error) { return en.NewNumber().FmtFloat64(buf, 1234.5678) }, "1,234.568"},
+		{func(buf *bytes.Buffer) (int, error) { return de.NewNumber().FmtFloat64(buf, 1234.5678) }, "1.234,568"},
+		{func(buf *bytes.Buffer) (int, error) { return en.NewPercent().FmtFloat64(buf, 0.25) }, "25%"},
+		{func(buf *bytes.Buffer) (int, error) { return de.NewPercent().FmtFloat64(buf, 0.25) }, "25 %"},
+		{func(buf *bytes.Buffer) (int, error) { return en.NewScientific().FmtInt64(buf, 1234) }, "1.234E3"},
+		{func(buf *bytes.Buffer) (int, error) { return en.NewNumber().FmtCompact(buf, 999) }, "999.000"},
+		{func(buf *bytes.Buffer) (int, error) { return en.NewNumber().FmtCompact(buf, 1234) }, "1.2K"},
+		{func(buf *bytes.Buffer) (int, error) { return en.NewNumber().FmtCompact(buf, -12345) }, "-12K"},
+		{func(buf *bytes.Buffer) (int, error) { return en.NewNumber().FmtCompact(buf, 999999) }, "1M"},
+		{func(buf *bytes.Buffer) (int, error) { return de.NewNumber().FmtCompact(buf, 1234) }, "1.234,000"},
+		{func(buf *bytes.Buffer) (int, error) { return de.NewNumber().FmtCompact(buf, 1234567) }, "1,2 Mio."},
+		{func(buf *bytes.Buffer) (int, error) { return en.NewCurrency(currency.USD).FmtFloat64(buf, 1234.5) }, "$1,234.50"},
+		{func(buf *bytes.Buffer) (int, error) { return de.NewCurrency(currency.EUR).FmtFloat64(buf, 1234.5) }, "1.234,50 €"},
+		{func(buf *bytes.Buffer) (int, error) { return en.NewCurrency(currency.JPY).FmtFloat64(buf, 1234.5) }, "¥1,235"},
+		{func(buf *bytes.Buffer) (int, error) { return en.NewAccounting(currency.USD).FmtFloat64(buf, -5) }, "($5.00)"},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		_, err := test.fmt(&buf)
+		assert.NoError(t, err, "Index %d", i)
+		assert.Exactly(t, test.want, buf.String(), "Index %d", i)
+	}
+}
+
+func TestNumberLocaleCurrencyName(t *testing.T) {
+	en := i18n.NumberLocaleByTag(language.English)
+	fr := i18n.NumberLocaleByTag(language.French)
+
+	assert.Exactly(t, "US dollar", en.CurrencyName(currency.USD, "1"))
+	assert.Exactly(t, "US dollars", en.CurrencyName(currency.USD, "1.00"))
+	assert.Exactly(t, "dollar des États-Unis", fr.CurrencyName(currency.USD, "1.5"))
+	assert.Exactly(t, "dollars des États-Unis", fr.CurrencyName(currency.USD, "2"))
+	assert.Exactly(t, "Australian dollars", en.CurrencyName(currency.AUD, "2"))
+	assert.Exactly(t, "AUD", i18n.NumberLocale{}.CurrencyName(currency.AUD, "2"))
+	assert.Exactly(t, i18n.PluralOne, fr.PluralCategory("0"))
+}
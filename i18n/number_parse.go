@@ -0,0 +1,330 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/juju/errgo"
+)
+
+// maxParseDigits maximum amount of digits of the integer or fractional part
+// which fit into an int64.
+const maxParseDigits = 18
+
+var (
+	ErrNumberInvalid  = errors.New("Cannot parse number")
+	ErrNumberOverflow = errors.New("Number overflows int64")
+)
+
+// ParseNumber parses a localized number and is the inverse of FmtNumber. The
+// decimal and the grouping separator of the format are required, so
+// "1.234,56" is valid for a German and invalid for an English formatter. A
+// grouping separator which is a space also accepts all other spaces and an
+// apostrophe accepts all other apostrophes. Negative numbers can be written
+// with a leading or trailing minus sign or in parentheses (accounting
+// format). The characters of the prefix and the suffix of the formats, like
+// %, and spaces get ignored. Percent and per mille formats divide the number,
+// so "25 %" gets parsed as 0.25. The returned values have the same meaning as
+// the arguments of FmtNumber: sign is -1 or +1, intgr contains the sign if
+// not zero and prec specifies the length of frac. Errors have as cause
+// ErrNumberInvalid or ErrNumberOverflow. Thread safe.
+func (no *Number) ParseNumber(s string) (sign int, intgr int64, prec int, frac int64, err error) {
+	no.mu.Lock()
+	defer no.mu.Unlock()
+
+	p, err := no.parser()
+	if err != nil {
+		return 0, 0, 0, 0, errgo.Mask(err)
+	}
+	return p.parse(s)
+}
+
+// ParseBigNumber parses a localized number like ParseNumber but the integer
+// part can exceed an int64. Thread safe.
+func (no *Number) ParseBigNumber(s string) (sign int, intgr *big.Int, prec int, frac int64, err error) {
+	no.mu.Lock()
+	defer no.mu.Unlock()
+
+	p, err := no.parser()
+	if err != nil {
+		return 0, nil, 0, 0, errgo.Mask(err)
+	}
+	return p.parseBig(s)
+}
+
+// parser creates the parser with the symbols of the formats. Must be
+// protected by a mutex.
+func (no *Number) parser() (numberParser, error) {
+	fo, err := no.GetFormat(false)
+	if err != nil {
+		return numberParser{}, errgo.Mask(err)
+	}
+	fneg, err := no.GetFormat(true)
+	if err != nil {
+		return numberParser{}, errgo.Mask(err)
+	}
+
+	group := fo.group
+	if group == 0 {
+		group = no.sym.Group
+	}
+	return numberParser{
+		decimal: fo.decimal,
+		group:   group,
+		minus:   [...]rune{fo.minusSign, fneg.minusSign, no.sym.MinusSign},
+		plus:    [...]rune{fo.plusSign, no.sym.PlusSign},
+		affixes: string(fo.prefix) + string(fo.suffix) + string(fneg.prefix) + string(fneg.suffix),
+		shift:   fo.shift,
+	}, nil
+}
+
+// ParseNumber parses a localized currency amount and is the inverse of
+// FmtNumber. The currency sign and the 3-letter ISO code are optional and
+// can be on both sides of the number. For details see Number.ParseNumber.
+// Thread safe.
+func (c *Currency) ParseNumber(s string) (sign int, intgr int64, prec int, frac int64, err error) {
+	return c.Number.ParseNumber(c.trimSign(s))
+}
+
+// ParseBigNumber parses a localized currency amount like ParseNumber but
+// the integer part can exceed an int64. Thread safe.
+func (c *Currency) ParseBigNumber(s string) (sign int, intgr *big.Int, prec int, frac int64, err error) {
+	return c.Number.ParseBigNumber(c.trimSign(s))
+}
+
+// trimSign removes the first currency sign or ISO code from s.
+func (c *Currency) trimSign(s string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, sgn := range [...]string{string(c.sgn), c.ISO.String()} {
+		if sgn != "" && strings.Contains(s, sgn) {
+			return strings.Replace(s, sgn, "", 1)
+		}
+	}
+	return s
+}
+
+// numberParser contains the symbols of a format required for parsing.
+type numberParser struct {
+	decimal rune
+	group   rune
+	minus   [3]rune
+	plus    [2]rune
+	affixes string
+	// shift of the percent and per mille formats moves the decimal point
+	// back to the left.
+	shift int
+}
+
+func (p numberParser) parse(s string) (sign int, intgr int64, prec int, frac int64, err error) {
+	sign, intDigits, fracDigits, err := p.digits(s)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	if len(intDigits) > maxParseDigits || len(fracDigits) > maxParseDigits {
+		return 0, 0, 0, 0, errgo.WithCausef(nil, ErrNumberOverflow, "%s: %q", ErrNumberOverflow, s)
+	}
+	if len(intDigits) > 0 {
+		intgr, _ = strconv.ParseInt(string(intDigits), 10, 64)
+	}
+	if len(fracDigits) > 0 {
+		frac, _ = strconv.ParseInt(string(fracDigits), 10, 64)
+	}
+	if sign < 0 {
+		intgr = -intgr
+	}
+	return sign, intgr, len(fracDigits), frac, nil
+}
+
+func (p numberParser) parseBig(s string) (sign int, intgr *big.Int, prec int, frac int64, err error) {
+	sign, intDigits, fracDigits, err := p.digits(s)
+	if err != nil {
+		return 0, nil, 0, 0, err
+	}
+	if len(fracDigits) > maxParseDigits {
+		return 0, nil, 0, 0, errgo.WithCausef(nil, ErrNumberOverflow, "%s: %q", ErrNumberOverflow, s)
+	}
+	intgr = new(big.Int)
+	if len(intDigits) > 0 {
+		intgr.SetString(string(intDigits), 10)
+	}
+	if len(fracDigits) > 0 {
+		frac, _ = strconv.ParseInt(string(fracDigits), 10, 64)
+	}
+	if sign < 0 {
+		intgr.Neg(intgr)
+	}
+	return sign, intgr, len(fracDigits), frac, nil
+}
+
+// digits returns the sign, the integer and the fractional digits without
+// leading respectively trailing zeros.
+func (p numberParser) digits(s string) (sign int, intDigits, fracDigits []byte, err error) {
+	// the number starts at the first and ends after the last digit
+	start := strings.IndexFunc(s, isASCIIDigit)
+	if start < 0 {
+		return 0, nil, nil, p.errorf(s)
+	}
+	end := strings.LastIndexFunc(s, isASCIIDigit) + 1
+	if r, w := utf8.DecodeLastRuneInString(s[:start]); r == p.decimal {
+		start -= w // e.g. .5 or -,5
+	}
+
+	// groups counts the digits after a grouping separator. Only the last
+	// group must have three digits, e.g. 12,34,567 in India.
+	var hasDecimal, hasGroup bool
+	var groups int
+	for _, r := range s[start:end] {
+		switch {
+		case isASCIIDigit(r) && hasDecimal:
+			fracDigits = append(fracDigits, byte(r))
+		case isASCIIDigit(r):
+			intDigits = append(intDigits, byte(r))
+			groups++
+		case r == p.decimal && false == hasDecimal && (false == hasGroup || groups == 3):
+			hasDecimal = true
+		case p.isGroup(r) && false == hasDecimal && (false == hasGroup || groups == 2 || groups == 3) && groups > 0:
+			hasGroup = true
+			groups = 0
+		default:
+			return 0, nil, nil, p.errorf(s)
+		}
+	}
+	if hasGroup && false == hasDecimal && groups != 3 {
+		return 0, nil, nil, p.errorf(s)
+	}
+
+	neg, plus, open, closed := 0, 0, false, false
+	for i, r := range s {
+		if i >= start && i < end {
+			continue
+		}
+		switch {
+		case r == '(' && i < start && false == open:
+			open = true
+		case r == ')' && i >= end && false == closed:
+			closed = true
+		case p.isMinus(r):
+			neg++
+		case p.isPlus(r):
+			plus++
+		case unicode.IsSpace(r), isBidiMark(r), r > 0 && strings.ContainsRune(p.affixes, r):
+			// ignore
+		default:
+			return 0, nil, nil, p.errorf(s)
+		}
+	}
+	if open != closed || neg+plus > 1 || (open && neg+plus > 0) {
+		return 0, nil, nil, p.errorf(s)
+	}
+
+	sign = 1
+	if neg > 0 || open {
+		sign = -1
+	}
+	if p.shift > 0 {
+		intDigits, fracDigits = unshiftDigits(intDigits, fracDigits, p.shift)
+	}
+	return sign, trimLeft(intDigits), trimRight(fracDigits), nil
+}
+
+// unshiftDigits divides the number by 10^n and is the inverse of
+// shiftNumber, e.g. 25% gets parsed as 0.25.
+func unshiftDigits(intDigits, fracDigits []byte, n int) ([]byte, []byte) {
+	for len(intDigits) < n {
+		intDigits = append([]byte{'0'}, intDigits...)
+	}
+	i := len(intDigits) - n
+	frac := make([]byte, 0, n+len(fracDigits))
+	frac = append(append(frac, intDigits[i:]...), fracDigits...)
+	return intDigits[:i], frac
+}
+
+func (p numberParser) errorf(s string) error {
+	return errgo.WithCausef(nil, ErrNumberInvalid, "%s: %q with decimal %q and group %q", ErrNumberInvalid, s, p.decimal, p.group)
+}
+
+func (p numberParser) isGroup(r rune) bool {
+	switch {
+	case p.group == 0 || p.group == p.decimal:
+		return false
+	case r == p.group:
+		return true
+	case unicode.IsSpace(p.group):
+		return unicode.IsSpace(r)
+	case isApostrophe(p.group):
+		return isApostrophe(r)
+	}
+	return false
+}
+
+func (p numberParser) isMinus(r rune) bool {
+	switch r {
+	case '-', '\u2212', '\u2013', '\u2014': // hyphen-minus, minus, en and em dash
+		return true
+	}
+	for _, m := range p.minus {
+		if m > 0 && m == r {
+			return true
+		}
+	}
+	return false
+}
+
+func (p numberParser) isPlus(r rune) bool {
+	for _, m := range p.plus {
+		if m > 0 && m == r {
+			return true
+		}
+	}
+	return r == '+'
+}
+
+func isASCIIDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’' || r == 'ʼ'
+}
+
+// isBidiMark reports the left-to-right, right-to-left and Arabic letter
+// marks which surround numbers in Arabic, Hebrew or Persian locales.
+func isBidiMark(r rune) bool {
+	return r == '\u200e' || r == '\u200f' || r == '\u061c'
+}
+
+// trimLeft removes leading zeros
+func trimLeft(d []byte) []byte {
+	for len(d) > 0 && d[0] == '0' {
+		d = d[1:]
+	}
+	return d
+}
+
+// trimRight removes trailing zeros if there are more digits than an int64
+// can handle.
+func trimRight(d []byte) []byte {
+	for len(d) > maxParseDigits && d[len(d)-1] == '0' {
+		d = d[:len(d)-1]
+	}
+	return d
+}
@@ -0,0 +1,180 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n_test
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/corestoreio/csfw/locale"
+	"github.com/juju/errgo"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/currency"
+)
+
+func TestNumberParseNumber(t *testing.T) {
+	en := i18n.NewNumber(i18n.SetNumberFormat("#,##0.00;(#,##0.00)"))
+	de := i18n.NewNumber(i18n.SetNumberFormat("#,##0.00", i18n.Symbols{Decimal: ',', Group: '.'}))
+	ch := i18n.NewNumber(i18n.SetNumberFormat("#,##0.00", i18n.Symbols{Decimal: '.', Group: '’'}))
+	fr := i18n.NewNumber(i18n.SetNumberFormat("#,##0.00", i18n.Symbols{Decimal: ',', Group: ' '}))
+
+	tests := []struct {
+		nf        i18n.NumberFormatter
+		have      string
+		wantSign  int
+		wantI     int64
+		wantPrec  int
+		wantFrac  int64
+		wantCause error
+	}{
+		{en, "1,234.56", 1, 1234, 2, 56, nil},
+		{en, " +1234.5 ", 1, 1234, 1, 5, nil},
+		{en, "(1,234.56)", -1, -1234, 2, 56, nil},
+		{en, "-0.05", -1, 0, 2, 5, nil},
+		{en, ".5", 1, 0, 1, 5, nil},
+		{en, "1.234", 1, 1, 3, 234, nil},
+		{en, "1,234", 1, 1234, 0, 0, nil},
+		{en, "1234−", -1, -1234, 0, 0, nil},
+		{en, "1.234,56", 0, 0, 0, 0, i18n.ErrNumberInvalid},
+		{en, "1,23", 0, 0, 0, 0, i18n.ErrNumberInvalid},
+		{en, "1,,234", 0, 0, 0, 0, i18n.ErrNumberInvalid},
+		{en, "(1234", 0, 0, 0, 0, i18n.ErrNumberInvalid},
+		{en, "-(1234)", 0, 0, 0, 0, i18n.ErrNumberInvalid},
+		{en, "--1234", 0, 0, 0, 0, i18n.ErrNumberInvalid},
+		{en, "12a34", 0, 0, 0, 0, i18n.ErrNumberInvalid},
+		{en, "1234 km", 0, 0, 0, 0, i18n.ErrNumberInvalid},
+		{en, "", 0, 0, 0, 0, i18n.ErrNumberInvalid},
+		{en, "12345678901234567890", 0, 0, 0, 0, i18n.ErrNumberOverflow},
+		{de, "1.234,56", 1, 1234, 2, 56, nil},
+		{de, "1.234", 1, 1234, 0, 0, nil},
+		{de, "-1.234.567,8", -1, -1234567, 1, 8, nil},
+		{de, "1,234.56", 0, 0, 0, 0, i18n.ErrNumberInvalid},
+		{ch, "1'234.56", 1, 1234, 2, 56, nil},
+		{ch, "1’234.56", 1, 1234, 2, 56, nil},
+		{fr, "1 234,56", 1, 1234, 2, 56, nil},
+		{fr, "1 234,56", 1, 1234, 2, 56, nil},
+		{i18n.NewNumber(i18n.SetNumberFormat("#,##,##0.00")), "12,34,567.89", 1, 1234567, 2, 89, nil},
+		{i18n.NewNumber(i18n.SetNumberFormat("#,##0\u00a0%", i18n.Symbols{Decimal: ',', Group: '.', PercentSign: '%'})), "25\u00a0%", 1, 0, 2, 25, nil},
+		{i18n.NewNumber(i18n.SetNumberFormat("#,##0.#%")), "-1,234.5%", -1, -12, 3, 345, nil},
+		{i18n.NewNumber(i18n.SetNumberFormat("#,##0‰")), "5‰", 1, 0, 3, 5, nil},
+		{i18n.NewCurrency(i18n.SetCurrencyISO("EUR"), i18n.SetCurrencySign([]byte("€")), i18n.SetCurrencyFormat("#,##0.00 ¤", i18n.Symbols{Decimal: ',', Group: '.'})), "-1.234,56 €", -1, -1234, 2, 56, nil},
+		{i18n.NewCurrency(i18n.SetCurrencyISO("CHF"), i18n.SetCurrencyFormat("¤ #,##0.00;¤-#,##0.00", i18n.Symbols{Decimal: '.', Group: '\''})), "CHF-1'234.56", -1, -1234, 2, 56, nil},
+		{i18n.NewCurrency(i18n.SetCurrencyISO("USD"), i18n.SetCurrencySign([]byte("$"))), "1,234.56", 1, 1234, 2, 56, nil},
+		{i18n.NewCurrency(i18n.SetCurrencyISO("USD"), i18n.SetCurrencySign([]byte("$"))), "€1,234.56", 0, 0, 0, 0, i18n.ErrNumberInvalid},
+	}
+	for i, test := range tests {
+		sign, intgr, prec, frac, err := test.nf.ParseNumber(test.have)
+		if test.wantCause != nil {
+			assert.True(t, errgo.Cause(err) == test.wantCause, "Index %d => %s", i, err)
+			continue
+		}
+		assert.NoError(t, err, "Index %d", i)
+		assert.Exactly(t, test.wantSign, sign, "Index %d", i)
+		assert.Exactly(t, test.wantI, intgr, "Index %d", i)
+		assert.Exactly(t, test.wantPrec, prec, "Index %d", i)
+		assert.Exactly(t, test.wantFrac, frac, "Index %d", i)
+	}
+}
+
+func TestNumberParseBigNumber(t *testing.T) {
+	de := i18n.NewCurrency(i18n.SetCurrencyISO("EUR"), i18n.SetCurrencySign([]byte("€")), i18n.SetCurrencyFormat("#,##0.00 ¤", i18n.Symbols{Decimal: ',', Group: '.'}))
+
+	sign, intgr, prec, frac, err := de.ParseBigNumber("-123.456.789.012.345.678.901.234,56 €")
+	assert.NoError(t, err)
+	assert.Exactly(t, -1, sign)
+	assert.Exactly(t, "-123456789012345678901234", intgr.String())
+	assert.Exactly(t, 2, prec)
+	assert.Exactly(t, int64(56), frac)
+
+	var buf bytes.Buffer
+	_, err = de.FmtBigNumber(&buf, sign, intgr, prec, frac)
+	assert.NoError(t, err)
+	assert.Exactly(t, "-123.456.789.012.345.678.901.234,56 €", buf.String())
+
+	_, intgr, _, _, err = de.ParseBigNumber("1.234")
+	assert.NoError(t, err)
+	assert.Exactly(t, "1234", intgr.String())
+
+	_, _, _, _, err = de.ParseBigNumber("1,1234567890123456789")
+	assert.True(t, errgo.Cause(err) == i18n.ErrNumberOverflow, "%s", err)
+	_, _, _, _, err = de.ParseBigNumber("1,234.56")
+	assert.True(t, errgo.Cause(err) == i18n.ErrNumberInvalid, "%s", err)
+}
+
+func TestNumberParseNumberRoundTrip(t *testing.T) {
+	values := []struct {
+		sign int
+		i    int64
+		prec int
+		frac int64
+		want string // parsed value as fixed point with two decimals
+	}{
+		{1, 1234567, 2, 89, "123456789"},
+		{-1, -1234, 2, 5, "-123405"},
+		{-1, 0, 2, 5, "-5"},
+		{1, 999, 0, 0, "99900"},
+	}
+
+	for _, l := range locale.AllowedLocales {
+		tag, err := i18n.GetLocaleTag(l)
+		if !assert.NoError(t, err, "Locale %s", l) {
+			continue
+		}
+		nl, ok := i18n.LookupNumberLocale(tag)
+		if !assert.True(t, ok, "Missing number data for locale %s", l) {
+			continue
+		}
+		formats := []struct {
+			name string
+			nf   i18n.NumberFormatter
+		}{
+			{"decimal", nl.NewNumber()},
+			{"percent", nl.NewPercent()},
+			{"currency", nl.NewCurrency(currency.EUR)},
+			{"accounting", nl.NewAccounting(currency.EUR)},
+		}
+		for _, f := range formats {
+			for _, v := range values {
+				var buf bytes.Buffer
+				_, err := f.nf.FmtNumber(&buf, v.sign, v.i, v.prec, v.frac)
+				assert.NoError(t, err, "%s %s", l, f.name)
+
+				sign, intgr, prec, frac, err := f.nf.ParseNumber(buf.String())
+				if !assert.NoError(t, err, "%s %s %q", l, f.name, buf.String()) {
+					continue
+				}
+				assert.Exactly(t, v.sign, sign, "%s %s %q", l, f.name, buf.String())
+				// the decimal format writes three and the percent format no
+				// fraction digits
+				have := intgr * 100
+				switch {
+				case prec > 2:
+					have += int64(sign) * frac / int64(math.Pow10(prec-2))
+				default:
+					have += int64(sign) * frac * int64(math.Pow10(2-prec))
+				}
+				assert.Exactly(t, v.want, strconv.FormatInt(have, 10), "%s %s %q", l, f.name, buf.String())
+
+				var buf2 bytes.Buffer
+				_, err = f.nf.FmtNumber(&buf2, sign, intgr, prec, frac)
+				assert.NoError(t, err, "%s %s", l, f.name)
+				assert.Exactly(t, buf.String(), buf2.String(), "%s %s %q", l, f.name, buf.String())
+			}
+		}
+	}
+}
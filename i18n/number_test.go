@@ -17,6 +17,7 @@ package i18n_test
 import (
 	"bytes"
 	"math"
+	"math/big"
 	"sync"
 	"testing"
 
@@ -216,6 +217,39 @@ func genParallelTests(suffix string) []fmtNumberData {
 	return tests
 }
 
+func TestNumberFmtBigNumber(t *testing.T) {
+	tests := []struct {
+		format string
+		sign   int
+		i      string
+		prec   int
+		frac   int64
+		want   string
+	}{
+		{"#,##0.###", 1, "123456789012345678901234", 2, 5, "123,456,789,012,345,678,901,234.050"},
+		{"#,##0.###", -1, "-123456789012345678901234", 2, 5, "-123,456,789,012,345,678,901,234.050"},
+		{"¤\u00a0#,##0.00;¤\u00a0-#,##0.00", -1, "-123456789012345678901234", 3, 555, "¤\u00a0—123,456,789,012,345,678,901,234.56"},
+		{"#,##0.00;(#,##0.00)", -1, "-123456789012345678901234", 2, 5, "(123,456,789,012,345,678,901,234.05)"},
+		{"#,##0", 1, "99999999999999999999", 1, 5, "100,000,000,000,000,000,000"},
+		{"#,##0%", 1, "12345678901234567890", 2, 5, "1,234,567,890,123,456,789,005%"},
+		{"#E0", 1, "12345678901234567890", 0, 0, "1.234567890123456789E19"},
+		{"#,##0.###", 1, "1234", 2, 56, "1,234.560"}, // fits into int64
+	}
+	for _, test := range tests {
+		i, ok := new(big.Int).SetString(test.i, 10)
+		assert.True(t, ok, "%v", test)
+		var buf bytes.Buffer
+		_, err := i18n.NewNumber(
+			i18n.SetNumberFormat(test.format, testDefaultNumberSymbols),
+		).FmtBigNumber(&buf, test.sign, i, test.prec, test.frac)
+		assert.NoError(t, err, "%v", test)
+		assert.Exactly(t, test.want, buf.String(), "%v", test)
+	}
+
+	_, err := i18n.NewNumber().FmtBigNumber(&bytes.Buffer{}, 1, new(big.Int).Lsh(big.NewInt(1), 70), 1, 12)
+	assert.EqualError(t, err, i18n.ErrPrecIsTooShort.Error())
+}
+
 func TestNumberFmtNumberParallel(t *testing.T) {
 	queue := make(chan fmtNumberData)
 	ncpu := runtime.NumCPU()
@@ -337,6 +371,10 @@ func TestNumberFmtFloat64(t *testing.T) {
 		{"#.;(#.)", -1234 * 10, "(12340)", nil},
 
 		{"#,##0.###", 12345.6 * 10, "123,456.000", nil},
+		{"#,##0.00", 1.05, "1.05", nil},
+		{"#,##0.00", -0.05, "-0.05", nil},
+		{"#,##0%", 0.256, "26%", nil},
+		{"#,##0.0‰", 0.01234, "12.3‰", nil},
 		//
 		//		// invalid
 		{"#\U0001f4b0###.##", 1234, "1234.00\U0001f4b0", nil},
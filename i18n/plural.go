@@ -0,0 +1,278 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+// Plural categories as defined in CLDR. Not every language uses all
+// categories but every language uses PluralOther.
+const (
+	PluralZero  = "zero"
+	PluralOne   = "one"
+	PluralTwo   = "two"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// ErrPluralRule gets returned when a CLDR plural rule cannot be parsed.
+var ErrPluralRule = errors.New("Invalid plural rule")
+
+// pluralCategories defines the order in which the rules get evaluated.
+var pluralCategories = [...]string{PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany}
+
+// PluralRules selects the plural category of a number with the CLDR plural
+// rules of a language. The zero value always returns PluralOther.
+// http://unicode.org/reports/tr35/tr35-numbers.html#Language_Plural_Rules
+type PluralRules struct {
+	rules []pluralRule
+}
+
+type pluralRule struct {
+	category string
+	// or contains the and conditions which are combined with or.
+	or [][]pluralRelation
+}
+
+type pluralRelation struct {
+	operand byte // n, i, v, w, f, t, e or c
+	mod     int64
+	not     bool
+	ranges  [][2]int64
+}
+
+// pluralOperands are the values of a number a rule can check. nIsInt
+// reports if the absolute value n has no fractional digits, then n == i.
+type pluralOperands struct {
+	i, v, w, f, t int64
+	nIsInt        bool
+}
+
+// NewPluralRules parses the CLDR plural rules. The key of the map is the
+// category and the value the rule, e.g. "one": "i = 1 and v = 0". Samples
+// starting with @integer or @decimal get ignored. A rule for PluralOther is
+// not required.
+func NewPluralRules(rules map[string]string) (PluralRules, error) {
+	var pr PluralRules
+	for _, cat := range pluralCategories {
+		rule, ok := rules[cat]
+		if !ok {
+			continue
+		}
+		if i := strings.IndexByte(rule, '@'); i >= 0 {
+			rule = rule[:i]
+		}
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		pRule := pluralRule{category: cat}
+		for _, ac := range strings.Split(rule, " or ") {
+			var and []pluralRelation
+			for _, r := range strings.Split(ac, " and ") {
+				rel, err := parsePluralRelation(r)
+				if err != nil {
+					return PluralRules{}, errgo.WithCausef(nil, ErrPluralRule, "%s: %s: %q", ErrPluralRule, cat, rules[cat])
+				}
+				and = append(and, rel)
+			}
+			pRule.or = append(pRule.or, and)
+		}
+		pr.rules = append(pr.rules, pRule)
+	}
+	return pr, nil
+}
+
+// MustNewPluralRules same as NewPluralRules but panics on error.
+func MustNewPluralRules(rules map[string]string) PluralRules {
+	pr, err := NewPluralRules(rules)
+	if err != nil {
+		panic(err)
+	}
+	return pr
+}
+
+// parsePluralRelation parses an expression like "n % 10 = 2..4,6" or
+// "v != 0".
+func parsePluralRelation(s string) (pluralRelation, error) {
+	var rel pluralRelation
+	op := strings.Index(s, "=")
+	if op < 1 {
+		return rel, ErrPluralRule
+	}
+	expr, ranges := s[:op], s[op+1:]
+	if expr[len(expr)-1] == '!' {
+		rel.not = true
+		expr = expr[:len(expr)-1]
+	}
+
+	expr = strings.TrimSpace(expr)
+	if i := strings.IndexByte(expr, '%'); i > 0 {
+		mod, err := strconv.ParseInt(strings.TrimSpace(expr[i+1:]), 10, 64)
+		if err != nil || mod < 1 {
+			return rel, ErrPluralRule
+		}
+		rel.mod = mod
+		expr = strings.TrimSpace(expr[:i])
+	}
+	if len(expr) != 1 || false == strings.Contains("nivwftec", expr) {
+		return rel, ErrPluralRule
+	}
+	rel.operand = expr[0]
+
+	for _, rs := range strings.Split(ranges, ",") {
+		from, to := strings.TrimSpace(rs), ""
+		if i := strings.Index(from, ".."); i > 0 {
+			from, to = from[:i], from[i+2:]
+		} else {
+			to = from
+		}
+		f, err := strconv.ParseInt(from, 10, 64)
+		if err != nil {
+			return rel, ErrPluralRule
+		}
+		t, err := strconv.ParseInt(to, 10, 64)
+		if err != nil || t < f {
+			return rel, ErrPluralRule
+		}
+		rel.ranges = append(rel.ranges, [2]int64{f, t})
+	}
+	return rel, nil
+}
+
+// Category returns the plural category of a number formatted with a dot as
+// decimal separator, e.g. "1", "1.0" or "-2.50". Visible trailing zeros are
+// important for some languages: in English "1" is PluralOne and "1.0" is
+// PluralOther. Invalid numbers return PluralOther.
+func (pr PluralRules) Category(number string) string {
+	ops, ok := newPluralOperands(number)
+	if !ok {
+		return PluralOther
+	}
+	for _, r := range pr.rules {
+		if r.match(ops) {
+			return r.category
+		}
+	}
+	return PluralOther
+}
+
+// CategoryInt64 returns the plural category of an integer.
+func (pr PluralRules) CategoryInt64(i int64) string {
+	return pr.Category(strconv.FormatInt(i, 10))
+}
+
+// Categories returns all categories of the language. The last one is always
+// PluralOther.
+func (pr PluralRules) Categories() []string {
+	cats := make([]string, 0, len(pr.rules)+1)
+	for _, r := range pr.rules {
+		cats = append(cats, r.category)
+	}
+	return append(cats, PluralOther)
+}
+
+func (r pluralRule) match(ops pluralOperands) bool {
+	for _, and := range r.or {
+		ok := true
+		for _, rel := range and {
+			if !rel.match(ops) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (rel pluralRelation) match(ops pluralOperands) bool {
+	isInt := true
+	var val int64
+	switch rel.operand {
+	case 'n':
+		val, isInt = ops.i, ops.nIsInt
+	case 'i':
+		val = ops.i
+	case 'v':
+		val = ops.v
+	case 'w':
+		val = ops.w
+	case 'f':
+		val = ops.f
+	case 't':
+		val = ops.t
+	case 'e', 'c':
+		val = 0 // the compact exponent of numbers without a compact format
+	}
+	if rel.mod > 0 {
+		val %= rel.mod
+	}
+	found := false
+	for _, r := range rel.ranges {
+		if isInt && val >= r[0] && val <= r[1] {
+			found = true
+			break
+		}
+	}
+	return found != rel.not
+}
+
+// newPluralOperands calculates the operands of a number. Fractions with more
+// than 18 digits get truncated.
+func newPluralOperands(number string) (pluralOperands, bool) {
+	var ops pluralOperands
+	number = strings.TrimLeft(strings.TrimSpace(number), "+-")
+	intPart, fracPart := number, ""
+	if i := strings.IndexByte(number, '.'); i >= 0 {
+		intPart, fracPart = number[:i], number[i+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return ops, false
+	}
+	if intPart != "" {
+		i, err := strconv.ParseUint(intPart, 10, 63)
+		if err != nil {
+			return ops, false
+		}
+		ops.i = int64(i)
+	}
+	if len(fracPart) > maxParseDigits {
+		fracPart = fracPart[:maxParseDigits]
+	}
+	trimmed := strings.TrimRight(fracPart, "0")
+	ops.v, ops.w = int64(len(fracPart)), int64(len(trimmed))
+	if fracPart != "" {
+		f, err := strconv.ParseUint(fracPart, 10, 63)
+		if err != nil {
+			return ops, false
+		}
+		ops.f = int64(f)
+	}
+	if trimmed != "" {
+		t, _ := strconv.ParseUint(trimmed, 10, 63)
+		ops.t = int64(t)
+	}
+	ops.nIsInt = ops.t == 0
+	return ops, true
+}
@@ -0,0 +1,76 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n_test
+
+import (
+	"testing"
+
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/juju/errgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluralRulesCategory(t *testing.T) {
+	en := i18n.MustNewPluralRules(map[string]string{
+		i18n.PluralOne: "i = 1 and v = 0 @integer 1",
+	})
+	fr := i18n.MustNewPluralRules(map[string]string{
+		i18n.PluralOne:  "i = 0,1 @integer 0, 1 @decimal 0.0~1.5",
+		i18n.PluralMany: "e = 0 and i != 0 and i % 1000000 = 0 and v = 0 or e != 0..5 @integer 1000000, 1c6",
+	})
+	pl := i18n.MustNewPluralRules(map[string]string{
+		i18n.PluralOne:  "i = 1 and v = 0",
+		i18n.PluralFew:  "v = 0 and i % 10 = 2..4 and i % 100 != 12..14",
+		i18n.PluralMany: "v = 0 and i != 1 and i % 10 = 0..1 or v = 0 and i % 10 = 5..9 or v = 0 and i % 100 = 12..14",
+	})
+
+	tests := []struct {
+		pr     i18n.PluralRules
+		number string
+		want   string
+	}{
+		{en, "1", i18n.PluralOne},
+		{en, "-1", i18n.PluralOne},
+		{en, "1.0", i18n.PluralOther},
+		{en, "0", i18n.PluralOther},
+		{en, "2", i18n.PluralOther},
+		{en, "", i18n.PluralOther},
+		{en, "1x", i18n.PluralOther},
+		{fr, "0", i18n.PluralOne},
+		{fr, "1.5", i18n.PluralOne},
+		{fr, "2", i18n.PluralOther},
+		{fr, "1000000", i18n.PluralMany},
+		{fr, "1000000.0", i18n.PluralOther},
+		{pl, "1", i18n.PluralOne},
+		{pl, "3", i18n.PluralFew},
+		{pl, "13", i18n.PluralMany},
+		{pl, "22", i18n.PluralFew},
+		{pl, "25", i18n.PluralMany},
+		{pl, "2.5", i18n.PluralOther},
+		{i18n.PluralRules{}, "1", i18n.PluralOther},
+	}
+	for i, test := range tests {
+		assert.Exactly(t, test.want, test.pr.Category(test.number), "Index %d => %q", i, test.number)
+	}
+	assert.Exactly(t, i18n.PluralFew, pl.CategoryInt64(104))
+	assert.Exactly(t, []string{i18n.PluralOne, i18n.PluralFew, i18n.PluralMany, i18n.PluralOther}, pl.Categories())
+}
+
+func TestNewPluralRulesError(t *testing.T) {
+	for _, rule := range []string{"i == 1", "x = 1", "i = a", "i % 0 = 1", "i = 3..1", "= 1"} {
+		_, err := i18n.NewPluralRules(map[string]string{i18n.PluralOne: rule})
+		assert.EqualError(t, errgo.Cause(err), i18n.ErrPluralRule.Error(), "Rule %q", rule)
+	}
+}
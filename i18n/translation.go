@@ -0,0 +1,301 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/juju/errgo"
+)
+
+// Priority defines the precedence of a dictionary. Like in Magento a theme
+// translation overwrites a module translation and a language package
+// overwrites both.
+type Priority int
+
+// Priorities of the dictionaries, the higher one wins.
+const (
+	PriorityModule Priority = iota
+	PriorityTheme
+	PriorityPackage
+	PriorityDatabase
+)
+
+// Message defines a translation of a source text.
+type Message struct {
+	// ID is the source text, e.g. "Add to Cart" or "%1 item(s)".
+	ID string
+	// Text contains the translation.
+	Text string
+	// Plural contains the translations per plural category. Optional.
+	Plural map[string]string
+	// Type can be "module" or "theme" and Name e.g. Magento_Catalog. Both
+	// are only informational and come from the third and fourth column of a
+	// Magento CSV file.
+	Type, Name string
+}
+
+// Dictionary contains the messages of one locale. The key is Message.ID.
+type Dictionary map[string]Message
+
+// Merge copies all messages of d2 into d and overwrites existing ones.
+func (d Dictionary) Merge(d2 Dictionary) Dictionary {
+	for k, m := range d2 {
+		d[k] = m
+	}
+	return d
+}
+
+// TranslatorOptions applies options to the Translator and returns the
+// previous option.
+type TranslatorOptions func(*Translator) TranslatorOptions
+
+// SetTranslatorMissing sets a hook which gets called when a message cannot
+// be found in any dictionary of a locale including the fallbacks. Use it to
+// log or collect untranslated texts. The hook must be thread safe.
+func SetTranslatorMissing(f func(locale, id string)) TranslatorOptions {
+	return func(t *Translator) TranslatorOptions {
+		previous := t.missing
+		t.missing = f
+		return SetTranslatorMissing(previous)
+	}
+}
+
+// Translator translates messages for different locales. The locale of the
+// dictionaries is something like de_CH or de. Lookups search first in the
+// exact locale and then in the base language, each time from the highest
+// to the lowest priority. If a message cannot be found the source text gets
+// returned. Thread safe.
+type Translator struct {
+	mu      sync.RWMutex
+	dicts   map[string][]prioDictionary
+	missing func(locale, id string)
+}
+
+type prioDictionary struct {
+	prio Priority
+	dict Dictionary
+}
+
+// NewTranslator creates a new empty Translator.
+func NewTranslator(opts ...TranslatorOptions) *Translator {
+	t := &Translator{
+		dicts: make(map[string][]prioDictionary),
+	}
+	t.SetOptions(opts...)
+	return t
+}
+
+// SetOptions applies the options and returns the last previous option.
+func (t *Translator) SetOptions(opts ...TranslatorOptions) (previous TranslatorOptions) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, o := range opts {
+		if o != nil {
+			previous = o(t)
+		}
+	}
+	return
+}
+
+// Add adds a dictionary for a locale with a priority. Dictionaries with the
+// same locale and priority get merged, later added messages win.
+func (t *Translator) Add(locale string, p Priority, d Dictionary) *Translator {
+	locale = normalizeLocale(locale)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pds := t.dicts[locale]
+	for _, pd := range pds {
+		if pd.prio == p {
+			pd.dict.Merge(d)
+			return t
+		}
+	}
+	pds = append(pds, prioDictionary{prio: p, dict: make(Dictionary, len(d)).Merge(d)})
+	sort.Sort(byPriority(pds))
+	t.dicts[locale] = pds
+	return t
+}
+
+// LoadFiles loads all Magento CSV or gettext PO files matching the glob
+// pattern, e.g. app/code/*/*/i18n/*.csv. The locale gets extracted from the
+// file name like de_DE.csv. PO files use the plural rules of the locale or
+// only the category other if the locale has no registered plural rules.
+func (t *Translator) LoadFiles(p Priority, pattern string) error {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	sort.Strings(files) // reproducible precedence
+	for _, file := range files {
+		if err := t.loadFile(p, file); err != nil {
+			return errgo.Notef(err, "File %q", file)
+		}
+	}
+	return nil
+}
+
+func (t *Translator) loadFile(p Priority, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	defer f.Close()
+
+	ext := filepath.Ext(file)
+	locale := strings.TrimSuffix(filepath.Base(file), ext)
+	var d Dictionary
+	switch strings.ToLower(ext) {
+	case ".csv":
+		d, err = ReadCSV(f)
+	case ".po":
+		pr, _ := t.pluralRules(locale) // without rules all plural forms fall back to other
+		d, err = ReadPO(f, pr)
+	default:
+		return errgo.Newf("Unsupported file extension %q", ext)
+	}
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	t.Add(locale, p, d)
+	return nil
+}
+
+// Message returns the message with the highest precedence. The bool
+// reports if the message has been found.
+func (t *Translator) Message(locale, id string) (Message, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, l := range localeFallbacks(normalizeLocale(locale)) {
+		for _, pd := range t.dicts[l] {
+			if m, ok := pd.dict[id]; ok {
+				return m, true
+			}
+		}
+	}
+	return Message{}, false
+}
+
+// T translates the source text into the locale, the equivalent of the PHP
+// function __(). Placeholders like %1 and %2 get replaced by the arguments.
+// If the text cannot be found the missing hook gets called and the source
+// text returned.
+func (t *Translator) T(locale, id string, args ...interface{}) string {
+	m, ok := t.lookup(locale, id)
+	if !ok {
+		return ReplacePlaceholders(id, args...)
+	}
+	return ReplacePlaceholders(m.Text, args...)
+}
+
+// TN translates the source text and chooses the plural form of the number
+// n with the CLDR plural rules of the locale. n is the first placeholder
+// %1. Without plural forms it behaves like T. If no plural rules have been
+// registered for the locale the form of the category other gets used.
+func (t *Translator) TN(locale, id string, n int64, args ...interface{}) string {
+	args = append([]interface{}{n}, args...)
+	m, ok := t.lookup(locale, id)
+	if !ok {
+		return ReplacePlaceholders(id, args...)
+	}
+	text := m.Text
+	if len(m.Plural) > 0 {
+		pr, _ := t.pluralRules(locale)
+		if pt, ok := m.Plural[pr.CategoryInt64(n)]; ok {
+			text = pt
+		} else if pt, ok := m.Plural[PluralOther]; ok {
+			text = pt
+		}
+	}
+	return ReplacePlaceholders(text, args...)
+}
+
+func (t *Translator) lookup(locale, id string) (Message, bool) {
+	m, ok := t.Message(locale, id)
+	if !ok {
+		t.mu.RLock()
+		missing := t.missing
+		t.mu.RUnlock()
+		if missing != nil {
+			missing(normalizeLocale(locale), id)
+		}
+	}
+	return m, ok
+}
+
+// pluralRules returns the plural rules of the registered NumberLocale. The
+// bool reports if the locale has been registered.
+func (t *Translator) pluralRules(locale string) (PluralRules, bool) {
+	tag, err := GetLocaleTag(locale)
+	if err != nil {
+		return PluralRules{}, false
+	}
+	nl, ok := LookupNumberLocale(tag)
+	return nl.plural, ok
+}
+
+// ReplacePlaceholders replaces %1 with the first argument, %2 with the
+// second and so on. Placeholders without an argument stay untouched.
+func ReplacePlaceholders(text string, args ...interface{}) string {
+	if len(args) == 0 || strings.IndexByte(text, '%') < 0 {
+		return text
+	}
+	var buf []byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c != '%' || i+1 == len(text) || text[i+1] < '1' || text[i+1] > '9' {
+			buf = append(buf, c)
+			continue
+		}
+		j, n := i+1, 0
+		for ; j < len(text) && text[j] >= '0' && text[j] <= '9'; j++ {
+			n = n*10 + int(text[j]-'0')
+		}
+		if n > len(args) {
+			buf = append(buf, text[i:j]...)
+		} else {
+			buf = append(buf, fmt.Sprint(args[n-1])...)
+		}
+		i = j - 1
+	}
+	return string(buf)
+}
+
+// normalizeLocale converts de-CH into de_CH.
+func normalizeLocale(locale string) string {
+	return strings.Replace(locale, "-", LocaleSeparator, -1)
+}
+
+// localeFallbacks returns de_Latn_CH, de_Latn and de for de_Latn_CH.
+func localeFallbacks(locale string) []string {
+	ls := []string{locale}
+	for i := strings.LastIndex(locale, LocaleSeparator); i > 0; i = strings.LastIndex(locale, LocaleSeparator) {
+		locale = locale[:i]
+		ls = append(ls, locale)
+	}
+	return ls
+}
+
+type byPriority []prioDictionary
+
+func (p byPriority) Len() int           { return len(p) }
+func (p byPriority) Less(i, j int) bool { return p[i].prio > p[j].prio }
+func (p byPriority) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
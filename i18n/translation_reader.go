@@ -0,0 +1,186 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errgo"
+)
+
+// ReadCSV reads a Magento translation file. Each line contains the source
+// text, the translation and optionally the type and name of the module or
+// theme:
+//	"Add to Cart","In den Warenkorb","module","Magento_Catalog"
+// Plural forms get separated by a pipe and start with the CLDR category in
+// curly braces:
+//	"%1 item(s)","{one}%1 Artikel|{other}%1 Artikel"
+func ReadCSV(r io.Reader) (Dictionary, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	d := make(Dictionary)
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return d, nil
+		}
+		if err != nil {
+			return nil, errgo.Mask(err)
+		}
+		if len(rec) == 1 && strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		if len(rec) < 2 {
+			return nil, errgo.Newf("Line %d: expecting at least two columns but got %d", line, len(rec))
+		}
+		m := Message{ID: rec[0], Text: rec[1]}
+		if len(rec) > 3 {
+			m.Type, m.Name = rec[2], rec[3]
+		}
+		if pl, ok := parsePluralText(m.Text); ok {
+			m.Plural = pl
+			m.Text = pl[PluralOther]
+		}
+		d[m.ID] = m
+	}
+}
+
+// parsePluralText parses {one}text|{other}texts. Returns false if the text
+// does not contain plural forms.
+func parsePluralText(text string) (map[string]string, bool) {
+	if len(text) < 3 || text[0] != '{' {
+		return nil, false
+	}
+	pl := make(map[string]string)
+	for _, part := range strings.Split(text, "|") {
+		end := strings.IndexByte(part, '}')
+		if len(part) < 3 || part[0] != '{' || end < 2 {
+			return nil, false
+		}
+		pl[part[1:end]] = part[end+1:]
+	}
+	return pl, true
+}
+
+// ReadPO reads a gettext PO file. The msgstr[n] of a plural message get
+// mapped in the order of the plural categories, see PluralRules.Categories,
+// and the last form gets used for all remaining categories and always for
+// the category other. Empty PluralRules of an unknown locale therefore map
+// the last form to other. Fuzzy entries
+// and the header get skipped. Context in msgctxt is not supported and
+// ignored.
+func ReadPO(r io.Reader, pr PluralRules) (Dictionary, error) {
+	d := make(Dictionary)
+	cats := pr.Categories()
+
+	var (
+		id, idPlural string
+		strs         []string
+		fuzzy        bool
+		target       *string
+		hasEntry     bool
+	)
+	flush := func() {
+		if hasEntry && id != "" && false == fuzzy && len(strs) > 0 {
+			m := Message{ID: id, Text: strs[0]}
+			if idPlural != "" {
+				m.Plural = make(map[string]string, len(cats))
+				for i, c := range cats {
+					if i < len(strs) && c != PluralOther {
+						m.Plural[c] = strs[i]
+					} else {
+						m.Plural[c] = strs[len(strs)-1]
+					}
+				}
+			}
+			if m.Text != "" {
+				d[id] = m
+			}
+		}
+		id, idPlural, strs, fuzzy, target, hasEntry = "", "", nil, false, nil, false
+	}
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		l := strings.TrimSpace(sc.Text())
+		switch {
+		case l == "":
+			flush()
+			continue
+		case strings.HasPrefix(l, "#,"):
+			if len(strs) > 0 {
+				flush()
+			}
+			fuzzy = fuzzy || strings.Contains(l, "fuzzy")
+			continue
+		case l[0] == '#':
+			continue
+		case l[0] == '"':
+			if target == nil {
+				return nil, errgo.Newf("Line %d: unexpected string", line)
+			}
+			s, err := strconv.Unquote(l)
+			if err != nil {
+				return nil, errgo.Newf("Line %d: %s", line, err)
+			}
+			*target += s
+			continue
+		}
+
+		kw, val := l, ""
+		if i := strings.IndexAny(l, " \t"); i > 0 {
+			kw, val = l[:i], strings.TrimSpace(l[i:])
+		}
+		s, err := strconv.Unquote(val)
+		if err != nil {
+			return nil, errgo.Newf("Line %d: %s", line, err)
+		}
+		switch {
+		case kw == "msgctxt", kw == "msgid":
+			if len(strs) > 0 {
+				flush()
+			}
+			hasEntry = true
+			if kw == "msgctxt" {
+				target = new(string) // ignored
+				continue
+			}
+			id, target = s, &id
+		case kw == "msgid_plural":
+			idPlural, target = s, &idPlural
+		case kw == "msgstr":
+			strs = append(strs[:0], s)
+			target = &strs[0]
+		case strings.HasPrefix(kw, "msgstr[") && strings.HasSuffix(kw, "]"):
+			n, err := strconv.Atoi(kw[len("msgstr[") : len(kw)-1])
+			if err != nil || n != len(strs) {
+				return nil, errgo.Newf("Line %d: invalid plural index %q", line, kw)
+			}
+			strs = append(strs, s)
+			target = &strs[n]
+		default:
+			return nil, errgo.Newf("Line %d: unknown keyword %q", line, kw)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errgo.Mask(err)
+	}
+	flush()
+	return d, nil
+}
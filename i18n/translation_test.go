@@ -0,0 +1,123 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustNewTestTranslator(t *testing.T, opts ...i18n.TranslatorOptions) *i18n.Translator {
+	tr := i18n.NewTranslator(opts...)
+	assert.NoError(t, tr.LoadFiles(i18n.PriorityModule, "testdata/app/code/*/*/i18n/*.csv"))
+	assert.NoError(t, tr.LoadFiles(i18n.PriorityTheme, "testdata/app/design/frontend/*/*/i18n/*.csv"))
+	assert.NoError(t, tr.LoadFiles(i18n.PriorityTheme, "testdata/app/design/frontend/*/*/i18n/*.po"))
+	return tr
+}
+
+func TestTranslatorT(t *testing.T) {
+	var mu sync.Mutex
+	var missing []string
+	tr := mustNewTestTranslator(t, i18n.SetTranslatorMissing(func(locale, id string) {
+		mu.Lock()
+		missing = append(missing, locale+":"+id)
+		mu.Unlock()
+	}))
+
+	tests := []struct {
+		locale, id string
+		args       []interface{}
+		want       string
+	}{
+		{"de_DE", "Add to Cart", nil, "Kaufen"},                      // theme overwrites module
+		{"de-DE", "Add to Compare", nil, "Zum Vergleich hinzufügen"}, // module
+		{"de_DE", "Shopping Cart", nil, "Warenkorb"},                 // fallback to de
+		{"de_CH", "Shopping Cart", nil, "Warenkorb"},
+		{"de_CH", "Price", nil, "Price"}, // de_DE is not a fallback of de_CH
+		{"fr_FR", "Add to Cart", nil, "Ajouter au panier"},
+		{"fr_FR", "Update", nil, "Mettre à jour"},
+		{"fr_FR", "Price", nil, "Price"}, // fuzzy
+		{"eo", "Add to Cart", nil, "Aldoni al ĉaro"},
+		{"en_US", "Hello %1, you have %2 new messages. %3", []interface{}{"Gopher", 3}, "Hello Gopher, you have 3 new messages. %3"},
+	}
+	for i, test := range tests {
+		assert.Exactly(t, test.want, tr.T(test.locale, test.id, test.args...), "Index %d", i)
+	}
+	assert.Exactly(t, []string{"de_CH:Price", "fr_FR:Price", "en_US:Hello %1, you have %2 new messages. %3"}, missing)
+
+	m, ok := tr.Message("de_DE", "Add to Compare")
+	assert.True(t, ok)
+	assert.Exactly(t, i18n.Message{ID: "Add to Compare", Text: "Zum Vergleich hinzufügen", Type: "module", Name: "Magento_Catalog"}, m)
+}
+
+func TestTranslatorTN(t *testing.T) {
+	tr := mustNewTestTranslator(t)
+	tr.Add("ja", i18n.PriorityDatabase, i18n.Dictionary{
+		"%1 item(s)": {ID: "%1 item(s)", Plural: map[string]string{i18n.PluralOne: "%1 item", i18n.PluralOther: "%1 items"}},
+	})
+	tr.Add("en", i18n.PriorityDatabase, i18n.Dictionary{
+		"%1 item(s) in %2": {ID: "%1 item(s) in %2", Plural: map[string]string{i18n.PluralOne: "%1 item in %2", i18n.PluralOther: "%1 items in %2"}},
+	})
+
+	tests := []struct {
+		locale, id string
+		n          int64
+		want       string
+	}{
+		{"en_US", "%1 item(s) in %2", 1, "1 item in Cart"},
+		{"en_US", "%1 item(s) in %2", 0, "0 items in Cart"},
+		{"fr_FR", "%1 item(s)", 0, "0 article"},
+		{"fr_FR", "%1 item(s)", 1, "1 article"},
+		{"fr_FR", "%1 item(s)", 2, "2 articles"},
+		{"de_DE", "%1 item(s)", 2, "2 Artikel"},
+		{"nl_NL", "%1 item(s)", 2, "2 item(s)"},
+		{"ja_JP", "%1 item(s)", 1, "1 items"},
+		{"eo", "%1 item(s)", 1, "1 varoj"}, // no plural rules, falls back to other
+		{"eo", "%1 item(s)", 2, "2 varoj"},
+	}
+	for i, test := range tests {
+		assert.Exactly(t, test.want, tr.TN(test.locale, test.id, test.n, "Cart"), "Index %d", i)
+	}
+}
+
+func TestReadCSVError(t *testing.T) {
+	_, err := i18n.ReadCSV(strings.NewReader("\"Add to Cart\"\n"))
+	assert.EqualError(t, err, "Line 1: expecting at least two columns but got 1")
+	_, err = i18n.ReadCSV(strings.NewReader("\"Add to Cart,\"Kaufen\"\n"))
+	assert.Error(t, err)
+}
+
+func TestReadPOError(t *testing.T) {
+	tests := []string{
+		"msgid \"a\"\nmsgstr[1] \"b\"\n",
+		"msgid \"a\nmsgstr \"b\"\n",
+		"\"a\"\n",
+		"msgfoo \"a\"\n",
+	}
+	for _, test := range tests {
+		_, err := i18n.ReadPO(strings.NewReader(test), i18n.PluralRules{})
+		assert.Error(t, err, "%q", test)
+	}
+}
+
+func TestReplacePlaceholders(t *testing.T) {
+	assert.Exactly(t, "100% of 10 in 5", i18n.ReplacePlaceholders("100% of %1 in %2", 10, 5))
+	assert.Exactly(t, "a %0 b %", i18n.ReplacePlaceholders("a %0 b %", 1))
+	assert.Exactly(t, "k2", i18n.ReplacePlaceholders("%11%2", 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, "k"))
+}
@@ -0,0 +1,56 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package locale
+
+import (
+	"time"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/directory"
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/juju/errgo"
+)
+
+// Location returns the time zone of a scope from the configuration path
+// general/locale/timezone, e.g. Europe/Berlin. Without a configured value
+// the default of the configuration element applies. An empty value returns
+// UTC.
+func Location(sg config.ScopedGetter) (*time.Location, error) {
+	tz := directory.Backend.GeneralLocaleTimezone.Get(sg)
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, errgo.Notef(err, "Timezone %q", tz)
+	}
+	return loc, nil
+}
+
+// NewDate creates a date formatter with the locale from general/locale/code
+// and the time zone from general/locale/timezone of a scope. Locales without
+// registered calendar data fall back to i18n.LocaleDefault, see
+// i18n.DateLocaleByTag.
+func NewDate(sg config.ScopedGetter, opts ...i18n.DateOptions) (*i18n.Date, error) {
+	loc, err := Location(sg)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	t, err := i18n.GetLocaleTag(Code(sg))
+	if err != nil {
+		return nil, errgo.Notef(err, "Locale %q", Code(sg))
+	}
+	return i18n.DateLocaleByTag(t).NewDate(append([]i18n.DateOptions{i18n.SetDateLocation(loc)}, opts...)...), nil
+}
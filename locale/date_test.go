@@ -0,0 +1,72 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package locale_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/directory"
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/corestoreio/csfw/locale"
+	"github.com/corestoreio/csfw/store/scope"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDate(t *testing.T) {
+	sg := config.NewMockGetter(config.WithMockValues(config.MockPV{
+		directory.Backend.GeneralLocaleCode.FQPathInt64(scope.StrDefault, 0):     "de_DE",
+		directory.Backend.GeneralLocaleTimezone.FQPathInt64(scope.StrDefault, 0): "Europe/Berlin",
+	})).NewScoped(0, 0, 0)
+
+	d, err := locale.NewDate(sg)
+	assert.NoError(t, err)
+	assert.Exactly(t, "Europe/Berlin", d.Location().String())
+
+	var buf bytes.Buffer
+	_, err = d.FmtDateTime(&buf, time.Date(2016, 1, 31, 23, 30, 0, 0, time.UTC), 1, 3)
+	assert.NoError(t, err)
+	assert.Exactly(t, "1. Februar 2016, 00:30", buf.String())
+}
+
+func TestLocation(t *testing.T) {
+	loc, err := locale.Location(config.NewMockGetter().NewScoped(0, 0, 0))
+	assert.NoError(t, err)
+	assert.Exactly(t, "America/Los_Angeles", loc.String(), "default of the config element")
+
+	_, err = locale.NewDate(config.NewMockGetter(config.WithMockValues(config.MockPV{
+		directory.Backend.GeneralLocaleTimezone.FQPathInt64(scope.StrDefault, 0): "Mars/Olympus",
+	})).NewScoped(0, 0, 0))
+	assert.Error(t, err)
+
+}
+
+func TestNewDateFallback(t *testing.T) {
+	newDate := func(code string) string {
+		d, err := locale.NewDate(config.NewMockGetter(config.WithMockValues(config.MockPV{
+			directory.Backend.GeneralLocaleCode.FQPathInt64(scope.StrDefault, 0):     code,
+			directory.Backend.GeneralLocaleTimezone.FQPathInt64(scope.StrDefault, 0): "UTC",
+		})).NewScoped(0, 0, 0))
+		assert.NoError(t, err, "Locale %q", code)
+		var buf bytes.Buffer
+		_, err = d.FmtDateTime(&buf, time.Date(2016, 1, 31, 23, 30, 0, 0, time.UTC), 1, 3)
+		assert.NoError(t, err, "Locale %q", code)
+		return buf.String()
+	}
+	assert.NotEmpty(t, newDate("es_ES"), "store locales must not fail")
+	assert.Exactly(t, newDate(i18n.LocaleDefault), newDate("eo"), "eo has no calendar data and falls back to the default locale")
+}
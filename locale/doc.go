@@ -19,6 +19,13 @@ Contains logic to handle currency and number formatting based on locale.
 Provides access to locale information translated into given languages.
 Manages locale information for a store instance.
 
+The middleware WithTranslate reads the locale of the requested store from
+the configuration path general/locale/code and adds an i18n.Translator bound
+to this locale to the context. Retrieve it with FromContextTranslate.
+
+NewDate creates an i18n.Date with the locale and the time zone of a scope,
+read from general/locale/code and general/locale/timezone.
+
 @todo namespace Magento\Framework\Locale
 */
 package locale
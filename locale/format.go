@@ -17,6 +17,10 @@ package locale
 import (
 	"strconv"
 	"unicode"
+
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/juju/errgo"
+	"golang.org/x/text/currency"
 )
 
 // @todo see lib/internal/Magento/Framework/Locale/Format.php
@@ -32,6 +36,10 @@ import (
 //     '2 054,10' = 2054.1
 //     '2'054.52' = 2054.52
 //     '2,46 GB' = 2.46
+//
+// The decimal separator gets guessed which fails for inputs like '1,234'. If
+// the locale is known use the strict i18n.Number.ParseNumber or
+// money.Money.ParseLocalized.
 func ExtractNumber(n string) (float64, error) {
 
 	if n == "" {
@@ -96,13 +104,22 @@ OuterLoop:
 	return strconv.ParseFloat(string(nums), 64)
 }
 
-// FormatPrice returns price formatting information for a given locale.
-// @todo implement
-func FormatPrice( /*localCode, currencyCode */ ) /*some kind of struct*/ {
-	// if currency code has been provided: load currency from directory package
-	// otherwise determine it from the current loaded store
-
-	// if localeCode is empty, the locale code will be determined from the current store
-
-	// move i18n.Symbols including formatting string into this package
+// FormatPrice returns a currency formatter for a locale like de_CH and a
+// 3-letter ISO 4217 currency code. Symbols, format and fraction digits are
+// taken from the CLDR data registered in package i18n. Returns an error for
+// locales without registered data.
+func FormatPrice(localeCode, currencyCode string) (*i18n.Currency, error) {
+	t, err := i18n.GetLocaleTag(localeCode)
+	if err != nil {
+		return nil, errgo.Notef(err, "Locale %q", localeCode)
+	}
+	u, err := currency.ParseISO(currencyCode)
+	if err != nil {
+		return nil, errgo.Notef(err, "Currency %q", currencyCode)
+	}
+	nl, ok := i18n.LookupNumberLocale(t)
+	if !ok {
+		return nil, errgo.Newf("Locale %q: no CLDR number data registered", localeCode)
+	}
+	return nl.NewCurrency(u), nil
 }
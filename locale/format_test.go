@@ -14,8 +14,10 @@
 
 package locale_test
 
-import "testing"
 import (
+	"bytes"
+	"testing"
+
 	"github.com/corestoreio/csfw/locale"
 	"github.com/stretchr/testify/assert"
 )
@@ -83,5 +85,31 @@ func benchmarkExtractNumber(b *testing.B, input string, want float64) {
 }
 
 func TestFormatPrice(t *testing.T) {
-	t.Log("@todo")
+	tests := []struct {
+		locale, currency string
+		price            float64
+		want             string
+		wantErr          bool
+	}{
+		{"en_US", "USD", 1234.567, "$1,234.57", false},
+		{"de_DE", "EUR", -1234.5, "-1.234,50\u00a0€", false},
+		{"de_CH", "CHF", 1234.5, "CHF\u00a01’234.50", false},
+		{"fr_FR", "USD", 3.5, "3,50\u00a0$US", false},
+		{"de_DE", "XYZW", 0, "", true},
+		{"@#$", "EUR", 0, "", true},
+		{"ja_JP", "JPY", 1234, "￥1,234", false},
+		{"eo", "EUR", 0, "", true},
+	}
+	for _, test := range tests {
+		c, err := locale.FormatPrice(test.locale, test.currency)
+		if test.wantErr {
+			assert.Error(t, err, "%#v", test)
+			continue
+		}
+		assert.NoError(t, err, "%#v", test)
+		var buf bytes.Buffer
+		_, err = c.FmtFloat64(&buf, test.price)
+		assert.NoError(t, err, "%#v", test)
+		assert.Exactly(t, test.want, buf.String(), "%#v", test)
+	}
 }
@@ -0,0 +1,99 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package locale
+
+import (
+	"net/http"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/directory"
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/corestoreio/csfw/net/ctxhttp"
+	"github.com/corestoreio/csfw/store"
+	"github.com/juju/errgo"
+	"golang.org/x/net/context"
+)
+
+// Translate binds a Translator to the locale of a store.
+type Translate struct {
+	Translator *i18n.Translator
+	// Locale like de_CH from the configuration path general/locale/code.
+	Locale string
+}
+
+// T translates a text into the locale. See i18n.Translator.T.
+func (t Translate) T(id string, args ...interface{}) string {
+	if t.Translator == nil {
+		return i18n.ReplacePlaceholders(id, args...)
+	}
+	return t.Translator.T(t.Locale, id, args...)
+}
+
+// TN translates a text with the plural form of n. See i18n.Translator.TN.
+func (t Translate) TN(id string, n int64, args ...interface{}) string {
+	if t.Translator == nil {
+		return i18n.ReplacePlaceholders(id, append([]interface{}{n}, args...)...)
+	}
+	return t.Translator.TN(t.Locale, id, n, args...)
+}
+
+// Code returns the configured locale of a scope from the path
+// general/locale/code. Falls back to i18n.LocaleDefault.
+func Code(sg config.ScopedGetter) string {
+	if c := directory.Backend.GeneralLocaleCode.Get(sg); c != "" {
+		return c
+	}
+	return i18n.LocaleDefault
+}
+
+type ctxTranslateKey struct{}
+
+// WithContextTranslate adds a Translate to the context.
+func WithContextTranslate(ctx context.Context, t Translate) context.Context {
+	return context.WithValue(ctx, ctxTranslateKey{}, t)
+}
+
+// FromContextTranslate returns the Translate of a request. If not found
+// the returned Translate returns the source texts and uses the locale
+// i18n.LocaleDefault.
+func FromContextTranslate(ctx context.Context) (Translate, bool) {
+	t, ok := ctx.Value(ctxTranslateKey{}).(Translate)
+	if !ok {
+		t.Locale = i18n.LocaleDefault
+	}
+	return t, ok
+}
+
+// WithTranslate is a middleware which reads the locale of the requested
+// store and adds a Translate to the context. Expects a store.Reader in the
+// context, see store.WithContextReader.
+func WithTranslate(tr *i18n.Translator) ctxhttp.Middleware {
+	return func(h ctxhttp.HandlerFunc) ctxhttp.HandlerFunc {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			_, requestedStore, err := store.FromContextReader(ctx)
+			if err != nil {
+				if PkgLog.IsDebug() {
+					PkgLog.Debug("locale.WithTranslate.FromContextReader", "err", err)
+				}
+				return errgo.Mask(err)
+			}
+			t := Translate{
+				Translator: tr,
+				Locale:     Code(requestedStore.Config),
+			}
+			return h(WithContextTranslate(ctx, t), w, r)
+		}
+	}
+}
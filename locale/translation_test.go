@@ -0,0 +1,85 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package locale_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/directory"
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/corestoreio/csfw/locale"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/corestoreio/csfw/store"
+	storemock "github.com/corestoreio/csfw/store/mock"
+	"github.com/corestoreio/csfw/store/scope"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestWithTranslate(t *testing.T) {
+	tr := i18n.NewTranslator().Add("de", i18n.PriorityModule, i18n.Dictionary{
+		"Add to Cart":  {ID: "Add to Cart", Text: "In den Warenkorb"},
+		"%1 item(s)":   {ID: "%1 item(s)", Plural: map[string]string{i18n.PluralOne: "%1 Artikel", i18n.PluralOther: "%1 Artikel"}},
+		"Hello %1 %2!": {ID: "Hello %1 %2!", Text: "Hallo %2 %1!"},
+	})
+
+	cfg := config.NewMockGetter(config.WithMockValues(config.MockPV{
+		directory.Backend.GeneralLocaleCode.FQPathInt64(scope.StrStores, 1): "de_CH",
+	}))
+	ctx := storemock.WithContextMustService(scope.Option{}, func(ms *storemock.Storage) {
+		ms.MockStore = func() (*store.Store, error) {
+			return store.NewStore(
+				&store.TableStore{StoreID: 1, Code: dbr.NewNullString("ch"), WebsiteID: 1, GroupID: 1, Name: "Switzerland", SortOrder: 10, IsActive: true},
+				&store.TableWebsite{WebsiteID: 1, Code: dbr.NewNullString("euro"), Name: dbr.NewNullString("Europe"), SortOrder: 0, DefaultGroupID: 1, IsDefault: dbr.NewNullBool(true)},
+				&store.TableGroup{GroupID: 1, WebsiteID: 1, Name: "DACH Group", RootCategoryID: 2, DefaultStoreID: 1},
+				store.WithStoreConfig(cfg),
+			)
+		}
+	})
+
+	var called bool
+	h := locale.WithTranslate(tr)(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		called = true
+		lt, ok := locale.FromContextTranslate(ctx)
+		assert.True(t, ok)
+		assert.Exactly(t, "de_CH", lt.Locale)
+		assert.Exactly(t, "In den Warenkorb", lt.T("Add to Cart"))
+		assert.Exactly(t, "Hallo Gopher Go!", lt.T("Hello %1 %2!", "Go", "Gopher"))
+		assert.Exactly(t, "2 Artikel", lt.TN("%1 item(s)", 2))
+		assert.Exactly(t, "Price", lt.T("Price"))
+		return nil
+	})
+	req, err := http.NewRequest("GET", "http://corestore.io", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, h.ServeHTTPContext(ctx, httptest.NewRecorder(), req))
+	assert.True(t, called)
+
+	err = h.ServeHTTPContext(context.Background(), httptest.NewRecorder(), req)
+	assert.EqualError(t, err, store.ErrContextServiceNotFound.Error())
+}
+
+func TestFromContextTranslate(t *testing.T) {
+	lt, ok := locale.FromContextTranslate(context.Background())
+	assert.False(t, ok)
+	assert.Exactly(t, i18n.LocaleDefault, lt.Locale)
+	assert.Exactly(t, "1 item(s)", lt.TN("%1 item(s)", 1))
+}
+
+func TestCode(t *testing.T) {
+	assert.Exactly(t, i18n.LocaleDefault, locale.Code(config.NewMockGetter().NewScoped(0, 0, 0)))
+}
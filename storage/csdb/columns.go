@@ -29,6 +29,7 @@ import (
 const (
 	ColumnPrimary       = "PRI"
 	ColumnUnique        = "UNI"
+	ColumnIndex         = "MUL"
 	ColumnNull          = "YES"
 	ColumnNotNull       = "NO"
 	ColumnAutoIncrement = "auto_increment"
@@ -39,8 +40,12 @@ type (
 	// Columns contains a slice of column types
 	Columns []Column
 	// Column contains info about one database column retrieved from `SHOW COLUMNS FROM table`
+	// or from information_schema.COLUMNS.
 	Column struct {
 		Field, Type, Null, Key, Default, Extra dbr.NullString
+		// Comment, CharacterSet and Collation are only available when
+		// loaded with GetFullColumns.
+		Comment, CharacterSet, Collation dbr.NullString
 	}
 )
 
@@ -86,7 +91,8 @@ func GetColumns(dbrSess dbr.SessionRunner, table string) (Columns, error) {
 	return cols, nil
 }
 
-// Hash calculates a non-cryptographic, fast and efficient hash value from all columns.
+// Hash calculates a non-cryptographic, fast and efficient hash value from all columns
+// including the comment, character set and collation of GetFullColumns.
 // Current hash algorithm is fnv64.
 func (cs Columns) Hash() ([]byte, error) {
 	var tr byte = 0x74 // letter t for true
@@ -116,6 +122,18 @@ func (cs Columns) Hash() ([]byte, error) {
 		if c.Extra.Valid {
 			buf.WriteByte(tr)
 		}
+		buf.WriteString(c.Comment.String)
+		if c.Comment.Valid {
+			buf.WriteByte(tr)
+		}
+		buf.WriteString(c.CharacterSet.String)
+		if c.CharacterSet.Valid {
+			buf.WriteByte(tr)
+		}
+		buf.WriteString(c.Collation.String)
+		if c.Collation.Valid {
+			buf.WriteByte(tr)
+		}
 	}
 	f64 := fnv.New64()
 	if _, err := f64.Write(buf.Bytes()); err != nil {
@@ -176,6 +194,14 @@ func (cs Columns) ColumnsNoPK() Columns {
 	})
 }
 
+// ColumnsNoAutoIncrement returns all columns without the auto increment
+// property.
+func (cs Columns) ColumnsNoAutoIncrement() Columns {
+	return cs.Filter(func(c Column) bool {
+		return !c.IsAutoIncrement()
+	})
+}
+
 // Len returns the length
 func (cs Columns) Len() int {
 	return len(cs)
@@ -225,6 +251,25 @@ func (cs Columns) JoinFields(sep ...string) string {
 	return strings.Join(cs.FieldNames(), aSep)
 }
 
+// GoString returns the Go types representation. Comment, CharacterSet and
+// Collation are only included when valid. See interface fmt.GoStringer
+func (c Column) GoString() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "csdb.Column{Field:%#v, Type:%#v, Null:%#v, Key:%#v, Default:%#v, Extra:%#v",
+		c.Field, c.Type, c.Null, c.Key, c.Default, c.Extra)
+	if c.Comment.Valid {
+		fmt.Fprintf(&buf, ", Comment:%#v", c.Comment)
+	}
+	if c.CharacterSet.Valid {
+		fmt.Fprintf(&buf, ", CharacterSet:%#v", c.CharacterSet)
+	}
+	if c.Collation.Valid {
+		fmt.Fprintf(&buf, ", Collation:%#v", c.Collation)
+	}
+	buf.WriteRune('}')
+	return buf.String()
+}
+
 // Name returns the name of the column, a helper function.
 func (c Column) Name() string {
 	return c.Field.String
@@ -240,6 +285,11 @@ func (c Column) IsUnique() bool {
 	return c.Field.Valid && c.Key.Valid && c.Key.String == ColumnUnique
 }
 
+// IsIndex checks if column is the first column of a non-unique index
+func (c Column) IsIndex() bool {
+	return c.Field.Valid && c.Key.Valid && c.Key.String == ColumnIndex
+}
+
 // IsAutoIncrement checks if column has an auto increment property
 func (c Column) IsAutoIncrement() bool {
 	return c.Field.Valid && c.Extra.Valid && c.Extra.String == ColumnAutoIncrement
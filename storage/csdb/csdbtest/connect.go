@@ -0,0 +1,108 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdbtest
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+
+	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/juju/errgo"
+)
+
+// EnvRecord is the name of the environment variable which forces the
+// recording of existing golden files. CS_DSN_TEST must be set, too.
+const EnvRecord = "CS_DBTEST_RECORD"
+
+// ErrGoldenNotFound gets returned by ConnectTest when the golden file does
+// not exist and no test database has been configured to record it.
+var ErrGoldenNotFound = errors.New("Golden file not found and env var " + csdb.EnvDSNTest + " not set")
+
+// MustConnectTest is the replacement for csdb.MustConnectTest. It records or
+// replays the golden file GoldenFile(name) and panics on errors.
+func MustConnectTest(name string, opts ...dbr.ConnectionOption) *dbr.Connection {
+	c, err := ConnectTest(GoldenFile(name), opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// ConnectTest records all queries into the golden file if CS_DSN_TEST has
+// been set and the file does not exist or CS_DBTEST_RECORD has been set. In
+// all other cases the golden file gets replayed.
+func ConnectTest(file string, opts ...dbr.ConnectionOption) (*dbr.Connection, error) {
+	dsn, dsnErr := csdb.GetDSNTest()
+	_, statErr := os.Stat(file)
+
+	switch {
+	case dsnErr == nil && (os.IsNotExist(statErr) || os.Getenv(EnvRecord) != ""):
+		return Record(file, dbr.DriverNameMySQL, dsn, opts...)
+	case statErr == nil:
+		return Replay(file, opts...)
+	case os.IsNotExist(statErr):
+		return nil, errgo.WithCausef(nil, ErrGoldenNotFound, "%s: %s", ErrGoldenNotFound, file)
+	}
+	return nil, errgo.Mask(statErr)
+}
+
+// Replay creates a connection which answers all queries from the golden
+// file. No database is needed.
+func Replay(file string, opts ...dbr.ConnectionOption) (*dbr.Connection, error) {
+	g, err := ReadGolden(file)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	players.Lock()
+	players.m[file] = newPlayer(g)
+	players.Unlock()
+
+	return open(DriverNameReplay, file, opts...)
+}
+
+// Record creates a connection to a real database with the driver and the
+// DSN. All queries and their responses get written into the golden file.
+// The file is complete after the connection has been closed.
+func Record(file, driverName, dsn string, opts ...dbr.ConnectionOption) (*dbr.Connection, error) {
+	// sql.Open provides the access to the registered driver
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	drv := db.Driver()
+	if err := db.Close(); err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	recorders.Lock()
+	recorders.m[file] = &recorder{file: file, drv: drv, dsn: dsn}
+	recorders.Unlock()
+
+	return open(DriverNameRecord, file, opts...)
+}
+
+func open(driverName, file string, opts ...dbr.ConnectionOption) (*dbr.Connection, error) {
+	db, err := sql.Open(driverName, file)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	c, err := dbr.NewConnection(append([]dbr.ConnectionOption{dbr.SetDB(db)}, opts...)...)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return c, nil
+}
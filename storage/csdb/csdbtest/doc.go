@@ -0,0 +1,39 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package csdbtest provides database connections for tests which run without
+// a MySQL server.
+//
+// A test connects with MustConnectTest and a name for its golden file. If the
+// environment variable CS_DSN_TEST has been set and the golden file does not
+// yet exist, all queries get sent to the real database and the query/response
+// pairs get written to testdata/csdbtest/<name>.json. Otherwise the golden
+// file gets replayed through a fake database/sql/driver and no database is
+// needed. Set CS_DBTEST_RECORD to record an existing golden file again.
+//
+//	func init() {
+//		dbc := csdbtest.MustConnectTest("store_init")
+//		defer dbc.Close()
+//		if err := store.TableCollection.Init(dbc.NewSession()); err != nil {
+//			panic(err)
+//		}
+//	}
+//
+// Queries get matched by their SQL text and their arguments. Arguments which
+// change with every run, for example time.Now(), prevent a match.
+//
+// LoadFixtures inserts rows from YAML files into tables. When recording, the
+// inserts get also recorded so the fixtures do not need a database during
+// replay.
+package csdbtest
@@ -0,0 +1,193 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdbtest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/juju/errgo"
+)
+
+const (
+	// DriverNameReplay is the name of the driver which replays a golden
+	// file. The DSN is the path to the golden file.
+	DriverNameReplay = "csdbtest_replay"
+	// DriverNameRecord is the name of the driver which records all queries
+	// sent to a real database. The DSN is the path to the golden file.
+	DriverNameRecord = "csdbtest_record"
+)
+
+// ErrNotRecorded gets returned by the replay driver when a query cannot be
+// found in the golden file.
+var ErrNotRecorded = errors.New("Query not found in golden file")
+
+func init() {
+	sql.Register(DriverNameReplay, replayDriver{})
+	sql.Register(DriverNameRecord, recordDriver{})
+}
+
+// players contains the golden files loaded with Replay. Key is the file
+// name which is also the DSN.
+var players = struct {
+	sync.Mutex
+	m map[string]*player
+}{
+	m: make(map[string]*player),
+}
+
+// player answers queries with the recorded responses of a golden file. If a
+// query has been recorded several times, the responses get returned in the
+// recorded order and the last one gets repeated.
+type player struct {
+	mu    sync.Mutex
+	pos   map[string][]int // key to indexes in exchanges
+	next  map[string]int   // key to next position in pos
+	exchs []Exchange
+}
+
+func newPlayer(g *Golden) *player {
+	p := &player{
+		pos:   make(map[string][]int),
+		next:  make(map[string]int),
+		exchs: g.Exchanges,
+	}
+	for i, e := range g.Exchanges {
+		k := e.key()
+		p.pos[k] = append(p.pos[k], i)
+	}
+	return p
+}
+
+func (p *player) find(query string, args []driver.Value) (Exchange, error) {
+	k := Exchange{Query: query, Args: newValues(args)}.key()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	idx, ok := p.pos[k]
+	if !ok {
+		return Exchange{}, errgo.WithCausef(nil, ErrNotRecorded, "%s: %q Args: %v", ErrNotRecorded, normalizeQuery(query), args)
+	}
+	n := p.next[k]
+	if n < len(idx)-1 {
+		p.next[k] = n + 1
+	}
+	return p.exchs[idx[n]], nil
+}
+
+type replayDriver struct{}
+
+func (replayDriver) Open(name string) (driver.Conn, error) {
+	players.Lock()
+	defer players.Unlock()
+	p, ok := players.m[name]
+	if !ok {
+		return nil, errgo.Newf("Golden file %q has not been loaded", name)
+	}
+	return &replayConn{p: p}, nil
+}
+
+type replayConn struct {
+	p *player
+}
+
+var (
+	_ driver.Conn    = (*replayConn)(nil)
+	_ driver.Queryer = (*replayConn)(nil)
+	_ driver.Execer  = (*replayConn)(nil)
+)
+
+func (c *replayConn) Prepare(query string) (driver.Stmt, error) {
+	return &replayStmt{c: c, query: query}, nil
+}
+
+func (c *replayConn) Close() error { return nil }
+
+func (c *replayConn) Begin() (driver.Tx, error) { return replayTx{}, nil }
+
+func (c *replayConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	e, err := c.p.find(query, args)
+	if err != nil {
+		return nil, err
+	}
+	if e.Error != "" {
+		return nil, errors.New(e.Error)
+	}
+	return &replayRows{cols: e.Columns, rows: e.Rows}, nil
+}
+
+func (c *replayConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	e, err := c.p.find(query, args)
+	if err != nil {
+		return nil, err
+	}
+	if e.Error != "" {
+		return nil, errors.New(e.Error)
+	}
+	return replayResult{id: e.LastInsertID, affected: e.RowsAffected}, nil
+}
+
+type replayTx struct{}
+
+func (replayTx) Commit() error   { return nil }
+func (replayTx) Rollback() error { return nil }
+
+type replayStmt struct {
+	c     *replayConn
+	query string
+}
+
+func (s *replayStmt) Close() error  { return nil }
+func (s *replayStmt) NumInput() int { return -1 }
+
+func (s *replayStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.c.Exec(s.query, args)
+}
+
+func (s *replayStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.c.Query(s.query, args)
+}
+
+type replayResult struct {
+	id, affected int64
+}
+
+func (r replayResult) LastInsertId() (int64, error) { return r.id, nil }
+func (r replayResult) RowsAffected() (int64, error) { return r.affected, nil }
+
+type replayRows struct {
+	cols []string
+	rows [][]Value
+	pos  int
+}
+
+func (r *replayRows) Columns() []string { return r.cols }
+func (r *replayRows) Close() error      { return nil }
+
+func (r *replayRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+	for i, v := range r.rows[r.pos] {
+		if i < len(dest) {
+			dest[i] = v.Value
+		}
+	}
+	r.pos++
+	return nil
+}
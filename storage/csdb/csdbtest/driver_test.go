@@ -0,0 +1,139 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdbtest_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/juju/errgo"
+	"github.com/stretchr/testify/assert"
+)
+
+var replayFile = csdbtest.GoldenFile("replay")
+
+type testWebsite struct {
+	WebsiteID int64          `db:"website_id"`
+	Code      string         `db:"code"`
+	Name      dbr.NullString `db:"name"`
+	IsDefault bool           `db:"is_default"`
+}
+
+// runQueries runs all queries of the golden file replay.json.
+func runQueries(t *testing.T, dbc *dbr.Connection) {
+	var ws []*testWebsite
+	n, err := dbc.NewSession().
+		SelectBySql("SELECT website_id, code, name, is_default FROM store_website WHERE website_id > ?", 0).
+		LoadStructs(&ws)
+	assert.NoError(t, err)
+	assert.Exactly(t, 2, n)
+	assert.Exactly(t, &testWebsite{WebsiteID: 1, Code: "euro", Name: dbr.NewNullString("Europe"), IsDefault: true}, ws[0])
+	assert.Exactly(t, &testWebsite{WebsiteID: 2, Code: "oz"}, ws[1])
+
+	// white spaces do not matter
+	res, err := dbc.DB.Exec("UPDATE store_website\n\tSET name = ?\n\tWHERE website_id = ?", "Australia", 2)
+	assert.NoError(t, err)
+	ra, err := res.RowsAffected()
+	assert.NoError(t, err)
+	assert.Exactly(t, int64(1), ra)
+
+	// same query returns the responses in the recorded order
+	for _, want := range []int{2, 3} {
+		var count int
+		assert.NoError(t, dbc.DB.QueryRow("SELECT COUNT(*) FROM store_website").Scan(&count))
+		assert.Exactly(t, want, count)
+	}
+
+	_, err = dbc.DB.Query("SELECT * FROM store_missing")
+	assert.EqualError(t, err, "Error 1146: Table 'test.store_missing' doesn't exist")
+}
+
+func TestReplay(t *testing.T) {
+	dbc, err := csdbtest.Replay(replayFile)
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, dbc.Close()) }()
+
+	runQueries(t, dbc)
+
+	// the last response gets repeated
+	var count int
+	assert.NoError(t, dbc.DB.QueryRow("SELECT COUNT(*) FROM store_website").Scan(&count))
+	assert.Exactly(t, 3, count)
+
+	_, err = dbc.DB.Exec("DELETE FROM store_website")
+	assert.Exactly(t, csdbtest.ErrNotRecorded, errgo.Cause(err))
+	assert.Contains(t, err.Error(), `"DELETE FROM store_website"`)
+
+	stmt, err := dbc.DB.Prepare("SELECT COUNT(*) FROM store_website")
+	assert.NoError(t, err)
+	assert.NoError(t, stmt.QueryRow().Scan(&count))
+	assert.Exactly(t, 3, count)
+	assert.NoError(t, stmt.Close())
+}
+
+func TestReplayFileNotFound(t *testing.T) {
+	_, err := csdbtest.Replay(filepath.Join("testdata", "not_found.json"))
+	assert.Contains(t, err.Error(), "no such file or directory")
+}
+
+// TestRecord records the replay driver and compares the new golden file
+// with the replayed one.
+func TestRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "csdbtest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// registers the golden file for the replay driver
+	replay, err := csdbtest.Replay(replayFile)
+	assert.NoError(t, err)
+	defer replay.Close()
+
+	file := filepath.Join(dir, "record.json")
+	dbc, err := csdbtest.Record(file, csdbtest.DriverNameReplay, replayFile)
+	assert.NoError(t, err)
+	runQueries(t, dbc)
+	assert.NoError(t, dbc.Close())
+
+	want, err := csdbtest.ReadGolden(replayFile)
+	assert.NoError(t, err)
+	have, err := csdbtest.ReadGolden(file)
+	assert.NoError(t, err)
+
+	assert.Len(t, have.Exchanges, len(want.Exchanges))
+	for i, w := range want.Exchanges {
+		h := have.Exchanges[i]
+		assert.Exactly(t, w.Columns, h.Columns, "Index %d", i)
+		assert.Exactly(t, w.Rows, h.Rows, "Index %d", i)
+		assert.Exactly(t, w.Args, h.Args, "Index %d", i)
+		assert.Exactly(t, w.RowsAffected, h.RowsAffected, "Index %d", i)
+		assert.Exactly(t, w.Error, h.Error, "Index %d", i)
+	}
+}
+
+func TestConnectTestGoldenNotFound(t *testing.T) {
+	if os.Getenv("CS_DSN_TEST") != "" {
+		t.Skip("CS_DSN_TEST has been set and the golden file would be recorded")
+	}
+	_, err := csdbtest.ConnectTest(filepath.Join("testdata", "not_found.json"))
+	assert.Exactly(t, csdbtest.ErrGoldenNotFound, errgo.Cause(err))
+
+	dbc, err := csdbtest.ConnectTest(replayFile)
+	assert.NoError(t, err)
+	assert.NoError(t, dbc.Close())
+}
@@ -0,0 +1,143 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdbtest
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/juju/errgo"
+	"gopkg.in/yaml.v2"
+)
+
+// Fixture contains the rows of several tables. The order of the tables and
+// columns is the same as in the YAML file.
+//
+//	store_website:
+//	  - website_id: 1
+//	    code: euro
+//	    name: Europe
+//	store_group:
+//	  - group_id: 1
+//	    website_id: 1
+//	    name: DACH Group
+type Fixture struct {
+	Tables []FixtureTable
+}
+
+// FixtureTable contains the rows of one table.
+type FixtureTable struct {
+	Name string
+	Rows []FixtureRow
+}
+
+// FixtureRow contains the columns and the values of one row.
+type FixtureRow struct {
+	Columns []string
+	Values  []interface{}
+}
+
+// ReadFixture parses a YAML fixture file.
+func ReadFixture(file string) (*Fixture, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	f, err := ParseFixture(data)
+	if err != nil {
+		return nil, errgo.Notef(err, "File %s", file)
+	}
+	return f, nil
+}
+
+// ParseFixture parses YAML data. The top level keys are the table names and
+// each table contains a list of rows.
+func ParseFixture(data []byte) (*Fixture, error) {
+	var ms yaml.MapSlice
+	if err := yaml.Unmarshal(data, &ms); err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	f := new(Fixture)
+	for _, t := range ms {
+		ft := FixtureTable{Name: fmt.Sprint(t.Key)}
+		rows, ok := t.Value.([]interface{})
+		if !ok && t.Value != nil {
+			return nil, errgo.Newf("Table %s: expecting a list of rows but got %T", ft.Name, t.Value)
+		}
+		for i, r := range rows {
+			cols, ok := r.(yaml.MapSlice)
+			if !ok {
+				return nil, errgo.Newf("Table %s row %d: expecting columns but got %T", ft.Name, i, r)
+			}
+			var fr FixtureRow
+			for _, c := range cols {
+				fr.Columns = append(fr.Columns, fmt.Sprint(c.Key))
+				fr.Values = append(fr.Values, c.Value)
+			}
+			ft.Rows = append(ft.Rows, fr)
+		}
+		f.Tables = append(f.Tables, ft)
+	}
+	return f, nil
+}
+
+// LoadFixtures deletes all rows in the tables of the fixture files and
+// inserts the rows of the fixtures. All statements run in one transaction
+// with disabled foreign key checks.
+func LoadFixtures(dbc *dbr.Connection, files ...string) error {
+	fs := make([]*Fixture, len(files))
+	for i, file := range files {
+		f, err := ReadFixture(file)
+		if err != nil {
+			return errgo.Mask(err)
+		}
+		fs[i] = f
+	}
+
+	tx, err := dbc.NewSession().Begin()
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	if _, err := tx.Tx.Exec("SET FOREIGN_KEY_CHECKS=0"); err != nil {
+		return errgo.Mask(err)
+	}
+	for _, f := range fs {
+		if err := f.load(tx); err != nil {
+			return errgo.Mask(err)
+		}
+	}
+	if _, err := tx.Tx.Exec("SET FOREIGN_KEY_CHECKS=1"); err != nil {
+		return errgo.Mask(err)
+	}
+	return errgo.Mask(tx.Commit())
+}
+
+func (f *Fixture) load(tx *dbr.Tx) error {
+	for _, t := range f.Tables {
+		if _, err := tx.DeleteFrom(t.Name).Exec(); err != nil {
+			return errgo.Notef(err, "Table %s", t.Name)
+		}
+		for i, r := range t.Rows {
+			if _, err := tx.InsertInto(t.Name).Columns(r.Columns...).Values(r.Values...).Exec(); err != nil {
+				return errgo.Notef(err, "Table %s row %d", t.Name, i)
+			}
+		}
+	}
+	return nil
+}
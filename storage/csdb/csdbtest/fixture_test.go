@@ -0,0 +1,76 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdbtest_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+var fixtureFile = filepath.Join("testdata", "fixture.yaml")
+
+func TestReadFixture(t *testing.T) {
+	f, err := csdbtest.ReadFixture(fixtureFile)
+	assert.NoError(t, err)
+	assert.Exactly(t, &csdbtest.Fixture{
+		Tables: []csdbtest.FixtureTable{
+			{
+				Name: "store_website",
+				Rows: []csdbtest.FixtureRow{
+					{
+						Columns: []string{"website_id", "code", "name", "is_default"},
+						Values:  []interface{}{1, "euro", "Europe", true},
+					},
+					{
+						Columns: []string{"website_id", "code", "name"},
+						Values:  []interface{}{2, "oz", nil},
+					},
+				},
+			},
+			{
+				Name: "store_group",
+				Rows: []csdbtest.FixtureRow{
+					{
+						Columns: []string{"group_id", "website_id", "name", "root_category_id"},
+						Values:  []interface{}{1, 1, "DACH Group", 2},
+					},
+				},
+			},
+		},
+	}, f)
+}
+
+func TestParseFixtureErrors(t *testing.T) {
+	_, err := csdbtest.ParseFixture([]byte("store: 1"))
+	assert.EqualError(t, err, "Table store: expecting a list of rows but got int")
+	_, err = csdbtest.ParseFixture([]byte("store:\n  - 1"))
+	assert.EqualError(t, err, "Table store row 0: expecting columns but got int")
+	_, err = csdbtest.ParseFixture([]byte("store: ["))
+	assert.Error(t, err)
+}
+
+func TestLoadFixtures(t *testing.T) {
+	dbc, err := csdbtest.Replay(csdbtest.GoldenFile("fixture"))
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, dbc.Close()) }()
+
+	assert.NoError(t, csdbtest.LoadFixtures(dbc, fixtureFile))
+
+	err = csdbtest.LoadFixtures(dbc, fixtureFile, filepath.Join("testdata", "not_found.yaml"))
+	assert.Contains(t, err.Error(), "no such file or directory")
+}
@@ -0,0 +1,212 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdbtest
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/juju/errgo"
+)
+
+// GoldenVersion is the current format version of a golden file. A golden
+// file with a higher version cannot be read.
+const GoldenVersion = 1
+
+// GoldenDir is the directory relative to the package of a test where
+// MustConnectTest reads and writes the golden files.
+var GoldenDir = filepath.Join("testdata", "csdbtest")
+
+// ErrGoldenVersion gets returned when reading a golden file with an unknown
+// format version.
+var ErrGoldenVersion = errors.New("Unsupported golden file version")
+
+// Golden contains all recorded query/response pairs of a test.
+type Golden struct {
+	Version   int
+	Exchanges []Exchange
+}
+
+// Exchange is one query or statement together with the response of the
+// database.
+type Exchange struct {
+	Query        string
+	Args         []Value   `json:",omitempty"`
+	Columns      []string  `json:",omitempty"`
+	Rows         [][]Value `json:",omitempty"`
+	LastInsertID int64     `json:",omitempty"`
+	RowsAffected int64     `json:",omitempty"`
+	Error        string    `json:",omitempty"`
+}
+
+// key identifies an exchange by its normalized query and its arguments.
+func (e Exchange) key() string {
+	var buf bytes.Buffer
+	buf.WriteString(normalizeQuery(e.Query))
+	for _, a := range e.Args {
+		buf.WriteByte(0)
+		buf.WriteString(a.String())
+	}
+	return buf.String()
+}
+
+// GoldenFile returns the path to the golden file for name.
+func GoldenFile(name string) string {
+	return filepath.Join(GoldenDir, name+".json")
+}
+
+// ReadGolden reads a golden file.
+func ReadGolden(file string) (*Golden, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	g := new(Golden)
+	if err := json.Unmarshal(data, g); err != nil {
+		return nil, errgo.Notef(err, "File %s", file)
+	}
+	if g.Version > GoldenVersion {
+		return nil, ErrGoldenVersion
+	}
+	return g, nil
+}
+
+// WriteFile writes the golden file and creates the directory if it does not
+// exist.
+func (g *Golden) WriteFile(file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return errgo.Mask(err)
+	}
+	g.Version = GoldenVersion
+	data, err := json.MarshalIndent(g, "", "\t")
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	return errgo.Mask(ioutil.WriteFile(file, append(data, '\n'), 0644))
+}
+
+// normalizeQuery collapses all white spaces so that formatting changes in
+// the Go code do not break a golden file.
+func normalizeQuery(q string) string {
+	return strings.Join(strings.Fields(q), " ")
+}
+
+// Value wraps a driver.Value to keep its type in the JSON encoding. A plain
+// JSON number cannot tell an int64 from a float64 and a string from []byte.
+type Value struct {
+	driver.Value
+}
+
+func newValues(dv []driver.Value) []Value {
+	if len(dv) == 0 {
+		return nil
+	}
+	vs := make([]Value, len(dv))
+	for i, v := range dv {
+		vs[i] = Value{Value: v}
+	}
+	return vs
+}
+
+// String returns a type prefixed string representation used for matching.
+func (v Value) String() string {
+	switch t := v.Value.(type) {
+	case nil:
+		return "null"
+	case []byte:
+		return "bytes:" + string(t)
+	case time.Time:
+		return "time:" + t.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%T:%v", v.Value, v.Value)
+}
+
+// jsonValue is the JSON representation of a Value. Only one field gets set.
+type jsonValue struct {
+	Int    *int64   `json:"int,omitempty"`
+	Float  *float64 `json:"float,omitempty"`
+	Bool   *bool    `json:"bool,omitempty"`
+	Bytes  *string  `json:"bytes,omitempty"`
+	String *string  `json:"string,omitempty"`
+	Time   *string  `json:"time,omitempty"`
+}
+
+// MarshalJSON encodes nil as null and all other values as an object with the
+// type as key, e.g. {"int":3} or {"bytes":"text"}.
+func (v Value) MarshalJSON() ([]byte, error) {
+	var jv jsonValue
+	switch t := v.Value.(type) {
+	case nil:
+		return []byte("null"), nil
+	case int64:
+		jv.Int = &t
+	case float64:
+		jv.Float = &t
+	case bool:
+		jv.Bool = &t
+	case []byte:
+		s := string(t)
+		jv.Bytes = &s
+	case string:
+		jv.String = &t
+	case time.Time:
+		s := t.Format(time.RFC3339Nano)
+		jv.Time = &s
+	default:
+		return nil, fmt.Errorf("Unsupported driver.Value type %T", v.Value)
+	}
+	return json.Marshal(jv)
+}
+
+// UnmarshalJSON decodes the representation created by MarshalJSON.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		v.Value = nil
+		return nil
+	}
+	var jv jsonValue
+	if err := json.Unmarshal(data, &jv); err != nil {
+		return err
+	}
+	switch {
+	case jv.Int != nil:
+		v.Value = *jv.Int
+	case jv.Float != nil:
+		v.Value = *jv.Float
+	case jv.Bool != nil:
+		v.Value = *jv.Bool
+	case jv.Bytes != nil:
+		v.Value = []byte(*jv.Bytes)
+	case jv.String != nil:
+		v.Value = *jv.String
+	case jv.Time != nil:
+		t, err := time.Parse(time.RFC3339Nano, *jv.Time)
+		if err != nil {
+			return err
+		}
+		v.Value = t
+	default:
+		return fmt.Errorf("Cannot decode value %s", data)
+	}
+	return nil
+}
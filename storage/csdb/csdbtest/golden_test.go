@@ -0,0 +1,83 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdbtest_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueJSONRoundTrip(t *testing.T) {
+	now := time.Date(2016, 2, 3, 4, 5, 6, 7, time.UTC)
+	have := []csdbtest.Value{
+		{Value: nil},
+		{Value: int64(-3)},
+		{Value: 1.5},
+		{Value: float64(2)},
+		{Value: true},
+		{Value: []byte("euro")},
+		{Value: "oz"},
+		{Value: now},
+	}
+	data, err := json.Marshal(have)
+	assert.NoError(t, err)
+	assert.Exactly(t,
+		`[null,{"int":-3},{"float":1.5},{"float":2},{"bool":true},{"bytes":"euro"},{"string":"oz"},{"time":"2016-02-03T04:05:06.000000007Z"}]`,
+		string(data),
+	)
+
+	var want []csdbtest.Value
+	assert.NoError(t, json.Unmarshal(data, &want))
+	assert.Exactly(t, have, want)
+
+	_, err = json.Marshal(csdbtest.Value{Value: int32(1)})
+	assert.Error(t, err)
+	assert.Error(t, json.Unmarshal([]byte(`{}`), &want[0]))
+}
+
+func TestGoldenWriteRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "csdbtest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "sub", "golden.json")
+	g := &csdbtest.Golden{
+		Exchanges: []csdbtest.Exchange{
+			{Query: "SELECT 1", Columns: []string{"1"}, Rows: [][]csdbtest.Value{{{Value: int64(1)}}}},
+			{Query: "DELETE FROM a", RowsAffected: 4},
+		},
+	}
+	assert.NoError(t, g.WriteFile(file))
+
+	g2, err := csdbtest.ReadGolden(file)
+	assert.NoError(t, err)
+	assert.Exactly(t, csdbtest.GoldenVersion, g2.Version)
+	assert.Exactly(t, g, g2)
+
+	assert.NoError(t, ioutil.WriteFile(file, []byte(`{"Version":2}`), 0644))
+	_, err = csdbtest.ReadGolden(file)
+	assert.EqualError(t, err, csdbtest.ErrGoldenVersion.Error())
+}
+
+func TestGoldenFile(t *testing.T) {
+	assert.Exactly(t, filepath.Join("testdata", "csdbtest", "store_init.json"), csdbtest.GoldenFile("store_init"))
+}
@@ -0,0 +1,205 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdbtest
+
+import (
+	"database/sql/driver"
+	"io"
+	"sync"
+
+	"github.com/juju/errgo"
+)
+
+// recorders contains the recorders created with Record. Key is the file
+// name of the golden file which is also the DSN.
+var recorders = struct {
+	sync.Mutex
+	m map[string]*recorder
+}{
+	m: make(map[string]*recorder),
+}
+
+// recorder sends all queries to a real database and appends the responses
+// to a golden file.
+type recorder struct {
+	file string
+	drv  driver.Driver
+	dsn  string
+
+	mu sync.Mutex
+	g  Golden
+}
+
+// add appends an exchange. The error of the database gets stored as text.
+func (r *recorder) add(e Exchange, err error) {
+	if err != nil {
+		e.Error = err.Error()
+	}
+	r.mu.Lock()
+	r.g.Exchanges = append(r.g.Exchanges, e)
+	r.mu.Unlock()
+}
+
+// save writes all exchanges recorded so far into the golden file.
+func (r *recorder) save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return errgo.Mask(r.g.WriteFile(r.file))
+}
+
+type recordDriver struct{}
+
+func (recordDriver) Open(name string) (driver.Conn, error) {
+	recorders.Lock()
+	r, ok := recorders.m[name]
+	recorders.Unlock()
+	if !ok {
+		return nil, errgo.Newf("Recorder for golden file %q not found", name)
+	}
+	c, err := r.drv.Open(r.dsn)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return &recordConn{r: r, c: c}, nil
+}
+
+// recordConn wraps the connection of the real driver. Every connection
+// writes the golden file when it gets closed, so the file is complete after
+// the sql.DB has been closed.
+type recordConn struct {
+	r *recorder
+	c driver.Conn
+}
+
+var (
+	_ driver.Conn    = (*recordConn)(nil)
+	_ driver.Queryer = (*recordConn)(nil)
+	_ driver.Execer  = (*recordConn)(nil)
+)
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) {
+	s, err := c.c.Prepare(query)
+	if err != nil {
+		c.r.add(Exchange{Query: query}, err)
+		return nil, err
+	}
+	return &recordStmt{r: c.r, s: s, query: query}, nil
+}
+
+func (c *recordConn) Close() error {
+	if err := c.c.Close(); err != nil {
+		return err
+	}
+	return c.r.save()
+}
+
+func (c *recordConn) Begin() (driver.Tx, error) { return c.c.Begin() }
+
+func (c *recordConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	var rows driver.Rows
+	err := driver.ErrSkip
+	if q, ok := c.c.(driver.Queryer); ok {
+		rows, err = q.Query(query, args)
+	}
+	if err == driver.ErrSkip {
+		var s driver.Stmt
+		if s, err = c.c.Prepare(query); err == nil {
+			defer s.Close()
+			rows, err = s.Query(args)
+		}
+	}
+	return c.r.rows(query, args, rows, err)
+}
+
+func (c *recordConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	var res driver.Result
+	err := driver.ErrSkip
+	if ex, ok := c.c.(driver.Execer); ok {
+		res, err = ex.Exec(query, args)
+	}
+	if err == driver.ErrSkip {
+		var s driver.Stmt
+		if s, err = c.c.Prepare(query); err == nil {
+			defer s.Close()
+			res, err = s.Exec(args)
+		}
+	}
+	return c.r.result(query, args, res, err)
+}
+
+type recordStmt struct {
+	r     *recorder
+	s     driver.Stmt
+	query string
+}
+
+func (s *recordStmt) Close() error  { return s.s.Close() }
+func (s *recordStmt) NumInput() int { return s.s.NumInput() }
+
+func (s *recordStmt) Exec(args []driver.Value) (driver.Result, error) {
+	res, err := s.s.Exec(args)
+	return s.r.result(s.query, args, res, err)
+}
+
+func (s *recordStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows, err := s.s.Query(args)
+	return s.r.rows(s.query, args, rows, err)
+}
+
+// rows reads all rows of the real database into an exchange and returns
+// them as replayRows because the driver can reuse the memory of the values.
+func (r *recorder) rows(query string, args []driver.Value, rows driver.Rows, err error) (driver.Rows, error) {
+	e := Exchange{Query: query, Args: newValues(args)}
+	if err != nil {
+		r.add(e, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	e.Columns = rows.Columns()
+	dest := make([]driver.Value, len(e.Columns))
+	for {
+		if err = rows.Next(dest); err != nil {
+			break
+		}
+		row := make([]Value, len(dest))
+		for i, v := range dest {
+			if b, ok := v.([]byte); ok {
+				v = append([]byte(nil), b...)
+			}
+			row[i] = Value{Value: v}
+		}
+		e.Rows = append(e.Rows, row)
+	}
+	if err != io.EOF {
+		r.add(e, err)
+		return nil, err
+	}
+	r.add(e, nil)
+	return &replayRows{cols: e.Columns, rows: e.Rows}, nil
+}
+
+func (r *recorder) result(query string, args []driver.Value, res driver.Result, err error) (driver.Result, error) {
+	e := Exchange{Query: query, Args: newValues(args)}
+	if err != nil {
+		r.add(e, err)
+		return nil, err
+	}
+	// errors are ignored because not all drivers support both values
+	e.LastInsertID, _ = res.LastInsertId()
+	e.RowsAffected, _ = res.RowsAffected()
+	r.add(e, nil)
+	return res, nil
+}
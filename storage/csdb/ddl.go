@@ -0,0 +1,384 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdb
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/juju/errgo"
+)
+
+// Execer defines the only needed function to run a DDL statement, e.g.
+// *sql.DB, *sql.Tx, *dbr.Connection or *dbr.Tx.
+type Execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// DDL a CREATE, ALTER or DROP TABLE statement generated by Table.Create,
+// Table.Alter or Table.Drop. MySQL commits DDL statements implicitly, even
+// within a transaction.
+type DDL struct {
+	// SQL the generated statement
+	SQL string
+	db  Execer
+}
+
+// String returns the SQL statement.
+func (d *DDL) String() string {
+	return d.SQL
+}
+
+// Exec runs the statement.
+func (d *DDL) Exec() (sql.Result, error) {
+	res, err := d.db.Exec(d.SQL)
+	return res, errgo.Mask(err)
+}
+
+// CreateSQL generates the CREATE TABLE statement from the columns including
+// the primary key, the unique keys and the indexes. Without loaded metadata
+// each unique key and index covers a single column because SHOW COLUMNS only
+// reports the first column of an index. With LoadMetadata the statement
+// contains all indexes, foreign keys and table options.
+func (ts *Table) CreateSQL() (string, error) {
+	if ts == nil {
+		return "", ErrTableNotFound
+	}
+	if ts.Columns.Len() == 0 {
+		return "", ErrTableNoColumns
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("CREATE TABLE ")
+	writeIdent(&buf, ts.Name)
+	buf.WriteString(" (\n")
+	for i, c := range ts.Columns {
+		if i > 0 {
+			buf.WriteString(",\n")
+		}
+		buf.WriteString("  ")
+		writeColumnDefinition(&buf, c)
+	}
+	if len(ts.Indexes) > 0 {
+		ts.writeIndexes(&buf)
+	} else {
+		ts.writeColumnKeys(&buf)
+	}
+	for _, fk := range ts.ForeignKeys {
+		buf.WriteString(",\n  CONSTRAINT ")
+		writeIdent(&buf, fk.Name)
+		buf.WriteString(" FOREIGN KEY (")
+		writeIdentList(&buf, fk.Columns)
+		buf.WriteString(") REFERENCES ")
+		writeIdent(&buf, fk.RefTable)
+		buf.WriteString(" (")
+		writeIdentList(&buf, fk.RefColumns)
+		buf.WriteRune(')')
+		if fk.OnDelete != "" {
+			buf.WriteString(" ON DELETE " + fk.OnDelete)
+		}
+		if fk.OnUpdate != "" {
+			buf.WriteString(" ON UPDATE " + fk.OnUpdate)
+		}
+	}
+
+	engine, charset := ts.Engine, ts.CharacterSet
+	if engine == "" {
+		engine = "InnoDB"
+	}
+	if charset == "" {
+		charset = "utf8"
+	}
+	buf.WriteString("\n) ENGINE=" + engine + " DEFAULT CHARSET=" + charset)
+	if ts.Collation != "" {
+		buf.WriteString(" COLLATE=" + ts.Collation)
+	}
+	if ts.Comment != "" {
+		buf.WriteString(" COMMENT=")
+		writeString(&buf, ts.Comment)
+	}
+	return buf.String(), nil
+}
+
+// writeColumnKeys writes the keys derived from the Key field of the columns.
+func (ts *Table) writeColumnKeys(buf *bytes.Buffer) {
+	if ts.CountPK > 0 {
+		buf.WriteString(",\n  PRIMARY KEY (")
+		writeIdentList(buf, ts.fieldsPK)
+		buf.WriteRune(')')
+	}
+	for _, c := range ts.Columns {
+		switch {
+		case c.IsUnique():
+			buf.WriteString(",\n  UNIQUE KEY ")
+			writeIdent(buf, indexName("UNQ", ts.Name, c.Field.String))
+		case c.IsIndex():
+			buf.WriteString(",\n  KEY ")
+			writeIdent(buf, indexName("IDX", ts.Name, c.Field.String))
+		default:
+			continue
+		}
+		buf.WriteString(" (")
+		writeIdent(buf, c.Field.String)
+		buf.WriteRune(')')
+	}
+}
+
+// writeIndexes writes the indexes loaded from information_schema.
+func (ts *Table) writeIndexes(buf *bytes.Buffer) {
+	for _, i := range ts.Indexes {
+		buf.WriteString(",\n  ")
+		switch {
+		case i.IsPrimary():
+			buf.WriteString("PRIMARY KEY (")
+			writeIdentList(buf, i.Columns)
+			buf.WriteRune(')')
+			continue
+		case i.Type == "FULLTEXT":
+			buf.WriteString("FULLTEXT KEY ")
+		case i.Unique:
+			buf.WriteString("UNIQUE KEY ")
+		default:
+			buf.WriteString("KEY ")
+		}
+		writeIdent(buf, i.Name)
+		buf.WriteString(" (")
+		writeIdentList(buf, i.Columns)
+		buf.WriteRune(')')
+	}
+}
+
+// AlterSQL generates an ALTER TABLE statement which transforms the current
+// columns into the columns of this table. Missing columns get added at their
+// position, changed columns get modified and superfluous columns get
+// dropped. Indexes are not compared. Returns an empty string if the columns
+// are equal.
+func (ts *Table) AlterSQL(current Columns) string {
+	diffs := ts.DiffColumns(current)
+	if len(diffs) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	buf.WriteString("ALTER TABLE ")
+	writeIdent(&buf, ts.Name)
+	buf.WriteRune(' ')
+	for i, d := range diffs {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		switch d.Kind {
+		case ColumnMissing:
+			buf.WriteString("ADD COLUMN ")
+			writeColumnDefinition(&buf, d.Want)
+			if d.After == "" {
+				buf.WriteString(" FIRST")
+			} else {
+				buf.WriteString(" AFTER ")
+				writeIdent(&buf, d.After)
+			}
+		case ColumnChanged:
+			buf.WriteString("MODIFY COLUMN ")
+			writeColumnDefinition(&buf, d.Want)
+		case ColumnSuperfluous:
+			buf.WriteString("DROP COLUMN ")
+			writeIdent(&buf, d.Have.Field.String)
+		}
+	}
+	return buf.String()
+}
+
+// ColumnDiffKind describes the difference of a column between the table
+// definition and the database.
+type ColumnDiffKind uint8
+
+const (
+	// ColumnMissing column is defined in the table but not in the database.
+	ColumnMissing ColumnDiffKind = iota + 1
+	// ColumnChanged column type, null, default or extra differs.
+	ColumnChanged
+	// ColumnSuperfluous column exists in the database but is not defined
+	// in the table.
+	ColumnSuperfluous
+)
+
+// ColumnDiff contains a column which differs between the table definition
+// and the database.
+type ColumnDiff struct {
+	Kind ColumnDiffKind
+	// Want the column as defined in the table. Empty for ColumnSuperfluous.
+	Want Column
+	// Have the column as found in the database. Empty for ColumnMissing.
+	Have Column
+	// After name of the preceding column of Want. Empty if Want is the
+	// first column.
+	After string
+}
+
+// ColumnDiffs a list of column differences as returned by Table.DiffColumns.
+type ColumnDiffs []ColumnDiff
+
+// String prints one line per difference, prefixed with + for a missing, ~ for
+// a changed and - for a superfluous column.
+func (cds ColumnDiffs) String() string {
+	var buf bytes.Buffer
+	for _, d := range cds {
+		switch d.Kind {
+		case ColumnMissing:
+			buf.WriteString("+ ")
+			writeColumnDefinition(&buf, d.Want)
+		case ColumnChanged:
+			buf.WriteString("~ ")
+			writeColumnDefinition(&buf, d.Have)
+			buf.WriteString(" => ")
+			writeColumnDefinition(&buf, d.Want)
+		case ColumnSuperfluous:
+			buf.WriteString("- ")
+			writeColumnDefinition(&buf, d.Have)
+		}
+		buf.WriteRune('\n')
+	}
+	return buf.String()
+}
+
+// DiffColumns compares the columns of the table with the current columns,
+// e.g. retrieved with GetColumns. Keys are not compared.
+func (ts *Table) DiffColumns(current Columns) ColumnDiffs {
+	var diffs ColumnDiffs
+	for i, c := range ts.Columns {
+		d := ColumnDiff{
+			Want: c,
+			Have: current.ByName(c.Field.String),
+		}
+		switch {
+		case !d.Have.Field.Valid:
+			d.Kind = ColumnMissing
+			if i > 0 {
+				d.After = ts.Columns[i-1].Field.String
+			}
+		case !columnDefinitionEqual(c, d.Have):
+			d.Kind = ColumnChanged
+		default:
+			continue
+		}
+		diffs = append(diffs, d)
+	}
+	for _, cur := range current {
+		if !ts.In(cur.Field.String) {
+			diffs = append(diffs, ColumnDiff{Kind: ColumnSuperfluous, Have: cur})
+		}
+	}
+	return diffs
+}
+
+// Diff loads the columns of the table from the database with GetColumns and
+// compares them with the table definition.
+func (ts *Table) Diff(dbrSess dbr.SessionRunner) (ColumnDiffs, error) {
+	if ts == nil {
+		return nil, ErrTableNotFound
+	}
+	current, err := GetColumns(dbrSess, ts.Name)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return ts.DiffColumns(current), nil
+}
+
+// DropSQL generates the DROP TABLE IF EXISTS statement.
+func (ts *Table) DropSQL() string {
+	return "DROP TABLE IF EXISTS " + dbr.Quoter.QuoteAs(ts.Name)
+}
+
+// columnDefinitionEqual compares all column properties except the key. The
+// comment and the collation get only compared if a defines them.
+func columnDefinitionEqual(a, b Column) bool {
+	return strings.EqualFold(a.Type.String, b.Type.String) &&
+		a.IsNull() == b.IsNull() &&
+		a.Default.Valid == b.Default.Valid && a.Default.String == b.Default.String &&
+		strings.EqualFold(a.Extra.String, b.Extra.String) &&
+		(!a.Comment.Valid || a.Comment.String == b.Comment.String) &&
+		(!a.Collation.Valid || strings.EqualFold(a.Collation.String, b.Collation.String))
+}
+
+// writeColumnDefinition writes e.g. `email` varchar(128) NULL DEFAULT NULL
+func writeColumnDefinition(buf *bytes.Buffer, c Column) {
+	writeIdent(buf, c.Field.String)
+	buf.WriteRune(' ')
+	buf.WriteString(c.Type.String)
+	if c.CharacterSet.Valid && c.CharacterSet.String != "" {
+		buf.WriteString(" CHARACTER SET " + c.CharacterSet.String)
+	}
+	if c.Collation.Valid && c.Collation.String != "" {
+		buf.WriteString(" COLLATE " + c.Collation.String)
+	}
+	if c.IsNull() {
+		buf.WriteString(" NULL")
+	} else {
+		buf.WriteString(" NOT NULL")
+	}
+	switch {
+	case c.Default.Valid:
+		buf.WriteString(" DEFAULT ")
+		writeDefault(buf, c.Default.String)
+	case c.IsNull():
+		buf.WriteString(" DEFAULT NULL")
+	}
+	if c.Extra.Valid && c.Extra.String != "" {
+		buf.WriteRune(' ')
+		buf.WriteString(c.Extra.String)
+	}
+	if c.Comment.Valid && c.Comment.String != "" {
+		buf.WriteString(" COMMENT ")
+		writeString(buf, c.Comment.String)
+	}
+}
+
+// writeDefault quotes a default value except the current timestamp function.
+func writeDefault(buf *bytes.Buffer, d string) {
+	if strings.HasPrefix(strings.ToUpper(d), "CURRENT_TIMESTAMP") {
+		buf.WriteString(d)
+		return
+	}
+	writeString(buf, d)
+}
+
+// writeString writes a quoted string literal. Quotes get doubled so the
+// statement survives dbr.Preprocess.
+func writeString(buf *bytes.Buffer, s string) {
+	buf.WriteRune('\'')
+	buf.WriteString(strings.NewReplacer(`'`, `''`, `\`, `\\`).Replace(s))
+	buf.WriteRune('\'')
+}
+
+func writeIdent(buf *bytes.Buffer, name string) {
+	buf.WriteRune('`')
+	buf.WriteString(strings.Replace(name, "`", "``", -1))
+	buf.WriteRune('`')
+}
+
+func writeIdentList(buf *bytes.Buffer, names []string) {
+	for i, n := range names {
+		if i > 0 {
+			buf.WriteRune(',')
+		}
+		writeIdent(buf, n)
+	}
+}
+
+// indexName creates a Magento style index name like UNQ_ADMIN_USER_USERNAME.
+func indexName(prefix, table, column string) string {
+	return strings.ToUpper(prefix + "_" + table + "_" + column)
+}
@@ -0,0 +1,173 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdb
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/juju/errgo"
+)
+
+// ConflictError gets returned by VersionedUpdate.Exec when the row has been
+// modified by someone else since it has been loaded or when it has been
+// deleted.
+type ConflictError struct {
+	Table    string
+	PKValues []interface{}
+	// Version the expected value of the version column
+	Version interface{}
+}
+
+// Error implements the error interface.
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("Table %q: Row %v has been modified or deleted. Expected version %v", e.Table, e.PKValues, e.Version)
+}
+
+// IsConflict checks if err or one of its underlying errors is a
+// ConflictError.
+func IsConflict(err error) bool {
+	for err != nil {
+		if _, ok := err.(*ConflictError); ok {
+			return true
+		}
+		u, ok := err.(interface {
+			Underlying() error
+		})
+		if !ok {
+			return false
+		}
+		err = u.Underlying()
+	}
+	return false
+}
+
+// VersionedUpdate is an UPDATE statement which only changes the row if its
+// version matches. Set the values with Set or SetMap and run it with Exec.
+type VersionedUpdate struct {
+	*dbr.UpdateBuilder
+	Table    string
+	PKValues []interface{}
+	Version  interface{}
+}
+
+// Set sets a column to a value.
+func (vu *VersionedUpdate) Set(column string, value interface{}) *VersionedUpdate {
+	vu.UpdateBuilder.Set(column, value)
+	return vu
+}
+
+// SetMap sets the columns to the values of the map.
+func (vu *VersionedUpdate) SetMap(clauses map[string]interface{}) *VersionedUpdate {
+	vu.UpdateBuilder.SetMap(clauses)
+	return vu
+}
+
+// Exec executes the UPDATE statement. Returns a *ConflictError if no row
+// has been changed.
+func (vu *VersionedUpdate) Exec() (sql.Result, error) {
+	res, err := vu.UpdateBuilder.Exec()
+	if err != nil {
+		return res, errgo.Mask(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return res, errgo.Mask(err)
+	}
+	if n == 0 {
+		return res, &ConflictError{Table: vu.Table, PKValues: vu.PKValues, Version: vu.Version}
+	}
+	return res, nil
+}
+
+// UpdateVersion generates an UPDATE statement for optimistic locking. The
+// row with the primary key values only gets changed if the VersionColumn
+// still contains the version which has been loaded with the row and if the
+// row has not been soft deleted. The
+// version gets incremented or, for date columns, set to NOW(). Returns
+// ErrTableNoVersionColumn if the table has no VersionColumn.
+//
+//	vu, err := table.UpdateVersion(dbrSess, entity.Version, entity.ID)
+//	...
+//	_, err = vu.Set("name", entity.Name).Exec()
+//	if csdb.IsConflict(err) {
+//		// reload the entity and show the changes
+//	}
+func (ts *Table) UpdateVersion(dbrSess dbr.SessionRunner, version interface{}, pkValues ...interface{}) (*VersionedUpdate, error) {
+	if ts == nil {
+		return nil, ErrTableNotFound
+	}
+	if ts.VersionColumn == "" {
+		return nil, ErrTableNoVersionColumn
+	}
+	ub, err := ts.updateRow(dbrSess, pkValues)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	ub.Where(dbr.ConditionMap(dbr.Eq{ts.VersionColumn: version}))
+	return &VersionedUpdate{
+		UpdateBuilder: ub,
+		Table:         ts.Name,
+		PKValues:      pkValues,
+		Version:       version,
+	}, nil
+}
+
+// SoftDelete generates an UPDATE statement which marks the row with the
+// primary key values as deleted. The SoftDeleteColumn gets set to NOW() or
+// to 1 for integer columns. Rows which are already soft deleted stay
+// untouched. The version of a versioned table gets changed but not checked.
+// Returns ErrTableNoSoftDelete if the table has no
+// SoftDeleteColumn.
+func (ts *Table) SoftDelete(dbrSess dbr.SessionRunner, pkValues ...interface{}) (*dbr.UpdateBuilder, error) {
+	if ts == nil {
+		return nil, ErrTableNotFound
+	}
+	if ts.SoftDeleteColumn == "" {
+		return nil, ErrTableNoSoftDelete
+	}
+	ub, err := ts.updateRow(dbrSess, pkValues)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	if ts.Columns.ByName(ts.SoftDeleteColumn).IsInt() {
+		return ub.Set(ts.SoftDeleteColumn, 1), nil
+	}
+	return ub.Set(ts.SoftDeleteColumn, dbr.Expr("NOW()")), nil
+}
+
+// setNextVersion increments the version column or sets date columns to the
+// current time.
+func (ts *Table) setNextVersion(ub *dbr.UpdateBuilder) {
+	if ts.Columns.ByName(ts.VersionColumn).IsDate() {
+		ub.Set(ts.VersionColumn, dbr.Expr("NOW()"))
+		return
+	}
+	ub.Set(ts.VersionColumn, dbr.Expr(dbr.Quoter.QuoteAs(ts.VersionColumn)+" + 1"))
+}
+
+// notDeletedCondition excludes the soft deleted rows. The column gets
+// prefixed with the table alias if not empty.
+func (ts *Table) notDeletedCondition(alias string) dbr.ConditionArg {
+	col := ts.SoftDeleteColumn
+	if alias != "" {
+		col = alias + "." + col
+	}
+	if ts.Columns.ByName(ts.SoftDeleteColumn).IsInt() {
+		return dbr.ConditionRaw(dbr.Quoter.QuoteAs(col) + " = 0")
+	}
+	return dbr.IsNull(col)
+}
@@ -0,0 +1,188 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdb_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/juju/errgo"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	tableLockVersion csdb.Index = iota
+	tableLockUpdatedAt
+)
+
+func newLockingTable(versionType, deletedType, deletedNull string) *csdb.Table {
+	return csdb.NewTable(
+		"customer_entity",
+		csdb.Column{
+			Field: dbr.NewNullString("entity_id"),
+			Type:  dbr.NewNullString("int(10) unsigned"),
+			Null:  dbr.NewNullString("NO"),
+			Key:   dbr.NewNullString("PRI"),
+			Extra: dbr.NewNullString("auto_increment"),
+		},
+		csdb.Column{
+			Field: dbr.NewNullString("email"),
+			Type:  dbr.NewNullString("varchar(255)"),
+			Null:  dbr.NewNullString("YES"),
+		},
+		csdb.Column{
+			Field: dbr.NewNullString("version"),
+			Type:  dbr.NewNullString(versionType),
+			Null:  dbr.NewNullString("NO"),
+		},
+		csdb.Column{
+			Field: dbr.NewNullString("deleted"),
+			Type:  dbr.NewNullString(deletedType),
+			Null:  dbr.NewNullString(deletedNull),
+		},
+	)
+}
+
+func TestTableManagerLockingOptions(t *testing.T) {
+	tm := csdb.NewTableManager(
+		csdb.AddTableByName(tableLockVersion, "customer_entity"),
+		csdb.WithVersionColumn(tableLockVersion, "version"),
+		csdb.WithSoftDeleteColumn(tableLockVersion, "deleted_at"),
+	)
+	ts, err := tm.Structure(tableLockVersion)
+	assert.NoError(t, err)
+	assert.Exactly(t, "version", ts.VersionColumn)
+	assert.Exactly(t, "deleted_at", ts.SoftDeleteColumn)
+
+	assert.Panics(t, func() {
+		csdb.NewTableManager(csdb.WithVersionColumn(tableLockUpdatedAt, "updated_at"))
+	})
+}
+
+func TestTableUpdateVersionSQL(t *testing.T) {
+	dbrSess := createFakeSession()
+
+	ts := newLockingTable("int(10) unsigned", "timestamp", "YES")
+	vu, err := ts.UpdateVersion(dbrSess, 4, 33)
+	assert.Nil(t, vu)
+	assert.EqualError(t, err, csdb.ErrTableNoVersionColumn.Error())
+
+	ts.VersionColumn = "version"
+	vu, err = ts.UpdateVersion(dbrSess, 4, 33)
+	assert.NoError(t, err)
+	sql, args, err := vu.Set("email", "a@b.c").ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "UPDATE `customer_entity` SET `version` = `version` + 1, `email` = ? WHERE (`entity_id` = ?) AND (`version` = ?)", sql)
+	assert.Exactly(t, []interface{}{"a@b.c", 33, 4}, args)
+
+	ub, err := ts.Update(dbrSess, 33)
+	assert.Nil(t, ub)
+	assert.EqualError(t, err, csdb.ErrTableVersioned.Error())
+
+	ts = newLockingTable("timestamp", "smallint(5) unsigned", "NO")
+	ts.VersionColumn = "version"
+	vu, err = ts.UpdateVersion(dbrSess, "2016-01-02 03:04:05", 33)
+	assert.NoError(t, err)
+	sql, _, err = vu.SetMap(map[string]interface{}{"email": "a@b.c"}).ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "UPDATE `customer_entity` SET `version` = NOW(), `email` = ? WHERE (`entity_id` = ?) AND (`version` = ?)", sql)
+}
+
+func TestTableUpdateVersionConflict(t *testing.T) {
+	dbrSess, dbMock := newMockSession(t)
+	ts := newLockingTable("int(10) unsigned", "timestamp", "YES")
+	ts.VersionColumn = "version"
+
+	dbMock.ExpectExec("UPDATE `customer_entity` SET `version` = `version` \\+ 1, `email` = 'a@b.c' WHERE \\(`entity_id` = 33\\) AND \\(`version` = 4\\)").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("UPDATE `customer_entity` SET `version` = `version` \\+ 1, `email` = 'a@b.c' WHERE \\(`entity_id` = 33\\) AND \\(`version` = 4\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	vu, err := ts.UpdateVersion(dbrSess, 4, 33)
+	assert.NoError(t, err)
+	_, err = vu.Set("email", "a@b.c").Exec()
+	assert.NoError(t, err)
+
+	vu, err = ts.UpdateVersion(dbrSess, 4, 33)
+	assert.NoError(t, err)
+	_, err = vu.Set("email", "a@b.c").Exec()
+	assert.True(t, csdb.IsConflict(err))
+	assert.True(t, csdb.IsConflict(errgo.Mask(err)))
+	assert.False(t, csdb.IsConflict(csdb.ErrTableNotFound))
+	assert.EqualError(t, err, `Table "customer_entity": Row [33] has been modified or deleted. Expected version 4`)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestTableSoftDelete(t *testing.T) {
+	dbrSess := createFakeSession()
+
+	ts := newLockingTable("int(10) unsigned", "timestamp", "YES")
+	ub, err := ts.SoftDelete(dbrSess, 33)
+	assert.Nil(t, ub)
+	assert.EqualError(t, err, csdb.ErrTableNoSoftDelete.Error())
+
+	ts.SoftDeleteColumn = "deleted"
+	sb, err := ts.Select(dbrSess)
+	assert.NoError(t, err)
+	sql, _, err := sb.ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "SELECT `main_table`.`entity_id`, `main_table`.`email`, `main_table`.`version`, `main_table`.`deleted` FROM `customer_entity` AS `main_table` WHERE (`main_table`.`deleted` IS NULL)", sql)
+
+	sb, err = ts.SelectWithDeleted(dbrSess)
+	assert.NoError(t, err)
+	sql, _, err = sb.ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "SELECT `main_table`.`entity_id`, `main_table`.`email`, `main_table`.`version`, `main_table`.`deleted` FROM `customer_entity` AS `main_table`", sql)
+
+	ub, err = ts.SoftDelete(dbrSess, 33)
+	assert.NoError(t, err)
+	sql, _, err = ub.ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "UPDATE `customer_entity` SET `deleted` = NOW() WHERE (`entity_id` = ?) AND (`deleted` IS NULL)", sql)
+
+	ts = newLockingTable("int(10) unsigned", "smallint(5) unsigned", "NO")
+	ts.SoftDeleteColumn = "deleted"
+	sb, err = ts.Select(dbrSess)
+	assert.NoError(t, err)
+	sql, _, err = sb.Where(dbr.ConditionMap(dbr.Eq{"email": "a@b.c"})).ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "SELECT `main_table`.`entity_id`, `main_table`.`email`, `main_table`.`version`, `main_table`.`deleted` FROM `customer_entity` AS `main_table` WHERE (`main_table`.`deleted` = 0) AND (`email` = ?)", sql)
+
+	ub, err = ts.SoftDelete(dbrSess, 33)
+	assert.NoError(t, err)
+	sql, args, err := ub.ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "UPDATE `customer_entity` SET `deleted` = ? WHERE (`entity_id` = ?) AND (`deleted` = 0)", sql)
+	assert.Exactly(t, []interface{}{1, 33}, args)
+}
+
+func TestTableUpdateVersionSoftDeleted(t *testing.T) {
+	dbrSess, dbMock := newMockSession(t)
+	ts := newLockingTable("int(10) unsigned", "timestamp", "YES")
+	ts.VersionColumn = "version"
+	ts.SoftDeleteColumn = "deleted"
+
+	// the row 33 with version 4 exists but has been soft deleted
+	dbMock.ExpectExec("UPDATE `customer_entity` SET `version` = `version` \\+ 1, `email` = 'a@b.c' WHERE \\(`entity_id` = 33\\) AND \\(`deleted` IS NULL\\) AND \\(`version` = 4\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	vu, err := ts.UpdateVersion(dbrSess, 4, 33)
+	assert.NoError(t, err)
+	_, err = vu.Set("email", "a@b.c").Exec()
+	assert.True(t, csdb.IsConflict(err), "Error: %s", err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -36,6 +36,13 @@ const (
 
 var (
 	ErrTableNotFound         = errors.New("Table not found")
+	ErrTableNoPrimaryKey     = errors.New("Table has no primary key")
+	ErrTableNoUniqueKey      = errors.New("Table has no unique key")
+	ErrTableUnchanged        = errors.New("Table columns are unchanged")
+	ErrTableNoColumns        = errors.New("Table has no columns")
+	ErrTableNoVersionColumn  = errors.New("Table has no version column")
+	ErrTableVersioned        = errors.New("Table has a version column, use UpdateVersion")
+	ErrTableNoSoftDelete     = errors.New("Table has no soft delete column")
 	ErrManagerIncorrectValue = errors.New("NewTableManager: Incorrect value for idx or name")
 	ErrManagerInitReload     = errors.New("You cannot force reload when the init process were not able to run.")
 )
@@ -71,6 +78,7 @@ type (
 	// TableManager implements interface Manager
 	TableManager struct {
 		initDone bool
+		metadata bool // Init loads the full metadata
 		errs     []error
 		mu       sync.RWMutex
 		ts       map[Index]*Table
@@ -93,6 +101,42 @@ func AddTableByName(idx Index, name string) ManagerOption {
 	}
 }
 
+// WithTableMetadata lets Init load the full metadata with indexes, foreign
+// keys and table options from information_schema instead of only the columns.
+func WithTableMetadata() ManagerOption {
+	return func(tm *TableManager) {
+		tm.metadata = true
+	}
+}
+
+// WithVersionColumn sets the column used for optimistic locking to the table
+// with the index. Must be applied after the table has been added. See
+// Table.VersionColumn.
+func WithVersionColumn(idx Index, column string) ManagerOption {
+	return func(tm *TableManager) {
+		ts, err := tm.Structure(idx)
+		if err != nil {
+			tm.appendErr(errgo.Notef(err, "Index %d", idx))
+			return
+		}
+		ts.VersionColumn = column
+	}
+}
+
+// WithSoftDeleteColumn sets the soft delete column to the table with the
+// index. Must be applied after the table has been added. See
+// Table.SoftDeleteColumn.
+func WithSoftDeleteColumn(idx Index, column string) ManagerOption {
+	return func(tm *TableManager) {
+		ts, err := tm.Structure(idx)
+		if err != nil {
+			tm.appendErr(errgo.Notef(err, "Index %d", idx))
+			return
+		}
+		ts.SoftDeleteColumn = column
+	}
+}
+
 // NewTableManager creates a new TableManager satisfying interface Manager.
 // Panics if an error occurs in an option function. Errors will be logged.
 // This function is only used in generated codes.
@@ -157,7 +201,8 @@ func (tm *TableManager) Append(i Index, ts *Table) error {
 	return nil
 }
 
-// Init loads the column definitions from the database for each table. Set reInit
+// Init loads the column definitions, or the full metadata when created with
+// WithTableMetadata, from the database for each table. Set reInit
 // to true to allow reloading otherwise it loads only once.
 func (tm *TableManager) Init(dbrSess dbr.SessionRunner, reInit ...bool) error {
 	reLoad := false
@@ -175,7 +220,11 @@ func (tm *TableManager) Init(dbrSess dbr.SessionRunner, reInit ...bool) error {
 	tm.initDone = true
 
 	for _, table := range tm.ts {
-		if err := table.LoadColumns(dbrSess); err != nil {
+		load := table.LoadColumns
+		if tm.metadata {
+			load = table.LoadMetadata
+		}
+		if err := load(dbrSess); err != nil {
 			if PkgLog.IsDebug() {
 				PkgLog.Debug("csdb.TableManager.Init.LoadColumns", "err", err, "table", table)
 			}
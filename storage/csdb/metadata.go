@@ -0,0 +1,270 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdb
+
+import (
+	"bytes"
+	"hash/fnv"
+	"strings"
+
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/juju/errgo"
+)
+
+// IndexPrimary name of the primary key index in information_schema.STATISTICS
+const IndexPrimary = "PRIMARY"
+
+// TableIndex describes an index of a table. The columns are in the order of
+// the index.
+type TableIndex struct {
+	Name   string
+	Unique bool
+	// Type BTREE, HASH or FULLTEXT
+	Type    string
+	Columns []string
+}
+
+// IsPrimary checks if the index is the primary key.
+func (ti TableIndex) IsPrimary() bool {
+	return ti.Name == IndexPrimary
+}
+
+// ForeignKey describes a foreign key constraint of a table.
+type ForeignKey struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	// OnUpdate and OnDelete contain the referential actions like CASCADE,
+	// SET NULL, RESTRICT or NO ACTION.
+	OnUpdate, OnDelete string
+}
+
+// GetFullColumns returns all columns of a table from
+// information_schema.COLUMNS including comment, character set and collation.
+func GetFullColumns(dbrSess dbr.SessionRunner, table string) (Columns, error) {
+	var cols = make(Columns, 0, 100)
+
+	sel := dbrSess.SelectBySql("SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY, COLUMN_DEFAULT, EXTRA, COLUMN_COMMENT, CHARACTER_SET_NAME, COLLATION_NAME "+
+		"FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION", table)
+
+	selSql, selArg, err := sel.ToSql()
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	rows, err := sel.Query(selSql, selArg...)
+	if err != nil {
+		if PkgLog.IsDebug() {
+			PkgLog.Debug("csdb.GetFullColumns.Query", "err", err, "query", selSql, "args", selArg)
+		}
+		return nil, errgo.Mask(err)
+	}
+	defer rows.Close()
+
+	col := Column{}
+	for rows.Next() {
+		err := rows.Scan(&col.Field, &col.Type, &col.Null, &col.Key, &col.Default, &col.Extra, &col.Comment, &col.CharacterSet, &col.Collation)
+		if err != nil {
+			return nil, errgo.Mask(err)
+		}
+		cols = append(cols, col)
+	}
+	return cols, errgo.Mask(rows.Err())
+}
+
+// GetIndexes returns all indexes of a table from
+// information_schema.STATISTICS ordered by the index name.
+func GetIndexes(dbrSess dbr.SessionRunner, table string) ([]TableIndex, error) {
+	sel := dbrSess.SelectBySql("SELECT INDEX_NAME, NON_UNIQUE, INDEX_TYPE, COLUMN_NAME "+
+		"FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY INDEX_NAME, SEQ_IN_INDEX", table)
+
+	selSql, selArg, err := sel.ToSql()
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	rows, err := sel.Query(selSql, selArg...)
+	if err != nil {
+		if PkgLog.IsDebug() {
+			PkgLog.Debug("csdb.GetIndexes.Query", "err", err, "query", selSql, "args", selArg)
+		}
+		return nil, errgo.Mask(err)
+	}
+	defer rows.Close()
+
+	var idxs []TableIndex
+	for rows.Next() {
+		var name, typ, column string
+		var nonUnique int
+		if err := rows.Scan(&name, &nonUnique, &typ, &column); err != nil {
+			return nil, errgo.Mask(err)
+		}
+		if l := len(idxs); l > 0 && idxs[l-1].Name == name {
+			idxs[l-1].Columns = append(idxs[l-1].Columns, column)
+			continue
+		}
+		idxs = append(idxs, TableIndex{
+			Name:    name,
+			Unique:  nonUnique == 0,
+			Type:    typ,
+			Columns: []string{column},
+		})
+	}
+	return idxs, errgo.Mask(rows.Err())
+}
+
+// GetForeignKeys returns all foreign keys of a table from
+// information_schema.KEY_COLUMN_USAGE and REFERENTIAL_CONSTRAINTS ordered by
+// the constraint name.
+func GetForeignKeys(dbrSess dbr.SessionRunner, table string) ([]ForeignKey, error) {
+	sel := dbrSess.SelectBySql("SELECT kcu.CONSTRAINT_NAME, kcu.COLUMN_NAME, kcu.REFERENCED_TABLE_NAME, kcu.REFERENCED_COLUMN_NAME, rc.UPDATE_RULE, rc.DELETE_RULE "+
+		"FROM information_schema.KEY_COLUMN_USAGE kcu "+
+		"INNER JOIN information_schema.REFERENTIAL_CONSTRAINTS rc ON rc.CONSTRAINT_SCHEMA = kcu.CONSTRAINT_SCHEMA AND rc.CONSTRAINT_NAME = kcu.CONSTRAINT_NAME "+
+		"WHERE kcu.TABLE_SCHEMA = DATABASE() AND kcu.TABLE_NAME = ? AND kcu.REFERENCED_TABLE_NAME IS NOT NULL "+
+		"ORDER BY kcu.CONSTRAINT_NAME, kcu.ORDINAL_POSITION", table)
+
+	selSql, selArg, err := sel.ToSql()
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+
+	rows, err := sel.Query(selSql, selArg...)
+	if err != nil {
+		if PkgLog.IsDebug() {
+			PkgLog.Debug("csdb.GetForeignKeys.Query", "err", err, "query", selSql, "args", selArg)
+		}
+		return nil, errgo.Mask(err)
+	}
+	defer rows.Close()
+
+	var fks []ForeignKey
+	for rows.Next() {
+		var name, column, refTable, refColumn, onUpdate, onDelete string
+		if err := rows.Scan(&name, &column, &refTable, &refColumn, &onUpdate, &onDelete); err != nil {
+			return nil, errgo.Mask(err)
+		}
+		if l := len(fks); l > 0 && fks[l-1].Name == name {
+			fks[l-1].Columns = append(fks[l-1].Columns, column)
+			fks[l-1].RefColumns = append(fks[l-1].RefColumns, refColumn)
+			continue
+		}
+		fks = append(fks, ForeignKey{
+			Name:       name,
+			Columns:    []string{column},
+			RefTable:   refTable,
+			RefColumns: []string{refColumn},
+			OnUpdate:   onUpdate,
+			OnDelete:   onDelete,
+		})
+	}
+	return fks, errgo.Mask(rows.Err())
+}
+
+// LoadMetadata reads the columns, indexes, foreign keys and table options
+// from information_schema. LoadColumns only reads the columns.
+func (ts *Table) LoadMetadata(dbrSess dbr.SessionRunner) error {
+	if ts == nil {
+		return ErrTableNotFound
+	}
+	err := ts.loadTableOptions(dbrSess)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	if ts.Columns, err = GetFullColumns(dbrSess, ts.Name); err != nil {
+		return errgo.Mask(err)
+	}
+	if ts.Indexes, err = GetIndexes(dbrSess, ts.Name); err != nil {
+		return errgo.Mask(err)
+	}
+	if ts.ForeignKeys, err = GetForeignKeys(dbrSess, ts.Name); err != nil {
+		return errgo.Mask(err)
+	}
+	ts.update()
+	return nil
+}
+
+// loadTableOptions reads engine, collation, character set and comment from
+// information_schema.TABLES.
+func (ts *Table) loadTableOptions(dbrSess dbr.SessionRunner) error {
+	sel := dbrSess.SelectBySql("SELECT t.ENGINE, t.TABLE_COLLATION, ccsa.CHARACTER_SET_NAME, t.TABLE_COMMENT "+
+		"FROM information_schema.TABLES t "+
+		"LEFT JOIN information_schema.COLLATION_CHARACTER_SET_APPLICABILITY ccsa ON ccsa.COLLATION_NAME = t.TABLE_COLLATION "+
+		"WHERE t.TABLE_SCHEMA = DATABASE() AND t.TABLE_NAME = ?", ts.Name)
+	selSql, selArg, err := sel.ToSql()
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	rows, err := sel.Query(selSql, selArg...)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return errgo.Mask(err)
+		}
+		return errgo.Newf("Table %q not found in information_schema", ts.Name)
+	}
+	var engine, collation, charset, comment dbr.NullString
+	if err := rows.Scan(&engine, &collation, &charset, &comment); err != nil {
+		return errgo.Mask(err)
+	}
+	ts.Engine, ts.Collation, ts.CharacterSet, ts.Comment = engine.String, collation.String, charset.String, comment.String
+	return nil
+}
+
+// Hash calculates a fnv64 hash value from the columns, indexes, foreign keys
+// and table options. Use it to detect drift between a definition and the
+// database.
+func (ts *Table) Hash() ([]byte, error) {
+	ch, err := ts.Columns.Hash()
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	var buf bytes.Buffer
+	buf.Write(ch)
+	buf.WriteString(ts.Engine)
+	buf.WriteByte(0)
+	buf.WriteString(ts.CharacterSet)
+	buf.WriteByte(0)
+	buf.WriteString(ts.Collation)
+	buf.WriteByte(0)
+	buf.WriteString(ts.Comment)
+	buf.WriteByte(0)
+	for _, i := range ts.Indexes {
+		buf.WriteString(i.Name)
+		if i.Unique {
+			buf.WriteByte('u')
+		}
+		buf.WriteString(i.Type)
+		buf.WriteString(strings.Join(i.Columns, ","))
+		buf.WriteByte(0)
+	}
+	for _, fk := range ts.ForeignKeys {
+		buf.WriteString(fk.Name)
+		buf.WriteString(strings.Join(fk.Columns, ","))
+		buf.WriteString(fk.RefTable)
+		buf.WriteString(strings.Join(fk.RefColumns, ","))
+		buf.WriteString(fk.OnUpdate)
+		buf.WriteString(fk.OnDelete)
+		buf.WriteByte(0)
+	}
+	f64 := fnv.New64()
+	if _, err := f64.Write(buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return f64.Sum(nil), nil
+}
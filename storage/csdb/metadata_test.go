@@ -0,0 +1,108 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdb_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/stretchr/testify/assert"
+)
+
+func expectAdminUserMetadata(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT t.ENGINE, t.TABLE_COLLATION, ccsa.CHARACTER_SET_NAME, t.TABLE_COMMENT FROM information_schema.TABLES").
+		WithArgs("admin_user").
+		WillReturnRows(sqlmock.NewRows([]string{"ENGINE", "TABLE_COLLATION", "CHARACTER_SET_NAME", "TABLE_COMMENT"}).
+			AddRow("InnoDB", "utf8_general_ci", "utf8", "Admin User Table"))
+	mock.ExpectQuery("SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY, COLUMN_DEFAULT, EXTRA, COLUMN_COMMENT, CHARACTER_SET_NAME, COLLATION_NAME FROM information_schema.COLUMNS").
+		WithArgs("admin_user").
+		WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_KEY", "COLUMN_DEFAULT", "EXTRA", "COLUMN_COMMENT", "CHARACTER_SET_NAME", "COLLATION_NAME"}).
+			AddRow("user_id", "int(10) unsigned", "NO", "PRI", nil, "auto_increment", "User ID", nil, nil).
+			AddRow("store_id", "smallint(5) unsigned", "NO", "MUL", "0", "", "", nil, nil).
+			AddRow("username", "varchar(40)", "YES", "MUL", nil, "", "", "utf8", "utf8_general_ci"))
+	mock.ExpectQuery("SELECT INDEX_NAME, NON_UNIQUE, INDEX_TYPE, COLUMN_NAME FROM information_schema.STATISTICS").
+		WithArgs("admin_user").
+		WillReturnRows(sqlmock.NewRows([]string{"INDEX_NAME", "NON_UNIQUE", "INDEX_TYPE", "COLUMN_NAME"}).
+			AddRow("ADMIN_USER_STORE_ID_USERNAME", 0, "BTREE", "store_id").
+			AddRow("ADMIN_USER_STORE_ID_USERNAME", 0, "BTREE", "username").
+			AddRow("PRIMARY", 0, "BTREE", "user_id"))
+	mock.ExpectQuery("SELECT kcu.CONSTRAINT_NAME, kcu.COLUMN_NAME, kcu.REFERENCED_TABLE_NAME, kcu.REFERENCED_COLUMN_NAME, rc.UPDATE_RULE, rc.DELETE_RULE FROM information_schema.KEY_COLUMN_USAGE").
+		WithArgs("admin_user").
+		WillReturnRows(sqlmock.NewRows([]string{"CONSTRAINT_NAME", "COLUMN_NAME", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME", "UPDATE_RULE", "DELETE_RULE"}).
+			AddRow("ADMIN_USER_STORE_ID_STORE_STORE_ID", "store_id", "store", "store_id", "NO ACTION", "CASCADE"))
+}
+
+func TestTableLoadMetadataSqlMock(t *testing.T) {
+	sess, mock := newMockSession(t)
+	expectAdminUserMetadata(mock)
+
+	ts := csdb.NewTable("admin_user")
+	assert.NoError(t, ts.LoadMetadata(sess))
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+
+	assert.Exactly(t, "InnoDB", ts.Engine)
+	assert.Exactly(t, "utf8", ts.CharacterSet)
+	assert.Exactly(t, "utf8_general_ci", ts.Collation)
+	assert.Exactly(t, "Admin User Table", ts.Comment)
+	assert.Exactly(t, 1, ts.CountPK)
+	assert.Exactly(t, []csdb.TableIndex{
+		{Name: "ADMIN_USER_STORE_ID_USERNAME", Unique: true, Type: "BTREE", Columns: []string{"store_id", "username"}},
+		{Name: "PRIMARY", Unique: true, Type: "BTREE", Columns: []string{"user_id"}},
+	}, ts.Indexes)
+	assert.Exactly(t, []csdb.ForeignKey{
+		{Name: "ADMIN_USER_STORE_ID_STORE_STORE_ID", Columns: []string{"store_id"}, RefTable: "store", RefColumns: []string{"store_id"}, OnUpdate: "NO ACTION", OnDelete: "CASCADE"},
+	}, ts.ForeignKeys)
+
+	assert.Exactly(t, "csdb.Column{Field:dbr.NewNullString(`username`), Type:dbr.NewNullString(`varchar(40)`), Null:dbr.NewNullString(`YES`), Key:dbr.NewNullString(`MUL`), Default:dbr.NewNullString(nil), Extra:dbr.NewNullString(``), Comment:dbr.NewNullString(``), CharacterSet:dbr.NewNullString(`utf8`), Collation:dbr.NewNullString(`utf8_general_ci`)}",
+		ts.Columns.ByName("username").GoString())
+
+	ddl, err := ts.CreateSQL()
+	assert.NoError(t, err)
+	assert.Exactly(t, "CREATE TABLE `admin_user` (\n"+
+		"  `user_id` int(10) unsigned NOT NULL auto_increment COMMENT 'User ID',\n"+
+		"  `store_id` smallint(5) unsigned NOT NULL DEFAULT '0',\n"+
+		"  `username` varchar(40) CHARACTER SET utf8 COLLATE utf8_general_ci NULL DEFAULT NULL,\n"+
+		"  UNIQUE KEY `ADMIN_USER_STORE_ID_USERNAME` (`store_id`,`username`),\n"+
+		"  PRIMARY KEY (`user_id`),\n"+
+		"  CONSTRAINT `ADMIN_USER_STORE_ID_STORE_STORE_ID` FOREIGN KEY (`store_id`) REFERENCES `store` (`store_id`) ON DELETE CASCADE ON UPDATE NO ACTION\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_general_ci COMMENT='Admin User Table'", ddl)
+}
+
+func TestTableHashDrift(t *testing.T) {
+	sess, mock := newMockSession(t)
+	expectAdminUserMetadata(mock)
+	ts := csdb.NewTable("admin_user")
+	assert.NoError(t, ts.LoadMetadata(sess))
+
+	h1, err := ts.Hash()
+	assert.NoError(t, err)
+
+	ts.Indexes[0].Columns = []string{"username", "store_id"}
+	h2, err := ts.Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, h1, h2, "Index column order must change the hash")
+
+	cols := ts.Columns.Map(func(c csdb.Column) csdb.Column { return c })
+	ch1, err := cols.Hash()
+	assert.NoError(t, err)
+	cols[2].Collation = dbr.NewNullString("utf8_unicode_ci")
+	ch2, err := cols.Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, ch1, ch2, "Collation must change the columns hash")
+}
@@ -0,0 +1,321 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdb
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/corestoreio/csfw/net/ctxhttp"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/juju/errgo"
+	"golang.org/x/net/context"
+)
+
+// DefaultLatencyBuckets upper bounds in seconds of the latency histograms
+// when creating new Metrics.
+var DefaultLatencyBuckets = []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// MetricsContentType the content type of the Prometheus text format.
+const MetricsContentType = "text/plain; version=0.0.4"
+
+// Metrics aggregates the query counts, latencies and errors of the dbr
+// builders per builder kind and table and the prepare and close counts of
+// ResurrectStmt. Metrics implements dbr.EventReceiver, StmtRecorder and
+// ctxhttp.Handler to expose the collected data in the Prometheus text format.
+// Metrics is safe for concurrent use.
+//
+//	m := csdb.NewMetrics()
+//	dbc, err := dbr.NewConnection(dbr.SetDB(db), dbr.SetEventReceiver(m))
+//	rs := csdb.NewResurrectStmt(dbc.DB, "SELECT ...")
+//	rs.Recorder = m
+//	http.Handle("/metrics", ctxhttp.NewAdapter(context.Background(), m))
+type Metrics struct {
+	// Buckets upper bounds in seconds, sorted ascending. Must not be changed
+	// once the first timing has been recorded.
+	Buckets []float64
+
+	mu         sync.Mutex // protects all following fields
+	latencies  map[metricKey]*histogram
+	errors     map[metricKey]uint64
+	events     map[string]uint64
+	resurrects map[string]uint64
+	closes     map[string]uint64
+}
+
+// metricKey kind is the event name without the dbr. prefix, e.g. select,
+// update or stmt_cache.hit.
+type metricKey struct {
+	kind  string
+	table string
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+var (
+	_ dbr.EventReceiver = (*Metrics)(nil)
+	_ StmtRecorder      = (*Metrics)(nil)
+	_ ctxhttp.Handler   = (*Metrics)(nil)
+)
+
+// NewMetrics creates a new metrics collector with the DefaultLatencyBuckets.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		Buckets:    DefaultLatencyBuckets,
+		latencies:  make(map[metricKey]*histogram),
+		errors:     make(map[metricKey]uint64),
+		events:     make(map[string]uint64),
+		resurrects: make(map[string]uint64),
+		closes:     make(map[string]uint64),
+	}
+}
+
+// Event counts the event. Implements dbr.EventReceiver.
+func (m *Metrics) Event(eventName string) {
+	m.EventKv(eventName, nil)
+}
+
+// EventKv counts the event. Implements dbr.EventReceiver.
+func (m *Metrics) EventKv(eventName string, kvs map[string]string) {
+	m.mu.Lock()
+	m.events[eventName]++
+	m.mu.Unlock()
+}
+
+// EventErr counts the error. Implements dbr.EventReceiver.
+func (m *Metrics) EventErr(eventName string, err error) error {
+	return m.EventErrKv(eventName, err, nil)
+}
+
+// EventErrKv counts the error per builder kind and table. The kind is the
+// first part of the event name after the dbr. prefix. A nil error does not
+// get counted. Implements dbr.EventReceiver.
+func (m *Metrics) EventErrKv(eventName string, err error, kvs map[string]string) error {
+	if err == nil {
+		return nil
+	}
+	kind := metricKind(eventName)
+	if i := strings.IndexByte(kind, '.'); i > 0 {
+		kind = kind[:i]
+	}
+	m.mu.Lock()
+	m.errors[metricKey{kind: kind, table: kvs["table"]}]++
+	m.mu.Unlock()
+	return err
+}
+
+// Timing records the duration. Implements dbr.EventReceiver.
+func (m *Metrics) Timing(eventName string, nanoseconds int64) {
+	m.TimingKv(eventName, nanoseconds, nil)
+}
+
+// TimingKv records the duration in the latency histogram per builder kind and
+// table. Implements dbr.EventReceiver.
+func (m *Metrics) TimingKv(eventName string, nanoseconds int64, kvs map[string]string) {
+	key := metricKey{kind: metricKind(eventName), table: kvs["table"]}
+	secs := float64(nanoseconds) / 1e9
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.latencies[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.Buckets))}
+		m.latencies[key] = h
+	}
+	for i, ub := range m.Buckets {
+		if secs <= ub {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += secs
+}
+
+// StmtPrepare counts a prepared or resurrected statement. Implements
+// StmtRecorder.
+func (m *Metrics) StmtPrepare(sql string) {
+	m.mu.Lock()
+	m.resurrects[sql]++
+	m.mu.Unlock()
+}
+
+// StmtClose counts a closed statement. Implements StmtRecorder.
+func (m *Metrics) StmtClose(sql string) {
+	m.mu.Lock()
+	m.closes[sql]++
+	m.mu.Unlock()
+}
+
+// ServeHTTPContext writes all metrics in the Prometheus text format.
+// Implements ctxhttp.Handler.
+func (m *Metrics) ServeHTTPContext(_ context.Context, w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", MetricsContentType)
+	return m.WritePrometheus(w)
+}
+
+// WritePrometheus writes all metrics sorted in the Prometheus text format
+// version 0.0.4 to w.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	m.mu.Lock()
+	m.writeLatencies(bw)
+	m.writeErrors(bw)
+	writeCounters(bw, "csdb_events_total", "Number of dbr events.", "event", m.events)
+	writeCounters(bw, "csdb_stmt_resurrect_total", "Number of prepared or resurrected statements of a ResurrectStmt.", "sql", m.resurrects)
+	writeCounters(bw, "csdb_stmt_close_total", "Number of closed statements of a ResurrectStmt.", "sql", m.closes)
+	m.mu.Unlock()
+
+	return errgo.Mask(bw.Flush())
+}
+
+func (m *Metrics) writeLatencies(w *bufio.Writer) {
+	if len(m.latencies) == 0 {
+		return
+	}
+	keys := make([]metricKey, 0, len(m.latencies))
+	for k := range m.latencies {
+		keys = append(keys, k)
+	}
+	sortMetricKeys(keys)
+
+	w.WriteString("# HELP csdb_query_duration_seconds Latency of the dbr queries.\n")
+	w.WriteString("# TYPE csdb_query_duration_seconds histogram\n")
+	for _, k := range keys {
+		h := m.latencies[k]
+		var cum uint64
+		for i, ub := range m.Buckets {
+			cum += h.counts[i]
+			writeSample(w, "csdb_query_duration_seconds_bucket", k, formatFloat(ub), cum)
+		}
+		writeSample(w, "csdb_query_duration_seconds_bucket", k, "+Inf", h.count)
+
+		w.WriteString("csdb_query_duration_seconds_sum")
+		writeKeyLabels(w, k, "")
+		w.WriteByte(' ')
+		w.WriteString(formatFloat(h.sum))
+		w.WriteByte('\n')
+
+		writeSample(w, "csdb_query_duration_seconds_count", k, "", h.count)
+	}
+}
+
+func (m *Metrics) writeErrors(w *bufio.Writer) {
+	if len(m.errors) == 0 {
+		return
+	}
+	keys := make([]metricKey, 0, len(m.errors))
+	for k := range m.errors {
+		keys = append(keys, k)
+	}
+	sortMetricKeys(keys)
+
+	w.WriteString("# HELP csdb_query_errors_total Number of dbr errors.\n")
+	w.WriteString("# TYPE csdb_query_errors_total counter\n")
+	for _, k := range keys {
+		writeSample(w, "csdb_query_errors_total", k, "", m.errors[k])
+	}
+}
+
+func writeCounters(w *bufio.Writer, name, help, label string, counters map[string]uint64) {
+	if len(counters) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(counters))
+	for k := range counters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	w.WriteString("# HELP " + name + " " + help + "\n")
+	w.WriteString("# TYPE " + name + " counter\n")
+	for _, k := range keys {
+		w.WriteString(name + "{" + label + "=\"")
+		writeLabelValue(w, k)
+		w.WriteString("\"} ")
+		w.WriteString(strconv.FormatUint(counters[k], 10))
+		w.WriteByte('\n')
+	}
+}
+
+func writeSample(w *bufio.Writer, name string, k metricKey, le string, v uint64) {
+	w.WriteString(name)
+	writeKeyLabels(w, k, le)
+	w.WriteByte(' ')
+	w.WriteString(strconv.FormatUint(v, 10))
+	w.WriteByte('\n')
+}
+
+func writeKeyLabels(w *bufio.Writer, k metricKey, le string) {
+	w.WriteString(`{kind="`)
+	writeLabelValue(w, k.kind)
+	w.WriteString(`",table="`)
+	writeLabelValue(w, k.table)
+	w.WriteByte('"')
+	if le != "" {
+		w.WriteString(`,le="` + le + `"`)
+	}
+	w.WriteByte('}')
+}
+
+// writeLabelValue escapes backslash, double quote and line feed.
+func writeLabelValue(w *bufio.Writer, v string) {
+	for _, r := range v {
+		switch r {
+		case '\\':
+			w.WriteString(`\\`)
+		case '"':
+			w.WriteString(`\"`)
+		case '\n':
+			w.WriteString(`\n`)
+		default:
+			w.WriteRune(r)
+		}
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortMetricKeys(keys []metricKey) {
+	sort.Sort(metricKeys(keys))
+}
+
+type metricKeys []metricKey
+
+func (mk metricKeys) Len() int      { return len(mk) }
+func (mk metricKeys) Swap(i, j int) { mk[i], mk[j] = mk[j], mk[i] }
+func (mk metricKeys) Less(i, j int) bool {
+	if mk[i].kind != mk[j].kind {
+		return mk[i].kind < mk[j].kind
+	}
+	return mk[i].table < mk[j].table
+}
+
+// metricKind removes the dbr. prefix from an event name.
+func metricKind(eventName string) string {
+	return strings.TrimPrefix(eventName, "dbr.")
+}
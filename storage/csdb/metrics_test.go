@@ -0,0 +1,143 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdb_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/csfw/net/ctxhttp"
+	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestMetricsWritePrometheus(t *testing.T) {
+	m := csdb.NewMetrics()
+	m.Buckets = []float64{0.001, 0.1}
+
+	m.TimingKv("dbr.select", int64(time.Microsecond*500), map[string]string{"table": "core_store"})
+	m.TimingKv("dbr.select", int64(time.Millisecond*50), map[string]string{"table": "core_store"})
+	m.TimingKv("dbr.select", int64(time.Second), map[string]string{"table": "core_store"})
+	m.TimingKv("dbr.update", int64(time.Millisecond*2), map[string]string{"table": "core_website"})
+	m.Event("dbr.begin")
+	m.Event("dbr.begin")
+	m.EventKv("dbr.commit", nil)
+	err := errors.New("Ups")
+	assert.Exactly(t, err, m.EventErrKv("dbr.select.load_one.query", err, map[string]string{"table": `a"b`}))
+	m.StmtPrepare("SELECT 1")
+	m.StmtPrepare("SELECT 1")
+	m.StmtClose("SELECT 1")
+
+	var buf bytes.Buffer
+	assert.NoError(t, m.WritePrometheus(&buf))
+	assert.Exactly(t, `# HELP csdb_query_duration_seconds Latency of the dbr queries.
+# TYPE csdb_query_duration_seconds histogram
+csdb_query_duration_seconds_bucket{kind="select",table="core_store",le="0.001"} 1
+csdb_query_duration_seconds_bucket{kind="select",table="core_store",le="0.1"} 2
+csdb_query_duration_seconds_bucket{kind="select",table="core_store",le="+Inf"} 3
+csdb_query_duration_seconds_sum{kind="select",table="core_store"} 1.0505
+csdb_query_duration_seconds_count{kind="select",table="core_store"} 3
+csdb_query_duration_seconds_bucket{kind="update",table="core_website",le="0.001"} 0
+csdb_query_duration_seconds_bucket{kind="update",table="core_website",le="0.1"} 1
+csdb_query_duration_seconds_bucket{kind="update",table="core_website",le="+Inf"} 1
+csdb_query_duration_seconds_sum{kind="update",table="core_website"} 0.002
+csdb_query_duration_seconds_count{kind="update",table="core_website"} 1
+# HELP csdb_query_errors_total Number of dbr errors.
+# TYPE csdb_query_errors_total counter
+csdb_query_errors_total{kind="select",table="a\"b"} 1
+# HELP csdb_events_total Number of dbr events.
+# TYPE csdb_events_total counter
+csdb_events_total{event="dbr.begin"} 2
+csdb_events_total{event="dbr.commit"} 1
+# HELP csdb_stmt_resurrect_total Number of prepared or resurrected statements of a ResurrectStmt.
+# TYPE csdb_stmt_resurrect_total counter
+csdb_stmt_resurrect_total{sql="SELECT 1"} 2
+# HELP csdb_stmt_close_total Number of closed statements of a ResurrectStmt.
+# TYPE csdb_stmt_close_total counter
+csdb_stmt_close_total{sql="SELECT 1"} 1
+`, buf.String())
+}
+
+func TestMetricsDbrAndResurrectStmt(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	m := csdb.NewMetrics()
+	dbc, err := dbr.NewConnection(dbr.SetDB(db), dbr.SetEventReceiver(m))
+	assert.NoError(t, err)
+	sess := dbc.NewSession()
+
+	dbMock.ExpectExec("UPDATE `core_store` SET `code` = 'de' WHERE \\(`store_id` = 1\\)").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = sess.Update("core_store").Set("code", "de").Where(dbr.ConditionMap(dbr.Eq{"store_id": 1})).Exec()
+	assert.NoError(t, err)
+
+	dbMock.ExpectExec("DELETE FROM `core_store`").WillReturnError(errors.New("Ups"))
+	_, err = sess.DeleteFrom("core_store").Exec()
+	assert.Error(t, err)
+
+	rs := csdb.NewResurrectStmt(db, "SELECT 1")
+	rs.Recorder = m
+	dbMock.ExpectPrepare("SELECT 1").WillBeClosed()
+	_, err = rs.Stmt()
+	assert.NoError(t, err)
+	assert.NoError(t, rs.StopIdleChecker())
+	assert.NoError(t, rs.StopIdleChecker(), "Second close must not be counted")
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://corestore.io/metrics", nil)
+	assert.NoError(t, err)
+	ctxhttp.NewAdapter(context.Background(), m).ServeHTTP(rec, req)
+
+	assert.Exactly(t, csdb.MetricsContentType, rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, `csdb_query_duration_seconds_count{kind="update",table="core_store"} 1`)
+	assert.Contains(t, body, `csdb_query_duration_seconds_count{kind="delete",table="core_store"} 1`)
+	assert.Contains(t, body, `csdb_query_errors_total{kind="delete",table="core_store"} 1`)
+	assert.Contains(t, body, `csdb_stmt_resurrect_total{sql="SELECT 1"} 1`)
+	assert.Contains(t, body, `csdb_stmt_close_total{sql="SELECT 1"} 1`)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestMetricsEventErrNil(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectClose()
+
+	m := csdb.NewMetrics()
+	assert.NoError(t, m.EventErr("dbr.select", nil))
+
+	cxn, err := dbr.NewConnection(dbr.SetDB(db), dbr.SetEventReceiver(m))
+	assert.NoError(t, err)
+	assert.NoError(t, cxn.Close())
+
+	var buf bytes.Buffer
+	assert.NoError(t, m.WritePrometheus(&buf))
+	assert.NotContains(t, buf.String(), "csdb_query_errors_total{", "A clean close must not be counted as an error")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
@@ -0,0 +1,400 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/corestoreio/csfw/util/sqlbeautifier"
+	"github.com/juju/errgo"
+)
+
+const (
+	// DefaultMigrationTable name of the table which stores the applied
+	// schema versions.
+	DefaultMigrationTable = "csdb_schema_version"
+	// DefaultMigrationLock name of the MySQL user lock which guarantees that
+	// only one node migrates at a time.
+	DefaultMigrationLock = "csdb_schema_migration"
+	// DefaultMigrationLockTimeout time to wait for the lock.
+	DefaultMigrationLockTimeout = time.Second * 30
+)
+
+// mysqlErrNoSuchTable MySQL error number of ER_NO_SUCH_TABLE.
+const mysqlErrNoSuchTable uint16 = 1146
+
+var (
+	ErrMigrationLocked  = errors.New("Migration lock is held by another process")
+	ErrMigrationVersion = errors.New("Migration version must be greater than zero and unique")
+	ErrMigrationSteps   = errors.New("Migration steps cannot be negative")
+)
+
+// Migration defines one version of the schema. Up contains the statements
+// to migrate from the previous version to this version and Down the
+// statements to revert them. Table.CreateSQL, Table.AlterSQL and
+// Table.DropSQL can generate the statements. Each statement gets executed
+// with dbr.Session.UpdateBySql.
+type Migration struct {
+	Version     uint64
+	Description string
+	Up          []string
+	Down        []string
+}
+
+// MigratorOption can be used as an argument in NewMigrator to configure a
+// migrator.
+type MigratorOption func(*Migrator)
+
+// Migrator applies migrations in the order of their versions. The current
+// version gets stored in a schema version table. Before migrating the
+// migrator acquires a MySQL user lock with GET_LOCK() so that only one node
+// of a cluster migrates the schema while the others wait or fail with
+// ErrMigrationLocked.
+type Migrator struct {
+	// TableName of the schema version table, defaults to
+	// DefaultMigrationTable.
+	TableName string
+	// LockName of the MySQL user lock, defaults to DefaultMigrationLock.
+	LockName string
+	// LockTimeout defaults to DefaultMigrationLockTimeout.
+	LockTimeout time.Duration
+	// DryRun if not nil, the SQL statements get written to DryRun instead of
+	// being executed.
+	DryRun io.Writer
+
+	migrations []Migration // sorted by version
+}
+
+// SetMigrationTable sets the name of the schema version table.
+func SetMigrationTable(name string) MigratorOption {
+	return func(m *Migrator) {
+		m.TableName = name
+	}
+}
+
+// SetMigrationLock sets the name of the lock and the time to wait for it.
+func SetMigrationLock(name string, timeout time.Duration) MigratorOption {
+	return func(m *Migrator) {
+		m.LockName = name
+		m.LockTimeout = timeout
+	}
+}
+
+// SetMigrationDryRun enables the dry-run mode. All statements get beautified
+// and written to w.
+func SetMigrationDryRun(w io.Writer) MigratorOption {
+	return func(m *Migrator) {
+		m.DryRun = w
+	}
+}
+
+// SetMigrations registers migrations. Panics on an invalid version.
+func SetMigrations(ms ...Migration) MigratorOption {
+	return func(m *Migrator) {
+		if err := m.Register(ms...); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// NewMigrator creates a new migrator with the default table and lock names.
+func NewMigrator(opts ...MigratorOption) *Migrator {
+	m := &Migrator{
+		TableName:   DefaultMigrationTable,
+		LockName:    DefaultMigrationLock,
+		LockTimeout: DefaultMigrationLockTimeout,
+	}
+	for _, o := range opts {
+		if o != nil {
+			o(m)
+		}
+	}
+	return m
+}
+
+// Register adds migrations. Versions must be greater than zero and unique.
+func (m *Migrator) Register(ms ...Migration) error {
+	all := append(append([]Migration(nil), m.migrations...), ms...)
+	sort.Sort(migrationsByVersion(all))
+	for i, mig := range all {
+		if mig.Version == 0 || (i > 0 && all[i-1].Version == mig.Version) {
+			return ErrMigrationVersion
+		}
+	}
+	m.migrations = all
+	return nil
+}
+
+// Latest returns the highest registered version or zero.
+func (m *Migrator) Latest() uint64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the current version of the schema from the schema version
+// table. Zero means that no migration has been applied or that the schema
+// version table does not yet exist.
+func (m *Migrator) Version(dbrSess dbr.SessionRunner) (uint64, error) {
+	v, err := dbrSess.
+		SelectBySql("SELECT IFNULL(MAX(`version`),0) FROM " + dbr.Quoter.QuoteAs(m.TableName)).
+		ReturnUint64()
+	if dbr.IsMySQLError(err, mysqlErrNoSuchTable) {
+		return 0, nil
+	}
+	return v, errgo.Mask(err)
+}
+
+// Up migrates the schema to the latest version.
+func (m *Migrator) Up(sess *dbr.Session) error {
+	return m.Migrate(sess, m.Latest())
+}
+
+// Down reverts the latest n applied migrations. Does nothing if no
+// migration has been applied. Returns ErrMigrationSteps if n is negative.
+func (m *Migrator) Down(sess *dbr.Session, n int) error {
+	if n < 0 {
+		return ErrMigrationSteps
+	}
+	current, err := m.Version(sess)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	if current == 0 {
+		return nil
+	}
+	i := m.index(current)
+	if i < 0 {
+		return errgo.Newf("Migration version %d is not registered", current)
+	}
+	var target uint64
+	if i-n >= 0 {
+		target = m.migrations[i-n].Version
+	}
+	return m.Migrate(sess, target)
+}
+
+// Migrate migrates the schema up or down to the target version. Zero reverts
+// all migrations. The statements of each migration and its version row get
+// committed in their own transaction while holding the lock, so a failed
+// migration leaves the schema version at the last successfully applied
+// migration. MySQL commits DDL statements implicitly, the DDL statements of
+// the failed migration executed before the error cannot be rolled back.
+func (m *Migrator) Migrate(sess *dbr.Session, target uint64) error {
+	if target > 0 && m.index(target) < 0 {
+		return errgo.Newf("Migration version %d is not registered", target)
+	}
+
+	if m.DryRun != nil {
+		current, err := m.Version(sess)
+		if err != nil {
+			// the schema version table does not yet exist
+			if PkgLog.IsDebug() {
+				PkgLog.Debug("csdb.Migrator.Migrate.DryRun.Version", "err", err, "table", m.TableName)
+			}
+			current = 0
+		}
+		return errgo.Mask(m.WriteSQL(m.DryRun, current, target))
+	}
+
+	// The transaction pins one connection because the MySQL user lock is
+	// bound to it. The migrations run on other connections.
+	tx, err := sess.Begin()
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	locked, err := tx.SelectBySql("SELECT GET_LOCK(?, ?)", m.LockName, int64(m.LockTimeout/time.Second)).ReturnInt64()
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	if locked != 1 {
+		return ErrMigrationLocked
+	}
+	err = m.migrate(sess, target)
+	if _, rErr := tx.SelectBySql("SELECT RELEASE_LOCK(?)", m.LockName).ReturnInt64(); rErr != nil {
+		PkgLog.Info("csdb.Migrator.Migrate.ReleaseLock", "err", rErr, "lock", m.LockName)
+	}
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	return errgo.Mask(tx.Commit())
+}
+
+// migrate applies the migrations while holding the lock.
+func (m *Migrator) migrate(sess *dbr.Session, target uint64) error {
+	if _, err := sess.UpdateBySql(m.createTableSQL()).Exec(); err != nil {
+		return errgo.Mask(err)
+	}
+
+	// read the version after acquiring the lock because another node might
+	// have migrated in the meantime.
+	current, err := m.Version(sess)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+
+	steps, err := m.steps(current, target)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	for _, s := range steps {
+		if err := m.apply(sess, s); err != nil {
+			return errgo.Mask(err)
+		}
+		PkgLog.Info("csdb.Migrator.Migrate", "version", s.Version, "up", s.up, "description", s.Description)
+	}
+	return nil
+}
+
+// apply executes the statements of one migration and writes or removes its
+// version row in one transaction.
+func (m *Migrator) apply(sess *dbr.Session, s migrationStep) error {
+	tx, err := sess.Begin()
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	for _, stmt := range s.statements() {
+		if _, err := tx.UpdateBySql(stmt).Exec(); err != nil {
+			return errgo.Notef(err, "Migration %d: %q", s.Version, stmt)
+		}
+	}
+	if s.up {
+		_, err = tx.InsertInto(dbr.Quoter.QuoteAs(m.TableName)).
+			Columns("version", "description").
+			Values(s.Version, s.Description).
+			Exec()
+	} else {
+		_, err = tx.DeleteFrom(m.TableName).
+			Where(dbr.ConditionMap(dbr.Eq{"version": s.Version})).
+			Exec()
+	}
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	return errgo.Mask(tx.Commit())
+}
+
+// WriteSQL writes all statements which migrate the schema from the current
+// to the target version into w. DML statements get formatted with
+// sqlbeautifier.
+func (m *Migrator) WriteSQL(w io.Writer, current, target uint64) error {
+	steps, err := m.steps(current, target)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	for _, s := range steps {
+		dir := "down"
+		if s.up {
+			dir = "up"
+		}
+		if _, err := fmt.Fprintf(w, "-- Migration %d %s: %s\n", s.Version, dir, s.Description); err != nil {
+			return errgo.Mask(err)
+		}
+		for _, stmt := range s.statements() {
+			if _, err := fmt.Fprintf(w, "%s;\n", beautifySQL(stmt)); err != nil {
+				return errgo.Mask(err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) createTableSQL() string {
+	return "CREATE TABLE IF NOT EXISTS " + dbr.Quoter.QuoteAs(m.TableName) + " (\n" +
+		"  `version` bigint(20) unsigned NOT NULL,\n" +
+		"  `description` varchar(255) NOT NULL DEFAULT '',\n" +
+		"  `applied_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`version`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8"
+}
+
+func (m *Migrator) index(version uint64) int {
+	for i, mig := range m.migrations {
+		if mig.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// migrationStep a migration applied up or down
+type migrationStep struct {
+	Migration
+	up bool
+}
+
+func (s migrationStep) statements() []string {
+	if s.up {
+		return s.Up
+	}
+	return s.Down
+}
+
+// steps returns the migrations to apply in their order. Up migrations in
+// ascending, down migrations in descending version order.
+func (m *Migrator) steps(current, target uint64) ([]migrationStep, error) {
+	var steps []migrationStep
+	if target >= current {
+		for _, mig := range m.migrations {
+			if mig.Version > current && mig.Version <= target {
+				steps = append(steps, migrationStep{Migration: mig, up: true})
+			}
+		}
+		return steps, nil
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if mig.Version <= current && mig.Version > target {
+			if len(mig.Down) == 0 {
+				return nil, errgo.Newf("Migration %d cannot be reverted: no down statements", mig.Version)
+			}
+			steps = append(steps, migrationStep{Migration: mig})
+		}
+	}
+	return steps, nil
+}
+
+// beautifySQL formats DML statements. The SQL parser does not support the
+// full DDL syntax so DDL statements get returned unchanged.
+func beautifySQL(stmt string) string {
+	s := strings.TrimSpace(stmt)
+	if len(s) < 6 {
+		return s
+	}
+	switch strings.ToUpper(s[:6]) {
+	case "SELECT", "INSERT", "UPDATE", "DELETE":
+		if buf, err := sqlbeautifier.FromString(s); err == nil {
+			return buf.String()
+		}
+	}
+	return s
+}
+
+type migrationsByVersion []Migration
+
+func (ms migrationsByVersion) Len() int           { return len(ms) }
+func (ms migrationsByVersion) Less(i, j int) bool { return ms[i].Version < ms[j].Version }
+func (ms migrationsByVersion) Swap(i, j int)      { ms[i], ms[j] = ms[j], ms[i] }
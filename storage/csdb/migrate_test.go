@@ -0,0 +1,248 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdb_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMigrations = []csdb.Migration{
+	{
+		Version:     2,
+		Description: "Add email",
+		Up:          []string{"ALTER TABLE `admin_user` ADD COLUMN `email` varchar(128) NULL DEFAULT NULL AFTER `user_id`"},
+		Down:        []string{"ALTER TABLE `admin_user` DROP COLUMN `email`"},
+	},
+	{
+		Version:     1,
+		Description: "Create admin_user",
+		Up:          []string{"CREATE TABLE `admin_user` (`user_id` int(10) unsigned NOT NULL auto_increment, PRIMARY KEY (`user_id`))"},
+		Down:        []string{"DROP TABLE IF EXISTS `admin_user`"},
+	},
+	{
+		Version:     3,
+		Description: "Default user",
+		Up:          []string{"INSERT INTO `admin_user` (`email`) VALUES ('a@b.c')"},
+	},
+}
+
+func newMockSession(t *testing.T) (*dbr.Session, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	cxn, err := dbr.NewConnection(dbr.SetDB(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cxn.NewSession(), mock
+}
+
+func TestMigratorRegister(t *testing.T) {
+	m := csdb.NewMigrator(csdb.SetMigrations(testMigrations...))
+	assert.Exactly(t, uint64(3), m.Latest())
+	assert.EqualError(t, m.Register(csdb.Migration{Version: 2}), csdb.ErrMigrationVersion.Error())
+	assert.EqualError(t, m.Register(csdb.Migration{}), csdb.ErrMigrationVersion.Error())
+	assert.Exactly(t, uint64(3), m.Latest(), "Failed registration must not change the migrations")
+	assert.Exactly(t, uint64(0), csdb.NewMigrator().Latest())
+}
+
+func TestMigratorWriteSQL(t *testing.T) {
+	m := csdb.NewMigrator(csdb.SetMigrations(testMigrations...))
+
+	var buf bytes.Buffer
+	assert.NoError(t, m.WriteSQL(&buf, 0, 3))
+	assert.Exactly(t, "-- Migration 1 up: Create admin_user\n"+
+		"CREATE TABLE `admin_user` (`user_id` int(10) unsigned NOT NULL auto_increment, PRIMARY KEY (`user_id`));\n"+
+		"-- Migration 2 up: Add email\n"+
+		"ALTER TABLE `admin_user` ADD COLUMN `email` varchar(128) NULL DEFAULT NULL AFTER `user_id`;\n"+
+		"-- Migration 3 up: Default user\n"+
+		"insert into \n\tadmin_user(\n\temail \n) values ('a@b.c');\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, m.WriteSQL(&buf, 2, 0))
+	assert.Exactly(t, "-- Migration 2 down: Add email\n"+
+		"ALTER TABLE `admin_user` DROP COLUMN `email`;\n"+
+		"-- Migration 1 down: Create admin_user\n"+
+		"DROP TABLE IF EXISTS `admin_user`;\n", buf.String())
+
+	buf.Reset()
+	assert.Error(t, m.WriteSQL(&buf, 3, 2), "Migration 3 has no down statements")
+	assert.Exactly(t, "", buf.String())
+}
+
+func TestMigratorDryRunSqlMock(t *testing.T) {
+	sess, mock := newMockSession(t)
+
+	mock.ExpectQuery("SELECT IFNULL\\(MAX\\(`version`\\),0\\) FROM `csdb_schema_version`").
+		WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(1))
+
+	var buf bytes.Buffer
+	m := csdb.NewMigrator(csdb.SetMigrations(testMigrations...), csdb.SetMigrationDryRun(&buf))
+	assert.NoError(t, m.Migrate(sess, 2))
+	assert.Exactly(t, "-- Migration 2 up: Add email\n"+
+		"ALTER TABLE `admin_user` ADD COLUMN `email` varchar(128) NULL DEFAULT NULL AFTER `user_id`;\n", buf.String())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestMigratorUpSqlMock(t *testing.T) {
+	sess, mock := newMockSession(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT GET_LOCK\\('csdb_schema_migration', 30\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"l"}).AddRow(1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS `csdb_schema_version`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT IFNULL\\(MAX\\(`version`\\),0\\) FROM `csdb_schema_version`").
+		WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER TABLE `admin_user` ADD COLUMN `email`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO `csdb_schema_version` \\(`version`,`description`\\) VALUES \\(2,'Add email'\\)").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `admin_user`").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO `csdb_schema_version` \\(`version`,`description`\\) VALUES \\(3,'Default user'\\)").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT RELEASE_LOCK\\('csdb_schema_migration'\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"l"}).AddRow(1))
+	mock.ExpectCommit()
+
+	m := csdb.NewMigrator(csdb.SetMigrations(testMigrations...))
+	assert.NoError(t, m.Up(sess))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestMigratorLockedSqlMock(t *testing.T) {
+	sess, mock := newMockSession(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT GET_LOCK\\('my_lock', 0\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"l"}).AddRow(0))
+	mock.ExpectRollback()
+
+	m := csdb.NewMigrator(csdb.SetMigrations(testMigrations...), csdb.SetMigrationLock("my_lock", 0))
+	assert.EqualError(t, m.Up(sess), csdb.ErrMigrationLocked.Error())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestMigratorDownSqlMock(t *testing.T) {
+	sess, mock := newMockSession(t)
+
+	mock.ExpectQuery("SELECT IFNULL\\(MAX\\(`version`\\),0\\) FROM `csdb_schema_version`").
+		WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(2))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT GET_LOCK").WillReturnRows(sqlmock.NewRows([]string{"l"}).AddRow(1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT IFNULL").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(2))
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER TABLE `admin_user` DROP COLUMN `email`").
+		WillReturnError(errors.New("Can't DROP 'email'"))
+	mock.ExpectRollback()
+	mock.ExpectQuery("SELECT RELEASE_LOCK").WillReturnRows(sqlmock.NewRows([]string{"l"}).AddRow(1))
+	mock.ExpectRollback()
+
+	m := csdb.NewMigrator(csdb.SetMigrations(testMigrations...))
+	err := m.Down(sess, 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Can't DROP 'email'")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestMigratorUpPartialSqlMock(t *testing.T) {
+	sess, mock := newMockSession(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT GET_LOCK").WillReturnRows(sqlmock.NewRows([]string{"l"}).AddRow(1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT IFNULL").WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER TABLE `admin_user` ADD COLUMN `email`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO `csdb_schema_version` \\(`version`,`description`\\) VALUES \\(2,'Add email'\\)").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit() // version 2 stays applied
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `admin_user`").
+		WillReturnError(errors.New("Duplicate entry 'admin'"))
+	mock.ExpectRollback()
+	mock.ExpectQuery("SELECT RELEASE_LOCK").WillReturnRows(sqlmock.NewRows([]string{"l"}).AddRow(1))
+	mock.ExpectRollback()
+
+	m := csdb.NewMigrator(csdb.SetMigrations(testMigrations...))
+	err := m.Up(sess)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Duplicate entry 'admin'")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestMigratorDownNothingAppliedSqlMock(t *testing.T) {
+	sess, mock := newMockSession(t)
+
+	mock.ExpectQuery("SELECT IFNULL\\(MAX\\(`version`\\),0\\) FROM `csdb_schema_version`").
+		WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(0))
+
+	m := csdb.NewMigrator(csdb.SetMigrations(testMigrations...))
+	assert.NoError(t, m.Down(sess, 1))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestMigratorDownNoVersionTableSqlMock(t *testing.T) {
+	sess, mock := newMockSession(t)
+
+	mock.ExpectQuery("SELECT IFNULL\\(MAX\\(`version`\\),0\\) FROM `csdb_schema_version`").
+		WillReturnError(&mysql.MySQLError{Number: 1146, Message: "Table 'magento.csdb_schema_version' doesn't exist"})
+
+	m := csdb.NewMigrator(csdb.SetMigrations(testMigrations...))
+	assert.NoError(t, m.Down(sess, 1))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestMigratorDownNegative(t *testing.T) {
+	m := csdb.NewMigrator(csdb.SetMigrations(testMigrations...))
+	assert.EqualError(t, m.Down(nil, -1), csdb.ErrMigrationSteps.Error())
+}
@@ -0,0 +1,179 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+
+	"github.com/juju/errgo"
+)
+
+// SnapshotVersion is the current format version of a schema snapshot. A
+// snapshot with a higher version cannot be read.
+const SnapshotVersion = 1
+
+// ErrSnapshotVersion gets returned when reading a snapshot with an unknown
+// format version.
+var ErrSnapshotVersion = errors.New("Unsupported schema snapshot version")
+
+// Snapshot contains the state of a Manager: all tables with their columns,
+// keys, indexes and foreign keys. A snapshot can be serialized into JSON or
+// Go source code and loaded later without a database connection.
+type Snapshot struct {
+	Version int
+	Tables  []SnapshotTable
+}
+
+// SnapshotTable a table within a snapshot.
+type SnapshotTable struct {
+	Index        Index
+	Name         string
+	Engine       string `json:",omitempty"`
+	CharacterSet string `json:",omitempty"`
+	Collation    string `json:",omitempty"`
+	Comment      string `json:",omitempty"`
+	Columns      Columns
+	Indexes      []TableIndex `json:",omitempty"`
+	ForeignKeys  []ForeignKey `json:",omitempty"`
+}
+
+// NewSnapshot creates a snapshot from all tables of a manager. Call Init on
+// the manager before to load the columns from the database.
+func NewSnapshot(m Manager) (*Snapshot, error) {
+	s := &Snapshot{Version: SnapshotVersion}
+	for i := Index(0); m.Next(i); i++ {
+		ts, err := m.Structure(i)
+		if err != nil {
+			return nil, errgo.Notef(err, "Index %d", i)
+		}
+		s.Tables = append(s.Tables, SnapshotTable{
+			Index:        i,
+			Name:         ts.Name,
+			Engine:       ts.Engine,
+			CharacterSet: ts.CharacterSet,
+			Collation:    ts.Collation,
+			Comment:      ts.Comment,
+			Columns:      ts.Columns,
+			Indexes:      ts.Indexes,
+			ForeignKeys:  ts.ForeignKeys,
+		})
+	}
+	return s, nil
+}
+
+// ReadSnapshot reads a JSON snapshot written by WriteJSON.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	s := new(Snapshot)
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, errgo.Mask(err)
+	}
+	if s.Version < 1 || s.Version > SnapshotVersion {
+		return nil, ErrSnapshotVersion
+	}
+	return s, nil
+}
+
+// WriteJSON writes the snapshot as indented JSON.
+func (s *Snapshot) WriteJSON(w io.Writer) error {
+	b, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return errgo.Mask(err)
+}
+
+// WriteGo writes the snapshot as formatted Go source code into package pkg.
+// The snapshot gets assigned to the variable varName.
+func (s *Snapshot) WriteGo(w io.Writer, pkg, varName string) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by csdb.Snapshot.WriteGo. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	buf.WriteString("import (\n\t\"github.com/corestoreio/csfw/storage/csdb\"\n")
+	if s.hasColumns() {
+		buf.WriteString("\t\"github.com/corestoreio/csfw/storage/dbr\"\n")
+	}
+	buf.WriteString(")\n\n")
+	fmt.Fprintf(&buf, "// %s database schema snapshot\n", varName)
+	fmt.Fprintf(&buf, "var %s = &csdb.Snapshot{\nVersion: %d,\nTables: []csdb.SnapshotTable{\n", varName, s.Version)
+	for _, t := range s.Tables {
+		fmt.Fprintf(&buf, "{\nIndex: %d,\nName: %q,\n", t.Index, t.Name)
+		for _, o := range [...]struct{ name, value string }{
+			{"Engine", t.Engine}, {"CharacterSet", t.CharacterSet}, {"Collation", t.Collation}, {"Comment", t.Comment},
+		} {
+			if o.value != "" {
+				fmt.Fprintf(&buf, "%s: %q,\n", o.name, o.value)
+			}
+		}
+		if len(t.Columns) > 0 {
+			fmt.Fprintf(&buf, "Columns: csdb.Columns{\n%#v,\n},\n", t.Columns)
+		}
+		if len(t.Indexes) > 0 {
+			fmt.Fprintf(&buf, "Indexes: %#v,\n", t.Indexes)
+		}
+		if len(t.ForeignKeys) > 0 {
+			fmt.Fprintf(&buf, "ForeignKeys: %#v,\n", t.ForeignKeys)
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("},\n}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return errgo.Notef(err, "Source:\n%s", buf.String())
+	}
+	_, err = w.Write(src)
+	return errgo.Mask(err)
+}
+
+func (s *Snapshot) hasColumns() bool {
+	for _, t := range s.Tables {
+		if len(t.Columns) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Manager creates a TableManager from the snapshot. Init does not touch the
+// database unless a reload gets forced.
+func (s *Snapshot) Manager() *TableManager {
+	return NewTableManager(WithSnapshot(s))
+}
+
+// WithSnapshot adds all tables of a snapshot to the TableManager and marks
+// the manager as initialized. Tests and code generators can so run offline.
+func WithSnapshot(s *Snapshot) ManagerOption {
+	return func(tm *TableManager) {
+		for _, t := range s.Tables {
+			ts := NewTable(t.Name, t.Columns...)
+			ts.Engine = t.Engine
+			ts.CharacterSet = t.CharacterSet
+			ts.Collation = t.Collation
+			ts.Comment = t.Comment
+			ts.Indexes = t.Indexes
+			ts.ForeignKeys = t.ForeignKeys
+			if err := tm.Append(t.Index, ts); err != nil {
+				tm.appendErr(err)
+			}
+		}
+		tm.initDone = true
+	}
+}
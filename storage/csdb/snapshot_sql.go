@@ -0,0 +1,405 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdb
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/juju/errgo"
+)
+
+// ReadSnapshotSQL creates a snapshot from the CREATE TABLE statements of a
+// schema dump written by `mysqldump --no-data`. The Key column gets derived
+// from the indexes like SHOW COLUMNS does: PRI for primary key columns, UNI
+// for single column unique indexes and MUL for the first column of all other
+// indexes. Character set and collation of a column are only set if the dump
+// contains them. The tables get sorted by name.
+func ReadSnapshotSQL(r io.Reader) (*Snapshot, error) {
+	s := &Snapshot{Version: SnapshotVersion}
+	var t *SnapshotTable
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		l := strings.TrimSpace(sc.Text())
+		switch {
+		case t == nil && strings.HasPrefix(l, "CREATE TABLE "):
+			p := &sqlParser{s: l[len("CREATE TABLE "):]}
+			name := p.ident()
+			if name == "" {
+				return nil, errgo.Newf("Line %d: missing table name", line)
+			}
+			t = &SnapshotTable{Name: name}
+		case t == nil:
+			continue
+		case strings.HasPrefix(l, ")"):
+			readTableOptions(t, l[1:])
+			setColumnKeys(t)
+			sort.Sort(indexesByName(t.Indexes))
+			sort.Sort(foreignKeysByName(t.ForeignKeys))
+			s.Tables = append(s.Tables, *t)
+			t = nil
+		default:
+			if err := readTableDefinition(t, strings.TrimSuffix(l, ",")); err != nil {
+				return nil, errgo.Notef(err, "Line %d: table %q", line, t.Name)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errgo.Mask(err)
+	}
+	if t != nil {
+		return nil, errgo.Newf("CREATE TABLE %q is incomplete", t.Name)
+	}
+
+	sort.Sort(snapshotTablesByName(s.Tables))
+	for i := range s.Tables {
+		s.Tables[i].Index = Index(i)
+	}
+	return s, nil
+}
+
+// readTableDefinition parses one column, index or foreign key definition.
+func readTableDefinition(t *SnapshotTable, def string) error {
+	p := &sqlParser{s: def}
+	switch {
+	case p.peek() == '`':
+		c, err := readColumn(p)
+		if err != nil {
+			return errgo.Mask(err)
+		}
+		t.Columns = append(t.Columns, c)
+	case p.keyword("PRIMARY KEY"):
+		t.Indexes = append(t.Indexes, TableIndex{Name: IndexPrimary, Unique: true, Type: "BTREE", Columns: p.identList()})
+	case p.keyword("UNIQUE KEY"):
+		t.Indexes = append(t.Indexes, readIndex(p, true, "BTREE"))
+	case p.keyword("KEY"):
+		t.Indexes = append(t.Indexes, readIndex(p, false, "BTREE"))
+	case p.keyword("FULLTEXT KEY"):
+		t.Indexes = append(t.Indexes, readIndex(p, false, "FULLTEXT"))
+	case p.keyword("CONSTRAINT"):
+		fk := ForeignKey{Name: p.ident(), OnUpdate: "RESTRICT", OnDelete: "RESTRICT"}
+		if !p.keyword("FOREIGN KEY") {
+			return errgo.Newf("Unsupported constraint %q", def)
+		}
+		fk.Columns = p.identList()
+		if !p.keyword("REFERENCES") {
+			return errgo.Newf("Missing REFERENCES in %q", def)
+		}
+		fk.RefTable = p.ident()
+		fk.RefColumns = p.identList()
+		for p.keyword("ON") {
+			action := &fk.OnDelete
+			if p.keyword("UPDATE") {
+				action = &fk.OnUpdate
+			} else {
+				p.keyword("DELETE")
+			}
+			switch {
+			case p.keyword("SET NULL"):
+				*action = "SET NULL"
+			case p.keyword("NO ACTION"):
+				*action = "NO ACTION"
+			default:
+				*action = p.word()
+			}
+		}
+		t.ForeignKeys = append(t.ForeignKeys, fk)
+	default:
+		return errgo.Newf("Unsupported definition %q", def)
+	}
+	return nil
+}
+
+// readColumn parses a column definition like
+// `name` varchar(255) NOT NULL DEFAULT '0' COMMENT 'Name'.
+func readColumn(p *sqlParser) (Column, error) {
+	c := Column{
+		Field: dbr.NewNullString(p.ident()),
+		Null:  dbr.NewNullString(ColumnNull),
+		Key:   dbr.NewNullString(""),
+		Extra: dbr.NewNullString(""),
+	}
+	typ := strings.ToLower(p.word())
+	if p.peek() == '(' {
+		typ += p.parens()
+	}
+	for _, attr := range [...]string{"unsigned", "zerofill"} {
+		if p.keyword(attr) {
+			typ += " " + attr
+		}
+	}
+	c.Type = dbr.NewNullString(typ)
+
+	for p.skipSpace(); p.more(); p.skipSpace() {
+		switch {
+		case p.keyword("CHARACTER SET"):
+			c.CharacterSet = dbr.NewNullString(p.word())
+		case p.keyword("COLLATE"):
+			c.Collation = dbr.NewNullString(p.word())
+		case p.keyword("NOT NULL"):
+			c.Null = dbr.NewNullString(ColumnNotNull)
+		case p.keyword("NULL"):
+			c.Null = dbr.NewNullString(ColumnNull)
+		case p.keyword("DEFAULT"):
+			if p.peek() == '\'' {
+				c.Default = dbr.NewNullString(p.quoted())
+			} else if w := p.word(); !strings.EqualFold(w, "NULL") {
+				c.Default = dbr.NewNullString(w)
+			}
+		case p.keyword("AUTO_INCREMENT"):
+			c.Extra = dbr.NewNullString(ColumnAutoIncrement)
+		case p.keyword("ON UPDATE"):
+			c.Extra = dbr.NewNullString("on update " + p.word())
+		case p.keyword("COMMENT"):
+			c.Comment = dbr.NewNullString(p.quoted())
+		default:
+			return c, errgo.Newf("Column %q: unsupported attribute %q", c.Field.String, p.s[p.pos:])
+		}
+	}
+	return c, nil
+}
+
+func readIndex(p *sqlParser, unique bool, typ string) TableIndex {
+	ti := TableIndex{Name: p.ident(), Unique: unique, Type: typ, Columns: p.identList()}
+	if p.keyword("USING") {
+		ti.Type = p.word()
+	}
+	return ti
+}
+
+// readTableOptions parses ENGINE=InnoDB DEFAULT CHARSET=utf8 COMMENT='x';
+func readTableOptions(t *SnapshotTable, opts string) {
+	p := &sqlParser{s: strings.TrimSuffix(strings.TrimSpace(opts), ";")}
+	for p.skipSpace(); p.more(); p.skipSpace() {
+		p.keyword("DEFAULT")
+		key := strings.ToUpper(p.word())
+		if p.peek() == '=' {
+			p.pos++
+		}
+		var val string
+		if p.peek() == '\'' {
+			val = p.quoted()
+		} else {
+			val = p.word()
+		}
+		switch key {
+		case "ENGINE":
+			t.Engine = val
+		case "CHARSET":
+			t.CharacterSet = val
+		case "COLLATE":
+			t.Collation = val
+		case "COMMENT":
+			t.Comment = val
+		}
+	}
+}
+
+// setColumnKeys sets the Key field of the columns from the indexes.
+func setColumnKeys(t *SnapshotTable) {
+	keys := make(map[string]string)
+	for _, ti := range t.Indexes {
+		switch {
+		case ti.IsPrimary():
+			for _, c := range ti.Columns {
+				keys[c] = ColumnPrimary
+			}
+		case ti.Unique && len(ti.Columns) == 1:
+			if keys[ti.Columns[0]] != ColumnPrimary {
+				keys[ti.Columns[0]] = ColumnUnique
+			}
+		default:
+			if keys[ti.Columns[0]] == "" {
+				keys[ti.Columns[0]] = ColumnIndex
+			}
+		}
+	}
+	for i, c := range t.Columns {
+		if k, ok := keys[c.Field.String]; ok {
+			t.Columns[i].Key = dbr.NewNullString(k)
+		}
+	}
+}
+
+// sqlParser a minimal scanner for the definitions in a mysqldump.
+type sqlParser struct {
+	s   string
+	pos int
+}
+
+func (p *sqlParser) more() bool { return p.pos < len(p.s) }
+
+func (p *sqlParser) skipSpace() {
+	for p.more() && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *sqlParser) peek() byte {
+	p.skipSpace()
+	if !p.more() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+// keyword consumes the case insensitive keyword if it follows.
+func (p *sqlParser) keyword(kw string) bool {
+	p.skipSpace()
+	end := p.pos + len(kw)
+	if end > len(p.s) || !strings.EqualFold(p.s[p.pos:end], kw) {
+		return false
+	}
+	if end < len(p.s) && isWordChar(p.s[end]) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+// word returns the next token up to a space, comma or bracket.
+func (p *sqlParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.more() && isWordChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// ident returns a back tick quoted identifier.
+func (p *sqlParser) ident() string {
+	if p.peek() != '`' {
+		return p.word()
+	}
+	end := strings.IndexByte(p.s[p.pos+1:], '`')
+	if end < 0 {
+		return ""
+	}
+	id := p.s[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	return id
+}
+
+// identList returns the identifiers of (`a`,`b`(255)). The prefix length
+// gets discarded.
+func (p *sqlParser) identList() []string {
+	var ids []string
+	if p.peek() != '(' {
+		return ids
+	}
+	p.pos++
+	for p.more() {
+		switch p.peek() {
+		case ')':
+			p.pos++
+			return ids
+		case ',':
+			p.pos++
+		case '(':
+			p.parens()
+		default:
+			ids = append(ids, p.ident())
+		}
+	}
+	return ids
+}
+
+// parens returns the text in brackets including the brackets. Quoted
+// strings can contain brackets.
+func (p *sqlParser) parens() string {
+	start := p.pos
+	for depth := 0; p.more(); {
+		switch p.s[p.pos] {
+		case '\'':
+			p.quoted()
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		p.pos++
+		if depth == 0 {
+			return p.s[start:p.pos]
+		}
+	}
+	return p.s[start:p.pos]
+}
+
+// quoted returns the unescaped content of a single quoted string.
+func (p *sqlParser) quoted() string {
+	p.skipSpace()
+	p.pos++ // opening quote
+	var buf []byte
+	for p.more() {
+		c := p.s[p.pos]
+		p.pos++
+		switch {
+		case c == '\\' && p.more():
+			buf = append(buf, unescapeSQL(p.s[p.pos]))
+			p.pos++
+		case c == '\'' && p.more() && p.s[p.pos] == '\'':
+			buf = append(buf, '\'')
+			p.pos++
+		case c == '\'':
+			return string(buf)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return string(buf)
+}
+
+func unescapeSQL(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case '0':
+		return 0
+	}
+	return c
+}
+
+func isWordChar(c byte) bool {
+	return c != ' ' && c != '\t' && c != ',' && c != '(' && c != ')' && c != '=' && c != '\''
+}
+
+type snapshotTablesByName []SnapshotTable
+
+func (ts snapshotTablesByName) Len() int           { return len(ts) }
+func (ts snapshotTablesByName) Less(i, j int) bool { return ts[i].Name < ts[j].Name }
+func (ts snapshotTablesByName) Swap(i, j int)      { ts[i], ts[j] = ts[j], ts[i] }
+
+type indexesByName []TableIndex
+
+func (is indexesByName) Len() int           { return len(is) }
+func (is indexesByName) Less(i, j int) bool { return is[i].Name < is[j].Name }
+func (is indexesByName) Swap(i, j int)      { is[i], is[j] = is[j], is[i] }
+
+type foreignKeysByName []ForeignKey
+
+func (fks foreignKeysByName) Len() int           { return len(fks) }
+func (fks foreignKeysByName) Less(i, j int) bool { return fks[i].Name < fks[j].Name }
+func (fks foreignKeysByName) Swap(i, j int)      { fks[i], fks[j] = fks[j], fks[i] }
@@ -0,0 +1,167 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdb_test
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotJSONRoundTrip(t *testing.T) {
+	s, err := csdb.NewSnapshot(tableMap)
+	assert.NoError(t, err)
+	assert.Len(t, s.Tables, 4)
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.WriteJSON(&buf))
+	assert.Contains(t, buf.String(), `"Version": 1`)
+
+	s2, err := csdb.ReadSnapshot(&buf)
+	assert.NoError(t, err)
+	assert.Exactly(t, s, s2)
+
+	tm := s2.Manager()
+	assert.NoError(t, tm.Init(nil), "Init must not access the database")
+	for i := csdb.Index(0); tableMap.Next(i); i++ {
+		want := mustStructure(i)
+		have, err := tm.Structure(i)
+		assert.NoError(t, err)
+		assert.Exactly(t, want.Name, have.Name)
+		assert.Exactly(t, want.Columns, have.Columns)
+		assert.Exactly(t, want.CountPK, have.CountPK)
+		assert.Exactly(t, want.AllColumnAliasQuote("a"), have.AllColumnAliasQuote("a"))
+	}
+}
+
+func TestReadSnapshotVersion(t *testing.T) {
+	_, err := csdb.ReadSnapshot(strings.NewReader(`{"Version":2,"Tables":[]}`))
+	assert.EqualError(t, err, csdb.ErrSnapshotVersion.Error())
+	_, err = csdb.ReadSnapshot(strings.NewReader(`{"Tables":[]}`))
+	assert.EqualError(t, err, csdb.ErrSnapshotVersion.Error())
+	_, err = csdb.ReadSnapshot(strings.NewReader(`{`))
+	assert.Error(t, err)
+}
+
+func TestSnapshotWriteGo(t *testing.T) {
+	s, err := csdb.NewSnapshot(tableMap)
+	assert.NoError(t, err)
+	s.Tables[3].Engine = "InnoDB"
+	s.Tables[3].Indexes = []csdb.TableIndex{{Name: "PRIMARY", Unique: true, Type: "BTREE", Columns: []string{"user_id"}}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.WriteGo(&buf, "mage1", "Schema"))
+	src := buf.String()
+
+	_, err = parser.ParseFile(token.NewFileSet(), "schema.go", src, 0)
+	assert.NoError(t, err)
+	assert.Contains(t, src, "package mage1\n")
+	assert.Contains(t, src, "var Schema = &csdb.Snapshot{")
+	assert.Contains(t, src, "csdb.Column{Field: dbr.NewNullString(`user_id`), Type: dbr.NewNullString(`int(10) unsigned`)")
+	assert.Contains(t, src, `Engine: "InnoDB",`)
+	assert.Contains(t, src, `Indexes: []csdb.TableIndex{csdb.TableIndex{Name: "PRIMARY", Unique: true, Type: "BTREE", Columns: []string{"user_id"}}},`)
+}
+
+func TestReadSnapshotSQL(t *testing.T) {
+	const dump = "-- MySQL dump\n" +
+		"CREATE TABLE `store` (\n" +
+		"  `store_id` smallint(5) unsigned NOT NULL AUTO_INCREMENT COMMENT 'Store Id',\n" +
+		"  `code` varchar(32) DEFAULT NULL COMMENT 'It''s the code',\n" +
+		"  `website_id` smallint(5) unsigned NOT NULL DEFAULT '0' COMMENT 'Website Id',\n" +
+		"  `name` varchar(255) CHARACTER SET latin1 COLLATE latin1_bin NOT NULL,\n" +
+		"  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+		"  `price` decimal(12,4) NOT NULL DEFAULT '0.0000',\n" +
+		"  PRIMARY KEY (`store_id`),\n" +
+		"  UNIQUE KEY `STORE_CODE` (`code`),\n" +
+		"  KEY `STORE_WEBSITE_ID` (`website_id`),\n" +
+		"  FULLTEXT KEY `STORE_NAME` (`name`(100)),\n" +
+		"  CONSTRAINT `STORE_WEBSITE_ID_STORE_WEBSITE_WEBSITE_ID` FOREIGN KEY (`website_id`) REFERENCES `store_website` (`website_id`) ON DELETE CASCADE\n" +
+		") ENGINE=InnoDB AUTO_INCREMENT=2 DEFAULT CHARSET=utf8 COMMENT='Stores';\n" +
+		"CREATE TABLE `admin_user` (\n" +
+		"  `user_id` int(10) unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  PRIMARY KEY (`user_id`)\n" +
+		") ENGINE=MEMORY DEFAULT CHARSET=utf8;\n"
+
+	s, err := csdb.ReadSnapshotSQL(strings.NewReader(dump))
+	assert.NoError(t, err)
+	if !assert.Len(t, s.Tables, 2) {
+		return
+	}
+	assert.Exactly(t, csdb.SnapshotVersion, s.Version)
+	assert.Exactly(t, "admin_user", s.Tables[0].Name)
+	assert.Exactly(t, "MEMORY", s.Tables[0].Engine)
+
+	st := s.Tables[1]
+	assert.Exactly(t, csdb.Index(1), st.Index)
+	assert.Exactly(t, "store", st.Name)
+	assert.Exactly(t, "InnoDB", st.Engine)
+	assert.Exactly(t, "utf8", st.CharacterSet)
+	assert.Exactly(t, "Stores", st.Comment)
+	assert.Exactly(t, csdb.Columns{
+		csdb.Column{Field: dbr.NewNullString("store_id"), Type: dbr.NewNullString("smallint(5) unsigned"), Null: dbr.NewNullString("NO"), Key: dbr.NewNullString("PRI"), Default: dbr.NewNullString(nil), Extra: dbr.NewNullString("auto_increment"), Comment: dbr.NewNullString("Store Id")},
+		csdb.Column{Field: dbr.NewNullString("code"), Type: dbr.NewNullString("varchar(32)"), Null: dbr.NewNullString("YES"), Key: dbr.NewNullString("UNI"), Default: dbr.NewNullString(nil), Extra: dbr.NewNullString(""), Comment: dbr.NewNullString("It's the code")},
+		csdb.Column{Field: dbr.NewNullString("website_id"), Type: dbr.NewNullString("smallint(5) unsigned"), Null: dbr.NewNullString("NO"), Key: dbr.NewNullString("MUL"), Default: dbr.NewNullString("0"), Extra: dbr.NewNullString(""), Comment: dbr.NewNullString("Website Id")},
+		csdb.Column{Field: dbr.NewNullString("name"), Type: dbr.NewNullString("varchar(255)"), Null: dbr.NewNullString("NO"), Key: dbr.NewNullString("MUL"), Default: dbr.NewNullString(nil), Extra: dbr.NewNullString(""), CharacterSet: dbr.NewNullString("latin1"), Collation: dbr.NewNullString("latin1_bin")},
+		csdb.Column{Field: dbr.NewNullString("updated_at"), Type: dbr.NewNullString("timestamp"), Null: dbr.NewNullString("NO"), Key: dbr.NewNullString(""), Default: dbr.NewNullString("CURRENT_TIMESTAMP"), Extra: dbr.NewNullString("on update CURRENT_TIMESTAMP")},
+		csdb.Column{Field: dbr.NewNullString("price"), Type: dbr.NewNullString("decimal(12,4)"), Null: dbr.NewNullString("NO"), Key: dbr.NewNullString(""), Default: dbr.NewNullString("0.0000"), Extra: dbr.NewNullString("")},
+	}, st.Columns)
+	assert.Exactly(t, []csdb.TableIndex{
+		{Name: "PRIMARY", Unique: true, Type: "BTREE", Columns: []string{"store_id"}},
+		{Name: "STORE_CODE", Unique: true, Type: "BTREE", Columns: []string{"code"}},
+		{Name: "STORE_NAME", Type: "FULLTEXT", Columns: []string{"name"}},
+		{Name: "STORE_WEBSITE_ID", Type: "BTREE", Columns: []string{"website_id"}},
+	}, st.Indexes)
+	assert.Exactly(t, []csdb.ForeignKey{
+		{Name: "STORE_WEBSITE_ID_STORE_WEBSITE_WEBSITE_ID", Columns: []string{"website_id"}, RefTable: "store_website", RefColumns: []string{"website_id"}, OnUpdate: "RESTRICT", OnDelete: "CASCADE"},
+	}, st.ForeignKeys)
+
+	_, err = csdb.ReadSnapshotSQL(strings.NewReader("CREATE TABLE `a` (\n  `b` int(10) UNKNOWN\n"))
+	assert.Error(t, err)
+	_, err = csdb.ReadSnapshotSQL(strings.NewReader("CREATE TABLE `a` (\n  `b` int(10)\n"))
+	assert.EqualError(t, err, "CREATE TABLE \"a\" is incomplete")
+}
+
+// TestSnapshotTestdata checks that the committed JSON snapshots match the
+// mysqldump schema files they have been created from.
+func TestSnapshotTestdata(t *testing.T) {
+	for _, version := range []string{"magento-1.9.2.1", "magento-2.0.0"} {
+		path := filepath.Join("..", "..", "testdata", version+"-schema")
+
+		f, err := os.Open(path + ".sql")
+		if !assert.NoError(t, err) {
+			continue
+		}
+		want, err := csdb.ReadSnapshotSQL(f)
+		f.Close()
+		assert.NoError(t, err, version)
+
+		f, err = os.Open(path + ".json")
+		if !assert.NoError(t, err) {
+			continue
+		}
+		have, err := csdb.ReadSnapshot(f)
+		f.Close()
+		assert.NoError(t, err, version)
+		assert.Exactly(t, want, have, version)
+	}
+}
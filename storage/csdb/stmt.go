@@ -35,6 +35,16 @@ type Preparer interface {
 	Prepare(query string) (*sql.Stmt, error)
 }
 
+// StmtRecorder gets notified when a ResurrectStmt prepares or closes its
+// statement. See type Metrics.
+type StmtRecorder interface {
+	// StmtPrepare gets called after a statement has been prepared, either
+	// the first time or resurrected after being idle.
+	StmtPrepare(sql string)
+	// StmtClose gets called after an open statement has been closed.
+	StmtClose(sql string)
+}
+
 // ResurrectStmt creates a long living sql.Stmt in the database but closes it
 // if within an idle time no query will be executed. Once there is a new
 // query the statement gets resurrected. The ResurrectStmt type is safe for
@@ -50,7 +60,9 @@ type ResurrectStmt struct {
 	// Idle defines the duration how to wait until no query will be executed.
 	Idle time.Duration
 	// Log default logger is PkgLof
-	Log              log.Logger
+	Log log.Logger
+	// Recorder optional, records the prepare and close calls.
+	Recorder         StmtRecorder
 	stop             chan struct{} // tells the ticker to stop and close
 	idleCheckStarted bool
 
@@ -118,6 +130,9 @@ func (su *ResurrectStmt) close() error {
 	if su.Log.IsDebug() {
 		su.Log.Debug("csdb.ResurrectStmt.stmt.Close", "SQL", su.SQL)
 	}
+	if su.Recorder != nil && !su.closed {
+		su.Recorder.StmtClose(su.SQL)
+	}
 	return errgo.Mask(su.stmt.Close())
 }
 
@@ -169,6 +184,9 @@ func (su *ResurrectStmt) Stmt() (*sql.Stmt, error) {
 	if su.Log.IsDebug() {
 		su.Log.Debug("csdb.ResurrectStmt.stmt.Prepare", "SQL", su.SQL)
 	}
+	if su.Recorder != nil {
+		su.Recorder.StmtPrepare(su.SQL)
+	}
 	su.closed = false
 	return su.stmt, nil
 }
@@ -30,6 +30,25 @@ type Table struct {
 	// CountUnique number of unique keys
 	CountUnique int
 
+	// Indexes, ForeignKeys and the table options are only available when
+	// loaded with LoadMetadata.
+	Indexes      []TableIndex
+	ForeignKeys  []ForeignKey
+	Engine       string
+	CharacterSet string
+	Collation    string
+	Comment      string
+
+	// VersionColumn optional column for optimistic locking. Either an
+	// integer column which gets incremented or a date column which gets set
+	// to NOW() with each Update. See UpdateVersion.
+	VersionColumn string
+	// SoftDeleteColumn optional column which marks a row as deleted. Either
+	// a nullable date column, NULL means not deleted, or an integer flag
+	// column, zero means not deleted. Select excludes deleted rows. See
+	// SoftDelete.
+	SoftDeleteColumn string
+
 	// internal caches
 	fieldsPK  []string // all PK column field
 	fieldsUNI []string // all unique key column field
@@ -94,8 +113,21 @@ func (ts *Table) In(n string) bool {
 	return false
 }
 
-// Select generates a SELECT * FROM tableName statement
+// Select generates a SELECT * FROM tableName statement. Rows marked as
+// deleted in the SoftDeleteColumn are excluded. See SelectWithDeleted.
 func (ts *Table) Select(dbrSess dbr.SessionRunner) (*dbr.SelectBuilder, error) {
+	sb, err := ts.SelectWithDeleted(dbrSess)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	if ts.SoftDeleteColumn != "" {
+		sb.Where(ts.notDeletedCondition(MainTable))
+	}
+	return sb, nil
+}
+
+// SelectWithDeleted same as Select but includes the soft deleted rows.
+func (ts *Table) SelectWithDeleted(dbrSess dbr.SessionRunner) (*dbr.SelectBuilder, error) {
 	if ts == nil {
 		return nil, ErrTableNotFound
 	}
@@ -104,9 +136,129 @@ func (ts *Table) Select(dbrSess dbr.SessionRunner) (*dbr.SelectBuilder, error) {
 		From(ts.Name, MainTable), nil
 }
 
-func (ts *Table) Update() {}
-func (ts *Table) Delete() {}
-func (ts *Table) Insert() {}
-func (ts *Table) Alter()  {}
-func (ts *Table) Drop()   {}
-func (ts *Table) Create() {}
+// Insert generates an INSERT INTO tableName statement with all columns
+// except the auto increment column. Add the values with Values() or Record().
+func (ts *Table) Insert(dbrSess dbr.SessionRunner) (*dbr.InsertBuilder, error) {
+	if ts == nil {
+		return nil, ErrTableNotFound
+	}
+	return dbrSess.
+		InsertInto(dbr.Quoter.QuoteAs(ts.Name)).
+		Columns(ts.Columns.ColumnsNoAutoIncrement().FieldNames()...), nil
+}
+
+// Upsert generates an INSERT INTO tableName ... ON DUPLICATE KEY UPDATE
+// statement. All columns except the auto increment column get inserted and
+// all non key columns get updated if the row exists already. Returns
+// ErrTableNoUniqueKey if the table has no unique key.
+func (ts *Table) Upsert(dbrSess dbr.SessionRunner) (*dbr.InsertBuilder, error) {
+	if ts == nil {
+		return nil, ErrTableNotFound
+	}
+	if ts.CountUnique == 0 {
+		return nil, ErrTableNoUniqueKey
+	}
+	updCols := ts.Columns.Filter(func(c Column) bool {
+		return !c.IsPK() && !c.IsUnique() && !c.IsAutoIncrement()
+	}).FieldNames()
+	if len(updCols) == 0 {
+		// MySQL needs at least one column in the ON DUPLICATE KEY clause
+		updCols = ts.fieldsUNI[:1]
+	}
+	ib, err := ts.Insert(dbrSess)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return ib.OnDuplicateKey(updCols...), nil
+}
+
+// Update generates an UPDATE tableName statement restricted to the row with
+// the primary key values. The order of the values must match the order of the
+// primary key columns. Add the new values with Set(). Returns
+// ErrTableVersioned if the table has a VersionColumn, rows of those tables
+// can only be changed with UpdateVersion.
+func (ts *Table) Update(dbrSess dbr.SessionRunner, pkValues ...interface{}) (*dbr.UpdateBuilder, error) {
+	if ts != nil && ts.VersionColumn != "" {
+		return nil, ErrTableVersioned
+	}
+	return ts.updateRow(dbrSess, pkValues)
+}
+
+// updateRow generates the UPDATE statement for the row with the primary key
+// values and increments the version if the table has a VersionColumn. Soft
+// deleted rows do not match, like in Select.
+func (ts *Table) updateRow(dbrSess dbr.SessionRunner, pkValues []interface{}) (*dbr.UpdateBuilder, error) {
+	conds, err := ts.pkConditions(pkValues)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	if ts.SoftDeleteColumn != "" {
+		conds = append(conds, ts.notDeletedCondition(""))
+	}
+	ub := dbrSess.Update(dbr.Quoter.QuoteAs(ts.Name)).Where(conds...)
+	if ts.VersionColumn != "" {
+		ts.setNextVersion(ub)
+	}
+	return ub, nil
+}
+
+// Delete generates a DELETE FROM tableName statement restricted to the row
+// with the primary key values. The order of the values must match the order
+// of the primary key columns.
+func (ts *Table) Delete(dbrSess dbr.SessionRunner, pkValues ...interface{}) (*dbr.DeleteBuilder, error) {
+	conds, err := ts.pkConditions(pkValues)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return dbrSess.DeleteFrom(dbr.Quoter.QuoteAs(ts.Name)).Where(conds...), nil
+}
+
+// pkConditions creates an equal condition for each primary key column.
+func (ts *Table) pkConditions(pkValues []interface{}) ([]dbr.ConditionArg, error) {
+	if ts == nil {
+		return nil, ErrTableNotFound
+	}
+	if ts.CountPK == 0 {
+		return nil, ErrTableNoPrimaryKey
+	}
+	if len(pkValues) != ts.CountPK {
+		return nil, errgo.Newf("Table %q: Expecting %d primary key values but got %d", ts.Name, ts.CountPK, len(pkValues))
+	}
+	conds := make([]dbr.ConditionArg, len(pkValues))
+	for i, pk := range ts.fieldsPK {
+		conds[i] = dbr.ConditionMap(dbr.Eq{pk: pkValues[i]})
+	}
+	return conds, nil
+}
+
+// Create generates the CREATE TABLE statement from the columns. See
+// CreateSQL. Run it with Exec().
+func (ts *Table) Create(db Execer) (*DDL, error) {
+	ddl, err := ts.CreateSQL()
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return &DDL{SQL: ddl, db: db}, nil
+}
+
+// Alter generates the ALTER TABLE statement which changes the current
+// columns, e.g. retrieved with GetColumns, into the columns of this table.
+// Returns ErrTableUnchanged if there are no differences. See AlterSQL.
+func (ts *Table) Alter(db Execer, current Columns) (*DDL, error) {
+	if ts == nil {
+		return nil, ErrTableNotFound
+	}
+	ddl := ts.AlterSQL(current)
+	if ddl == "" {
+		return nil, ErrTableUnchanged
+	}
+	return &DDL{SQL: ddl, db: db}, nil
+}
+
+// Drop generates the DROP TABLE IF EXISTS statement. Run it with Exec().
+func (ts *Table) Drop(db Execer) (*DDL, error) {
+	if ts == nil {
+		return nil, ErrTableNotFound
+	}
+	return &DDL{SQL: ts.DropSQL(), db: db}, nil
+}
@@ -15,8 +15,10 @@
 package csdb_test
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/corestoreio/csfw/storage/csdb"
 	"github.com/corestoreio/csfw/storage/dbr"
 	"github.com/stretchr/testify/assert"
@@ -257,3 +259,154 @@ func TestTableStructureIn(t *testing.T) {
 		assert.EqualValues(t, want2[table.Name], have, "Table %s", table.Name)
 	}
 }
+
+func TestTableInsertUpsert(t *testing.T) {
+	dbrSess := createFakeSession()
+
+	ib, err := mustStructure(table4).Insert(dbrSess)
+	assert.NoError(t, err)
+	sql, args, err := ib.Values("a@b.c", "ab").ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "INSERT INTO `admin_user` (`email`,`username`) VALUES (?,?)", sql)
+	assert.Exactly(t, []interface{}{"a@b.c", "ab"}, args)
+
+	ib, err = mustStructure(table4).Upsert(dbrSess)
+	assert.NoError(t, err)
+	sql, _, err = ib.Values("a@b.c", "ab").ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "INSERT INTO `admin_user` (`email`,`username`) VALUES (?,?) ON DUPLICATE KEY UPDATE `email`=VALUES(`email`)", sql)
+
+	ib, err = mustStructure(table1).Upsert(dbrSess)
+	assert.Nil(t, ib)
+	assert.EqualError(t, err, csdb.ErrTableNoUniqueKey.Error())
+}
+
+func TestTableUpdateDelete(t *testing.T) {
+	dbrSess := createFakeSession()
+
+	ub, err := mustStructure(table4).Update(dbrSess, 3)
+	assert.NoError(t, err)
+	sql, args, err := ub.Set("email", "x@y.z").ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "UPDATE `admin_user` SET `email` = ? WHERE (`user_id` = ?)", sql)
+	assert.Exactly(t, []interface{}{"x@y.z", 3}, args)
+
+	db, err := mustStructure(table4).Delete(dbrSess, 3)
+	assert.NoError(t, err)
+	sql, args, err = db.ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "DELETE FROM `admin_user` WHERE (`user_id` = ?)", sql)
+	assert.Exactly(t, []interface{}{3}, args)
+
+	db, err = mustStructure(table4).Delete(dbrSess, 3, 4)
+	assert.Nil(t, db)
+	assert.Error(t, err)
+
+	ub, err = mustStructure(table3).Update(dbrSess, 1)
+	assert.Nil(t, ub)
+	assert.EqualError(t, err, csdb.ErrTableNoPrimaryKey.Error())
+}
+
+func TestTableCreateDrop(t *testing.T) {
+	ddl, err := mustStructure(table4).CreateSQL()
+	assert.NoError(t, err)
+	assert.Exactly(t, "CREATE TABLE `admin_user` (\n"+
+		"  `user_id` int(10) unsigned NOT NULL auto_increment,\n"+
+		"  `email` varchar(128) NULL DEFAULT NULL,\n"+
+		"  `username` varchar(40) NULL DEFAULT NULL,\n"+
+		"  PRIMARY KEY (`user_id`),\n"+
+		"  UNIQUE KEY `UNQ_ADMIN_USER_USERNAME` (`username`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8", ddl)
+
+	ddl, err = mustStructure(table1).CreateSQL()
+	assert.NoError(t, err)
+	assert.Exactly(t, "CREATE TABLE `catalog_category_anc_categs_index_idx` (\n"+
+		"  `category_id` int(10) unsigned NOT NULL DEFAULT '0',\n"+
+		"  `path` varchar(255) NULL DEFAULT NULL,\n"+
+		"  KEY `IDX_CATALOG_CATEGORY_ANC_CATEGS_INDEX_IDX_CATEGORY_ID` (`category_id`),\n"+
+		"  KEY `IDX_CATALOG_CATEGORY_ANC_CATEGS_INDEX_IDX_PATH` (`path`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8", ddl)
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMock.ExpectExec("CREATE TABLE `admin_user`").WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectExec("DROP TABLE IF EXISTS `admin_user`").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	create, err := mustStructure(table4).Create(db)
+	assert.NoError(t, err)
+	assert.Contains(t, create.String(), "CREATE TABLE `admin_user` (\n")
+	_, err = create.Exec()
+	assert.NoError(t, err)
+
+	_, err = csdb.NewTable("empty").CreateSQL()
+	assert.EqualError(t, err, csdb.ErrTableNoColumns.Error())
+	create, err = csdb.NewTable("empty").Create(db)
+	assert.Nil(t, create)
+	assert.EqualError(t, err, csdb.ErrTableNoColumns.Error())
+
+	drop, err := mustStructure(table4).Drop(db)
+	assert.NoError(t, err)
+	assert.Exactly(t, "DROP TABLE IF EXISTS `admin_user`", drop.SQL)
+	_, err = drop.Exec()
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestTableAlter(t *testing.T) {
+	ts := mustStructure(table4)
+	current := csdb.Columns{
+		csdb.Column{
+			Field:   dbr.NewNullString("user_id"),
+			Type:    dbr.NewNullString("int(10) unsigned"),
+			Null:    dbr.NewNullString("NO"),
+			Key:     dbr.NewNullString("PRI"),
+			Default: dbr.NullString{},
+			Extra:   dbr.NewNullString("auto_increment"),
+		},
+		csdb.Column{
+			Field:   dbr.NewNullString("username"),
+			Type:    dbr.NewNullString("varchar(32)"),
+			Null:    dbr.NewNullString("YES"),
+			Key:     dbr.NewNullString("UNI"),
+			Default: dbr.NullString{},
+			Extra:   dbr.NewNullString(""),
+		},
+		csdb.Column{
+			Field:   dbr.NewNullString("lastname"),
+			Type:    dbr.NewNullString("varchar(32)"),
+			Null:    dbr.NewNullString("NO"),
+			Default: dbr.NewNullString("it's"),
+		},
+	}
+	assert.Exactly(t, "ALTER TABLE `admin_user` "+
+		"ADD COLUMN `email` varchar(128) NULL DEFAULT NULL AFTER `user_id`, "+
+		"MODIFY COLUMN `username` varchar(40) NULL DEFAULT NULL, "+
+		"DROP COLUMN `lastname`", ts.AlterSQL(current))
+
+	diffs := ts.DiffColumns(current)
+	assert.Len(t, diffs, 3)
+	assert.Exactly(t, csdb.ColumnMissing, diffs[0].Kind)
+	assert.Exactly(t, "user_id", diffs[0].After)
+	assert.Exactly(t, "+ `email` varchar(128) NULL DEFAULT NULL\n"+
+		"~ `username` varchar(32) NULL DEFAULT NULL => `username` varchar(40) NULL DEFAULT NULL\n"+
+		"- `lastname` varchar(32) NOT NULL DEFAULT 'it''s'\n", diffs.String())
+	assert.Len(t, ts.DiffColumns(ts.Columns), 0)
+
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	alter, err := ts.Alter(db, ts.Columns)
+	assert.Nil(t, alter)
+	assert.EqualError(t, err, csdb.ErrTableUnchanged.Error())
+
+	dbMock.ExpectExec("ALTER TABLE `admin_user` ADD COLUMN `email`").WillReturnError(errors.New("Duplicate column name 'email'"))
+	alter, err = ts.Alter(db, current)
+	assert.NoError(t, err)
+	assert.Exactly(t, ts.AlterSQL(current), alter.SQL)
+	_, err = alter.Exec()
+	assert.EqualError(t, err, "Duplicate column name 'email'")
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,189 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueryCache defines a storage backend for the decoded results of a
+// SelectBuilder. The key is always the interpolated SQL query string as
+// returned by Preprocess. Tags are mostly table names and allow to remove all
+// entries related to a table once an INSERT, UPDATE or DELETE has been
+// executed on that table. Implementations must be safe for concurrent use.
+type QueryCache interface {
+	// Get returns the value for a key. The bool is false when the key cannot
+	// be found or the entry has expired.
+	Get(key string) (interface{}, bool)
+	// Set stores a value with a time to live and optional tags. A ttl
+	// less or equal zero means that the entry never expires.
+	Set(key string, value interface{}, ttl time.Duration, tags ...string)
+	// Invalidate removes all entries which have been stored with at least
+	// one of the provided tags.
+	Invalidate(tags ...string)
+}
+
+// SetQueryCache sets the cache backend for all SelectBuilder which have
+// been marked with Cache(). The same backend gets notified by the Insert,
+// Update and Delete builders to invalidate entries of a table. Builders of a
+// transaction bypass the cache and the changed tables get invalidated after
+// the commit.
+func SetQueryCache(qc QueryCache) ConnectionOption {
+	return func(c *Connection) {
+		c.queryCache = qc
+	}
+}
+
+// Cache enables the result cache of the connection for this SELECT
+// statement. The decoded results are stored with the interpolated SQL as key
+// for the duration ttl. All involved tables of the FROM and JOIN parts are
+// added automatically as tags. Additional tags can be provided to invalidate
+// the entry manually. Without a QueryCache set to the connection this
+// function has no effect.
+func (b *SelectBuilder) Cache(ttl time.Duration, tags ...string) *SelectBuilder {
+	b.cacheEnabled = true
+	b.cacheTTL = ttl
+	b.cacheTags = append(b.cacheTags, tags...)
+	return b
+}
+
+// queryCache returns the cache of the underlying connection or nil.
+func (sess *Session) queryCache() QueryCache {
+	if sess == nil || sess.cxn == nil {
+		return nil
+	}
+	return sess.cxn.queryCache
+}
+
+// invalidateCache removes all cached entries of the tables from the
+// connections cache. Within a transaction, tt not nil, the tables get
+// collected and invalidated after the commit.
+func (sess *Session) invalidateCache(tt *txTables, tables ...string) {
+	qc := sess.queryCache()
+	if qc == nil {
+		return
+	}
+	if tt != nil {
+		tt.add(tables...)
+		return
+	}
+	tags := make([]string, 0, len(tables))
+	for _, t := range tables {
+		if t != "" {
+			tags = append(tags, cacheTableTag(t))
+		}
+	}
+	if len(tags) > 0 {
+		qc.Invalidate(tags...)
+		sess.EventKv("dbr.cache.invalidate", kvs{"tags": strings.Join(tags, ",")})
+	}
+}
+
+// cacheTableTag normalizes a table name to a tag.
+func cacheTableTag(table string) string {
+	return Quoter.unQuote(table)
+}
+
+// useCache checks if the result of this builder can be served or stored in
+// the cache. Transactions might see or write uncommitted rows and bypass the
+// cache.
+func (b *SelectBuilder) useCache() bool {
+	return b.cacheEnabled && b.txTables == nil && b.queryCache() != nil
+}
+
+// txTables collects the tables changed within a transaction and its nested
+// transactions.
+type txTables struct {
+	mu     sync.Mutex
+	tables []string
+}
+
+func (tt *txTables) add(tables ...string) {
+	tt.mu.Lock()
+	tt.tables = append(tt.tables, tables...)
+	tt.mu.Unlock()
+}
+
+// reset returns the collected tables and clears the list.
+func (tt *txTables) reset() []string {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	tables := tt.tables
+	tt.tables = nil
+	return tables
+}
+
+// allCacheTags returns the manually provided tags and the table names of the
+// FROM and JOIN parts.
+func (b *SelectBuilder) allCacheTags() []string {
+	tags := make([]string, 0, len(b.cacheTags)+len(b.JoinFragments)+1)
+	tags = append(tags, b.cacheTags...)
+	if b.FromTable.Expression != "" {
+		tags = append(tags, cacheTableTag(b.FromTable.Expression))
+	}
+	for _, f := range b.JoinFragments {
+		tags = append(tags, cacheTableTag(f.Table.Expression))
+	}
+	return tags
+}
+
+// cacheLoad looks up the fullSql in the cache and returns the stored value
+// only if its type matches typ.
+func (b *SelectBuilder) cacheLoad(fullSql string, typ reflect.Type) (reflect.Value, bool) {
+	v, ok := b.queryCache().Get(fullSql)
+	if !ok {
+		b.EventKv("dbr.select.cache.miss", kvs{"sql": fullSql})
+		return reflect.Value{}, false
+	}
+	rv, ok := v.(reflect.Value)
+	if !ok || rv.Type() != typ {
+		b.EventKv("dbr.select.cache.miss", kvs{"sql": fullSql})
+		return reflect.Value{}, false
+	}
+	b.EventKv("dbr.select.cache.hit", kvs{"sql": fullSql})
+	return rv, true
+}
+
+// cacheStore writes a copy of the decoded value into the cache.
+func (b *SelectBuilder) cacheStore(fullSql string, v reflect.Value) {
+	b.queryCache().Set(fullSql, copyValue(v), b.cacheTTL, b.allCacheTags()...)
+}
+
+// copyValue creates a shallow copy of v. Slices get copied and if the slice
+// contains pointers to structs, the structs get copied too. So the caller
+// cannot modify the cached entry.
+func copyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Slice:
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return c
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(v.Elem())
+		return c
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
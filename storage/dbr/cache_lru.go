@@ -0,0 +1,160 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DefaultLRUCacheSize maximum number of entries in a LRUCache when created
+// with a size less or equal zero.
+const DefaultLRUCacheSize = 1024
+
+var _ QueryCache = (*LRUCache)(nil)
+
+// LRUCache is an in-memory least recently used cache with a time to live
+// per entry. Once the maximum number of entries has been reached the oldest
+// entry gets removed. Expired entries get removed lazily on access. LRUCache
+// is safe for concurrent use.
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	tags       map[string]map[string]struct{} // tag => keys
+	// now used in tests to manipulate the time
+	now func() time.Time
+}
+
+type lruEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time // zero means never
+	tags    []string
+}
+
+// NewLRUCache creates a new LRU cache with a maximum number of entries.
+func NewLRUCache(maxEntries int) *LRUCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultLRUCacheSize
+	}
+	return &LRUCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		tags:       make(map[string]map[string]struct{}),
+		now:        time.Now,
+	}
+}
+
+// Get returns a value by its key. Expired entries will be removed.
+func (c *LRUCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ele, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := ele.Value.(*lruEntry)
+	if !e.expires.IsZero() && c.now().After(e.expires) {
+		c.removeElement(ele)
+		return nil, false
+	}
+	c.ll.MoveToFront(ele)
+	return e.value, true
+}
+
+// Set adds or overwrites a value. If the cache exceeds its maximum size the
+// least recently used entry gets removed.
+func (c *LRUCache) Set(key string, value interface{}, ttl time.Duration, tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ele, ok := c.items[key]; ok {
+		c.removeElement(ele)
+	}
+
+	e := &lruEntry{
+		key:   key,
+		value: value,
+		tags:  tags,
+	}
+	if ttl > 0 {
+		e.expires = c.now().Add(ttl)
+	}
+	c.items[key] = c.ll.PushFront(e)
+	for _, t := range tags {
+		keys, ok := c.tags[t]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.tags[t] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// Invalidate removes all entries tagged with at least one of the tags.
+func (c *LRUCache) Invalidate(tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, t := range tags {
+		for key := range c.tags[t] {
+			if ele, ok := c.items[key]; ok {
+				c.removeElement(ele)
+			}
+		}
+		delete(c.tags, t)
+	}
+}
+
+// Len returns the number of cached entries including the expired ones.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// Clear removes all entries.
+func (c *LRUCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	c.tags = make(map[string]map[string]struct{})
+}
+
+// removeElement must be called while holding the lock.
+func (c *LRUCache) removeElement(ele *list.Element) {
+	if ele == nil {
+		return
+	}
+	e := c.ll.Remove(ele).(*lruEntry)
+	delete(c.items, e.key)
+	for _, t := range e.tags {
+		if keys, ok := c.tags[t]; ok {
+			delete(keys, e.key)
+			if len(keys) == 0 {
+				delete(c.tags, t)
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCacheTTL(t *testing.T) {
+	c := NewLRUCache(0)
+	now := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1, time.Second)
+	c.Set("b", 2, 0)
+
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Exactly(t, 1, v)
+
+	now = now.Add(time.Second * 2)
+	v, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Nil(t, v)
+
+	v, ok = c.Get("b")
+	assert.True(t, ok)
+	assert.Exactly(t, 2, v)
+	assert.Exactly(t, 1, c.Len())
+}
+
+func TestLRUCacheEviction(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	_, _ = c.Get("a") // a is now the most recently used
+	c.Set("c", 3, 0)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Exactly(t, 2, c.Len())
+}
+
+func TestLRUCacheInvalidate(t *testing.T) {
+	c := NewLRUCache(10)
+	c.Set("a", 1, 0, "eav_attribute")
+	c.Set("b", 2, 0, "eav_attribute", "eav_entity_type")
+	c.Set("c", 3, 0, "directory_country")
+
+	c.Invalidate("eav_entity_type")
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+
+	c.Invalidate("eav_attribute", "directory_country")
+	assert.Exactly(t, 0, c.Len())
+	assert.Len(t, c.tags, 0)
+}
+
+func TestSelectCacheTags(t *testing.T) {
+	s := createFakeSession()
+	sel := s.Select("a").
+		From("`eav_attribute`", "ea").
+		Join(JoinTable("eav_entity_type", "et"), JoinColumns("b"), ConditionRaw("ea.entity_type_id = et.entity_type_id")).
+		Cache(time.Minute, "eav")
+	assert.True(t, sel.cacheEnabled)
+	assert.Exactly(t, time.Minute, sel.cacheTTL)
+	assert.Exactly(t, []string{"eav", "eav_attribute", "eav_entity_type"}, sel.allCacheTags())
+	assert.False(t, sel.useCache(), "No QueryCache set to the connection")
+}
+
+func TestSessionInvalidateCache(t *testing.T) {
+	qc := NewLRUCache(10)
+	cxn, err := NewConnection(SetQueryCache(qc))
+	assert.NoError(t, err)
+	s := cxn.NewSession()
+
+	qc.Set("SELECT 1", 1, 0, "directory_country")
+	qc.Set("SELECT 2", 2, 0, "eav_attribute")
+
+	s.invalidateCache(nil, "`directory_country`", "")
+	_, ok := qc.Get("SELECT 1")
+	assert.False(t, ok)
+	_, ok = qc.Get("SELECT 2")
+	assert.True(t, ok)
+}
+
+func TestTxInvalidateCacheOnCommit(t *testing.T) {
+	resetTxLog(0)
+	s := newTxSession()
+	qc := NewLRUCache(10)
+	s.cxn.ApplyOpts(SetQueryCache(qc))
+
+	qc.Set("SELECT a", 1, 0, "a")
+	qc.Set("SELECT b", 2, 0, "b")
+	qc.Set("SELECT c", 3, 0, "c")
+
+	tx, err := s.Begin()
+	assert.NoError(t, err)
+	assert.False(t, tx.Select("x").From("a").Cache(time.Minute).useCache(), "Transactions bypass the cache")
+
+	_, err = tx.Update("a").Set("x", 1).Exec()
+	assert.NoError(t, err)
+	nested, err := tx.Begin()
+	assert.NoError(t, err)
+	_, err = nested.DeleteFrom("`b`").Exec()
+	assert.NoError(t, err)
+	assert.NoError(t, nested.Commit())
+
+	_, ok := qc.Get("SELECT a")
+	assert.True(t, ok, "Uncommitted changes must not invalidate")
+	_, ok = qc.Get("SELECT b")
+	assert.True(t, ok, "Uncommitted changes must not invalidate")
+
+	assert.NoError(t, tx.Commit())
+	_, ok = qc.Get("SELECT a")
+	assert.False(t, ok)
+	_, ok = qc.Get("SELECT b")
+	assert.False(t, ok)
+
+	tx, err = s.Begin()
+	assert.NoError(t, err)
+	_, err = tx.InsertInto("c").Columns("x").Values(1).Exec()
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Rollback())
+	_, ok = qc.Get("SELECT c")
+	assert.True(t, ok, "Rolled back changes must not invalidate")
+}
+
+func TestSelectCacheReal(t *testing.T) {
+	s := createRealSessionWithFixtures()
+	s.cxn.ApplyOpts(SetQueryCache(NewLRUCache(10)))
+
+	var people []*dbrPerson
+	count, err := s.Select("id", "name", "email").From("dbr_people").OrderBy("id ASC").Cache(time.Minute).LoadStructs(&people)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	_, err = s.cxn.DB.Exec("DELETE FROM dbr_people") // bypasses the invalidation
+	assert.NoError(t, err)
+
+	var cached []*dbrPerson
+	count, err = s.Select("id", "name", "email").From("dbr_people").OrderBy("id ASC").Cache(time.Minute).LoadStructs(&cached)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, people[0].Name, cached[0].Name)
+
+	_, err = s.InsertInto("dbr_people").Columns("name", "email").Values("Barack", "obama@whitehouse.gov").Exec()
+	assert.NoError(t, err)
+
+	var fresh []*dbrPerson
+	count, err = s.Select("id", "name", "email").From("dbr_people").OrderBy("id ASC").Cache(time.Minute).LoadStructs(&fresh)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,233 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import "time"
+
+// Column represents a column of a table or alias. The typed columns
+// ColumnInt64, ColumnString, etc. embed it and get generated by
+// tableToStruct so that a renamed or removed column results in a compile
+// error instead of an SQL error at runtime.
+type Column struct {
+	// Table contains the name or the alias of the table. Can be empty.
+	Table string
+	// Name of the column
+	Name string
+}
+
+// NewColumn creates a new column for a table name or alias.
+func NewColumn(table, name string) Column {
+	return Column{Table: table, Name: name}
+}
+
+// String returns the not quoted identifier table.column or only column if
+// the table is empty.
+func (c Column) String() string {
+	if c.Table == "" {
+		return c.Name
+	}
+	return c.Table + "." + c.Name
+}
+
+// Quote returns the quoted identifier `table`.`column`.
+func (c Column) Quote() string {
+	return Quoter.QuoteAs(c.String())
+}
+
+// As returns the quoted identifier with an alias: `table`.`column` AS `as`.
+func (c Column) As(as string) string {
+	return Quoter.QuoteAs(c.String(), as)
+}
+
+// Asc returns the quoted identifier for an ORDER BY clause in ascending order.
+func (c Column) Asc() string {
+	return c.Quote() + " ASC"
+}
+
+// Desc returns the quoted identifier for an ORDER BY clause in descending
+// order.
+func (c Column) Desc() string {
+	return c.Quote() + " DESC"
+}
+
+// IsNull creates the condition `column` IS NULL.
+func (c Column) IsNull() ConditionArg {
+	return IsNull(c.String())
+}
+
+// NotNull creates the condition `column` IS NOT NULL.
+func (c Column) NotNull() ConditionArg {
+	return NotNull(c.String())
+}
+
+// ColumnInt64 represents an integer column.
+type ColumnInt64 struct {
+	Column
+}
+
+// NewColumnInt64 creates a new integer column for a table name or alias.
+func NewColumnInt64(table, name string) ColumnInt64 {
+	return ColumnInt64{Column: NewColumn(table, name)}
+}
+
+// Eq creates the condition `column` = v.
+func (c ColumnInt64) Eq(v int64) ConditionArg {
+	return typedCondition(c.String(), opEq, v, nil)
+}
+
+// NotEq creates the condition `column` != v.
+func (c ColumnInt64) NotEq(v int64) ConditionArg {
+	return typedCondition(c.String(), opNotEq, v, nil)
+}
+
+// Gt creates the condition `column` > v.
+func (c ColumnInt64) Gt(v int64) ConditionArg { return Gt(c.String(), v) }
+
+// Gte creates the condition `column` >= v.
+func (c ColumnInt64) Gte(v int64) ConditionArg { return Gte(c.String(), v) }
+
+// Lt creates the condition `column` < v.
+func (c ColumnInt64) Lt(v int64) ConditionArg { return Lt(c.String(), v) }
+
+// Lte creates the condition `column` <= v.
+func (c ColumnInt64) Lte(v int64) ConditionArg { return Lte(c.String(), v) }
+
+// Between creates the condition `column` BETWEEN from AND to.
+func (c ColumnInt64) Between(from, to int64) ConditionArg { return Between(c.String(), from, to) }
+
+// In creates the condition `column` IN (v...).
+func (c ColumnInt64) In(v ...int64) ConditionArg { return In(c.String(), v) }
+
+// NotIn creates the condition `column` NOT IN (v...).
+func (c ColumnInt64) NotIn(v ...int64) ConditionArg { return NotIn(c.String(), v) }
+
+// ColumnFloat64 represents a floating point or decimal column.
+type ColumnFloat64 struct {
+	Column
+}
+
+// NewColumnFloat64 creates a new float column for a table name or alias.
+func NewColumnFloat64(table, name string) ColumnFloat64 {
+	return ColumnFloat64{Column: NewColumn(table, name)}
+}
+
+// Eq creates the condition `column` = v.
+func (c ColumnFloat64) Eq(v float64) ConditionArg {
+	return typedCondition(c.String(), opEq, v, nil)
+}
+
+// NotEq creates the condition `column` != v.
+func (c ColumnFloat64) NotEq(v float64) ConditionArg {
+	return typedCondition(c.String(), opNotEq, v, nil)
+}
+
+// Gt creates the condition `column` > v.
+func (c ColumnFloat64) Gt(v float64) ConditionArg { return Gt(c.String(), v) }
+
+// Gte creates the condition `column` >= v.
+func (c ColumnFloat64) Gte(v float64) ConditionArg { return Gte(c.String(), v) }
+
+// Lt creates the condition `column` < v.
+func (c ColumnFloat64) Lt(v float64) ConditionArg { return Lt(c.String(), v) }
+
+// Lte creates the condition `column` <= v.
+func (c ColumnFloat64) Lte(v float64) ConditionArg { return Lte(c.String(), v) }
+
+// Between creates the condition `column` BETWEEN from AND to.
+func (c ColumnFloat64) Between(from, to float64) ConditionArg { return Between(c.String(), from, to) }
+
+// In creates the condition `column` IN (v...).
+func (c ColumnFloat64) In(v ...float64) ConditionArg { return In(c.String(), v) }
+
+// NotIn creates the condition `column` NOT IN (v...).
+func (c ColumnFloat64) NotIn(v ...float64) ConditionArg { return NotIn(c.String(), v) }
+
+// ColumnString represents a text column.
+type ColumnString struct {
+	Column
+}
+
+// NewColumnString creates a new text column for a table name or alias.
+func NewColumnString(table, name string) ColumnString {
+	return ColumnString{Column: NewColumn(table, name)}
+}
+
+// Eq creates the condition `column` = v.
+func (c ColumnString) Eq(v string) ConditionArg {
+	return typedCondition(c.String(), opEq, v, nil)
+}
+
+// NotEq creates the condition `column` != v.
+func (c ColumnString) NotEq(v string) ConditionArg {
+	return typedCondition(c.String(), opNotEq, v, nil)
+}
+
+// Like creates the condition `column` LIKE pattern.
+func (c ColumnString) Like(pattern string) ConditionArg { return Like(c.String(), pattern) }
+
+// NotLike creates the condition `column` NOT LIKE pattern.
+func (c ColumnString) NotLike(pattern string) ConditionArg { return NotLike(c.String(), pattern) }
+
+// In creates the condition `column` IN (v...).
+func (c ColumnString) In(v ...string) ConditionArg { return In(c.String(), v) }
+
+// NotIn creates the condition `column` NOT IN (v...).
+func (c ColumnString) NotIn(v ...string) ConditionArg { return NotIn(c.String(), v) }
+
+// ColumnBool represents a boolean column, mostly a tinyint or smallint in
+// MySQL.
+type ColumnBool struct {
+	Column
+}
+
+// NewColumnBool creates a new boolean column for a table name or alias.
+func NewColumnBool(table, name string) ColumnBool {
+	return ColumnBool{Column: NewColumn(table, name)}
+}
+
+// Eq creates the condition `column` = v.
+func (c ColumnBool) Eq(v bool) ConditionArg {
+	return typedCondition(c.String(), opEq, v, nil)
+}
+
+// ColumnTime represents a date, datetime or timestamp column.
+type ColumnTime struct {
+	Column
+}
+
+// NewColumnTime creates a new time column for a table name or alias.
+func NewColumnTime(table, name string) ColumnTime {
+	return ColumnTime{Column: NewColumn(table, name)}
+}
+
+// Eq creates the condition `column` = v.
+func (c ColumnTime) Eq(v time.Time) ConditionArg {
+	return typedCondition(c.String(), opEq, v, nil)
+}
+
+// Gt creates the condition `column` > v.
+func (c ColumnTime) Gt(v time.Time) ConditionArg { return Gt(c.String(), v) }
+
+// Gte creates the condition `column` >= v.
+func (c ColumnTime) Gte(v time.Time) ConditionArg { return Gte(c.String(), v) }
+
+// Lt creates the condition `column` < v.
+func (c ColumnTime) Lt(v time.Time) ConditionArg { return Lt(c.String(), v) }
+
+// Lte creates the condition `column` <= v.
+func (c ColumnTime) Lte(v time.Time) ConditionArg { return Lte(c.String(), v) }
+
+// Between creates the condition `column` BETWEEN from AND to.
+func (c ColumnTime) Between(from, to time.Time) ConditionArg { return Between(c.String(), from, to) }
@@ -0,0 +1,95 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestoreio/csfw/util/bufferpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnTypedConditions(t *testing.T) {
+	var (
+		id    = NewColumnInt64("main_table", "store_id")
+		code  = NewColumnString("main_table", "code")
+		price = NewColumnFloat64("", "price")
+		act   = NewColumnBool("main_table", "is_active")
+		upd   = NewColumnTime("main_table", "updated_at")
+		now   = time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+	)
+
+	tests := []struct {
+		cond     ConditionArg
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{id.Eq(1), "(`main_table`.`store_id` = ?)", []interface{}{int64(1)}},
+		{id.NotEq(1), "(`main_table`.`store_id` != ?)", []interface{}{int64(1)}},
+		{id.Between(1, 5), "(`main_table`.`store_id` BETWEEN ? AND ?)", []interface{}{int64(1), int64(5)}},
+		{id.In(1, 2), "(`main_table`.`store_id` IN ?)", []interface{}{[]int64{1, 2}}},
+		{id.In(3), "(`main_table`.`store_id` = ?)", []interface{}{int64(3)}},
+		{id.In(), "(1=0)", nil},
+		{id.NotIn(1, 2), "(`main_table`.`store_id` NOT IN ?)", []interface{}{[]int64{1, 2}}},
+		{id.IsNull(), "(`main_table`.`store_id` IS NULL)", nil},
+		{code.Eq("de"), "(`main_table`.`code` = ?)", []interface{}{"de"}},
+		{code.Like("d%"), "(`main_table`.`code` LIKE ?)", []interface{}{"d%"}},
+		{code.NotIn("at", "ch"), "(`main_table`.`code` NOT IN ?)", []interface{}{[]string{"at", "ch"}}},
+		{code.NotNull(), "(`main_table`.`code` IS NOT NULL)", nil},
+		{price.Gte(2.5), "(`price` >= ?)", []interface{}{2.5}},
+		{act.Eq(true), "(`main_table`.`is_active` = ?)", []interface{}{true}},
+		{upd.Lt(now), "(`main_table`.`updated_at` < ?)", []interface{}{now}},
+		{
+			Or(id.Eq(0), And(code.NotEq("admin"), act.Eq(true))),
+			"((`main_table`.`store_id` = ?) OR ((`main_table`.`code` != ?) AND (`main_table`.`is_active` = ?)))",
+			[]interface{}{int64(0), "admin", true},
+		},
+	}
+	for i, test := range tests {
+		buf := bufferpool.Get()
+		var args []interface{}
+		writeWhereFragmentsToSql(newWhereFragments(test.cond), buf, &args)
+		assert.Exactly(t, test.wantSQL, buf.String(), "Index %d", i)
+		assert.Exactly(t, test.wantArgs, args, "Index %d", i)
+		bufferpool.Put(buf)
+	}
+}
+
+func TestColumnTypedIdentifiers(t *testing.T) {
+	c := NewColumnInt64("main_table", "sort_order")
+	assert.Exactly(t, "main_table.sort_order", c.String())
+	assert.Exactly(t, "`main_table`.`sort_order`", c.Quote())
+	assert.Exactly(t, "`main_table`.`sort_order` AS `so`", c.As("so"))
+	assert.Exactly(t, "`main_table`.`sort_order` ASC", c.Asc())
+	assert.Exactly(t, "`main_table`.`sort_order` DESC", c.Desc())
+	assert.Exactly(t, "`name`", NewColumnString("", "name").Quote())
+}
+
+func TestColumnTypedSelectBuilder(t *testing.T) {
+	s := createFakeSession()
+	var (
+		websiteID = NewColumnInt64("main_table", "website_id")
+		sortOrder = NewColumnInt64("main_table", "sort_order")
+	)
+
+	sql, args, err := s.Select("a").From("store", "main_table").
+		Where(websiteID.Eq(1)).
+		OrderBy(sortOrder.Asc()).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "SELECT a FROM `store` AS `main_table` WHERE (`main_table`.`website_id` = ?) ORDER BY `main_table`.`sort_order` ASC", sql)
+	assert.Exactly(t, []interface{}{int64(1)}, args)
+}
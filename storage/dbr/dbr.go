@@ -18,12 +18,23 @@ type Connection struct {
 	dn string
 	// dsn Data Source Name
 	dsn string
+	// dialect escapes the arguments and rewrites the queries, see SetDialect
+	dialect Dialect
+	// queryCache optional cache for SELECT results, see SetQueryCache
+	queryCache QueryCache
+	// replicas optional read only connections, see SetReplicaDB
+	replicas replicaSet
 }
 
 // Session represents a business unit of execution for some connection
 type Session struct {
 	cxn *Connection
 	EventReceiver
+	// primaryForced 1 if SELECT queries must not be routed to a replica
+	// because the session has written data.
+	primaryForced uint32
+	// stmtCache optional cache for prepared statements
+	stmtCache *StmtCache
 }
 
 // ConnectionOption can be used as an argument in NewConnection to configure a connection.
@@ -49,14 +60,25 @@ func SetEventReceiver(log EventReceiver) ConnectionOption {
 	}
 }
 
-// SetDriver sets the driver name for a connection. At the moment only MySQL
-// is supported.
+// SetDriver sets the driver name for a connection. Supported are MySQL,
+// SQLite and PostgreSQL. The dialect of the driver gets used unless
+// SetDialect has been applied.
 func SetDriver(driverName string) ConnectionOption {
 	return func(c *Connection) {
 		c.dn = driverName
 	}
 }
 
+// SetDialect sets the SQL dialect for a connection. The builders always
+// generate MySQL flavoured SQL which gets rewritten into the dialect before
+// sending it to the database. Default dialect is D for MySQL or the dialect
+// of the driver set with SetDriver.
+func SetDialect(d Dialect) ConnectionOption {
+	return func(c *Connection) {
+		c.dialect = d
+	}
+}
+
 // SetDSN sets the data source name for a connection.
 func SetDSN(dsn string) ConnectionOption {
 	if dsn == "" {
@@ -78,20 +100,31 @@ func NewConnection(opts ...ConnectionOption) (*Connection, error) {
 
 	switch c.dn {
 	case DriverNameMySQL:
+		if c.dialect == nil {
+			c.dialect = D
+		}
+	case DriverNameSQLite, DriverNamePostgres:
+		if c.dialect == nil {
+			c.dialect = dialectByDriver(c.dn)
+		}
 	default:
 		return nil, errgo.Newf("unsupported driver: %s", c.dn)
 	}
 
-	if c.DB != nil {
-		return c, nil
-	}
-
-	if c.dsn != "" {
+	if c.DB == nil && c.dsn != "" {
 		var err error
 		if c.DB, err = sql.Open(c.dn, c.dsn); err != nil {
 			return nil, err
 		}
 	}
+	if err := c.replicas.open(c.dn); err != nil {
+		// do not leak the pools of the primary and the already opened replicas
+		_ = c.closeReplicas()
+		if c.DB != nil {
+			_ = c.DB.Close()
+		}
+		return nil, err
+	}
 	return c, nil
 }
 
@@ -118,6 +151,14 @@ func (c *Connection) ApplyOpts(opts ...ConnectionOption) *Connection {
 	return c
 }
 
+// Dialect returns the SQL dialect of the connection.
+func (c *Connection) Dialect() Dialect {
+	if c == nil || c.dialect == nil {
+		return D
+	}
+	return c.dialect
+}
+
 // NewSession instantiates a Session for the Connection
 func (c *Connection) NewSession(opts ...SessionOption) *Session {
 	s := &Session{
@@ -128,9 +169,19 @@ func (c *Connection) NewSession(opts ...SessionOption) *Session {
 	return s
 }
 
-// Close closes the database, releasing any open resources.
+// Close closes the database and all replicas, releasing any open resources.
+// The primary gets closed even if closing a replica fails. The first error
+// will be returned.
 func (c *Connection) Close() error {
-	return c.EventErr("dbr.connection.close", c.DB.Close())
+	rErr := c.closeReplicas()
+	if rErr != nil {
+		rErr = c.EventErr("dbr.connection.close.replica", rErr)
+	}
+	err := c.EventErr("dbr.connection.close", c.DB.Close())
+	if rErr != nil {
+		return rErr
+	}
+	return err
 }
 
 // Ping verifies a connection to the database is still alive, establishing a connection if necessary.
@@ -165,6 +216,20 @@ func (s *Session) ApplyOpts(opts ...SessionOption) (previous SessionOption) {
 	return previous
 }
 
+// dialect returns the dialect of the connection or D if the session has no
+// connection.
+func (sess *Session) dialect() Dialect {
+	if sess == nil {
+		return D
+	}
+	return sess.cxn.Dialect()
+}
+
+// preprocess interpolates the arguments with the dialect of the connection.
+func (sess *Session) preprocess(sql string, vals []interface{}) (string, error) {
+	return preprocess(sess.dialect(), sql, vals)
+}
+
 // SessionRunner can do anything that a Session can except start a transaction.
 type SessionRunner interface {
 	Select(cols ...string) *SelectBuilder
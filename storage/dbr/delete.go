@@ -12,6 +12,8 @@ import (
 type DeleteBuilder struct {
 	*Session
 	runner
+	// txTables collects the changed tables when bound to a transaction.
+	txTables *txTables
 
 	From           alias
 	WhereFragments []*whereFragment
@@ -37,9 +39,10 @@ func (sess *Session) DeleteFrom(from ...string) *DeleteBuilder {
 // in the context for a transaction
 func (tx *Tx) DeleteFrom(from ...string) *DeleteBuilder {
 	return &DeleteBuilder{
-		Session: tx.Session,
-		runner:  tx.Tx,
-		From:    newAlias(from...),
+		Session:  tx.Session,
+		runner:   tx.Tx,
+		txTables: tx.tables,
+		From:     newAlias(from...),
 	}
 }
 
@@ -131,19 +134,23 @@ func (b *DeleteBuilder) Exec() (sql.Result, error) {
 		return nil, b.EventErrKv("dbr.delete.exec.tosql", err, nil)
 	}
 
-	fullSql, err := Preprocess(sql, args)
+	fullSql, err := b.preprocess(sql, args)
 	if err != nil {
-		return nil, b.EventErrKv("dbr.delete.exec.interpolate", err, kvs{"sql": fullSql})
+		return nil, b.EventErrKv("dbr.delete.exec.interpolate", err, tableKvs(fullSql, b.From.Expression))
 	}
 
 	// Start the timer:
 	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.delete", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
+	defer func() {
+		b.TimingKv("dbr.delete", time.Since(startTime).Nanoseconds(), tableKvs(fullSql, b.From.Expression))
+	}()
 
-	result, err := b.runner.Exec(fullSql)
+	result, err := b.exec(b.runner, sql, args, fullSql)
 	if err != nil {
-		return result, b.EventErrKv("dbr.delete.exec.exec", err, kvs{"sql": fullSql})
+		return result, b.EventErrKv("dbr.delete.exec.exec", err, tableKvs(fullSql, b.From.Expression))
 	}
+	b.markWrite()
+	b.invalidateCache(b.txTables, b.From.Expression)
 
 	return result, nil
 }
@@ -1,7 +1,14 @@
 package dbr
 
-import "time"
+import (
+	"strings"
+	"time"
 
+	"github.com/corestoreio/csfw/util/bufferpool"
+)
+
+// D is the default dialect used by Preprocess and by all connections which
+// neither define a dialect with SetDialect nor a driver with SetDriver.
 var D Dialect = Mysql{}
 
 // Dialect is an interface that wraps the diverse properties of individual
@@ -11,5 +18,61 @@ type Dialect interface {
 	EscapeBool(w QueryWriter, b bool)
 	EscapeString(w QueryWriter, s string)
 	EscapeTime(w QueryWriter, t time.Time)
+	// ApplyLimitAndOffset writes the LIMIT and OFFSET clause. A zero limit
+	// means no limit.
 	ApplyLimitAndOffset(w QueryWriter, limit, offset uint64)
+	// Placeholder writes the placeholder for the argument at the zero based
+	// position n.
+	Placeholder(w QueryWriter, n int)
+}
+
+// dialectByDriver returns the dialect for a driver name or nil.
+func dialectByDriver(driverName string) Dialect {
+	switch driverName {
+	case DriverNameMySQL:
+		return Mysql{}
+	case DriverNameSQLite:
+		return Sqlite{}
+	case DriverNamePostgres:
+		return Postgres{}
+	}
+	return nil
+}
+
+// Rebind rewrites a query generated by the builders, which uses back tick
+// quoted identifiers and ? placeholders, into the syntax of the dialect d.
+// Content in single or double quotes stays untouched. For MySQL the query
+// gets returned as it is.
+func Rebind(d Dialect, query string) string {
+	if _, ok := d.(Mysql); ok || d == nil {
+		return query
+	}
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+
+	n := 0
+	for pos := 0; pos < len(query); {
+		switch c := query[pos]; c {
+		case '?':
+			d.Placeholder(buf, n)
+			n++
+			pos++
+		case '`', '\'', '"':
+			end := strings.IndexByte(query[pos+1:], c)
+			if end == -1 {
+				buf.WriteString(query[pos:])
+				return buf.String()
+			}
+			if c == '`' {
+				d.EscapeIdent(buf, query[pos+1:pos+1+end])
+			} else {
+				buf.WriteString(query[pos : pos+end+2])
+			}
+			pos += end + 2
+		default:
+			buf.WriteByte(c)
+			pos++
+		}
+	}
+	return buf.String()
 }
@@ -70,3 +70,7 @@ func (Mysql) ApplyLimitAndOffset(w QueryWriter, limit, offset uint64) {
 		fmt.Fprintf(w, " OFFSET %d", offset)
 	}
 }
+
+func (Mysql) Placeholder(w QueryWriter, _ int) {
+	w.WriteRune('?')
+}
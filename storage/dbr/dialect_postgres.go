@@ -0,0 +1,76 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"strconv"
+	"time"
+)
+
+// postgresTimeFormat includes the time zone offset for the TIMESTAMPTZ type.
+const postgresTimeFormat = "2006-01-02 15:04:05.000000-07:00"
+
+// DriverNamePostgres is the name of the github.com/lib/pq driver.
+const DriverNamePostgres = "postgres"
+
+// Postgres implements the Dialect for PostgreSQL. Identifiers get quoted with
+// double quotes, strings follow the SQL standard which requires the setting
+// standard_conforming_strings=on, the default since PostgreSQL 9.1, and
+// placeholders are written as $1, $2, ...
+type Postgres struct{}
+
+// EscapeIdent quotes each dot separated part with double quotes.
+func (Postgres) EscapeIdent(w QueryWriter, ident string) {
+	escapeIdentDoubleQuote(w, ident)
+}
+
+// EscapeBool writes TRUE or FALSE.
+func (Postgres) EscapeBool(w QueryWriter, b bool) {
+	if b {
+		w.WriteString("TRUE")
+	} else {
+		w.WriteString("FALSE")
+	}
+}
+
+// EscapeString doubles single quotes. Backslashes have no special meaning.
+func (Postgres) EscapeString(w QueryWriter, s string) {
+	escapeStringStandard(w, s)
+}
+
+// EscapeTime writes the time with its zone offset.
+func (d Postgres) EscapeTime(w QueryWriter, t time.Time) {
+	d.EscapeString(w, t.Format(postgresTimeFormat))
+}
+
+// ApplyLimitAndOffset writes LIMIT ALL if there is only an offset.
+func (Postgres) ApplyLimitAndOffset(w QueryWriter, limit, offset uint64) {
+	w.WriteString(" LIMIT ")
+	if limit == 0 {
+		w.WriteString("ALL")
+	} else {
+		w.WriteString(strconv.FormatUint(limit, 10))
+	}
+	if offset > 0 {
+		w.WriteString(" OFFSET ")
+		w.WriteString(strconv.FormatUint(offset, 10))
+	}
+}
+
+// Placeholder writes $n+1.
+func (Postgres) Placeholder(w QueryWriter, n int) {
+	w.WriteRune('$')
+	w.WriteString(strconv.Itoa(n + 1))
+}
@@ -0,0 +1,90 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqliteTimeFormat is understood by the SQLite date and time functions.
+const sqliteTimeFormat = "2006-01-02 15:04:05.000000"
+
+// DriverNameSQLite is the name of the github.com/mattn/go-sqlite3 driver.
+const DriverNameSQLite = "sqlite3"
+
+// Sqlite implements the Dialect for SQLite 3. Identifiers get quoted with
+// double quotes and strings follow the SQL standard.
+type Sqlite struct{}
+
+// EscapeIdent quotes each dot separated part with double quotes.
+func (Sqlite) EscapeIdent(w QueryWriter, ident string) {
+	escapeIdentDoubleQuote(w, ident)
+}
+
+// EscapeBool writes 1 or 0, SQLite has no boolean type.
+func (Sqlite) EscapeBool(w QueryWriter, b bool) {
+	if b {
+		w.WriteRune('1')
+	} else {
+		w.WriteRune('0')
+	}
+}
+
+// EscapeString doubles single quotes. Backslashes have no special meaning.
+func (Sqlite) EscapeString(w QueryWriter, s string) {
+	escapeStringStandard(w, s)
+}
+
+// EscapeTime writes the time in UTC.
+func (d Sqlite) EscapeTime(w QueryWriter, t time.Time) {
+	d.EscapeString(w, t.UTC().Format(sqliteTimeFormat))
+}
+
+// ApplyLimitAndOffset writes LIMIT -1 if there is only an offset.
+func (Sqlite) ApplyLimitAndOffset(w QueryWriter, limit, offset uint64) {
+	w.WriteString(" LIMIT ")
+	if limit == 0 {
+		w.WriteString("-1")
+	} else {
+		w.WriteString(strconv.FormatUint(limit, 10))
+	}
+	if offset > 0 {
+		w.WriteString(" OFFSET ")
+		w.WriteString(strconv.FormatUint(offset, 10))
+	}
+}
+
+// Placeholder writes a question mark.
+func (Sqlite) Placeholder(w QueryWriter, _ int) {
+	w.WriteRune('?')
+}
+
+// escapeIdentDoubleQuote quotes an identifier according to the SQL standard.
+// `a.b` gets "a"."b".
+func escapeIdentDoubleQuote(w QueryWriter, ident string) {
+	w.WriteRune('"')
+	r := strings.NewReplacer(`"`, `""`, ".", `"."`)
+	w.WriteString(r.Replace(ident))
+	w.WriteRune('"')
+}
+
+// escapeStringStandard quotes a string according to the SQL standard.
+func escapeStringStandard(w QueryWriter, s string) {
+	w.WriteRune('\'')
+	w.WriteString(strings.Replace(s, "'", "''", -1))
+	w.WriteRune('\'')
+}
@@ -0,0 +1,118 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialectPreprocess(t *testing.T) {
+	tm := time.Date(2016, 1, 2, 3, 4, 5, 6000, time.FixedZone("CET", 3600))
+	args := []interface{}{"a'b\\c", true, tm, []string{"x", "y'"}}
+	query := "SELECT `t`.`c`, \"d\" FROM `t` WHERE `e` = ? AND f = ? AND g > ? AND h IN ? AND [i.j] = 'k'"
+
+	tests := []struct {
+		d    Dialect
+		want string
+	}{
+		{Mysql{}, "SELECT `t`.`c`, 'd' FROM `t` WHERE `e` = 'a\\'b\\\\c' AND f = 1 AND g > '2016-01-02 03:04:05' AND h IN ('x','y\\'') AND `i`.`j` = 'k'"},
+		{Sqlite{}, `SELECT "t"."c", "d" FROM "t" WHERE "e" = 'a''b\c' AND f = 1 AND g > '2016-01-02 02:04:05.000006' AND h IN ('x','y''') AND "i"."j" = 'k'`},
+		{Postgres{}, `SELECT "t"."c", "d" FROM "t" WHERE "e" = 'a''b\c' AND f = TRUE AND g > '2016-01-02 03:04:05.000006+01:00' AND h IN ('x','y''') AND "i"."j" = 'k'`},
+	}
+	for _, test := range tests {
+		have, err := preprocess(test.d, query, args)
+		assert.NoError(t, err, "%T", test.d)
+		assert.Exactly(t, test.want, have, "%T", test.d)
+	}
+}
+
+func TestDialectRebind(t *testing.T) {
+	query := "UPDATE `a` SET `b` = ?, c = 'x?`y`' WHERE (`d` IN (?,?))"
+	assert.Exactly(t, query, Rebind(Mysql{}, query))
+	assert.Exactly(t, `UPDATE "a" SET "b" = ?, c = 'x?`+"`y`"+`' WHERE ("d" IN (?,?))`, Rebind(Sqlite{}, query))
+	assert.Exactly(t, `UPDATE "a" SET "b" = $1, c = 'x?`+"`y`"+`' WHERE ("d" IN ($2,$3))`, Rebind(Postgres{}, query))
+	assert.Exactly(t, `SELECT 'unterminated ?`, Rebind(Postgres{}, `SELECT 'unterminated ?`))
+}
+
+func TestDialectLimitOffset(t *testing.T) {
+	tests := []struct {
+		d    Dialect
+		want string
+	}{
+		{Mysql{}, "SELECT a FROM `b` LIMIT 18446744073709551615 OFFSET 5"},
+		{Sqlite{}, "SELECT a FROM `b` LIMIT -1 OFFSET 5"},
+		{Postgres{}, "SELECT a FROM `b` LIMIT ALL OFFSET 5"},
+	}
+	for _, test := range tests {
+		s := createFakeSession()
+		s.cxn.dialect = test.d
+		sql, _, err := s.Select("a").From("b").Offset(5).ToSql()
+		assert.NoError(t, err)
+		assert.Exactly(t, test.want, sql, "%T", test.d)
+
+		sql, _, err = s.Select("a").From("b").Limit(2).Offset(5).ToSql()
+		assert.NoError(t, err)
+		assert.Exactly(t, "SELECT a FROM `b` LIMIT 2 OFFSET 5", sql, "%T", test.d)
+	}
+}
+
+func TestDialectConnection(t *testing.T) {
+	db, err := sql.Open("dbrstmt", "")
+	assert.NoError(t, err)
+
+	cxn, err := NewConnection(SetDB(db))
+	assert.NoError(t, err)
+	assert.Exactly(t, D, cxn.Dialect())
+
+	cxn, err = NewConnection(SetDB(db), SetDriver(DriverNameSQLite))
+	assert.NoError(t, err)
+	assert.Exactly(t, Dialect(Sqlite{}), cxn.Dialect())
+
+	cxn, err = NewConnection(SetDB(db), SetDriver(DriverNameSQLite), SetDialect(Postgres{}))
+	assert.NoError(t, err)
+	assert.Exactly(t, Dialect(Postgres{}), cxn.Dialect())
+
+	_, err = NewConnection(SetDB(db), SetDriver("oracle"))
+	assert.EqualError(t, err, "unsupported driver: oracle")
+}
+
+func TestDialectPostgresExec(t *testing.T) {
+	resetStmtStats()
+	db, err := sql.Open("dbrstmt", "")
+	assert.NoError(t, err)
+	cxn, err := NewConnection(SetDB(db), SetDialect(Postgres{}))
+	assert.NoError(t, err)
+
+	s := cxn.NewSession()
+	str, err := s.Update("a").Set("b", "c'd").Where(ConditionMap(Eq{"e": true})).String()
+	assert.NoError(t, err)
+	assert.Exactly(t, `UPDATE "a" SET "b" = 'c''d' WHERE ("e" = TRUE)`, str)
+
+	_, err = s.Update("a").Set("b", 1).Where(ConditionMap(Eq{"c": 2})).Exec()
+	assert.NoError(t, err)
+
+	s = cxn.NewSession(SetSessionStmtCache(NewStmtCache(10, time.Minute)))
+	_, err = s.Update("a").Set("b", 1).Where(ConditionMap(Eq{"c": 2})).Exec()
+	assert.NoError(t, err)
+
+	stmtStats.Lock()
+	assert.Exactly(t, []string{`UPDATE "a" SET "b" = 1 WHERE ("c" = 2)`}, stmtStats.direct)
+	assert.Exactly(t, []string{`UPDATE "a" SET "b" = $1 WHERE ("c" = $2)`}, stmtStats.prepared)
+	stmtStats.Unlock()
+}
@@ -12,6 +12,12 @@ type EventReceiver interface {
 
 type kvs map[string]string
 
+// tableKvs creates the key/value data for the events of a builder. The table
+// name is unquoted.
+func tableKvs(sql, table string) kvs {
+	return kvs{"sql": sql, "table": Quoter.unQuote(table)}
+}
+
 // NullEventReceiver is a sentinel EventReceiver; use it if the caller doesn't supply one
 type NullEventReceiver struct{}
 
@@ -16,12 +16,17 @@ import (
 type InsertBuilder struct {
 	*Session
 	runner
+	// txTables collects the changed tables when bound to a transaction.
+	txTables *txTables
 
 	Into string
 	Cols []string
 	Vals [][]interface{}
 	Recs []interface{}
 	Maps map[string]interface{}
+	// DupKeyCols columns which get updated with the new values when a
+	// unique key or primary key already exists.
+	DupKeyCols []string
 }
 
 var _ queryBuilder = (*InsertBuilder)(nil)
@@ -38,9 +43,10 @@ func (sess *Session) InsertInto(into string) *InsertBuilder {
 // InsertInto instantiates a InsertBuilder for the given table bound to a transaction
 func (tx *Tx) InsertInto(into string) *InsertBuilder {
 	return &InsertBuilder{
-		Session: tx.Session,
-		runner:  tx.Tx,
-		Into:    into,
+		Session:  tx.Session,
+		runner:   tx.Tx,
+		txTables: tx.tables,
+		Into:     into,
 	}
 }
 
@@ -74,6 +80,14 @@ func (b *InsertBuilder) Map(m map[string]interface{}) *InsertBuilder {
 	return b
 }
 
+// OnDuplicateKey appends ON DUPLICATE KEY UPDATE `column`=VALUES(`column`)
+// for each column to the statement. Rows with an already existing primary or
+// unique key get updated instead of inserted.
+func (b *InsertBuilder) OnDuplicateKey(columns ...string) *InsertBuilder {
+	b.DupKeyCols = append(b.DupKeyCols, columns...)
+	return b
+}
+
 // Pair adds a key/value pair to the statement. Uses not reflection.
 func (b *InsertBuilder) Pair(column string, value interface{}) *InsertBuilder {
 	if dbVal, ok := value.(driver.Valuer); ok {
@@ -172,6 +186,7 @@ func (b *InsertBuilder) ToSql() (string, []interface{}, error) {
 			args = append(args, v)
 		}
 	}
+	b.writeOnDuplicateKey(sql)
 
 	return sql.String(), args, nil
 }
@@ -217,10 +232,27 @@ func (b *InsertBuilder) MapToSql(sql *bytes.Buffer) (string, []interface{}, erro
 	for _, row := range vals {
 		args = append(args, row)
 	}
+	b.writeOnDuplicateKey(sql)
 
 	return sql.String(), args, nil
 }
 
+func (b *InsertBuilder) writeOnDuplicateKey(sql QueryWriter) {
+	if len(b.DupKeyCols) == 0 {
+		return
+	}
+	sql.WriteString(" ON DUPLICATE KEY UPDATE ")
+	for i, c := range b.DupKeyCols {
+		if i > 0 {
+			sql.WriteRune(',')
+		}
+		Quoter.writeQuotedColumn(c, sql)
+		sql.WriteString("=VALUES(")
+		Quoter.writeQuotedColumn(c, sql)
+		sql.WriteRune(')')
+	}
+}
+
 // Exec executes the statement represented by the InsertBuilder
 // It returns the raw database/sql Result and an error if there was one.
 // Regarding LastInsertID(): If you insert multiple rows using a single
@@ -233,19 +265,21 @@ func (b *InsertBuilder) Exec() (sql.Result, error) {
 		return nil, b.EventErrKv("dbr.insert.exec.tosql", err, nil)
 	}
 
-	fullSql, err := Preprocess(sql, args)
+	fullSql, err := b.preprocess(sql, args)
 	if err != nil {
 		return nil, b.EventErrKv("dbr.insert.exec.interpolate", err, kvs{"sql": sql, "args": fmt.Sprint(args)})
 	}
 
 	// Start the timer:
 	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.insert", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
+	defer func() { b.TimingKv("dbr.insert", time.Since(startTime).Nanoseconds(), tableKvs(fullSql, b.Into)) }()
 
-	result, err := b.runner.Exec(fullSql)
+	result, err := b.exec(b.runner, sql, args, fullSql)
 	if err != nil {
-		return result, b.EventErrKv("dbr.insert.exec.exec", err, kvs{"sql": fullSql})
+		return result, b.EventErrKv("dbr.insert.exec.exec", err, tableKvs(fullSql, b.Into))
 	}
+	b.markWrite()
+	b.invalidateCache(b.txTables, b.Into)
 
 	// If the structure has an "Id" field which is an int64, set it from the LastInsertId(). Otherwise, don't bother.
 	if len(b.Recs) == 1 {
@@ -257,7 +291,7 @@ func (b *InsertBuilder) Exec() (sql.Result, error) {
 				if lastID, err := result.LastInsertId(); err == nil {
 					idField.Set(reflect.ValueOf(lastID))
 				} else {
-					b.EventErrKv("dbr.insert.exec.last_inserted_id", err, kvs{"sql": fullSql})
+					b.EventErrKv("dbr.insert.exec.last_inserted_id", err, tableKvs(fullSql, b.Into))
 				}
 			}
 		}
@@ -64,6 +64,20 @@ func TestInsertRecordsToSql(t *testing.T) {
 	assert.Equal(t, fmt.Sprint(args), fmt.Sprint([]interface{}{1, 88, false, 2, 99, true}))
 }
 
+func TestInsertOnDuplicateKeyToSql(t *testing.T) {
+	s := createFakeSession()
+
+	sql, args, err := s.InsertInto("a").Columns("b", "c", "d").Values(1, 2, 3).OnDuplicateKey("c", "d").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO a (`b`,`c`,`d`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `c`=VALUES(`c`),`d`=VALUES(`d`)", sql)
+	assert.Equal(t, []interface{}{1, 2, 3}, args)
+
+	sql, args, err = s.InsertInto("a").Map(map[string]interface{}{"b": 1}).OnDuplicateKey("b").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO a (`b`) VALUES (?) ON DUPLICATE KEY UPDATE `b`=VALUES(`b`)", sql)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
 func TestInsertKeywordColumnName(t *testing.T) {
 	// Insert a column whose name is reserved
 	s := createRealSessionWithFixtures()
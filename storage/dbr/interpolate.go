@@ -48,6 +48,13 @@ var typeOfTime = reflect.TypeOf(time.Time{})
 // replace them with. It returns a blank string and error if the number of placeholders
 // does not match the number of arguments.
 func Preprocess(sql string, vals []interface{}) (string, error) {
+	return preprocess(D, sql, vals)
+}
+
+// preprocess same as Preprocess but escapes the arguments and identifiers
+// with dialect d. For dialects other than MySQL back tick quoted identifiers
+// get rewritten and double quotes are kept as identifiers.
+func preprocess(d Dialect, sql string, vals []interface{}) (string, error) {
 	// Get the number of arguments to add to this query
 	if sql == "" {
 		if len(vals) != 0 {
@@ -56,6 +63,7 @@ func Preprocess(sql string, vals []interface{}) (string, error) {
 		return "", nil
 	}
 
+	_, isMysql := d.(Mysql)
 	curVal := 0
 	var buf = bufferpool.Get()
 	defer bufferpool.Put(buf)
@@ -70,7 +78,7 @@ func Preprocess(sql string, vals []interface{}) (string, error) {
 			if curVal >= len(vals) {
 				return "", ErrArgumentMismatch
 			}
-			if err := interpolate(buf, vals[curVal]); err != nil {
+			if err := interpolate(d, buf, vals[curVal]); err != nil {
 				return "", err
 			}
 			curVal++
@@ -79,17 +87,22 @@ func Preprocess(sql string, vals []interface{}) (string, error) {
 			if p == -1 {
 				return "", ErrInvalidSyntax
 			}
-			if r == '"' {
+			switch {
+			case r == '`' && !isMysql:
+				d.EscapeIdent(buf, sql[pos:pos+p])
+			case r == '"' && isMysql:
 				r = '\''
+				fallthrough
+			default:
+				buf.WriteRune(r)
+				buf.WriteString(sql[pos : pos+p])
+				buf.WriteRune(r)
 			}
-			buf.WriteRune(r)
-			buf.WriteString(sql[pos : pos+p])
-			buf.WriteRune(r)
 			pos += p + 1
 		case r == '[':
 			w := strings.IndexRune(sql[pos:], ']')
 			col := sql[pos : pos+w]
-			D.EscapeIdent(buf, col)
+			d.EscapeIdent(buf, col)
 			pos += w + 1 // size of ']'
 		default:
 			buf.WriteRune(r)
@@ -102,7 +115,7 @@ func Preprocess(sql string, vals []interface{}) (string, error) {
 	return buf.String(), nil
 }
 
-func interpolate(w QueryWriter, v interface{}) error {
+func interpolate(d Dialect, w QueryWriter, v interface{}) error {
 	valuer, ok := v.(driver.Valuer)
 	if ok {
 		val, err := valuer.Value()
@@ -132,17 +145,17 @@ func interpolate(w QueryWriter, v interface{}) error {
 		if !utf8.ValidString(str) {
 			return ErrNotUTF8
 		}
-		D.EscapeString(w, str)
+		d.EscapeString(w, str)
 	case isFloat(kindOfV):
 		var fval = valueOfV.Float()
 
 		w.WriteString(strconv.FormatFloat(fval, 'f', -1, 64))
 	case kindOfV == reflect.Bool:
-		D.EscapeBool(w, valueOfV.Bool())
+		d.EscapeBool(w, valueOfV.Bool())
 	case kindOfV == reflect.Struct:
 		if typeOfV := valueOfV.Type(); typeOfV == typeOfTime {
 			t := valueOfV.Interface().(time.Time)
-			D.EscapeTime(w, t)
+			d.EscapeTime(w, t)
 		} else {
 			return ErrInvalidValue
 		}
@@ -174,7 +187,7 @@ func interpolate(w QueryWriter, v interface{}) error {
 					return ErrNotUTF8
 				}
 				var buf = bufferpool.Get()
-				D.EscapeString(buf, str)
+				d.EscapeString(buf, str)
 				stringSlice = append(stringSlice, buf.String())
 				bufferpool.Put(buf)
 			}
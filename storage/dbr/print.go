@@ -4,6 +4,7 @@ import "fmt"
 
 type queryBuilder interface {
 	ToSql() (string, []interface{}, error)
+	preprocess(sql string, vals []interface{}) (string, error)
 	EventReceiver
 }
 
@@ -12,7 +13,7 @@ func makeSql(b queryBuilder) (string, error) {
 	if err != nil {
 		return "", b.EventErrKv("dbr.makeSql.tosql", err, nil)
 	}
-	sql, err := Preprocess(sRaw, vals)
+	sql, err := b.preprocess(sRaw, vals)
 	if err != nil {
 		return "", b.EventErrKv("dbr.makeSql.string", err, kvs{"sql": sRaw, "args": fmt.Sprint(vals)})
 	}
@@ -17,6 +17,22 @@ func (q MysqlQuoter) writeQuotedColumn(column string, sql QueryWriter) {
 	_, _ = sql.WriteRune(quoteRune)
 }
 
+// writeQuotedIdentifier writes a column name with an optional table name or
+// alias without allocating. Already quoted identifiers and expressions like
+// COUNT(*) get written as they are. `table`.`column`
+func (q MysqlQuoter) writeQuotedIdentifier(ident string, sql QueryWriter) {
+	if strings.ContainsAny(ident, quote+"(") {
+		_, _ = sql.WriteString(ident)
+		return
+	}
+	if dotIndex := strings.IndexByte(ident, '.'); dotIndex > 0 {
+		q.writeQuotedColumn(ident[:dotIndex], sql)
+		_, _ = sql.WriteRune('.')
+		ident = ident[dotIndex+1:]
+	}
+	q.writeQuotedColumn(ident, sql)
+}
+
 func (q MysqlQuoter) unQuote(s string) string {
 	if !strings.ContainsRune(s, quoteRune) {
 		return s
@@ -42,8 +58,8 @@ func (q MysqlQuoter) quoteAs(parts ...string) string {
 	hasDot0 := strings.ContainsRune(parts[0], '.')
 
 	switch {
-	case lp == 1 && hasQuote0:
-		return parts[0] // already quoted
+	case lp == 1 && hasQuote0, lp == 2 && parts[1] == "" && hasQuote0:
+		return parts[0] // already quoted and no alias
 	case lp > 1 && parts[1] == "" && !hasQuote0 && !hasDot0:
 		return quote + q.unQuote(parts[0]) + quote // must be quoted
 	case lp == 1 && !hasQuote0 && hasDot0:
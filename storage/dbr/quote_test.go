@@ -14,6 +14,7 @@ func TestQuoteAs(t *testing.T) {
 		{[]string{"a"}, "`a`"},
 		{[]string{"a", "b"}, "`a` AS `b`"},
 		{[]string{"`c`"}, "`c`"},
+		{[]string{"`c`", ""}, "`c`"},
 		{[]string{"d.e"}, "`d`.`e`"},
 		{[]string{"`d`.`e`"}, "`d`.`e`"},
 		{[]string{"f", "g", "h"}, "`f` AS `g_h`"},
@@ -0,0 +1,285 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"database/sql"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReplicaBalancing defines how SELECT queries gets distributed to the
+// replicas.
+type ReplicaBalancing uint8
+
+const (
+	// ReplicaRoundRobin picks each healthy replica in turn.
+	ReplicaRoundRobin ReplicaBalancing = iota
+	// ReplicaWeighted picks a healthy replica in proportion to its weight.
+	ReplicaWeighted
+)
+
+// replica a read only database connection
+type replica struct {
+	db     *sql.DB
+	dsn    string
+	weight uint64
+	// healthy 1 means replica can be used, 0 the last ping failed.
+	healthy uint32
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadUint32(&r.healthy) == 1
+}
+
+// replicaSet contains all replicas of a connection.
+type replicaSet struct {
+	balancing ReplicaBalancing
+	replicas  []*replica
+	counter   uint64 // incremented atomically for each pick
+
+	mu           sync.Mutex // protects the health checker
+	checkStarted bool
+	stop         chan struct{}
+}
+
+// SetReplicaDB adds an already opened read replica to a connection. The
+// weight will only be considered with balancing ReplicaWeighted and must be
+// at least 1. The DB set via SetDB or SetDSN becomes the primary.
+func SetReplicaDB(db *sql.DB, weight uint64) ConnectionOption {
+	if db == nil {
+		panic("Replica DB argument cannot be nil")
+	}
+	return func(c *Connection) {
+		c.replicas.add(&replica{db: db, weight: weight})
+	}
+}
+
+// SetReplicaDSN adds a read replica by its data source name to a connection.
+// The connection will be opened in NewConnection. See SetReplicaDB.
+func SetReplicaDSN(dsn string, weight uint64) ConnectionOption {
+	if dsn == "" {
+		panic("Replica DSN argument cannot be empty")
+	}
+	return func(c *Connection) {
+		c.replicas.add(&replica{dsn: dsn, weight: weight})
+	}
+}
+
+// SetReplicaBalancing sets the algorithm to choose a replica. Default
+// ReplicaRoundRobin.
+func SetReplicaBalancing(b ReplicaBalancing) ConnectionOption {
+	return func(c *Connection) {
+		c.replicas.balancing = b
+	}
+}
+
+func (rs *replicaSet) add(r *replica) {
+	if r.weight < 1 {
+		r.weight = 1
+	}
+	r.healthy = 1
+	rs.replicas = append(rs.replicas, r)
+}
+
+// open opens all replicas which have only been added by their DSN.
+func (rs *replicaSet) open(driverName string) error {
+	for _, r := range rs.replicas {
+		if r.db != nil {
+			continue
+		}
+		var err error
+		if r.db, err = sql.Open(driverName, r.dsn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pick returns a healthy replica or nil if there are no replicas or none of
+// them is healthy.
+func (rs *replicaSet) pick() *sql.DB {
+	if len(rs.replicas) == 0 {
+		return nil
+	}
+	n := atomic.AddUint64(&rs.counter, 1) - 1
+
+	if rs.balancing == ReplicaWeighted {
+		var total uint64
+		for _, r := range rs.replicas {
+			if r.isHealthy() {
+				total += r.weight
+			}
+		}
+		if total == 0 {
+			return nil
+		}
+		n %= total
+		for _, r := range rs.replicas {
+			if !r.isHealthy() {
+				continue
+			}
+			if n < r.weight {
+				return r.db
+			}
+			n -= r.weight
+		}
+		return nil
+	}
+
+	l := uint64(len(rs.replicas))
+	for i := uint64(0); i < l; i++ {
+		if r := rs.replicas[(n+i)%l]; r.isHealthy() {
+			return r.db
+		}
+	}
+	return nil
+}
+
+// readDB returns a healthy replica or the primary DB if there are no
+// replicas or none of them is healthy.
+func (c *Connection) readDB() *sql.DB {
+	if db := c.replicas.pick(); db != nil {
+		return db
+	}
+	return c.DB
+}
+
+// HasReplicas returns true if at least one read replica has been configured.
+func (c *Connection) HasReplicas() bool {
+	return len(c.replicas.replicas) > 0
+}
+
+// PingReplicas pings all replicas and marks them as healthy or unhealthy.
+// Unhealthy replicas will be skipped until a later ping succeeds. Returns
+// the number of healthy replicas.
+func (c *Connection) PingReplicas() int {
+	healthy := 0
+	for i, r := range c.replicas.replicas {
+		if err := r.db.Ping(); err != nil {
+			if atomic.SwapUint32(&r.healthy, 0) == 1 {
+				_ = c.EventErrKv("dbr.connection.replica.unhealthy", err, kvs{"replica": strconv.Itoa(i)})
+			}
+			continue
+		}
+		if atomic.SwapUint32(&r.healthy, 1) == 0 {
+			c.EventKv("dbr.connection.replica.healthy", kvs{"replica": strconv.Itoa(i)})
+		}
+		healthy++
+	}
+	return healthy
+}
+
+// StartReplicaHealthCheck starts a goroutine which pings all replicas within
+// the interval. You can only start it once.
+func (c *Connection) StartReplicaHealthCheck(interval time.Duration) {
+	rs := &c.replicas
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.checkStarted || len(rs.replicas) == 0 {
+		return
+	}
+	rs.checkStarted = true
+	rs.stop = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.PingReplicas()
+			case <-stop:
+				return
+			}
+		}
+	}(rs.stop)
+}
+
+// StopReplicaHealthCheck stops the health check goroutine if it's running.
+func (c *Connection) StopReplicaHealthCheck() {
+	rs := &c.replicas
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.checkStarted {
+		close(rs.stop)
+	}
+	rs.checkStarted = false
+}
+
+// closeReplicas stops the health check and closes all replica connections.
+// Returns the first error.
+func (c *Connection) closeReplicas() error {
+	c.StopReplicaHealthCheck()
+	var err error
+	for _, r := range c.replicas.replicas {
+		if r.db == nil {
+			continue // never opened
+		}
+		if cErr := r.db.Close(); cErr != nil && err == nil {
+			err = cErr
+		}
+	}
+	return err
+}
+
+// readRunner routes all queries of a session to a replica as long as the
+// session has not written any data. Exec always uses the primary.
+type readRunner struct {
+	sess *Session
+}
+
+func (r readRunner) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return r.sess.cxn.DB.Exec(query, args...)
+}
+
+func (r readRunner) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	if r.sess.isPrimaryForced() {
+		return r.sess.cxn.DB.Query(query, args...)
+	}
+	return r.sess.cxn.readDB().Query(query, args...)
+}
+
+// selectRunner returns the runner for SELECT queries outside of a
+// transaction.
+func (sess *Session) selectRunner() runner {
+	if !sess.cxn.HasReplicas() {
+		return sess.cxn.DB
+	}
+	return readRunner{sess: sess}
+}
+
+// markWrite forces all following SELECT queries of this session to the
+// primary so that the session can read its own writes.
+func (sess *Session) markWrite() {
+	atomic.StoreUint32(&sess.primaryForced, 1)
+}
+
+func (sess *Session) isPrimaryForced() bool {
+	return atomic.LoadUint32(&sess.primaryForced) == 1
+}
+
+// SetSessionPrimary forces all queries of a session to the primary
+// database connection, even if replicas are available.
+func SetSessionPrimary() SessionOption {
+	return func(cxn *Connection, s *Session) SessionOption {
+		previous := atomic.SwapUint32(&s.primaryForced, 1)
+		return func(cxn *Connection, s *Session) SessionOption {
+			atomic.StoreUint32(&s.primaryForced, previous)
+			return SetSessionPrimary()
+		}
+	}
+}
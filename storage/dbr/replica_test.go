@@ -0,0 +1,196 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// routeDriver is a database/sql driver which returns an error containing the
+// DSN for each query. So we can see which connection has been used. Ping
+// fails for all DSN marked as down.
+type routeDriver struct{}
+
+type routeConn struct{ dsn string }
+
+var routeDownMu sync.Mutex
+var routeDown = map[string]bool{}
+
+func setRouteDown(dsn string, down bool) {
+	routeDownMu.Lock()
+	routeDown[dsn] = down
+	routeDownMu.Unlock()
+}
+
+func init() {
+	sql.Register("dbrroute", routeDriver{})
+}
+
+func (routeDriver) Open(dsn string) (driver.Conn, error) { return routeConn{dsn: dsn}, nil }
+
+func (c routeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare " + c.dsn)
+}
+func (c routeConn) Close() error {
+	if strings.HasPrefix(c.dsn, "closeErr") {
+		return errors.New("close " + c.dsn)
+	}
+	return nil
+}
+func (c routeConn) Begin() (driver.Tx, error) { return nil, errors.New("begin " + c.dsn) }
+func (c routeConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("query " + c.dsn)
+}
+func (c routeConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (c routeConn) Ping(_ context.Context) error {
+	routeDownMu.Lock()
+	defer routeDownMu.Unlock()
+	if routeDown[c.dsn] {
+		return errors.New("ping " + c.dsn)
+	}
+	return nil
+}
+
+func mustOpenRoute(dsn string) *sql.DB {
+	db, err := sql.Open("dbrroute", dsn)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+func routedTo(t *testing.T, sel *SelectBuilder) string {
+	_, err := sel.ReturnInt64()
+	if !assert.Error(t, err) {
+		return ""
+	}
+	return strings.TrimPrefix(err.Error(), "query ")
+}
+
+func TestReplicaRoundRobin(t *testing.T) {
+	cxn, err := NewConnection(
+		SetDB(mustOpenRoute("primary")),
+		SetReplicaDB(mustOpenRoute("replica1"), 1),
+		SetReplicaDB(mustOpenRoute("replica2"), 1),
+	)
+	assert.NoError(t, err)
+	defer cxn.Close()
+	assert.True(t, cxn.HasReplicas())
+
+	s := cxn.NewSession()
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, routedTo(t, s.Select("a").From("b")))
+	}
+	assert.Exactly(t, []string{"replica1", "replica2", "replica1", "replica2"}, got)
+}
+
+func TestReplicaWeighted(t *testing.T) {
+	cxn, err := NewConnection(
+		SetDB(mustOpenRoute("primary")),
+		SetReplicaDB(mustOpenRoute("replica1"), 3),
+		SetReplicaDB(mustOpenRoute("replica2"), 1),
+		SetReplicaBalancing(ReplicaWeighted),
+	)
+	assert.NoError(t, err)
+	defer cxn.Close()
+
+	s := cxn.NewSession()
+	count := map[string]int{}
+	for i := 0; i < 8; i++ {
+		count[routedTo(t, s.SelectBySql("SELECT 1"))]++
+	}
+	assert.Exactly(t, map[string]int{"replica1": 6, "replica2": 2}, count)
+}
+
+func TestReplicaUnhealthySkipped(t *testing.T) {
+	cxn, err := NewConnection(
+		SetDB(mustOpenRoute("primary")),
+		SetReplicaDB(mustOpenRoute("replicaDown"), 1),
+		SetReplicaDB(mustOpenRoute("replicaUp"), 1),
+	)
+	assert.NoError(t, err)
+	defer cxn.Close()
+
+	setRouteDown("replicaDown", true)
+	defer setRouteDown("replicaDown", false)
+	assert.Exactly(t, 1, cxn.PingReplicas())
+
+	s := cxn.NewSession()
+	for i := 0; i < 3; i++ {
+		assert.Exactly(t, "replicaUp", routedTo(t, s.Select("a").From("b")))
+	}
+
+	setRouteDown("replicaUp", true)
+	defer setRouteDown("replicaUp", false)
+	assert.Exactly(t, 0, cxn.PingReplicas())
+	assert.Exactly(t, "primary", routedTo(t, s.Select("a").From("b")), "Falls back to primary")
+}
+
+func TestReplicaPrimaryAfterWrite(t *testing.T) {
+	cxn, err := NewConnection(
+		SetDB(mustOpenRoute("primary")),
+		SetReplicaDB(mustOpenRoute("replica1"), 1),
+	)
+	assert.NoError(t, err)
+	defer cxn.Close()
+
+	s := cxn.NewSession()
+	sel := s.Select("a").From("b")
+	assert.Exactly(t, "replica1", routedTo(t, sel))
+
+	_, err = s.Update("b").Set("a", 1).Exec()
+	assert.NoError(t, err)
+	assert.Exactly(t, "primary", routedTo(t, sel), "Must read own writes")
+
+	s2 := cxn.NewSession(SetSessionPrimary())
+	assert.Exactly(t, "primary", routedTo(t, s2.Select("a").From("b")))
+}
+
+func TestReplicaCloseClosesPrimary(t *testing.T) {
+	primary := mustOpenRoute("primary")
+	replica := mustOpenRoute("closeErrReplica")
+	assert.NoError(t, replica.Ping()) // puts a connection into the pool
+	cxn, err := NewConnection(SetDB(primary), SetReplicaDB(replica, 1))
+	assert.NoError(t, err)
+
+	assert.EqualError(t, cxn.Close(), "close closeErrReplica")
+	assert.EqualError(t, primary.Ping(), "sql: database is closed", "Primary must be closed despite the replica error")
+}
+
+func TestReplicaOpenErrorClosesPrimary(t *testing.T) {
+	primary := mustOpenRoute("primary")
+	replica := mustOpenRoute("replica1")
+	cxn, err := NewConnection(
+		SetDriver(DriverNamePostgres), // driver is not registered so the replica DSN cannot be opened
+		SetDB(primary),
+		SetReplicaDB(replica, 1),
+		SetReplicaDSN("replica2", 1),
+	)
+	assert.Nil(t, cxn)
+	assert.EqualError(t, err, `sql: unknown driver "postgres" (forgotten import?)`)
+	assert.EqualError(t, primary.Ping(), "sql: database is closed")
+	assert.EqualError(t, replica.Ping(), "sql: database is closed")
+}
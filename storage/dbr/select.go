@@ -2,6 +2,7 @@ package dbr
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/corestoreio/csfw/util/bufferpool"
 )
@@ -10,6 +11,8 @@ import (
 type SelectBuilder struct {
 	*Session
 	runner
+	// txTables is set when bound to a transaction, the cache gets bypassed.
+	txTables *txTables
 
 	RawFullSql   string
 	RawArguments []interface{}
@@ -26,6 +29,11 @@ type SelectBuilder struct {
 	LimitValid      bool
 	OffsetCount     uint64
 	OffsetValid     bool
+
+	// cache settings, see Cache()
+	cacheEnabled bool
+	cacheTTL     time.Duration
+	cacheTags    []string
 }
 
 var _ queryBuilder = (*SelectBuilder)(nil)
@@ -34,7 +42,7 @@ var _ queryBuilder = (*SelectBuilder)(nil)
 func (sess *Session) Select(cols ...string) *SelectBuilder {
 	return &SelectBuilder{
 		Session: sess,
-		runner:  sess.cxn.DB,
+		runner:  sess.selectRunner(),
 		Columns: cols,
 	}
 }
@@ -43,7 +51,7 @@ func (sess *Session) Select(cols ...string) *SelectBuilder {
 func (sess *Session) SelectBySql(sql string, args ...interface{}) *SelectBuilder {
 	return &SelectBuilder{
 		Session:      sess,
-		runner:       sess.cxn.DB,
+		runner:       sess.selectRunner(),
 		RawFullSql:   sql,
 		RawArguments: args,
 	}
@@ -52,9 +60,10 @@ func (sess *Session) SelectBySql(sql string, args ...interface{}) *SelectBuilder
 // Select creates a new SelectBuilder that select that given columns bound to the transaction
 func (tx *Tx) Select(cols ...string) *SelectBuilder {
 	return &SelectBuilder{
-		Session: tx.Session,
-		runner:  tx.Tx,
-		Columns: cols,
+		Session:  tx.Session,
+		runner:   tx.Tx,
+		txTables: tx.tables,
+		Columns:  cols,
 	}
 }
 
@@ -63,6 +72,7 @@ func (tx *Tx) SelectBySql(sql string, args ...interface{}) *SelectBuilder {
 	return &SelectBuilder{
 		Session:      tx.Session,
 		runner:       tx.Tx,
+		txTables:     tx.tables,
 		RawFullSql:   sql,
 		RawArguments: args,
 	}
@@ -223,14 +233,17 @@ func (b *SelectBuilder) ToSql() (string, []interface{}, error) {
 		}
 	}
 
-	if b.LimitValid {
+	switch {
+	case b.LimitValid:
 		sql.WriteString(" LIMIT ")
 		fmt.Fprint(sql, b.LimitCount)
-	}
-
-	if b.OffsetValid {
-		sql.WriteString(" OFFSET ")
-		fmt.Fprint(sql, b.OffsetCount)
+		if b.OffsetValid {
+			sql.WriteString(" OFFSET ")
+			fmt.Fprint(sql, b.OffsetCount)
+		}
+	case b.OffsetValid:
+		// an OFFSET without a LIMIT requires a dialect specific LIMIT
+		b.dialect().ApplyLimitAndOffset(sql, 0, b.OffsetCount)
 	}
 	return sql.String(), args, nil
 }
@@ -55,34 +55,45 @@ func (b *SelectBuilder) LoadStructs(dest interface{}) (int, error) {
 		return 0, b.EventErr("dbr.select.load_structs.tosql", err)
 	}
 
-	fullSql, err := Preprocess(tSQL, tArg)
+	fullSql, err := b.preprocess(tSQL, tArg)
 	if err != nil {
 		return 0, b.EventErr("dbr.select.load_all.interpolate", err)
 	}
 
 	numberOfRowsReturned := 0
 
+	useCache := b.useCache()
+	if useCache {
+		if cv, ok := b.cacheLoad(fullSql, valueOfDest.Type()); ok {
+			valueOfDest.Set(reflect.AppendSlice(valueOfDest, copyValue(cv)))
+			return cv.Len(), nil
+		}
+	}
+	lenDestBefore := valueOfDest.Len()
+
 	// Start the timer:
 	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.select", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
+	defer func() {
+		b.TimingKv("dbr.select", time.Since(startTime).Nanoseconds(), tableKvs(fullSql, b.FromTable.Expression))
+	}()
 
 	// Run the query:
-	rows, err := b.runner.Query(fullSql)
+	rows, err := b.query(b.runner, tSQL, tArg, fullSql)
 	if err != nil {
-		return 0, b.EventErrKv("dbr.select.load_all.query", err, kvs{"sql": fullSql})
+		return 0, b.EventErrKv("dbr.select.load_all.query", err, tableKvs(fullSql, b.FromTable.Expression))
 	}
 	defer rows.Close()
 
 	// Get the columns returned
 	columns, err := rows.Columns()
 	if err != nil {
-		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_one.rows.Columns", err, kvs{"sql": fullSql})
+		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_one.rows.Columns", err, tableKvs(fullSql, b.FromTable.Expression))
 	}
 
 	// Create a map of this result set to the struct fields
 	fieldMap, err := b.calculateFieldMap(recordType, columns, false)
 	if err != nil {
-		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all.calculateFieldMap", err, kvs{"sql": fullSql})
+		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all.calculateFieldMap", err, tableKvs(fullSql, b.FromTable.Expression))
 	}
 
 	// Build a 'holder', which is an []interface{}. Each value will be the set to address of the field corresponding to our newly made records:
@@ -98,13 +109,13 @@ func (b *SelectBuilder) LoadStructs(dest interface{}) (int, error) {
 		// Prepare the holder for this record
 		scannable, err := b.prepareHolderFor(newRecord, fieldMap, holder)
 		if err != nil {
-			return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all.holderFor", err, kvs{"sql": fullSql})
+			return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all.holderFor", err, tableKvs(fullSql, b.FromTable.Expression))
 		}
 
 		// Load up our new structure with the row's values
 		err = rows.Scan(scannable...)
 		if err != nil {
-			return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all.scan", err, kvs{"sql": fullSql})
+			return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all.scan", err, tableKvs(fullSql, b.FromTable.Expression))
 		}
 
 		// Append our new record to the slice:
@@ -116,7 +127,11 @@ func (b *SelectBuilder) LoadStructs(dest interface{}) (int, error) {
 
 	// Check for errors at the end. Supposedly these are error that can happen during iteration.
 	if err = rows.Err(); err != nil {
-		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all.rows_err", err, kvs{"sql": fullSql})
+		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all.rows_err", err, tableKvs(fullSql, b.FromTable.Expression))
+	}
+
+	if useCache {
+		b.cacheStore(fullSql, valueOfDest.Slice(lenDestBefore, valueOfDest.Len()))
 	}
 
 	return numberOfRowsReturned, nil
@@ -147,32 +162,42 @@ func (b *SelectBuilder) LoadStruct(dest interface{}) error {
 		return b.EventErr("dbr.select.load_struct.tosql", err)
 	}
 
-	fullSql, err := Preprocess(tSQL, tArg)
+	fullSql, err := b.preprocess(tSQL, tArg)
 	if err != nil {
 		return err
 	}
 
+	useCache := b.useCache()
+	if useCache {
+		if cv, ok := b.cacheLoad(fullSql, recordType); ok {
+			indirectOfDest.Set(cv)
+			return nil
+		}
+	}
+
 	// Start the timer:
 	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.select", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
+	defer func() {
+		b.TimingKv("dbr.select", time.Since(startTime).Nanoseconds(), tableKvs(fullSql, b.FromTable.Expression))
+	}()
 
 	// Run the query:
-	rows, err := b.runner.Query(fullSql)
+	rows, err := b.query(b.runner, tSQL, tArg, fullSql)
 	if err != nil {
-		return b.EventErrKv("dbr.select.load_one.query", err, kvs{"sql": fullSql})
+		return b.EventErrKv("dbr.select.load_one.query", err, tableKvs(fullSql, b.FromTable.Expression))
 	}
 	defer rows.Close()
 
 	// Get the columns of this result set
 	columns, err := rows.Columns()
 	if err != nil {
-		return b.EventErrKv("dbr.select.load_one.rows.Columns", err, kvs{"sql": fullSql})
+		return b.EventErrKv("dbr.select.load_one.rows.Columns", err, tableKvs(fullSql, b.FromTable.Expression))
 	}
 
 	// Create a map of this result set to the struct columns
 	fieldMap, err := b.calculateFieldMap(recordType, columns, false)
 	if err != nil {
-		return b.EventErrKv("dbr.select.load_one.calculateFieldMap", err, kvs{"sql": fullSql})
+		return b.EventErrKv("dbr.select.load_one.calculateFieldMap", err, tableKvs(fullSql, b.FromTable.Expression))
 	}
 
 	// Build a 'holder', which is an []interface{}. Each value will be the set to address of the field corresponding to our newly made records:
@@ -182,19 +207,22 @@ func (b *SelectBuilder) LoadStruct(dest interface{}) error {
 		// Build a 'holder', which is an []interface{}. Each value will be the address of the field corresponding to our newly made record:
 		scannable, err := b.prepareHolderFor(indirectOfDest, fieldMap, holder)
 		if err != nil {
-			return b.EventErrKv("dbr.select.load_one.holderFor", err, kvs{"sql": fullSql})
+			return b.EventErrKv("dbr.select.load_one.holderFor", err, tableKvs(fullSql, b.FromTable.Expression))
 		}
 
 		// Load up our new structure with the row's values
 		err = rows.Scan(scannable...)
 		if err != nil {
-			return b.EventErrKv("dbr.select.load_one.scan", err, kvs{"sql": fullSql})
+			return b.EventErrKv("dbr.select.load_one.scan", err, tableKvs(fullSql, b.FromTable.Expression))
+		}
+		if useCache {
+			b.cacheStore(fullSql, indirectOfDest)
 		}
 		return nil
 	}
 
 	if err := rows.Err(); err != nil {
-		return b.EventErrKv("dbr.select.load_one.rows_err", err, kvs{"sql": fullSql})
+		return b.EventErrKv("dbr.select.load_one.rows_err", err, tableKvs(fullSql, b.FromTable.Expression))
 	}
 
 	return ErrNotFound
@@ -236,21 +264,32 @@ func (b *SelectBuilder) LoadValues(dest interface{}) (int, error) {
 		return 0, b.EventErr("dbr.select.load_values.tosql", err)
 	}
 
-	fullSql, err := Preprocess(tSQL, tArg)
+	fullSql, err := b.preprocess(tSQL, tArg)
 	if err != nil {
 		return 0, err
 	}
 
 	numberOfRowsReturned := 0
 
+	useCache := b.useCache()
+	if useCache {
+		if cv, ok := b.cacheLoad(fullSql, valueOfDest.Type()); ok {
+			valueOfDest.Set(reflect.AppendSlice(valueOfDest, copyValue(cv)))
+			return cv.Len(), nil
+		}
+	}
+	lenDestBefore := valueOfDest.Len()
+
 	// Start the timer:
 	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.select", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
+	defer func() {
+		b.TimingKv("dbr.select", time.Since(startTime).Nanoseconds(), tableKvs(fullSql, b.FromTable.Expression))
+	}()
 
 	// Run the query:
-	rows, err := b.runner.Query(fullSql)
+	rows, err := b.query(b.runner, tSQL, tArg, fullSql)
 	if err != nil {
-		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all_values.query", err, kvs{"sql": fullSql})
+		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all_values.query", err, tableKvs(fullSql, b.FromTable.Expression))
 	}
 	defer rows.Close()
 
@@ -262,7 +301,7 @@ func (b *SelectBuilder) LoadValues(dest interface{}) (int, error) {
 
 		err = rows.Scan(pointerToNewValue.Interface())
 		if err != nil {
-			return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all_values.scan", err, kvs{"sql": fullSql})
+			return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all_values.scan", err, tableKvs(fullSql, b.FromTable.Expression))
 		}
 
 		// Append our new value to the slice:
@@ -273,7 +312,11 @@ func (b *SelectBuilder) LoadValues(dest interface{}) (int, error) {
 	valueOfDest.Set(sliceValue)
 
 	if err := rows.Err(); err != nil {
-		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all_values.rows_err", err, kvs{"sql": fullSql})
+		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all_values.rows_err", err, tableKvs(fullSql, b.FromTable.Expression))
+	}
+
+	if useCache {
+		b.cacheStore(fullSql, valueOfDest.Slice(lenDestBefore, valueOfDest.Len()))
 	}
 
 	return numberOfRowsReturned, nil
@@ -298,32 +341,45 @@ func (b *SelectBuilder) LoadValue(dest interface{}) error {
 		return b.EventErr("dbr.select.load_value.tosql", err)
 	}
 
-	fullSql, err := Preprocess(tSQL, tArg)
+	fullSql, err := b.preprocess(tSQL, tArg)
 	if err != nil {
 		return err
 	}
 
+	useCache := b.useCache()
+	if useCache {
+		if cv, ok := b.cacheLoad(fullSql, valueOfDest.Elem().Type()); ok {
+			valueOfDest.Elem().Set(cv)
+			return nil
+		}
+	}
+
 	// Start the timer:
 	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.select", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
+	defer func() {
+		b.TimingKv("dbr.select", time.Since(startTime).Nanoseconds(), tableKvs(fullSql, b.FromTable.Expression))
+	}()
 
 	// Run the query:
-	rows, err := b.runner.Query(fullSql)
+	rows, err := b.query(b.runner, tSQL, tArg, fullSql)
 	if err != nil {
-		return b.EventErrKv("dbr.select.load_value.query", err, kvs{"sql": fullSql})
+		return b.EventErrKv("dbr.select.load_value.query", err, tableKvs(fullSql, b.FromTable.Expression))
 	}
 	defer rows.Close()
 
 	if rows.Next() {
 		err = rows.Scan(dest)
 		if err != nil {
-			return b.EventErrKv("dbr.select.load_value.scan", err, kvs{"sql": fullSql})
+			return b.EventErrKv("dbr.select.load_value.scan", err, tableKvs(fullSql, b.FromTable.Expression))
+		}
+		if useCache {
+			b.cacheStore(fullSql, valueOfDest.Elem())
 		}
 		return nil
 	}
 
 	if err := rows.Err(); err != nil {
-		return b.EventErrKv("dbr.select.load_value.rows_err", err, kvs{"sql": fullSql})
+		return b.EventErrKv("dbr.select.load_value.rows_err", err, tableKvs(fullSql, b.FromTable.Expression))
 	}
 
 	return ErrNotFound
@@ -0,0 +1,318 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/corestoreio/csfw/util/log"
+	"github.com/corestoreio/csfw/util/sqlbeautifier"
+)
+
+// DefaultSlowQueryThreshold queries running longer than this duration get
+// logged by a SlowQueryLog created with NewSlowQueryLog.
+var DefaultSlowQueryThreshold = time.Millisecond * 100
+
+// Queryer runs a query and returns rows. Implemented by *sql.DB and *sql.Tx.
+type Queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// SlowQueryLog is an EventReceiver which logs all queries of the SELECT,
+// INSERT, UPDATE and DELETE builders running longer than the Threshold. The
+// Info log entry contains the duration, the table, the caller outside of
+// this package, the pretty printed interpolated SQL and optionally the
+// EXPLAIN output. All events get forwarded to the embedded EventReceiver.
+//
+// Use it as an EventReceiver of a connection or wrap the receiver of a
+// session with SetSessionSlowQueryLog.
+type SlowQueryLog struct {
+	// EventReceiver receives all events. A nil value falls back to the
+	// NullEventReceiver.
+	EventReceiver
+	// Log must have Info enabled. A nil value falls back to PkgLog.
+	Log log.Logger
+	// Threshold minimum duration of a query to get logged.
+	Threshold time.Duration
+	// SampleRate between 0 and 1 defines the fraction of slow queries which
+	// get logged. 1 logs all slow queries, 0.1 every tenth on average. Lower
+	// the rate in production to avoid flooding the log and the database with
+	// EXPLAIN queries.
+	SampleRate float64
+	// Explain runs the EXPLAIN query. A nil value disables the capture of
+	// the query plan.
+	Explain Queryer
+	// Record optional writer to which all logged queries get written
+	// separated by a semicolon. The output can be replayed to analyze the
+	// queries. Must be safe for concurrent use, e.g. a log.MutexBuffer.
+	Record io.Writer
+}
+
+// NewSlowQueryLog creates a new slow query logger with a SampleRate of 1,
+// logging to l. A threshold <= 0 sets the DefaultSlowQueryThreshold.
+func NewSlowQueryLog(l log.Logger, threshold time.Duration) *SlowQueryLog {
+	if l == nil {
+		l = PkgLog
+	}
+	if threshold <= 0 {
+		threshold = DefaultSlowQueryThreshold
+	}
+	return &SlowQueryLog{
+		EventReceiver: nullReceiver,
+		Log:           l,
+		Threshold:     threshold,
+		SampleRate:    1,
+	}
+}
+
+// SetSessionSlowQueryLog wraps the event receiver of a session with a copy of
+// sl. If sl has no Explain Queryer the EXPLAIN queries run on the primary
+// database of the connection.
+func SetSessionSlowQueryLog(sl *SlowQueryLog) SessionOption {
+	return func(cxn *Connection, s *Session) SessionOption {
+		previous := s.EventReceiver
+		c := &SlowQueryLog{
+			EventReceiver: previous,
+			Log:           sl.Log,
+			Threshold:     sl.Threshold,
+			SampleRate:    sl.SampleRate,
+			Explain:       sl.Explain,
+			Record:        sl.Record,
+		}
+		if c.Explain == nil {
+			c.Explain = cxn.DB
+		}
+		s.EventReceiver = c
+		return SetSessionEventReceiver(previous)
+	}
+}
+
+func (sl *SlowQueryLog) receiver() EventReceiver {
+	if sl.EventReceiver == nil {
+		return nullReceiver
+	}
+	return sl.EventReceiver
+}
+
+func (sl *SlowQueryLog) logger() log.Logger {
+	if sl.Log == nil {
+		return PkgLog
+	}
+	return sl.Log
+}
+
+// Event forwards the event to the EventReceiver.
+func (sl *SlowQueryLog) Event(eventName string) {
+	sl.receiver().Event(eventName)
+}
+
+// EventKv forwards the event to the EventReceiver.
+func (sl *SlowQueryLog) EventKv(eventName string, kvs map[string]string) {
+	sl.receiver().EventKv(eventName, kvs)
+}
+
+// EventErr forwards the error to the EventReceiver.
+func (sl *SlowQueryLog) EventErr(eventName string, err error) error {
+	return sl.receiver().EventErr(eventName, err)
+}
+
+// EventErrKv forwards the error to the EventReceiver.
+func (sl *SlowQueryLog) EventErrKv(eventName string, err error, kvs map[string]string) error {
+	return sl.receiver().EventErrKv(eventName, err, kvs)
+}
+
+// Timing forwards the timing to the EventReceiver.
+func (sl *SlowQueryLog) Timing(eventName string, nanoseconds int64) {
+	sl.receiver().Timing(eventName, nanoseconds)
+}
+
+// TimingKv forwards the timing and logs the query if it is slow.
+func (sl *SlowQueryLog) TimingKv(eventName string, nanoseconds int64, kvs map[string]string) {
+	sl.receiver().TimingKv(eventName, nanoseconds, kvs)
+
+	switch eventName {
+	case "dbr.select", "dbr.insert", "dbr.update", "dbr.delete":
+	default:
+		return
+	}
+	l := sl.logger()
+	if nanoseconds < sl.Threshold.Nanoseconds() || !l.IsInfo() || !sl.sample() {
+		return
+	}
+
+	query := kvs["sql"]
+	args := []interface{}{
+		"event", eventName,
+		"duration", time.Duration(nanoseconds).String(),
+		"table", kvs["table"],
+		"caller", caller(),
+		"sql", beautifySQL(query),
+	}
+	if sl.Explain != nil {
+		plan, err := explain(sl.Explain, query)
+		if err != nil {
+			args = append(args, "explainErr", err)
+		} else {
+			args = append(args, "explain", plan)
+		}
+	}
+	l.Info("dbr.SlowQueryLog", args...)
+
+	if sl.Record != nil {
+		if _, err := fmt.Fprintf(sl.Record, "-- %s %s\n%s;\n", eventName, time.Duration(nanoseconds), query); err != nil {
+			l.Info("dbr.SlowQueryLog.Record.error", "err", err)
+		}
+	}
+}
+
+// ReplaySlowQueries executes all queries written by a SlowQueryLog to its
+// Record writer and returns the number of executed queries. SELECT queries
+// run as queries on the select runner of the session and the rows get
+// discarded, all other queries run with Exec. Use it in tests or
+// benchmarks to analyze the recorded slow queries against a database.
+func ReplaySlowQueries(s *Session, r io.Reader) (int, error) {
+	var buf bytes.Buffer
+	var n int
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "-- ") {
+			continue
+		}
+		buf.WriteString(line)
+		if !strings.HasSuffix(line, ";") {
+			buf.WriteByte('\n')
+			continue
+		}
+		query := strings.TrimSuffix(buf.String(), ";")
+		buf.Reset()
+
+		var err error
+		if Stmt.IsSelect(query) {
+			err = replaySelect(s, query)
+		} else {
+			_, err = s.UpdateBySql(query).Exec()
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, sc.Err()
+}
+
+// replaySelect runs a SELECT query, discards the rows and emits the same
+// dbr.select timing as the load functions of the SelectBuilder.
+func replaySelect(s *Session, query string) error {
+	b := s.SelectBySql(query)
+	startTime := time.Now()
+	defer func() {
+		b.TimingKv("dbr.select", time.Since(startTime).Nanoseconds(), tableKvs(query, ""))
+	}()
+
+	rows, err := b.query(b.runner, query, nil, query)
+	if err != nil {
+		return b.EventErrKv("dbr.select.replay.query", err, tableKvs(query, ""))
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	if err := rows.Err(); err != nil {
+		return b.EventErrKv("dbr.select.replay.rows_err", err, tableKvs(query, ""))
+	}
+	return nil
+}
+
+func (sl *SlowQueryLog) sample() bool {
+	return sl.SampleRate >= 1 || (sl.SampleRate > 0 && rand.Float64() < sl.SampleRate)
+}
+
+// beautifySQL pretty prints a query. Returns the query as it is if it
+// cannot be parsed.
+func beautifySQL(query string) string {
+	buf, err := sqlbeautifier.FromString(query)
+	if err != nil {
+		return query
+	}
+	return buf.String()
+}
+
+// caller returns the file and line of the first function outside of this
+// package which started the query.
+func caller() string {
+	for i := 3; ; i++ {
+		pc, file, line, ok := runtime.Caller(i)
+		if !ok {
+			return ""
+		}
+		fn := ""
+		if f := runtime.FuncForPC(pc); f != nil {
+			fn = f.Name()
+		}
+		inPkg := strings.Contains(fn, "/storage/dbr.") && !strings.HasSuffix(file, "_test.go")
+		if !inPkg && !strings.HasPrefix(fn, "runtime.") {
+			return file + ":" + strconv.Itoa(line)
+		}
+	}
+}
+
+// explain runs EXPLAIN for the query and formats the result as a text table
+// with one line per row and the columns separated by a pipe.
+func explain(q Queryer, query string) (string, error) {
+	rows, err := q.Query("EXPLAIN " + query)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(strings.Join(cols, " | "))
+
+	vals := make([]sql.RawBytes, len(cols))
+	ptrs := make([]interface{}, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return "", err
+		}
+		buf.WriteByte('\n')
+		for i, v := range vals {
+			if i > 0 {
+				buf.WriteString(" | ")
+			}
+			if v == nil {
+				buf.WriteString("NULL")
+			} else {
+				buf.Write(v)
+			}
+		}
+	}
+	return buf.String(), rows.Err()
+}
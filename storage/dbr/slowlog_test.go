@@ -0,0 +1,158 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/corestoreio/csfw/util/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// infoRecorder records the arguments of all Info calls.
+type infoRecorder struct {
+	log.BlackHole
+	mu      sync.Mutex
+	entries []map[string]interface{}
+}
+
+func newInfoRecorder() *infoRecorder {
+	return &infoRecorder{BlackHole: log.NewBlackHole()}
+}
+
+func (ir *infoRecorder) Info(msg string, args ...interface{}) {
+	e := map[string]interface{}{"msg": msg}
+	for i := 0; i+1 < len(args); i += 2 {
+		e[args[i].(string)] = args[i+1]
+	}
+	ir.mu.Lock()
+	ir.entries = append(ir.entries, e)
+	ir.mu.Unlock()
+}
+
+func newSlowQuerySession(er EventReceiver, sl *SlowQueryLog) *Session {
+	db, err := sql.Open("dbrstmt", "")
+	if err != nil {
+		panic(err)
+	}
+	cxn, err := NewConnection(SetDB(db), SetEventReceiver(er))
+	if err != nil {
+		panic(err)
+	}
+	return cxn.NewSession(SetSessionSlowQueryLog(sl))
+}
+
+func TestSlowQueryLogReplay(t *testing.T) {
+	f, err := os.Open("testdata/slow_queries.sql")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	ir := newInfoRecorder()
+	rec := &log.MutexBuffer{}
+	tr := &timingRecorder{}
+	sl := NewSlowQueryLog(ir, 0)
+	sl.Threshold = 0
+	sl.Record = rec
+	s := newSlowQuerySession(tr, sl)
+
+	n, err := ReplaySlowQueries(s, f)
+	assert.NoError(t, err)
+	assert.Exactly(t, 3, n)
+	assert.Exactly(t, []string{"dbr.select", "dbr.update", "dbr.select"}, tr.timings, "Events must be forwarded")
+
+	assert.Len(t, ir.entries, 3)
+	e := ir.entries[0]
+	assert.Exactly(t, "dbr.SlowQueryLog", e["msg"])
+	assert.Exactly(t, "dbr.select", e["event"])
+	assert.Contains(t, e["caller"], "slowlog_test.go:")
+	assert.Contains(t, e["sql"], "select \n\tid, \n\tname \n from \n\tdbr_people\n")
+	assert.Exactly(t, "a", e["explain"], "Fake driver returns only the column header")
+	assert.Exactly(t, "dbr.update", ir.entries[1]["event"])
+	assert.Contains(t, ir.entries[1]["sql"], "update \n\tdbr_people set email = 'jonathan@corestore.io'\n")
+
+	// recorded queries can be replayed again
+	ir.entries = nil
+	n, err = ReplaySlowQueries(s, strings.NewReader(rec.String()))
+	assert.NoError(t, err)
+	assert.Exactly(t, 3, n)
+	assert.Len(t, ir.entries, 3)
+}
+
+func TestSlowQueryLogThresholdAndSampling(t *testing.T) {
+	ir := newInfoRecorder()
+	sl := NewSlowQueryLog(ir, time.Hour)
+	assert.Exactly(t, time.Hour, sl.Threshold)
+	s := newSlowQuerySession(nil, sl)
+
+	_, err := s.Update("a").Set("b", 1).Exec()
+	assert.NoError(t, err)
+	assert.Len(t, ir.entries, 0, "Query is not slow")
+
+	sl.Threshold = 0
+	sl.SampleRate = 0
+	s = newSlowQuerySession(nil, sl)
+	_, err = s.Update("a").Set("b", 1).Exec()
+	assert.NoError(t, err)
+	assert.Len(t, ir.entries, 0, "Query is not sampled")
+
+	sl.SampleRate = 1
+	s = newSlowQuerySession(nil, sl)
+	s.Timing("dbr.stmt_cache.hit", 1)
+	s.TimingKv("dbr.stmt_cache.hit", 1, nil)
+	assert.Len(t, ir.entries, 0, "Only builder events get logged")
+	_, err = s.DeleteFrom("a").Exec()
+	assert.NoError(t, err)
+	assert.Len(t, ir.entries, 1)
+	assert.Exactly(t, "a", ir.entries[0]["table"])
+}
+
+func TestSlowQueryLogZeroValue(t *testing.T) {
+	sl := &SlowQueryLog{}
+	assert.NotPanics(t, func() {
+		sl.Event("a")
+		sl.EventKv("a", nil)
+		sl.Timing("a", 1)
+		sl.TimingKv("dbr.select", 1, kvs{"sql": "SELECT 1"})
+	})
+	err := errors.New("Oops")
+	assert.Exactly(t, err, sl.EventErr("a", err))
+	assert.Exactly(t, err, sl.EventErrKv("a", err, nil))
+}
+
+func TestSlowQueryLogExplainRealDB(t *testing.T) {
+	f, err := os.Open("testdata/slow_queries.sql")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	ir := newInfoRecorder()
+	sl := NewSlowQueryLog(ir, 0)
+	sl.Threshold = 0
+	s := createRealSessionWithFixtures()
+	s.ApplyOpts(SetSessionSlowQueryLog(sl))
+
+	n, err := ReplaySlowQueries(s, f)
+	assert.NoError(t, err)
+	assert.Exactly(t, 3, n)
+	for _, e := range ir.entries {
+		assert.Nil(t, e["explainErr"])
+		assert.Contains(t, e["explain"], "select_type")
+	}
+}
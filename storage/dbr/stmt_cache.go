@@ -0,0 +1,354 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"container/list"
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStmtCacheIdleTime is the idle time of a statement in a StmtCache.
+// If a statement has not been used within this time it gets closed.
+// Same behaviour as csdb.ResurrectStmt.
+var DefaultStmtCacheIdleTime = time.Second * 10
+
+// DefaultStmtCacheSize maximum number of prepared statements in a StmtCache
+// when created with a size less or equal zero.
+const DefaultStmtCacheSize = 256
+
+// StmtCache holds prepared statements keyed by the SQL text with placeholders
+// of a builder. If the maximum number of statements has been reached the
+// least recently used one gets closed. Statements which have not been used
+// within the Idle time get closed by the idle checker and prepared again on
+// the next use. A StmtCache can be shared between many sessions and is safe
+// for concurrent use.
+type StmtCache struct {
+	// Idle defines the duration after which an unused statement gets
+	// closed. Must be set before StartIdleChecker.
+	Idle time.Duration
+
+	mu               sync.Mutex // protects all following fields
+	maxEntries       int
+	ll               *list.List
+	items            map[stmtKey]*list.Element
+	stop             chan struct{}
+	idleCheckStarted bool
+}
+
+// stmtKey a statement is bound to the connection pool it has been prepared
+// with. Primary and replicas have their own statements.
+type stmtKey struct {
+	db  *sql.DB
+	sql string
+}
+
+type stmtEntry struct {
+	key      stmtKey
+	stmt     *sql.Stmt
+	lastUsed time.Time
+	inUse    int // number of running queries using the stmt
+}
+
+// NewStmtCache creates a new prepared statement cache. Apply it to a session
+// with SetSessionStmtCache.
+func NewStmtCache(maxEntries int, idle time.Duration) *StmtCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultStmtCacheSize
+	}
+	if idle <= 0 {
+		idle = DefaultStmtCacheIdleTime
+	}
+	return &StmtCache{
+		Idle:       idle,
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[stmtKey]*list.Element),
+		stop:       make(chan struct{}),
+	}
+}
+
+// SetSessionStmtCache sets a prepared statement cache to a session. Use the
+// same StmtCache for many sessions to benefit from the cached statements.
+// A nil argument disables the cache.
+func SetSessionStmtCache(sc *StmtCache) SessionOption {
+	return func(cxn *Connection, s *Session) SessionOption {
+		previous := s.stmtCache
+		s.stmtCache = sc
+		return SetSessionStmtCache(previous)
+	}
+}
+
+// StartIdleChecker starts the internal goroutine which closes all statements
+// which have not been used within the Idle time. You can only start it once.
+func (sc *StmtCache) StartIdleChecker() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.idleCheckStarted {
+		return
+	}
+	go sc.checkIdle()
+	sc.idleCheckStarted = true
+}
+
+// StopIdleChecker stops the internal goroutine if it's started and closes
+// all statements. Returns the first sql.Stmt.Close error.
+func (sc *StmtCache) StopIdleChecker() error {
+	sc.mu.Lock()
+	started := sc.idleCheckStarted
+	sc.idleCheckStarted = false
+	sc.mu.Unlock()
+	if started {
+		sc.stop <- struct{}{}
+	}
+	return sc.Close()
+}
+
+// Close closes and removes all statements. Returns the first error.
+func (sc *StmtCache) Close() error {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	var err error
+	for sc.ll.Len() > 0 {
+		if cErr := sc.removeElement(sc.ll.Back()); cErr != nil && err == nil {
+			err = cErr
+		}
+	}
+	return err
+}
+
+// Len returns the number of prepared statements.
+func (sc *StmtCache) Len() int {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.ll.Len()
+}
+
+func (sc *StmtCache) checkIdle() {
+	ticker := time.NewTicker(sc.Idle)
+	for {
+		select {
+		case t, ok := <-ticker.C:
+			if !ok {
+				return
+			}
+			if err := sc.closeIdle(t); err != nil {
+				PkgLog.Info("dbr.StmtCache.stmt.Close.error", "err", err)
+			}
+		case <-sc.stop:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// closeIdle closes all statements which have not been used since t minus
+// the idle time.
+func (sc *StmtCache) closeIdle(t time.Time) error {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	var err error
+	deadline := t.Add(-sc.Idle)
+	for ele := sc.ll.Back(); ele != nil; {
+		prev := ele.Prev()
+		e := ele.Value.(*stmtEntry)
+		if e.inUse == 0 && e.lastUsed.Before(deadline) {
+			if cErr := sc.removeElement(ele); cErr != nil && err == nil {
+				err = cErr
+			}
+		}
+		ele = prev
+	}
+	return err
+}
+
+// acquire returns a cache entry with a prepared statement for the query.
+// hit reports if the statement has been found in the cache. Each acquire
+// must be followed by a release.
+func (sc *StmtCache) acquire(db *sql.DB, query string) (_ *stmtEntry, hit bool, _ error) {
+	key := stmtKey{db: db, sql: query}
+
+	sc.mu.Lock()
+	if ele, ok := sc.items[key]; ok {
+		e := ele.Value.(*stmtEntry)
+		e.inUse++
+		e.lastUsed = time.Now()
+		sc.ll.MoveToFront(ele)
+		sc.mu.Unlock()
+		return e, true, nil
+	}
+	sc.mu.Unlock()
+
+	// prepare without holding the lock
+	stmt, err := db.Prepare(query)
+	if err != nil {
+		return nil, false, err
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if ele, ok := sc.items[key]; ok {
+		// another goroutine has been faster
+		if err := stmt.Close(); err != nil {
+			PkgLog.Info("dbr.StmtCache.stmt.Close.error", "err", err, "SQL", query)
+		}
+		e := ele.Value.(*stmtEntry)
+		e.inUse++
+		e.lastUsed = time.Now()
+		sc.ll.MoveToFront(ele)
+		return e, false, nil
+	}
+
+	e := &stmtEntry{
+		key:      key,
+		stmt:     stmt,
+		lastUsed: time.Now(),
+		inUse:    1,
+	}
+	sc.items[key] = sc.ll.PushFront(e)
+	sc.evict()
+	return e, false, nil
+}
+
+// release marks a statement as not used anymore.
+func (sc *StmtCache) release(e *stmtEntry) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	e.inUse--
+	e.lastUsed = time.Now()
+}
+
+// evict closes the least recently used statements which are not in use until
+// the cache fits its size. Must be called while holding the lock.
+func (sc *StmtCache) evict() {
+	for ele := sc.ll.Back(); ele != nil && sc.ll.Len() > sc.maxEntries; {
+		prev := ele.Prev()
+		if ele.Value.(*stmtEntry).inUse == 0 {
+			if err := sc.removeElement(ele); err != nil {
+				PkgLog.Info("dbr.StmtCache.stmt.Close.error", "err", err)
+			}
+		}
+		ele = prev
+	}
+}
+
+// removeElement must be called while holding the lock.
+func (sc *StmtCache) removeElement(ele *list.Element) error {
+	e := sc.ll.Remove(ele).(*stmtEntry)
+	delete(sc.items, e.key)
+	if PkgLog.IsDebug() {
+		PkgLog.Debug("dbr.StmtCache.stmt.Close", "SQL", e.key.sql)
+	}
+	return e.stmt.Close()
+}
+
+// canPrepare checks if a query with its arguments can be sent as a prepared
+// statement. Slices for the IN operator and the [identifier] syntax can only
+// be handled by Preprocess.
+func canPrepare(query string, args []interface{}) bool {
+	if strings.ContainsRune(query, '[') {
+		return false
+	}
+	for _, a := range args {
+		if _, ok := a.([]byte); ok {
+			continue
+		}
+		if a != nil && reflect.TypeOf(a).Kind() == reflect.Slice {
+			return false
+		}
+	}
+	return true
+}
+
+// stmtDB returns the connection pool on which a statement must be prepared
+// and the transaction if the runner is bound to one.
+func (sess *Session) stmtDB(r runner) (*sql.DB, *sql.Tx) {
+	switch rt := r.(type) {
+	case *sql.DB:
+		return rt, nil
+	case *sql.Tx:
+		return sess.cxn.DB, rt
+	case readRunner:
+		if rt.sess.isPrimaryForced() {
+			return sess.cxn.DB, nil
+		}
+		return sess.cxn.readDB(), nil
+	}
+	return nil, nil
+}
+
+// prepared returns a prepared statement from the cache and a release
+// function or a nil statement if the statement cache cannot be used.
+func (sess *Session) prepared(r runner, query string, args []interface{}) (*sql.Stmt, func(), error) {
+	if sess.stmtCache == nil || !canPrepare(query, args) {
+		return nil, nil, nil
+	}
+	db, tx := sess.stmtDB(r)
+	if db == nil {
+		return nil, nil, nil
+	}
+	query = Rebind(sess.dialect(), query)
+
+	start := time.Now()
+	e, hit, err := sess.stmtCache.acquire(db, query)
+	if err != nil {
+		return nil, nil, sess.EventErrKv("dbr.stmt_cache.prepare", err, kvs{"sql": query})
+	}
+	if hit {
+		sess.TimingKv("dbr.stmt_cache.hit", time.Since(start).Nanoseconds(), kvs{"sql": query})
+	} else {
+		sess.TimingKv("dbr.stmt_cache.miss", time.Since(start).Nanoseconds(), kvs{"sql": query})
+	}
+
+	release := func() { sess.stmtCache.release(e) }
+	if tx != nil {
+		txStmt := tx.Stmt(e.stmt)
+		return txStmt, func() {
+			_ = txStmt.Close()
+			release()
+		}, nil
+	}
+	return e.stmt, release, nil
+}
+
+// exec runs the query via a cached prepared statement if possible otherwise
+// it executes the already interpolated fullSql.
+func (sess *Session) exec(r runner, query string, args []interface{}, fullSql string) (sql.Result, error) {
+	stmt, release, err := sess.prepared(r, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if stmt == nil {
+		return r.Exec(fullSql)
+	}
+	defer release()
+	return stmt.Exec(args...)
+}
+
+// query same as exec but returns rows.
+func (sess *Session) query(r runner, query string, args []interface{}, fullSql string) (*sql.Rows, error) {
+	stmt, release, err := sess.prepared(r, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if stmt == nil {
+		return r.Query(fullSql)
+	}
+	defer release()
+	return stmt.Query(args...)
+}
@@ -0,0 +1,198 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stmtDriver counts the prepared and closed statements. Queries return an
+// empty result set.
+type stmtDriver struct{}
+
+type stmtConn struct{}
+
+type stmtStmt struct{ query string }
+
+type stmtRows struct{}
+
+var stmtStats = struct {
+	sync.Mutex
+	prepared []string
+	closed   int
+	direct   []string
+}{}
+
+func resetStmtStats() {
+	stmtStats.Lock()
+	stmtStats.prepared = nil
+	stmtStats.closed = 0
+	stmtStats.direct = nil
+	stmtStats.Unlock()
+}
+
+func init() {
+	sql.Register("dbrstmt", stmtDriver{})
+}
+
+func (stmtDriver) Open(dsn string) (driver.Conn, error) { return stmtConn{}, nil }
+
+func (stmtConn) Prepare(query string) (driver.Stmt, error) {
+	stmtStats.Lock()
+	stmtStats.prepared = append(stmtStats.prepared, query)
+	stmtStats.Unlock()
+	return stmtStmt{query: query}, nil
+}
+func (stmtConn) Close() error              { return nil }
+func (stmtConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+func (stmtConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	stmtStats.Lock()
+	stmtStats.direct = append(stmtStats.direct, query)
+	stmtStats.Unlock()
+	return driver.RowsAffected(1), nil
+}
+
+func (s stmtStmt) Close() error {
+	stmtStats.Lock()
+	stmtStats.closed++
+	stmtStats.Unlock()
+	return nil
+}
+func (s stmtStmt) NumInput() int { return -1 }
+func (s stmtStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (s stmtStmt) Query(args []driver.Value) (driver.Rows, error) { return stmtRows{}, nil }
+
+func (stmtRows) Columns() []string              { return []string{"a"} }
+func (stmtRows) Close() error                   { return nil }
+func (stmtRows) Next(dest []driver.Value) error { return io.EOF }
+
+// timingRecorder records the event names of all timings.
+type timingRecorder struct {
+	NullEventReceiver
+	mu      sync.Mutex
+	timings []string
+}
+
+func (tr *timingRecorder) TimingKv(eventName string, nanoseconds int64, kvs map[string]string) {
+	tr.mu.Lock()
+	tr.timings = append(tr.timings, eventName)
+	tr.mu.Unlock()
+}
+
+func newStmtCacheSession(sc *StmtCache, er EventReceiver) *Session {
+	db, err := sql.Open("dbrstmt", "")
+	if err != nil {
+		panic(err)
+	}
+	cxn, err := NewConnection(SetDB(db), SetEventReceiver(er))
+	if err != nil {
+		panic(err)
+	}
+	return cxn.NewSession(SetSessionStmtCache(sc))
+}
+
+func TestCanPrepare(t *testing.T) {
+	tests := []struct {
+		query string
+		args  []interface{}
+		want  bool
+	}{
+		{"SELECT a FROM b WHERE c = ?", []interface{}{1}, true},
+		{"SELECT a FROM b WHERE c = ?", []interface{}{[]byte("x")}, true},
+		{"SELECT a FROM b WHERE c = ?", []interface{}{nil}, true},
+		{"SELECT a FROM b WHERE c IN ?", []interface{}{[]int64{1, 2}}, false},
+		{"SELECT [a] FROM b", nil, false},
+	}
+	for _, test := range tests {
+		assert.Exactly(t, test.want, canPrepare(test.query, test.args), "%#v", test)
+	}
+}
+
+func TestStmtCacheHitMiss(t *testing.T) {
+	resetStmtStats()
+	tr := &timingRecorder{}
+	s := newStmtCacheSession(NewStmtCache(10, time.Minute), tr)
+
+	for i := 0; i < 3; i++ {
+		_, err := s.Update("a").Set("b", i).Where(ConditionRaw("c = ?", 1)).Exec()
+		assert.NoError(t, err)
+	}
+	var vals []int64
+	_, err := s.Select("a").From("b").Where(ConditionRaw("c = ?", 2)).LoadValues(&vals)
+	assert.NoError(t, err)
+
+	stmtStats.Lock()
+	assert.Exactly(t, []string{
+		"UPDATE `a` SET `b` = ? WHERE (c = ?)",
+		"SELECT a FROM `b` WHERE (c = ?)",
+	}, stmtStats.prepared)
+	assert.Len(t, stmtStats.direct, 0)
+	stmtStats.Unlock()
+
+	assert.Exactly(t, []string{
+		"dbr.stmt_cache.miss", "dbr.update",
+		"dbr.stmt_cache.hit", "dbr.update",
+		"dbr.stmt_cache.hit", "dbr.update",
+		"dbr.stmt_cache.miss", "dbr.select",
+	}, tr.timings)
+}
+
+func TestStmtCacheFallbackInterpolate(t *testing.T) {
+	resetStmtStats()
+	sc := NewStmtCache(10, time.Minute)
+	s := newStmtCacheSession(sc, nil)
+
+	_, err := s.DeleteFrom("a").Where(ConditionMap(Eq{"b": []int64{1, 2}})).Exec()
+	assert.NoError(t, err)
+	assert.Exactly(t, 0, sc.Len())
+
+	stmtStats.Lock()
+	assert.Len(t, stmtStats.prepared, 0)
+	assert.Exactly(t, []string{"DELETE FROM `a` WHERE (`b` IN (1,2))"}, stmtStats.direct)
+	stmtStats.Unlock()
+}
+
+func TestStmtCacheEvictionAndIdle(t *testing.T) {
+	resetStmtStats()
+	sc := NewStmtCache(1, time.Second)
+	s := newStmtCacheSession(sc, nil)
+
+	_, err := s.Update("a").Set("b", 1).Exec()
+	assert.NoError(t, err)
+	_, err = s.Update("c").Set("d", 1).Exec()
+	assert.NoError(t, err)
+	assert.Exactly(t, 1, sc.Len(), "Least recently used stmt must be evicted")
+
+	assert.NoError(t, sc.closeIdle(time.Now()))
+	assert.Exactly(t, 1, sc.Len(), "Stmt is not yet idle")
+	assert.NoError(t, sc.closeIdle(time.Now().Add(time.Second*2)))
+	assert.Exactly(t, 0, sc.Len())
+
+	stmtStats.Lock()
+	assert.Exactly(t, 2, stmtStats.closed)
+	stmtStats.Unlock()
+
+	sc.StartIdleChecker()
+	assert.NoError(t, sc.StopIdleChecker())
+}
@@ -0,0 +1,6 @@
+-- dbr.select 250ms
+SELECT `id`, `name` FROM `dbr_people` WHERE (`email` LIKE '%@uservoice.com') ORDER BY name;
+-- dbr.update 180ms
+UPDATE `dbr_people` SET `email` = 'jonathan@corestore.io' WHERE (`name` = 'Jonathan');
+-- dbr.select 120ms
+SELECT COUNT(*) FROM `dbr_people` AS `p` INNER JOIN `null_types` AS `n` ON (p.id = n.id);
@@ -2,12 +2,40 @@ package dbr
 
 import (
 	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL error numbers which indicate that a transaction can be retried.
+const (
+	mysqlErrLockWaitTimeout uint16 = 1205
+	mysqlErrDeadlock        uint16 = 1213
 )
 
+// TransactionMaxRetries defines how often Session.Transaction retries a
+// transaction which failed due to a deadlock or a lock wait timeout.
+var TransactionMaxRetries = 3
+
+// TransactionRetryBackoff is the wait time before the first retry of a
+// transaction. The time doubles with each further retry.
+var TransactionRetryBackoff = time.Millisecond * 50
+
 // Tx is a transaction for the given Session
 type Tx struct {
 	*Session
 	*sql.Tx
+	// savepoint name of a nested transaction created with Tx.Begin. Empty
+	// for the outermost transaction.
+	savepoint string
+	// depth nesting level, zero for the outermost transaction
+	depth int
+	// done nested transaction has been released or rolled back
+	done bool
+	// tables changed within the transaction, shared with the nested
+	// transactions. Their cache entries get invalidated after the commit.
+	tables *txTables
 }
 
 // Begin creates a transaction for the given session
@@ -22,22 +50,58 @@ func (sess *Session) Begin() (*Tx, error) {
 	return &Tx{
 		Session: sess,
 		Tx:      tx,
+		tables:  new(txTables),
+	}, nil
+}
+
+// Begin starts a nested transaction by setting a SAVEPOINT. Commit releases
+// the savepoint and Rollback rolls back to the savepoint. The outermost
+// transaction still needs to be committed.
+func (tx *Tx) Begin() (*Tx, error) {
+	sp := "cs_savepoint_" + strconv.Itoa(tx.depth+1)
+	if _, err := tx.Tx.Exec("SAVEPOINT " + sp); err != nil {
+		return nil, tx.EventErrKv("dbr.savepoint.error", err, kvs{"savepoint": sp})
+	}
+	tx.EventKv("dbr.savepoint", kvs{"savepoint": sp})
+	return &Tx{
+		Session:   tx.Session,
+		Tx:        tx.Tx,
+		savepoint: sp,
+		depth:     tx.depth + 1,
+		tables:    tx.tables,
 	}, nil
 }
 
 // Commit finishes the transaction
 func (tx *Tx) Commit() error {
+	if tx.savepoint != "" {
+		if _, err := tx.Tx.Exec("RELEASE SAVEPOINT " + tx.savepoint); err != nil {
+			return tx.EventErrKv("dbr.savepoint.release.error", err, kvs{"savepoint": tx.savepoint})
+		}
+		tx.done = true
+		tx.EventKv("dbr.savepoint.release", kvs{"savepoint": tx.savepoint})
+		return nil
+	}
 	err := tx.Tx.Commit()
 	if err != nil {
 		return tx.EventErr("dbr.commit.error", err)
 	} else {
 		tx.Event("dbr.commit")
 	}
+	tx.invalidateCache(nil, tx.tables.reset()...)
 	return nil
 }
 
 // Rollback cancels the transaction
 func (tx *Tx) Rollback() error {
+	if tx.savepoint != "" {
+		if _, err := tx.Tx.Exec("ROLLBACK TO SAVEPOINT " + tx.savepoint); err != nil {
+			return tx.EventErrKv("dbr.savepoint.rollback", err, kvs{"savepoint": tx.savepoint})
+		}
+		tx.done = true
+		tx.EventKv("dbr.savepoint.rollback", kvs{"savepoint": tx.savepoint})
+		return nil
+	}
 	err := tx.Tx.Rollback()
 	if err != nil {
 		return tx.EventErr("dbr.rollback", err)
@@ -51,6 +115,12 @@ func (tx *Tx) Rollback() error {
 // Useful to defer tx.RollbackUnlessCommitted() -- so you don't have to handle N failure cases
 // Keep in mind the only way to detect an error on the rollback is via the event log.
 func (tx *Tx) RollbackUnlessCommitted() {
+	if tx.savepoint != "" {
+		if !tx.done {
+			_ = tx.Rollback()
+		}
+		return
+	}
 	err := tx.Tx.Rollback()
 	if err == sql.ErrTxDone {
 		// ok
@@ -60,3 +130,83 @@ func (tx *Tx) RollbackUnlessCommitted() {
 		tx.Event("dbr.rollback")
 	}
 }
+
+// Transaction runs fn within a nested transaction. If fn returns an error
+// or panics the nested transaction gets rolled back to its savepoint
+// otherwise the savepoint gets released. Retries are only possible in
+// Session.Transaction.
+func (tx *Tx) Transaction(fn func(*Tx) error) error {
+	return runTransaction(tx.Begin, fn)
+}
+
+// Transaction runs fn within a transaction. If fn returns an error or panics
+// the transaction gets rolled back otherwise committed. When the transaction
+// fails due to a MySQL deadlock (1213) or lock wait timeout (1205) the whole
+// transaction gets retried up to TransactionMaxRetries times with an
+// exponential backoff starting at TransactionRetryBackoff. Each retry gets
+// reported to the EventReceiver. fn must therefore be safe to be called
+// multiple times.
+func (sess *Session) Transaction(fn func(*Tx) error) error {
+	for attempt := 0; ; attempt++ {
+		err := runTransaction(sess.Begin, fn)
+		if err == nil || attempt >= TransactionMaxRetries || !IsRetryableTxError(err) {
+			return err
+		}
+		wait := TransactionRetryBackoff << uint(attempt)
+		_ = sess.EventErrKv("dbr.transaction.retry", err, kvs{
+			"attempt": strconv.Itoa(attempt + 1),
+			"wait":    wait.String(),
+		})
+		time.Sleep(wait)
+	}
+}
+
+func runTransaction(begin func() (*Tx, error), fn func(*Tx) error) error {
+	tx, err := begin()
+	if err != nil {
+		return err
+	}
+	defer tx.RollbackUnlessCommitted() // also in case of a panic
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// IsRetryableTxError checks if err or one of its causes is a MySQL deadlock
+// or lock wait timeout error.
+func IsRetryableTxError(err error) bool {
+	return IsMySQLError(err, mysqlErrDeadlock, mysqlErrLockWaitTimeout)
+}
+
+// IsMySQLError checks if err or one of its causes is a MySQL error with one
+// of the error numbers.
+func IsMySQLError(err error, numbers ...uint16) bool {
+	for err != nil {
+		if me, ok := err.(*mysql.MySQLError); ok {
+			for _, n := range numbers {
+				if me.Number == n {
+					return true
+				}
+			}
+			return false
+		}
+		switch e := err.(type) {
+		case interface {
+			Underlying() error
+		}:
+			err = e.Underlying()
+		case interface {
+			Cause() error
+		}:
+			if c := e.Cause(); c != err {
+				err = c
+			} else {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return false
+}
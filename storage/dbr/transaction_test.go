@@ -1,9 +1,16 @@
 package dbr
 
 import (
-	// "database/sql"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
+	"github.com/juju/errgo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -52,3 +59,199 @@ func TestTransactionRollbackReal(t *testing.T) {
 	err = tx.Rollback()
 	assert.NoError(t, err)
 }
+
+// txDriver records all statements. An Exec of a query containing "deadlock"
+// fails with a MySQL deadlock error as long as txDeadlocks is greater zero.
+type txDriver struct{}
+
+type txConn struct{}
+
+type txTx struct{}
+
+var txLog = struct {
+	sync.Mutex
+	stmts     []string
+	deadlocks int
+}{}
+
+func resetTxLog(deadlocks int) {
+	txLog.Lock()
+	txLog.stmts = nil
+	txLog.deadlocks = deadlocks
+	txLog.Unlock()
+}
+
+func txLogged() []string {
+	txLog.Lock()
+	defer txLog.Unlock()
+	return append([]string(nil), txLog.stmts...)
+}
+
+func txRecord(s string) {
+	txLog.Lock()
+	txLog.stmts = append(txLog.stmts, s)
+	txLog.Unlock()
+}
+
+func init() {
+	sql.Register("dbrtx", txDriver{})
+}
+
+func (txDriver) Open(dsn string) (driver.Conn, error) { return txConn{}, nil }
+
+func (txConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (txConn) Close() error                              { return nil }
+func (txConn) Begin() (driver.Tx, error) {
+	txRecord("BEGIN")
+	return txTx{}, nil
+}
+func (txConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	txLog.Lock()
+	defer txLog.Unlock()
+	txLog.stmts = append(txLog.stmts, query)
+	if strings.Contains(query, "deadlock") && txLog.deadlocks > 0 {
+		txLog.deadlocks--
+		return nil, &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (txTx) Commit() error {
+	txRecord("COMMIT")
+	return nil
+}
+func (txTx) Rollback() error {
+	txRecord("ROLLBACK")
+	return nil
+}
+
+func newTxSession() *Session {
+	db, err := sql.Open("dbrtx", "")
+	if err != nil {
+		panic(err)
+	}
+	cxn, err := NewConnection(SetDB(db))
+	if err != nil {
+		panic(err)
+	}
+	return cxn.NewSession()
+}
+
+func TestTransactionNestedSavepoints(t *testing.T) {
+	resetTxLog(0)
+	s := newTxSession()
+
+	tx, err := s.Begin()
+	assert.NoError(t, err)
+	_, err = tx.Update("a").Set("b", 1).Exec()
+	assert.NoError(t, err)
+
+	nested, err := tx.Begin()
+	assert.NoError(t, err)
+	_, err = nested.Update("a").Set("b", 2).Exec()
+	assert.NoError(t, err)
+	assert.NoError(t, nested.Rollback())
+	nested.RollbackUnlessCommitted() // no-op
+
+	nested, err = tx.Begin()
+	assert.NoError(t, err)
+	nested2, err := nested.Begin()
+	assert.NoError(t, err)
+	assert.NoError(t, nested2.Commit())
+	assert.NoError(t, nested.Commit())
+
+	assert.NoError(t, tx.Commit())
+
+	assert.Exactly(t, []string{
+		"BEGIN",
+		"UPDATE `a` SET `b` = 1",
+		"SAVEPOINT cs_savepoint_1",
+		"UPDATE `a` SET `b` = 2",
+		"ROLLBACK TO SAVEPOINT cs_savepoint_1",
+		"SAVEPOINT cs_savepoint_1",
+		"SAVEPOINT cs_savepoint_2",
+		"RELEASE SAVEPOINT cs_savepoint_2",
+		"RELEASE SAVEPOINT cs_savepoint_1",
+		"COMMIT",
+	}, txLogged())
+}
+
+func TestSessionTransaction(t *testing.T) {
+	resetTxLog(0)
+	s := newTxSession()
+
+	errNested := errors.New("nested failed")
+	err := s.Transaction(func(tx *Tx) error {
+		if _, err := tx.Update("a").Set("b", 1).Exec(); err != nil {
+			return err
+		}
+		assert.Exactly(t, errNested, tx.Transaction(func(tx2 *Tx) error {
+			_, _ = tx2.DeleteFrom("a").Exec()
+			return errNested
+		}))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Exactly(t, []string{
+		"BEGIN",
+		"UPDATE `a` SET `b` = 1",
+		"SAVEPOINT cs_savepoint_1",
+		"DELETE FROM `a`",
+		"ROLLBACK TO SAVEPOINT cs_savepoint_1",
+		"COMMIT",
+	}, txLogged())
+}
+
+type retryRecorder struct {
+	NullEventReceiver
+	retries []string
+}
+
+func (rr *retryRecorder) EventErrKv(eventName string, err error, kvs map[string]string) error {
+	if eventName == "dbr.transaction.retry" {
+		rr.retries = append(rr.retries, kvs["attempt"])
+	}
+	return err
+}
+
+func TestSessionTransactionRetryDeadlock(t *testing.T) {
+	defer func(b time.Duration) { TransactionRetryBackoff = b }(TransactionRetryBackoff)
+	TransactionRetryBackoff = time.Microsecond
+
+	resetTxLog(2)
+	s := newTxSession()
+	rr := &retryRecorder{}
+	s.EventReceiver = rr
+
+	calls := 0
+	err := s.Transaction(func(tx *Tx) error {
+		calls++
+		_, err := tx.UpdateBySql("UPDATE deadlock SET a = 1").Exec()
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Exactly(t, 3, calls)
+	assert.Exactly(t, []string{"1", "2"}, rr.retries)
+	assert.Exactly(t, []string{
+		"BEGIN", "UPDATE deadlock SET a = 1", "ROLLBACK",
+		"BEGIN", "UPDATE deadlock SET a = 1", "ROLLBACK",
+		"BEGIN", "UPDATE deadlock SET a = 1", "COMMIT",
+	}, txLogged())
+
+	resetTxLog(10)
+	rr.retries = nil
+	err = s.Transaction(func(tx *Tx) error {
+		_, err := tx.UpdateBySql("UPDATE deadlock SET a = 1").Exec()
+		return err
+	})
+	assert.True(t, IsRetryableTxError(err))
+	assert.Exactly(t, []string{"1", "2", "3"}, rr.retries)
+}
+
+func TestIsRetryableTxError(t *testing.T) {
+	assert.True(t, IsRetryableTxError(&mysql.MySQLError{Number: 1205}))
+	assert.True(t, IsRetryableTxError(errgo.Mask(&mysql.MySQLError{Number: 1213})))
+	assert.False(t, IsRetryableTxError(&mysql.MySQLError{Number: 1062}))
+	assert.False(t, IsRetryableTxError(errors.New("1213")))
+	assert.False(t, IsRetryableTxError(nil))
+}
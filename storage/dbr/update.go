@@ -23,6 +23,8 @@ func Expr(sql string, values ...interface{}) *expr {
 type UpdateBuilder struct {
 	*Session
 	runner
+	// txTables collects the changed tables when bound to a transaction.
+	txTables *txTables
 
 	RawFullSql   string
 	RawArguments []interface{}
@@ -69,9 +71,10 @@ func (sess *Session) UpdateBySql(sql string, args ...interface{}) *UpdateBuilder
 // Update creates a new UpdateBuilder for the given table bound to a transaction
 func (tx *Tx) Update(table ...string) *UpdateBuilder {
 	return &UpdateBuilder{
-		Session: tx.Session,
-		runner:  tx.Tx,
-		Table:   newAlias(table...),
+		Session:  tx.Session,
+		runner:   tx.Tx,
+		txTables: tx.tables,
+		Table:    newAlias(table...),
 	}
 }
 
@@ -83,6 +86,7 @@ func (tx *Tx) UpdateBySql(sql string, args ...interface{}) *UpdateBuilder {
 	return &UpdateBuilder{
 		Session:      tx.Session,
 		runner:       tx.Tx,
+		txTables:     tx.tables,
 		RawFullSql:   sql,
 		RawArguments: args,
 	}
@@ -222,19 +226,23 @@ func (b *UpdateBuilder) Exec() (sql.Result, error) {
 		return nil, b.EventErrKv("dbr.update.exec.tosql", err, nil)
 	}
 
-	fullSql, err := Preprocess(sql, args)
+	fullSql, err := b.preprocess(sql, args)
 	if err != nil {
-		return nil, b.EventErrKv("dbr.update.exec.interpolate", err, kvs{"sql": fullSql})
+		return nil, b.EventErrKv("dbr.update.exec.interpolate", err, tableKvs(fullSql, b.Table.Expression))
 	}
 
 	// Start the timer:
 	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.update", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
+	defer func() {
+		b.TimingKv("dbr.update", time.Since(startTime).Nanoseconds(), tableKvs(fullSql, b.Table.Expression))
+	}()
 
-	result, err := b.runner.Exec(fullSql)
+	result, err := b.exec(b.runner, sql, args, fullSql)
 	if err != nil {
-		return result, b.EventErrKv("dbr.update.exec.exec", err, kvs{"sql": fullSql})
+		return result, b.EventErrKv("dbr.update.exec.exec", err, tableKvs(fullSql, b.Table.Expression))
 	}
+	b.markWrite()
+	b.invalidateCache(b.txTables, b.Table.Expression)
 
 	return result, nil
 }
@@ -1,6 +1,10 @@
 package dbr
 
-import "reflect"
+import (
+	"reflect"
+
+	"github.com/corestoreio/csfw/util/bufferpool"
+)
 
 // todo for maybe later the sync.Pool code
 //var wfPool = &sync.Pool{
@@ -30,6 +34,15 @@ type whereFragment struct {
 	Condition   string
 	Values      []interface{}
 	EqualityMap map[string]interface{}
+
+	// typed condition created by Gt, Like, Between, etc.
+	Column   string
+	Operator condOp
+	Arg1     interface{}
+	Arg2     interface{} // only used for BETWEEN
+	// Group contains the logical operator AND or OR to glue the Children
+	Group    string
+	Children []*whereFragment
 }
 
 type ConditionArg func(*whereFragment)
@@ -64,22 +77,36 @@ func newWhereFragments(wargs ...ConditionArg) []*whereFragment {
 func writeWhereFragmentsToSql(fragments []*whereFragment, sql QueryWriter, args *[]interface{}) {
 	anyConditions := false
 	for _, f := range fragments {
-		if f.Condition != "" {
-			if anyConditions {
-				_, _ = sql.WriteString(" AND (")
-			} else {
-				_, _ = sql.WriteRune('(')
-				anyConditions = true
-			}
-			_, _ = sql.WriteString(f.Condition)
-			_, _ = sql.WriteRune(')')
-			if len(f.Values) > 0 {
-				*args = append(*args, f.Values...)
-			}
-		} else if f.EqualityMap != nil {
-			anyConditions = writeEqualityMapToSql(f.EqualityMap, sql, args, anyConditions)
+		anyConditions = writeWhereFragment(f, sql, args, anyConditions)
+	}
+}
+
+func writeWhereFragment(f *whereFragment, sql QueryWriter, args *[]interface{}, anyConditions bool) bool {
+	switch {
+	case f.Condition != "":
+		if anyConditions {
+			_, _ = sql.WriteString(" AND (")
+		} else {
+			_, _ = sql.WriteRune('(')
+			anyConditions = true
+		}
+		_, _ = sql.WriteString(f.Condition)
+		_, _ = sql.WriteRune(')')
+		if len(f.Values) > 0 {
+			*args = append(*args, f.Values...)
+		}
+	case f.EqualityMap != nil:
+		anyConditions = writeEqualityMapToSql(f.EqualityMap, sql, args, anyConditions)
+	case f.Operator != opNone:
+		anyConditions = writeTypedCondition(f, sql, args, anyConditions)
+	case f.Group != "":
+		if anyConditions {
+			_, _ = sql.WriteString(" AND ")
 		}
+		anyConditions = true
+		writeConditionGroup(f, sql, args)
 	}
+	return anyConditions
 }
 
 func writeEqualityMapToSql(eq map[string]interface{}, sql QueryWriter, args *[]interface{}, anyConditions bool) bool {
@@ -131,3 +158,242 @@ func writeWhereCondition(sql QueryWriter, k string, pred string, anyConditions b
 
 	return anyConditions
 }
+
+// condOp defines the operator of a typed condition.
+type condOp uint8
+
+const (
+	opNone condOp = iota
+	opEq
+	opNotEq
+	opGt
+	opGte
+	opLt
+	opLte
+	opBetween
+	opLike
+	opNotLike
+	opIsNull
+	opNotNull
+	opIn
+	opNotIn
+)
+
+// predicate returns the SQL part after the column name.
+func (o condOp) predicate() string {
+	switch o {
+	case opEq:
+		return " = ?"
+	case opNotEq:
+		return " != ?"
+	case opGt:
+		return " > ?"
+	case opGte:
+		return " >= ?"
+	case opLt:
+		return " < ?"
+	case opLte:
+		return " <= ?"
+	case opBetween:
+		return " BETWEEN ? AND ?"
+	case opLike:
+		return " LIKE ?"
+	case opNotLike:
+		return " NOT LIKE ?"
+	case opIsNull:
+		return " IS NULL"
+	case opNotNull:
+		return " IS NOT NULL"
+	case opIn:
+		return " IN ?"
+	case opNotIn:
+		return " NOT IN ?"
+	}
+	return ""
+}
+
+func typedCondition(column string, op condOp, arg1, arg2 interface{}) ConditionArg {
+	return func(wf *whereFragment) {
+		wf.Column = column
+		wf.Operator = op
+		wf.Arg1 = arg1
+		wf.Arg2 = arg2
+	}
+}
+
+// Gt creates the condition `column` > value.
+func Gt(column string, value interface{}) ConditionArg {
+	return typedCondition(column, opGt, value, nil)
+}
+
+// Gte creates the condition `column` >= value.
+func Gte(column string, value interface{}) ConditionArg {
+	return typedCondition(column, opGte, value, nil)
+}
+
+// Lt creates the condition `column` < value.
+func Lt(column string, value interface{}) ConditionArg {
+	return typedCondition(column, opLt, value, nil)
+}
+
+// Lte creates the condition `column` <= value.
+func Lte(column string, value interface{}) ConditionArg {
+	return typedCondition(column, opLte, value, nil)
+}
+
+// Between creates the condition `column` BETWEEN from AND to.
+func Between(column string, from, to interface{}) ConditionArg {
+	return typedCondition(column, opBetween, from, to)
+}
+
+// Like creates the condition `column` LIKE pattern. The pattern will not be
+// escaped, so % and _ are wildcards.
+func Like(column string, pattern string) ConditionArg {
+	return typedCondition(column, opLike, pattern, nil)
+}
+
+// NotLike creates the condition `column` NOT LIKE pattern.
+func NotLike(column string, pattern string) ConditionArg {
+	return typedCondition(column, opNotLike, pattern, nil)
+}
+
+// IsNull creates the condition `column` IS NULL.
+func IsNull(column string) ConditionArg {
+	return typedCondition(column, opIsNull, nil, nil)
+}
+
+// NotNull creates the condition `column` IS NOT NULL.
+func NotNull(column string) ConditionArg {
+	return typedCondition(column, opNotNull, nil, nil)
+}
+
+// In creates the condition `column` IN (values). Argument values must be a
+// slice. An empty slice results in a condition which is always false.
+func In(column string, values interface{}) ConditionArg {
+	return typedCondition(column, opIn, values, nil)
+}
+
+// NotIn creates the condition `column` NOT IN (values). Argument values must
+// be a slice. An empty slice results in a condition which is always true.
+func NotIn(column string, values interface{}) ConditionArg {
+	return typedCondition(column, opNotIn, values, nil)
+}
+
+// And groups the conditions with AND and puts parentheses around them.
+// An empty group is always true.
+func And(conds ...ConditionArg) ConditionArg {
+	return func(wf *whereFragment) {
+		wf.Group = " AND "
+		wf.Children = newWhereFragments(conds...)
+	}
+}
+
+// Or groups the conditions with OR and puts parentheses around them. An
+// empty group is always false.
+//
+//	Where(Or(ConditionMap(Eq{"a": 1}), And(Gt("b", 2), IsNull("c"))))
+//
+// results in
+//
+//	WHERE ((`a` = ?) OR ((`b` > ?) AND (`c` IS NULL)))
+func Or(conds ...ConditionArg) ConditionArg {
+	return func(wf *whereFragment) {
+		wf.Group = " OR "
+		wf.Children = newWhereFragments(conds...)
+	}
+}
+
+func writeTypedCondition(f *whereFragment, sql QueryWriter, args *[]interface{}, anyConditions bool) bool {
+	switch f.Operator {
+	case opIsNull, opNotNull:
+		return writeWhereIdentCondition(sql, f.Column, f.Operator.predicate(), anyConditions)
+	case opIn, opNotIn:
+		return writeInCondition(f, sql, args, anyConditions)
+	case opBetween:
+		*args = append(*args, f.Arg1, f.Arg2)
+	default:
+		*args = append(*args, f.Arg1)
+	}
+	return writeWhereIdentCondition(sql, f.Column, f.Operator.predicate(), anyConditions)
+}
+
+// writeInCondition handles the same special cases as the Eq map: empty
+// slices and slices with only one element.
+func writeInCondition(f *whereFragment, sql QueryWriter, args *[]interface{}, anyConditions bool) bool {
+	isNotIn := f.Operator == opNotIn
+	vVal := reflect.ValueOf(f.Arg1)
+	if k := vVal.Kind(); k != reflect.Array && k != reflect.Slice {
+		*args = append(*args, f.Arg1)
+		if isNotIn {
+			return writeWhereIdentCondition(sql, f.Column, " != ?", anyConditions)
+		}
+		return writeWhereIdentCondition(sql, f.Column, " = ?", anyConditions)
+	}
+
+	switch vVal.Len() {
+	case 0:
+		if anyConditions {
+			_, _ = sql.WriteString(" AND ")
+		}
+		if isNotIn {
+			_, _ = sql.WriteString("(1=1)")
+		} else {
+			_, _ = sql.WriteString("(1=0)")
+		}
+		return true
+	case 1:
+		*args = append(*args, vVal.Index(0).Interface())
+		if isNotIn {
+			return writeWhereIdentCondition(sql, f.Column, " != ?", anyConditions)
+		}
+		return writeWhereIdentCondition(sql, f.Column, " = ?", anyConditions)
+	}
+	*args = append(*args, f.Arg1)
+	return writeWhereIdentCondition(sql, f.Column, f.Operator.predicate(), anyConditions)
+}
+
+// writeConditionGroup writes the children separated by AND or OR. Children
+// which produce no SQL, like an empty Eq map, get skipped. A group without
+// any SQL writes the neutral condition of its operator.
+func writeConditionGroup(f *whereFragment, sql QueryWriter, args *[]interface{}) {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+
+	written := 0
+	for _, c := range f.Children {
+		buf.Reset()
+		if !writeWhereFragment(c, buf, args, false) || buf.Len() == 0 {
+			continue
+		}
+		if written == 0 {
+			_, _ = sql.WriteRune('(')
+		} else {
+			_, _ = sql.WriteString(f.Group)
+		}
+		_, _ = sql.Write(buf.Bytes())
+		written++
+	}
+	switch {
+	case written > 0:
+		_, _ = sql.WriteRune(')')
+	case f.Group == " OR ":
+		_, _ = sql.WriteString("(1=0)")
+	default:
+		_, _ = sql.WriteString("(1=1)")
+	}
+}
+
+// writeWhereIdentCondition same as writeWhereCondition but quotes an
+// identifier which can contain a table name or alias.
+func writeWhereIdentCondition(sql QueryWriter, ident string, pred string, anyConditions bool) bool {
+	if anyConditions {
+		_, _ = sql.WriteString(" AND (")
+	} else {
+		_, _ = sql.WriteRune('(')
+		anyConditions = true
+	}
+	Quoter.writeQuotedIdentifier(ident, sql)
+	_, _ = sql.WriteString(pred)
+	_, _ = sql.WriteRune(')')
+	return anyConditions
+}
@@ -0,0 +1,146 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbr
+
+import (
+	"testing"
+
+	"github.com/corestoreio/csfw/util/bufferpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhereTypedConditions(t *testing.T) {
+	tests := []struct {
+		cond     ConditionArg
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{Gt("a", 1), "(`a` > ?)", []interface{}{1}},
+		{Gte("e.a", 1), "(`e`.`a` >= ?)", []interface{}{1}},
+		{Lt("`e`.`a`", 2.5), "(`e`.`a` < ?)", []interface{}{2.5}},
+		{Lte("a", "z"), "(`a` <= ?)", []interface{}{"z"}},
+		{Between("a", 1, 10), "(`a` BETWEEN ? AND ?)", []interface{}{1, 10}},
+		{Like("sku", "abc%"), "(`sku` LIKE ?)", []interface{}{"abc%"}},
+		{NotLike("sku", "%xyz"), "(`sku` NOT LIKE ?)", []interface{}{"%xyz"}},
+		{IsNull("a"), "(`a` IS NULL)", nil},
+		{NotNull("a"), "(`a` IS NOT NULL)", nil},
+		{In("a", []int64{1, 2}), "(`a` IN ?)", []interface{}{[]int64{1, 2}}},
+		{In("a", []int64{3}), "(`a` = ?)", []interface{}{int64(3)}},
+		{In("a", []int64{}), "(1=0)", nil},
+		{NotIn("a", []string{"x", "y"}), "(`a` NOT IN ?)", []interface{}{[]string{"x", "y"}}},
+		{NotIn("a", []string{"x"}), "(`a` != ?)", []interface{}{"x"}},
+		{NotIn("a", []string{}), "(1=1)", nil},
+		{NotIn("a", 4), "(`a` != ?)", []interface{}{4}},
+		{Or(), "(1=0)", nil},
+		{And(), "(1=1)", nil},
+		{
+			Or(Gt("a", 1), And(Lt("b", 2), IsNull("c")), ConditionRaw("d = ?", 3)),
+			"((`a` > ?) OR ((`b` < ?) AND (`c` IS NULL)) OR (d = ?))",
+			[]interface{}{1, 2, 3},
+		},
+		{
+			Or(ConditionMap(Eq{"a": 1}), NotNull("b")),
+			"((`a` = ?) OR (`b` IS NOT NULL))",
+			[]interface{}{1},
+		},
+		{
+			Or(ConditionMap(Eq{}), Gt("b", 2)),
+			"((`b` > ?))",
+			[]interface{}{2},
+		},
+		{
+			And(Gt("a", 1), ConditionMap(Eq{}), Lt("b", 2)),
+			"((`a` > ?) AND (`b` < ?))",
+			[]interface{}{1, 2},
+		},
+		{Or(ConditionMap(Eq{}), And()), "((1=1))", nil},
+		{Or(ConditionMap(Eq{})), "(1=0)", nil},
+	}
+	for i, test := range tests {
+		buf := bufferpool.Get()
+		var args []interface{}
+		writeWhereFragmentsToSql(newWhereFragments(test.cond), buf, &args)
+		assert.Exactly(t, test.wantSQL, buf.String(), "Index %d", i)
+		assert.Exactly(t, test.wantArgs, args, "Index %d", i)
+		bufferpool.Put(buf)
+	}
+}
+
+func TestWhereTypedConditionsBuilders(t *testing.T) {
+	s := createFakeSession()
+
+	sql, args, err := s.Select("a").From("b", "bb").
+		Join(JoinTable("c", "cc"), JoinColumns("cc.d"), ConditionRaw("cc.id = bb.id"), NotNull("cc.d")).
+		Where(Gte("bb.e", 1), Or(Like("bb.f", "x%"), IsNull("bb.f"))).
+		GroupBy("bb.g").
+		Having(Between("COUNT(*)", 2, 3)).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "SELECT a, cc.d FROM `b` AS `bb` INNER JOIN `c` AS `cc` ON (cc.id = bb.id) AND (`cc`.`d` IS NOT NULL) WHERE (`bb`.`e` >= ?) AND ((`bb`.`f` LIKE ?) OR (`bb`.`f` IS NULL)) GROUP BY bb.g HAVING (COUNT(*) BETWEEN ? AND ?)", sql)
+	assert.Exactly(t, []interface{}{1, "x%", 2, 3}, args)
+
+	fullSql, err := Preprocess(sql, args)
+	assert.NoError(t, err)
+	assert.Exactly(t, "SELECT a, cc.d FROM `b` AS `bb` INNER JOIN `c` AS `cc` ON (cc.id = bb.id) AND (`cc`.`d` IS NOT NULL) WHERE (`bb`.`e` >= 1) AND ((`bb`.`f` LIKE 'x%') OR (`bb`.`f` IS NULL)) GROUP BY bb.g HAVING (COUNT(*) BETWEEN 2 AND 3)", fullSql)
+
+	sql, args, err = s.Update("a").Set("b", 1).Where(NotIn("c", []int{1, 2}), Lt("d", 5)).ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "UPDATE `a` SET `b` = ? WHERE (`c` NOT IN ?) AND (`d` < ?)", sql)
+	assert.Exactly(t, []interface{}{1, []int{1, 2}, 5}, args)
+
+	sql, args, err = s.DeleteFrom("a").Where(And(Gt("b", 1), Lte("b", 9))).ToSql()
+	assert.NoError(t, err)
+	assert.Exactly(t, "DELETE FROM `a` WHERE ((`b` > ?) AND (`b` <= ?))", sql)
+	assert.Exactly(t, []interface{}{1, 9}, args)
+}
+
+func TestWhereTypedConditionsNoExtraAllocs(t *testing.T) {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	args := make([]interface{}, 0, 4)
+
+	eqFragments := newWhereFragments(ConditionMap(Eq{"a": 1}))
+	typedFragments := newWhereFragments(Gt("a", 1))
+
+	eqAllocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		args = args[:0]
+		writeWhereFragmentsToSql(eqFragments, buf, &args)
+	})
+	typedAllocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		args = args[:0]
+		writeWhereFragmentsToSql(typedFragments, buf, &args)
+	})
+	assert.True(t, typedAllocs <= eqAllocs, "Typed %f Eq %f", typedAllocs, eqAllocs)
+}
+
+func BenchmarkWhereEq(b *testing.B) {
+	s := createFakeSession()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = s.Select("a").From("b").Where(ConditionMap(Eq{"c": 1})).ToSql()
+	}
+}
+
+func BenchmarkWhereTyped(b *testing.B) {
+	s := createFakeSession()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = s.Select("a").From("b").Where(Gt("c", 1)).ToSql()
+	}
+}
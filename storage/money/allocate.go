@@ -0,0 +1,125 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package money
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrRatio occurs when the ratios of Allocate are empty, negative or sum up
+// to zero.
+var ErrRatio = errors.New("Invalid allocation ratios")
+
+// Allocate splits the money by the ratios without losing a single unit. The
+// parts always sum up to the original amount. The smallest unit is defined
+// by the precision or, if set, by the interval of the Swedish rounding. The
+// remaining units get distributed one by one starting with the first part.
+// An amount which is not a multiple of the Swedish interval adds the
+// difference to the first part.
+//	m := money.New(money.WithPrecision(100)).Setf(0.05)
+//	m.Allocate(3, 7) // 0.02, 0.03
+//	m.Allocate(1, 1, 1) // 0.02, 0.02, 0.01
+// All parts keep the options of m. Invalid ratios return nil and log
+// ErrRatio.
+func (m Money) Allocate(ratios ...int) []Money {
+	var total int64
+	for _, r := range ratios {
+		if r < 0 {
+			total = 0
+			break
+		}
+		total += int64(r)
+	}
+	if total == 0 {
+		PkgLog.Debug("money.Money.Allocate", "err", ErrRatio, "m", m, "ratios", ratios)
+		return nil
+	}
+
+	raw := m.Big()
+	sign := raw.Sign()
+	raw.Abs(raw)
+	unit := big.NewInt(m.unit())
+	units, rest := new(big.Int).QuoRem(raw, unit, new(big.Int))
+
+	bigTotal := big.NewInt(total)
+	shares := make([]*big.Int, len(ratios))
+	left := new(big.Int).Set(units)
+	for i, r := range ratios {
+		shares[i] = new(big.Int).Mul(units, big.NewInt(int64(r)))
+		shares[i].Quo(shares[i], bigTotal)
+		left.Sub(left, shares[i])
+	}
+	// left is always less than the number of ratios
+	one := big.NewInt(1)
+	for i := 0; left.Sign() > 0; i = (i + 1) % len(ratios) {
+		if ratios[i] == 0 {
+			continue
+		}
+		shares[i].Add(shares[i], one)
+		left.Sub(left, one)
+	}
+
+	parts := make([]Money, len(ratios))
+	for i, s := range shares {
+		s.Mul(s, unit)
+		if i == 0 {
+			s.Add(s, rest)
+		}
+		if sign < 0 {
+			s.Neg(s)
+		}
+		parts[i] = m.SetBig(s)
+	}
+	return parts
+}
+
+// Split divides the money into n equal parts. Remaining units get added to
+// the first parts. Returns nil if n is less than one.
+//	m := money.New(money.WithPrecision(100)).Setf(1)
+//	m.Split(3) // 0.34, 0.33, 0.33
+func (m Money) Split(n int) []Money {
+	if n < 1 {
+		PkgLog.Debug("money.Money.Split", "err", ErrRatio, "m", m, "n", n)
+		return nil
+	}
+	ratios := make([]int, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// unit returns the smallest allocatable amount in the internal
+// representation. With Swedish rounding the unit is the interval.
+func (m Money) unit() int64 {
+	var u int64
+	switch m.Interval {
+	case Interval005:
+		u = m.dp / 20
+	case Interval010, Interval015:
+		u = m.dp / 10
+	case Interval025:
+		u = m.dp / 4
+	case Interval050:
+		u = m.dp / 2
+	case Interval100:
+		u = m.dp
+	}
+	if u < 1 {
+		u = 1
+	}
+	return u
+}
@@ -0,0 +1,131 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package money_test
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/corestoreio/csfw/storage/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func rawParts(ms []money.Money) []int64 {
+	if ms == nil {
+		return nil
+	}
+	r := make([]int64, len(ms))
+	for i, m := range ms {
+		r[i] = m.Raw()
+	}
+	return r
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		prec   int
+		iv     money.Interval
+		raw    int64
+		ratios []int
+		want   []int64
+	}{
+		{100, money.Interval000, 5, []int{3, 7}, []int64{2, 3}},
+		{100, money.Interval000, 5, []int{1, 1, 1}, []int64{2, 2, 1}},
+		{100, money.Interval000, -5, []int{1, 1, 1}, []int64{-2, -2, -1}},
+		{100, money.Interval000, 100, []int{1, 0, 1}, []int64{50, 0, 50}},
+		{100, money.Interval000, 1, []int{0, 1}, []int64{0, 1}},
+		{100, money.Interval000, 0, []int{1, 2}, []int64{0, 0}},
+		{100, money.Interval000, 10000, []int{70, 20, 10}, []int64{7000, 2000, 1000}},
+		{1000, money.Interval000, 10000, []int{1, 1, 1}, []int64{3334, 3333, 3333}},
+		{100, money.Interval005, 100, []int{1, 1, 1}, []int64{35, 35, 30}},
+		{100, money.Interval005, 102, []int{1, 1, 1}, []int64{37, 35, 30}},
+		{100, money.Interval100, 1000, []int{1, 2}, []int64{400, 600}},
+		{100, money.Interval000, 5, nil, nil},
+		{100, money.Interval000, 5, []int{0, 0}, nil},
+		{100, money.Interval000, 5, []int{1, -1}, nil},
+	}
+	for _, test := range tests {
+		m := money.New(money.WithPrecision(test.prec), money.WithSwedish(test.iv)).Set(test.raw)
+		have := m.Allocate(test.ratios...)
+		assert.Exactly(t, test.want, rawParts(have), "Raw %d Ratios %v", test.raw, test.ratios)
+		for _, p := range have {
+			assert.Exactly(t, m.Precision(), p.Precision())
+			assert.Exactly(t, test.iv, p.Interval)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	m := money.New(money.WithPrecision(100)).Setf(1)
+	assert.Exactly(t, []int64{34, 33, 33}, rawParts(m.Split(3)))
+	assert.Exactly(t, []int64{100}, rawParts(m.Split(1)))
+	assert.Nil(t, m.Split(0))
+}
+
+func TestAllocateConservation(t *testing.T) {
+	ivs := []money.Interval{money.Interval000, money.Interval005, money.Interval010, money.Interval025, money.Interval050, money.Interval100}
+	f := func(raw int64, ratios []uint16, iv uint8) bool {
+		rs := make([]int, len(ratios))
+		for i, r := range ratios {
+			rs[i] = int(r)
+		}
+		m := money.New(money.WithSwedish(ivs[int(iv)%len(ivs)])).Set(raw)
+		parts := m.Allocate(rs...)
+		if parts == nil {
+			return true
+		}
+		if len(parts) != len(rs) {
+			return false
+		}
+		var sum int64
+		for i, p := range parts {
+			sum += p.Raw()
+			if rs[i] == 0 && i > 0 && p.Raw() != 0 {
+				return false
+			}
+			if p.Raw() != 0 && (p.Raw() < 0) != (raw < 0) {
+				return false
+			}
+		}
+		return sum == raw
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSplitFairness(t *testing.T) {
+	f := func(raw int32, n uint8) bool {
+		if n == 0 {
+			return money.New().Split(0) == nil
+		}
+		parts := money.New().Set(int64(raw)).Split(int(n))
+		var sum int64
+		min, max := parts[0].Raw(), parts[0].Raw()
+		for _, p := range parts {
+			sum += p.Raw()
+			if p.Raw() < min {
+				min = p.Raw()
+			}
+			if p.Raw() > max {
+				max = p.Raw()
+			}
+		}
+		return sum == int64(raw) && max-min <= 1
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
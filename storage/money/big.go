@@ -0,0 +1,157 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package money
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// maxExactFloat is the largest integer which a float64 can represent without
+// losing a digit in its shortest string representation.
+const maxExactFloat = 1e15
+
+var (
+	bigMaxInt64 = big.NewInt(math.MaxInt64)
+	bigMinInt64 = big.NewInt(math.MinInt64)
+)
+
+// IsBig returns true if the value does not fit into an int64 and the
+// arbitrary-precision representation is in use. Raw() cannot be used then.
+func (m Money) IsBig() bool {
+	return m.b != nil
+}
+
+// Big returns the raw value, like Raw(), as a new big.Int. Works for all
+// values.
+func (m Money) Big() *big.Int {
+	if m.b != nil {
+		return new(big.Int).Set(m.b)
+	}
+	return big.NewInt(m.m)
+}
+
+// intBig returns the integer part like Geti() but without the int64 limit.
+func (m Money) intBig() *big.Int {
+	if m.b != nil {
+		return new(big.Int).Quo(m.b, big.NewInt(m.dp))
+	}
+	return big.NewInt(m.m / m.dp)
+}
+
+// SetBig sets the raw value, like Set(). If the value fits into an int64 the
+// fast int64 representation gets used.
+func (m Money) SetBig(i *big.Int) Money {
+	m.Valid = true
+	if i.Cmp(bigMaxInt64) <= 0 && i.Cmp(bigMinInt64) >= 0 {
+		m.m, m.b = i.Int64(), nil
+		return m
+	}
+	m.m, m.b = 0, new(big.Int).Set(i)
+	return m
+}
+
+// Rat returns the exact value of the money.
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(m.Big(), big.NewInt(m.dp))
+}
+
+// SetRat sets the exact value and rounds half towards plus infinity to the
+// precision, like Setf().
+func (m Money) SetRat(r *big.Rat) Money {
+	v := new(big.Rat).Mul(r, new(big.Rat).SetInt64(m.dp))
+	return m.SetBig(roundHalfUp(v))
+}
+
+// roundHalfUp rounds half towards plus infinity which is the same behaviour
+// as rnd().
+func roundHalfUp(v *big.Rat) *big.Int {
+	n := new(big.Int).Mul(v.Num(), big.NewInt(2))
+	n.Add(n, v.Denom())
+	d := new(big.Int).Mul(v.Denom(), big.NewInt(2))
+	// floor division
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() < 0 {
+		q.Sub(q, big.NewInt(1))
+	}
+	return q
+}
+
+// roundHalfAway rounds half away from zero which is the same behaviour as
+// util.Round().
+func roundHalfAway(v *big.Rat) *big.Int {
+	n := new(big.Int).Abs(v.Num())
+	n.Mul(n, big.NewInt(2))
+	n.Add(n, v.Denom())
+	q := n.Quo(n, new(big.Int).Mul(v.Denom(), big.NewInt(2)))
+	if v.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q
+}
+
+// powRat returns r to the power of n, calculated exactly.
+func powRat(r *big.Rat, n int64) *big.Rat {
+	neg := n < 0
+	if neg {
+		n = -n
+	}
+	z := new(big.Rat).SetInt64(1)
+	b := new(big.Rat).Set(r)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			z.Mul(z, b)
+		}
+		b.Mul(b, b)
+	}
+	if neg && z.Sign() != 0 {
+		z.Inv(z)
+	}
+	return z
+}
+
+// ratFromFloat converts the shortest decimal representation of f, so 2.01
+// becomes exactly 2.01 and not its binary approximation.
+func ratFromFloat(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return r
+}
+
+// fitsFloat reports whether the raw value can be converted to a float64
+// without losing a decimal digit.
+func (m Money) fitsFloat() bool {
+	return m.b == nil && m.m < maxExactFloat && m.m > -maxExactFloat
+}
+
+// isPow10 reports whether p is 1, 10, 100, 1000 and so on.
+func isPow10(p int64) bool {
+	for p > 1 && p%10 == 0 {
+		p /= 10
+	}
+	return p == 1
+}
+
+// mulInt64 returns a*b and false if the multiplication overflows.
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return c, true
+}
@@ -0,0 +1,125 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package money_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/corestoreio/csfw/storage/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBigScanValueDecimal20_6(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantBig bool
+		want    interface{}
+	}{
+		{"12345678901234.567891", true, []byte("12345678901234.567891")},
+		{"-99999999999999.999999", true, []byte("-99999999999999.999999")},
+		{"1234.500000", false, 1234.5},
+		{"0.000001", false, 0.000001},
+	}
+	for _, test := range tests {
+		m := money.New(money.WithPrecision(1000000))
+		assert.NoError(t, m.Scan([]byte(test.src)))
+		assert.Exactly(t, 6, m.Precision())
+		assert.Exactly(t, test.wantBig, m.IsBig(), "%s", test.src)
+		have, err := m.Value()
+		assert.NoError(t, err)
+		assert.Exactly(t, test.want, have, "%s", test.src)
+	}
+}
+
+func TestBigFastPath(t *testing.T) {
+	m := money.New().SetBig(big.NewInt(12345))
+	assert.False(t, m.IsBig())
+	assert.Exactly(t, int64(12345), m.Raw())
+
+	m = m.SetBig(new(big.Int).Lsh(big.NewInt(1), 70))
+	assert.True(t, m.IsBig())
+	assert.Exactly(t, int64(0), m.Raw())
+	assert.Exactly(t, "1180591620717411303424", m.Big().String())
+
+	// back into the int64 range
+	m = m.Sub(money.New().SetBig(new(big.Int).Lsh(big.NewInt(1), 70)))
+	assert.False(t, m.IsBig())
+	assert.Exactly(t, int64(0), m.Raw())
+}
+
+func TestBigArithmetic(t *testing.T) {
+	max := money.New(money.WithPrecision(100)).Set(math.MaxInt64)
+
+	sum := max.Add(max)
+	assert.True(t, sum.IsBig())
+	assert.Exactly(t, "184467440737095516.14", string(sum.Ftoa()))
+	assert.Exactly(t, 1, sum.Sign())
+	assert.Exactly(t, "-184467440737095516.14", string(sum.Neg().Ftoa()))
+	assert.Exactly(t, "184467440737095516.14", string(sum.Neg().Abs().Ftoa()))
+	assert.Exactly(t, int64(14), sum.Dec())
+
+	assert.Exactly(t, "92233720368547758.07", string(sum.Div(money.New(money.WithPrecision(100)).Setf(2)).Ftoa()))
+	assert.Exactly(t, "276701161105643274.21", string(sum.Mulf(1.5).Ftoa()))
+	assert.Exactly(t, "1844674407370955161.40", string(sum.Mul(money.New(money.WithPrecision(100)).Setf(10)).Ftoa()))
+
+	have, err := sum.CompareTo(max)
+	assert.NoError(t, err)
+	assert.Exactly(t, 1, have)
+
+	parts := sum.Split(2)
+	assert.Exactly(t, "92233720368547758.07", string(parts[0].Ftoa()))
+	assert.Exactly(t, "92233720368547758.07", string(parts[1].Ftoa()))
+	assert.False(t, parts[0].IsBig())
+
+	assert.Exactly(t, "$\u00a0184,467,440,737,095,516.14", sum.String())
+	assert.Exactly(t, "$\u00a0-184,467,440,737,095,516.14", sum.Neg().String())
+}
+
+func TestBigSetf(t *testing.T) {
+	m := money.New().Setf(1e20)
+	assert.True(t, m.IsBig())
+	assert.Exactly(t, "100000000000000000000.0000", string(m.Ftoa()))
+	assert.Exactly(t, 1e20, m.Getf())
+}
+
+func TestBigDivZero(t *testing.T) {
+	assert.Exactly(t, money.New(), money.New().Setf(1).Div(money.New()))
+}
+
+func TestBigPow(t *testing.T) {
+	i, _ := new(big.Int).SetString("123456789012345678901", 10)
+	m := money.New(money.WithPrecision(100)).SetBig(i)
+	assert.Exactly(t, "1234567890123456789.01", string(m.Pow(1).Ftoa()))
+	assert.Exactly(t, "1524157875323883675043743356552659656.78", string(m.Pow(2).Ftoa()))
+}
+
+func TestBigSwedish(t *testing.T) {
+	i, _ := new(big.Int).SetString("123456789012345678901", 10)
+	m := money.New(money.WithPrecision(100)).SetBig(i)
+	assert.Exactly(t, "1234567890123456789.01", string(m.Swedish().Ftoa()))
+	assert.Exactly(t, "1234567890123456789.00", string(m.Swedish(money.WithSwedish(money.Interval005)).Ftoa()))
+	assert.Exactly(t, "1234567890123456789.00", string(m.Swedish(money.WithSwedish(money.Interval100)).Ftoa()))
+	assert.Exactly(t, "-1234567890123456789.50", string(m.Neg().Add(money.New(money.WithPrecision(100)).Setf(-0.24)).Swedish(money.WithSwedish(money.Interval050)).Ftoa()))
+}
+
+func TestBigScanNil(t *testing.T) {
+	m := money.New().SetBig(new(big.Int).Lsh(big.NewInt(1), 70))
+	assert.NoError(t, m.Scan(nil))
+	assert.False(t, m.IsBig())
+	assert.False(t, m.Valid)
+	assert.Exactly(t, int64(0), m.Raw())
+}
@@ -0,0 +1,168 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package money
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/juju/errgo"
+	"golang.org/x/text/currency"
+)
+
+// ErrCurrencyMismatch occurs when two money values with different currencies
+// get added, subtracted or compared.
+var ErrCurrencyMismatch = errors.New("Currency mismatch")
+
+// WithCurrency sets the Valuta and derives the precision and the Swedish
+// rounding from the CLDR supplemental currency data. JPY has no decimals,
+// EUR two decimals and CHF gets rounded to 0.05 in cash transactions. Apply
+// this option before setting a value because the precision changes.
+func WithCurrency(u currency.Unit) Option {
+	return func(m *Money) Option {
+		prevU, prevDP, prevIv := m.Valuta, int(m.dp), m.Interval
+		m.Valuta = u
+		scale, _ := currency.Standard.Rounding(u)
+		m.dp, m.dpf, m.prec = precision(int(pow10(scale)))
+		m.Interval = cashInterval(u)
+		return func(m *Money) Option {
+			previous := WithCurrency(m.Valuta)
+			m.Valuta = prevU
+			m.dp, m.dpf, m.prec = precision(prevDP)
+			m.Interval = prevIv
+			return previous
+		}
+	}
+}
+
+// cashInterval maps the CLDR cash rounding increment to an Interval. Only
+// currencies whose cash rounding differs from the standard rounding get an
+// interval, e.g. CHF 0.05 or HUF and CZK whole units.
+func cashInterval(u currency.Unit) Interval {
+	scale, inc := currency.Cash.Rounding(u)
+	if stdScale, stdInc := currency.Standard.Rounding(u); scale == stdScale && inc == stdInc {
+		return Interval000
+	}
+	// normalize the increment to units of 10^-2
+	for ; scale > 2 && inc%10 == 0; scale-- {
+		inc /= 10
+	}
+	for ; scale < 2; scale++ {
+		inc *= 10
+	}
+	if scale != 2 {
+		return Interval000
+	}
+	switch inc {
+	case 5:
+		return Interval005
+	case 10:
+		return Interval010
+	case 25:
+		return Interval025
+	case 50:
+		return Interval050
+	case 100:
+		return Interval100
+	}
+	return Interval000
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+// hasValuta reports whether the currency has been set.
+func hasValuta(u currency.Unit) bool {
+	return u != currency.Unit{}
+}
+
+// CheckCurrency returns ErrCurrencyMismatch if both money values have a
+// currency and the currencies differ. A money without a currency matches
+// all currencies.
+func (m Money) CheckCurrency(d Money) error {
+	if hasValuta(m.Valuta) && hasValuta(d.Valuta) && m.Valuta != d.Valuta {
+		return errgo.WithCausef(nil, ErrCurrencyMismatch, "%s: %s and %s", ErrCurrencyMismatch, m.Valuta, d.Valuta)
+	}
+	return nil
+}
+
+// AddChecked adds two money values and returns an error on different
+// currencies. The result has the currency of the money which has one. On
+// integer overflow the arbitrary-precision representation gets used.
+func (m Money) AddChecked(d Money) (Money, error) {
+	if err := m.CheckCurrency(d); err != nil {
+		return New(), err
+	}
+	r := m.m + d.m
+	switch {
+	case m.b != nil || d.b != nil || (r^m.m)&(r^d.m) < 0:
+		m = m.SetBig(new(big.Int).Add(m.Big(), d.Big()))
+	default:
+		m.m = r
+		m.Valid = true
+	}
+	if !hasValuta(m.Valuta) {
+		m.Valuta = d.Valuta
+	}
+	return m, nil
+}
+
+// SubChecked subtracts d from m and returns an error on different
+// currencies. The result has the currency of the money which has one. On
+// integer overflow the arbitrary-precision representation gets used.
+func (m Money) SubChecked(d Money) (Money, error) {
+	if err := m.CheckCurrency(d); err != nil {
+		return New(), err
+	}
+	r := m.m - d.m
+	switch {
+	case m.b != nil || d.b != nil || (r^m.m)&^(r^d.m) < 0:
+		valid := m.Valid
+		m = m.SetBig(new(big.Int).Sub(m.Big(), d.Big()))
+		m.Valid = valid
+	default:
+		m.m = r
+	}
+	if !hasValuta(m.Valuta) {
+		m.Valuta = d.Valuta
+	}
+	return m, nil
+}
+
+// CompareTo returns -1 if m is less than d, 0 if both are equal and +1 if m
+// is greater than d. Different precisions get considered. Returns an error
+// on different currencies.
+func (m Money) CompareTo(d Money) (int, error) {
+	if err := m.CheckCurrency(d); err != nil {
+		return 0, err
+	}
+	if m.dp == d.dp && m.b == nil && d.b == nil {
+		switch {
+		case m.m < d.m:
+			return -1, nil
+		case m.m > d.m:
+			return 1, nil
+		}
+		return 0, nil
+	}
+	a := new(big.Int).Mul(m.Big(), big.NewInt(d.dp))
+	b := new(big.Int).Mul(d.Big(), big.NewInt(m.dp))
+	return a.Cmp(b), nil
+}
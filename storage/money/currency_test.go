@@ -0,0 +1,120 @@
+// Copyright 2015-2016, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package money_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/corestoreio/csfw/storage/money"
+	"github.com/juju/errgo"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/currency"
+)
+
+func TestWithCurrency(t *testing.T) {
+	tests := []struct {
+		cur      currency.Unit
+		wantPrec int
+		wantIv   money.Interval
+	}{
+		{currency.EUR, 2, money.Interval000},
+		{currency.JPY, 0, money.Interval000},
+		{currency.CHF, 2, money.Interval005},
+		{currency.MustParseISO("BHD"), 3, money.Interval000},
+		{currency.MustParseISO("HUF"), 2, money.Interval100},
+		{currency.MustParseISO("CZK"), 2, money.Interval100},
+	}
+	for _, test := range tests {
+		m := money.New(money.WithCurrency(test.cur))
+		assert.Exactly(t, test.cur, m.Valuta)
+		assert.Exactly(t, test.wantPrec, m.Precision(), "%s", test.cur)
+		assert.Exactly(t, test.wantIv, m.Interval, "%s", test.cur)
+	}
+
+	m := money.New()
+	prev := m.Option(money.WithCurrency(currency.JPY))
+	assert.Exactly(t, 0, m.Precision())
+	m.Option(prev)
+	assert.Exactly(t, 4, m.Precision())
+	assert.Exactly(t, currency.Unit{}, m.Valuta)
+
+	chf := money.New(money.WithCurrency(currency.CHF)).Setf(1.23)
+	assert.Exactly(t, int64(125), chf.Swedish().Raw())
+
+	huf := money.New(money.WithCurrency(currency.MustParseISO("HUF"))).Setf(1234.56)
+	assert.Exactly(t, int64(123500), huf.Swedish().Raw())
+}
+
+func TestAddSubChecked(t *testing.T) {
+	eur1 := money.New(money.WithCurrency(currency.EUR)).Setf(1.5)
+	eur2 := money.New(money.WithCurrency(currency.EUR)).Setf(2.25)
+	usd := money.New(money.WithCurrency(currency.USD)).Setf(1)
+	none := money.New(money.WithPrecision(100)).Setf(1)
+
+	have, err := eur1.AddChecked(eur2)
+	assert.NoError(t, err)
+	assert.Exactly(t, int64(375), have.Raw())
+
+	have, err = eur1.SubChecked(eur2)
+	assert.NoError(t, err)
+	assert.Exactly(t, int64(-75), have.Raw())
+
+	have, err = none.AddChecked(eur1)
+	assert.NoError(t, err)
+	assert.Exactly(t, int64(250), have.Raw())
+	assert.Exactly(t, currency.EUR, have.Valuta)
+
+	_, err = eur1.AddChecked(usd)
+	assert.True(t, errgo.Cause(err) == money.ErrCurrencyMismatch, "Error: %s", err)
+	assert.EqualError(t, err, "Currency mismatch: EUR and USD")
+	_, err = eur1.SubChecked(usd)
+	assert.True(t, errgo.Cause(err) == money.ErrCurrencyMismatch, "Error: %s", err)
+
+	assert.Exactly(t, money.New(), eur1.Add(usd))
+	assert.Exactly(t, money.New(), eur1.Sub(usd))
+
+	have, err = money.New().Set(math.MaxInt64).AddChecked(money.New().Set(1))
+	assert.NoError(t, err)
+	assert.Exactly(t, "9223372036854775808", have.Big().String())
+	have, err = money.New().Set(math.MinInt64).SubChecked(money.New().Set(1))
+	assert.NoError(t, err)
+	assert.Exactly(t, "-9223372036854775809", have.Big().String())
+}
+
+func TestCompareTo(t *testing.T) {
+	eur := func(f float64) money.Money {
+		return money.New(money.WithCurrency(currency.EUR)).Setf(f)
+	}
+	tests := []struct {
+		a, b money.Money
+		want int
+	}{
+		{eur(1), eur(2), -1},
+		{eur(2), eur(2), 0},
+		{eur(3), eur(2), 1},
+		{eur(2), money.New().Setf(2), 0},
+		{money.New().Setf(2.0001), eur(2), 1},
+		{money.New().Setf(-2.0001), eur(-2), -1},
+	}
+	for i, test := range tests {
+		have, err := test.a.CompareTo(test.b)
+		assert.NoError(t, err, "Index %d", i)
+		assert.Exactly(t, test.want, have, "Index %d", i)
+	}
+
+	_, err := eur(1).CompareTo(money.New(money.WithCurrency(currency.JPY)).Setf(1))
+	assert.True(t, errgo.Cause(err) == money.ErrCurrencyMismatch, "Error: %s", err)
+}
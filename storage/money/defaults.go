@@ -16,7 +16,6 @@ package money
 
 import (
 	"errors"
-	"math"
 
 	"github.com/corestoreio/csfw/i18n"
 )
@@ -68,8 +67,7 @@ func DefaultGuard(g int) int {
 // 2 decimal places => 10^2; 3 decimal places => 10^3; x decimal places => 10^x
 // Returns the successful applied value.
 func DefaultPrecision(p int) int {
-	l := int64(math.Log(float64(p)))
-	if p == 0 || (p != 0 && (l%2) != 0) {
+	if p == 0 || !isPow10(int64(p)) {
 		p = gDPi
 	}
 	gDPi = p
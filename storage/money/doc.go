@@ -54,6 +54,38 @@ http://commandcenter.blogspot.com/2014/01/self-referential-functions-and-design.
 	defer m.Option(prev)
 	// do something with the different Swedish rounding
 
+Arbitrary precision
+
+Values which do not fit into an int64 switch automatically to a math/big
+based representation, so Add(), Sub(), Mul(), Mulf() and Div() do not overflow.
+Values which fit keep the fast int64 path. IsBig() reports the representation,
+Big() and SetBig() work with the raw value and Rat() returns the exact value.
+Scan() parses decimals exactly and Value() returns a decimal string if a
+float64 cannot hold all digits, e.g. for DECIMAL(20,6) columns:
+
+	m := New(WithPrecision(1000000))
+	err := m.Scan([]byte("12345678901234.567891"))
+
+Currencies
+
+WithCurrency() sets the Valuta field and derives the precision and the cash
+rounding from the CLDR supplemental currency data:
+
+	c := New(WithCurrency(currency.CHF)) // 2 decimals, Interval005
+
+Add(), Sub() and CompareTo() refuse to mix different currencies. Use
+AddChecked() and SubChecked() to get the ErrCurrencyMismatch error instead of
+an empty money type.
+
+Parsing
+
+ParseLocalized() reads user entered amounts with the currency formatter FmtCur
+and ParseNumber() with the number formatter FmtNum. Both are the inverse of
+Localize() and Number() and use the decimal and grouping separator of the
+formatter:
+
+	err := m.ParseLocalized("(1.234,56 €)") // -1234.56 with a German formatter
+
 Initial Idea: Copyright (c) 2011 Jad Dittmar
 https://github.com/Confunctionist/finance
 
@@ -52,17 +52,22 @@ func (m Money) MarshalJSON() ([]byte, error) {
 func (m *Money) UnmarshalJSON(src []byte) error {
 	m.applyDefaults()
 	if src == nil {
-		m.m, m.Valid = 0, false
+		m.m, m.b, m.Valid = 0, nil, false
 		return nil
 	}
 	return m.Decode(m, src)
 }
 
-// Value implements the SQL driver Valuer interface.
+// Value implements the SQL driver Valuer interface. Values with more digits
+// than a float64 can represent get returned as a decimal string, e.g. for
+// DECIMAL(20,6) columns.
 func (m Money) Value() (driver.Value, error) {
 	if !m.Valid {
 		return nil, nil
 	}
+	if !m.fitsFloat() {
+		return m.Ftoa(), nil
+	}
 	return m.Getf(), nil
 }
 
@@ -72,7 +77,7 @@ func (m *Money) Scan(src interface{}) error {
 	m.applyDefaults()
 
 	if src == nil {
-		m.m, m.Valid = 0, false
+		m.m, m.b, m.Valid = 0, nil, false
 		if PkgLog.IsDebug() {
 			PkgLog.Debug("money.Currency.Scan", "case", 87, "c", m, "src", src)
 		}
@@ -84,7 +84,7 @@ func (t JSONType) Decode(c *Money, b []byte) error {
 		if PkgLog.IsDebug() {
 			PkgLog.Debug("money.JSONType.UnmarshalJSON.1", "case", "invalid_bytes", "c", c, "bytes", string(b))
 		}
-		c.m, c.Valid = 0, false
+		c.m, c.b, c.Valid = 0, nil, false
 		return nil
 	}
 
@@ -106,7 +106,7 @@ func (t JSONType) Decode(c *Money, b []byte) error {
 		if PkgLog.IsDebug() {
 			PkgLog.Debug("money.JSONType.UnmarshalJSON.2", "case", "lenRunes=0", "c", c, "bytes", string(b))
 		}
-		c.m, c.Valid = 0, false
+		c.m, c.b, c.Valid = 0, nil, false
 		return nil
 	}
 
@@ -150,7 +150,7 @@ OuterLoop:
 			if PkgLog.IsDebug() {
 				PkgLog.Debug("money.JSONType.UnmarshalJSON.3", "case", "isNull", "c", c, "bytes", string(b), "runes", string(runes))
 			}
-			c.m, c.Valid = 0, false
+			c.m, c.b, c.Valid = 0, nil, false
 			return nil
 		}
 
@@ -158,7 +158,7 @@ OuterLoop:
 	}
 
 	if isArray { // now it's an error because no colon found
-		c.m, c.Valid = 0, false
+		c.m, c.b, c.Valid = 0, nil, false
 		if PkgLog.IsDebug() {
 			PkgLog.Debug("money.JSONType.UnmarshalJSON.MissingColon", "err", ErrDecodeMissingColon, "bytes", string(b), "number", string(number))
 		}
@@ -201,7 +201,7 @@ OuterLoop:
 		return c.ParseFloat(string(number))
 	}
 
-	c.m, c.Valid = 0, false
+	c.m, c.b, c.Valid = 0, nil, false
 	err := errgo.New("Invalid bytes")
 	if PkgLog.IsDebug() {
 		PkgLog.Debug("money.JSONType.UnmarshalJSON.Invalid", "err", err, "bytes", string(b), "number", string(number))
@@ -14,17 +14,11 @@
 
 package money
 
-/*
-	@todo
-	- http://unicode.org/reports/tr35/tr35-numbers.html#Supplemental_Currency_Data to automatically
-	- set the Swedish rounding
-	- Currency
-*/
-
 import (
 	"errors"
 	"io"
 	"math"
+	"math/big"
 	"strconv"
 
 	"github.com/corestoreio/csfw/i18n"
@@ -76,6 +70,9 @@ type (
 	Money struct {
 		// m money in Guard/DP
 		m int64
+		// b arbitrary-precision value in Guard/DP. Only set if the value
+		// does not fit into m.
+		b *big.Int
 		// FmtCur to allow language and format specific outputs in a currency format
 		FmtCur i18n.CurrencyFormatter
 		// FmtNum to allow language and format specific outputs in a number format
@@ -85,8 +82,8 @@ type (
 		// Interval defines how the swedish rounding can be applied.
 		Interval Interval
 
-		// Valuta TODO(cs) defines the currency of this money type and allows
-		// comparisons and calculations with other currencies.
+		// Valuta defines the currency of this money type. Calculations and
+		// comparisons with other currencies fail. See WithCurrency().
 		Valuta currency.Unit
 
 		Encoder // Encoder default ToJSON
@@ -197,8 +194,7 @@ func WithPrecision(p int) Option {
 // precision internal prec generator. Optimized to reduce allocs
 func precision(p int) (int64, float64, int) {
 	p64 := int64(p)
-	l := int64(math.Log(float64(p64)))
-	if p64 != 0 && (l%2) != 0 {
+	if p64 != 0 && !isPow10(p64) {
 		p64 = int64(gDPi)
 	}
 	if p64 == 0 { // check for division by zero
@@ -255,7 +251,7 @@ func (m *Money) Option(opts ...Option) (previous Option) {
 
 // Abs returns the absolute value of Currency
 func (m Money) Abs() Money {
-	if m.m < 0 {
+	if m.Sign() < 0 {
 		return m.Neg()
 	}
 	return m
@@ -263,28 +259,49 @@ func (m Money) Abs() Money {
 
 // Getf gets the float64 value of money (see Raw() for int64)
 func (m Money) Getf() float64 {
+	if m.b != nil {
+		f, _ := m.Rat().Float64()
+		return f
+	}
 	return float64(m.m) / m.dpf
 }
 
 // Geti gets value of money truncating after decimal precision (see Raw() for no truncation).
-// Rounds always down
+// Rounds always down. Returns 0 and logs ErrOverflow if the integer part does
+// not fit into an int64.
 func (m Money) Geti() int64 {
+	if m.b != nil {
+		q := new(big.Int).Quo(m.b, big.NewInt(m.dp))
+		if q.Cmp(bigMaxInt64) > 0 || q.Cmp(bigMinInt64) < 0 {
+			PkgLog.Debug("money.Currency.Geti.Overflow", "err", ErrOverflow, "m", m)
+			return 0
+		}
+		return q.Int64()
+	}
 	return m.m / m.dp
 }
 
 // Dec returns the decimals
 func (m Money) Dec() int64 {
+	if m.b != nil {
+		return new(big.Int).Rem(new(big.Int).Abs(m.b), big.NewInt(m.dp)).Int64()
+	}
 	return m.Abs().Raw() % m.dp
 }
 
 // Raw returns in int64 the value of Currency (also see Geti(), See Getf() for float64)
+// Returns 0 and logs ErrOverflow if IsBig() is true, use Big() instead.
 func (m Money) Raw() int64 {
+	if m.b != nil {
+		PkgLog.Debug("money.Currency.Raw.Overflow", "err", ErrOverflow, "m", m)
+		return 0
+	}
 	return m.m
 }
 
 // Set sets the raw Currency field m
 func (m Money) Set(i int64) Money {
-	m.m = i
+	m.m, m.b = i, nil
 	m.Valid = true
 	return m
 }
@@ -292,14 +309,26 @@ func (m Money) Set(i int64) Money {
 // Setf sets a float64 into a Currency type for precision calculations
 func (m Money) Setf(f float64) Money {
 	fDPf := f * m.dpf
+	if fDPf >= math.MaxInt64 || fDPf <= math.MinInt64 {
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			PkgLog.Debug("money.Currency.Setf", "err", ErrOverflow, "f", f)
+			return New()
+		}
+		return m.SetRat(ratFromFloat(f))
+	}
 	r := int64(f * m.dpf)
 	m.Valid = true
 	return m.Set(rnd(r, fDPf-float64(r)))
 }
 
-// ParseFloat transforms a string float value into a real float64 value and
-// sets it. Current value will be overridden. Returns a logged error.
+// ParseFloat transforms a string decimal value into the money type and sets
+// it. The value gets parsed exactly, so DECIMAL(20,6) columns do not lose any
+// digits. Current value will be overridden. Returns a logged error.
 func (m *Money) ParseFloat(s string) error {
+	if r, ok := new(big.Rat).SetString(s); ok {
+		*m = m.SetRat(r)
+		return nil
+	}
 	f, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		if PkgLog.IsDebug() {
@@ -312,12 +341,58 @@ func (m *Money) ParseFloat(s string) error {
 	return nil
 }
 
+// ParseLocalized parses a user entered amount with the currency formatter
+// FmtCur, e.g. "$ 1,234.56", "-1.234,56 €" or "(1’234.56)". The currency
+// sign is optional. This is the inverse of Localize(). Values get rounded to
+// the precision. Current value will be overridden. Returns a logged error.
+func (m *Money) ParseLocalized(s string) error {
+	if m.FmtCur == nil {
+		m.FmtCur = DefaultFormatterCurrency
+	}
+	return m.parseNumber(m.FmtCur, s)
+}
+
+// ParseNumber parses a user entered amount with the number formatter FmtNum,
+// e.g. "1,234.56" or "1 234,56". This is the inverse of Number(). Values get
+// rounded to the precision. Current value will be overridden. Returns a
+// logged error.
+func (m *Money) ParseNumber(s string) error {
+	if m.FmtNum == nil {
+		m.FmtNum = DefaultFormatterNumber
+	}
+	return m.parseNumber(m.FmtNum, s)
+}
+
+func (m *Money) parseNumber(nf i18n.NumberFormatter, s string) error {
+	sign, i, prec, frac, err := nf.ParseBigNumber(s)
+	if err != nil {
+		if PkgLog.IsDebug() {
+			PkgLog.Debug("money.Currency.ParseNumber", "err", err, "arg", s, "currency", m)
+		}
+		return errgo.Mask(err, errgo.Any)
+	}
+	shift := big.NewInt(pow10(prec))
+	v := new(big.Int).Mul(i.Abs(i), shift)
+	v.Add(v, big.NewInt(frac))
+	if sign < 0 {
+		v.Neg(v)
+	}
+	*m = m.SetRat(new(big.Rat).SetFrac(v, shift))
+	return nil
+}
+
 // Sign returns:
 //
 //	-1 if x <  0
 //	+1 if x >=  0
 //
 func (m Money) Sign() int {
+	if m.b != nil {
+		if m.b.Sign() < 0 {
+			return -1
+		}
+		return 1
+	}
 	if m.m < 0 {
 		return -1
 	}
@@ -342,6 +417,9 @@ func (m Money) LocalizeWriter(w io.Writer) (int, error) {
 	if false == m.Valid {
 		return w.Write(gNaN)
 	}
+	if m.b != nil {
+		return m.FmtCur.FmtBigNumber(w, m.Sign(), m.intBig(), m.Precision(), m.Dec())
+	}
 	return m.FmtCur.FmtNumber(w, m.Sign(), m.Geti(), m.Precision(), m.Dec())
 }
 
@@ -368,6 +446,9 @@ func (m Money) NumberWriter(w io.Writer) (int, error) {
 	if false == m.Valid {
 		return w.Write(gNaN)
 	}
+	if m.b != nil {
+		return m.FmtNum.FmtBigNumber(w, m.Sign(), m.intBig(), m.Precision(), m.Dec())
+	}
 	return m.FmtNum.FmtNumber(w, m.Sign(), m.Geti(), m.Precision(), m.Dec())
 }
 
@@ -391,72 +472,112 @@ func (m Money) FtoaAppend(dst []byte) []byte {
 	if dst == nil {
 		dst = make([]byte, 0, max(m.Precision()+4, 24))
 	}
+	if !m.fitsFloat() {
+		return append(dst, m.Rat().FloatString(m.Precision())...)
+	}
 	return strconv.AppendFloat(dst, m.Getf(), 'f', m.Precision(), 64)
 }
 
-// Add adds two Currency types. Returns empty Currency on integer overflow.
+// Add adds two Currency types. Returns empty Currency on different
+// currencies, see AddChecked().
 // Errors will be logged and a trace is available when the level for tracing has been set.
 func (m Money) Add(d Money) Money {
-	r := m.m + d.m
-	if (r^m.m)&(r^d.m) < 0 {
-		PkgLog.Debug("money.Currency.Add.Overflow", "err", ErrOverflow, "m", m, "n", d)
+	r, err := m.AddChecked(d)
+	if err != nil {
+		PkgLog.Debug("money.Currency.Add", "err", err, "m", m, "n", d)
 		return New()
 	}
-	m.m = r
-	m.Valid = true
-	return m
+	return r
 }
 
-// Sub subtracts one Currency type from another. Returns empty Currency on integer overflow.
+// Sub subtracts one Currency type from another. Returns empty Currency on
+// different currencies, see SubChecked().
 // Errors will be logged and a trace is available when the level for tracing has been set.
 func (m Money) Sub(d Money) Money {
-	r := m.m - d.m
-	if (r^m.m)&^(r^d.m) < 0 {
-		PkgLog.Debug("money.Currency.Sub.Overflow", "err", ErrOverflow, "m", m, "n", d)
+	r, err := m.SubChecked(d)
+	if err != nil {
+		PkgLog.Debug("money.Currency.Sub", "err", err, "m", m, "n", d)
 		return New()
 	}
-	m.m = r
-	return m
+	return r
 }
 
 // Mul multiplies two Currency types. Both types must have the same precision.
+// Switches to the arbitrary-precision representation if the product does not
+// fit into an int64.
 func (m Money) Mul(d Money) Money {
-	// @todo c.m*d.m will overflow int64
-	r := util.Round(float64(m.m*d.m)/m.dpf, .5, 0)
-	return m.Set(int64(r))
+	if p, ok := mulInt64(m.m, d.m); ok && m.b == nil && d.b == nil && p < 1<<53 && p > -1<<53 {
+		r := util.Round(float64(p)/m.dpf, .5, 0)
+		return m.Set(int64(r))
+	}
+	v := new(big.Rat).SetInt(new(big.Int).Mul(m.Big(), d.Big()))
+	v.Quo(v, new(big.Rat).SetInt64(m.dp))
+	return m.SetBig(roundHalfUp(v))
 }
 
-// Div divides one Currency type from another
+// Div divides one Currency type from another. Large values get divided with
+// arbitrary precision. Division by zero returns an empty Currency.
 func (m Money) Div(d Money) Money {
-	f := (m.guardf * m.dpf * float64(m.m)) / float64(d.m) / m.guardf
-	i := int64(f)
-	return m.Set(rnd(i, f-float64(i)))
+	if d.b == nil && d.m == 0 {
+		PkgLog.Debug("money.Currency.Div", "err", errors.New("Division by zero"), "m", m, "d", d)
+		return New()
+	}
+	if m.fitsFloat() && d.fitsFloat() && m.m < maxExactFloat/m.dp && m.m > -maxExactFloat/m.dp {
+		f := (m.guardf * m.dpf * float64(m.m)) / float64(d.m) / m.guardf
+		i := int64(f)
+		return m.Set(rnd(i, f-float64(i)))
+	}
+	v := new(big.Rat).SetFrac(new(big.Int).Mul(m.Big(), big.NewInt(m.dp)), d.Big())
+	return m.SetBig(roundHalfUp(v))
 }
 
 // Mulf multiplies a Currency with a float to return a money-stored type
 func (m Money) Mulf(f float64) Money {
-	i := m.m * int64(f*m.guardf*m.dpf)
-	r := i / m.guard / m.dp
-	return m.Set(rnd(r, float64(i)/m.guardf/m.dpf-float64(r)))
+	fg := f * m.guardf * m.dpf
+	if fg < math.MaxInt64 && fg > math.MinInt64 && m.b == nil {
+		if i, ok := mulInt64(m.m, int64(fg)); ok {
+			r := i / m.guard / m.dp
+			return m.Set(rnd(r, float64(i)/m.guardf/m.dpf-float64(r)))
+		}
+	}
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		PkgLog.Debug("money.Currency.Mulf", "err", ErrOverflow, "m", m, "f", f)
+		return New()
+	}
+	v := new(big.Rat).SetInt(m.Big())
+	v.Mul(v, ratFromFloat(f))
+	return m.SetBig(roundHalfUp(v))
 }
 
 // Neg returns the negative value of Currency
 func (m Money) Neg() Money {
+	if m.b != nil {
+		return m.SetBig(new(big.Int).Neg(m.b))
+	}
+	if m.m == math.MinInt64 {
+		return m.SetBig(new(big.Int).Neg(m.Big()))
+	}
 	if m.m != 0 {
 		m.m *= -1
 	}
 	return m
 }
 
-// Pow is the power of Currency
+// Pow is the power of Currency. Large values with an integer exponent get
+// calculated exactly.
 func (m Money) Pow(f float64) Money {
-	return m.Setf(math.Pow(m.Getf(), f))
+	if m.fitsFloat() || f != math.Trunc(f) || math.Abs(f) > 64 {
+		return m.Setf(math.Pow(m.Getf(), f))
+	}
+	return m.SetRat(powRat(m.Rat(), int64(f)))
 }
 
 // Swedish applies the Swedish rounding. You may set the usual options.
-// TODO: Consider text/currency package based on Valuta field
 func (m Money) Swedish(opts ...Option) Money {
 	m.Option(opts...)
+	if !m.fitsFloat() {
+		return m.swedishBig()
+	}
 	const (
 		roundOn float64 = .5
 		places  int     = 0
@@ -502,9 +623,33 @@ func (m Money) Swedish(opts ...Option) Money {
 	return m
 }
 
-// CompareTo depends on the Valuta field (TODO)
-func (m Money) CompareTo(d Money) bool {
-	return false
+// swedishBig applies the Swedish rounding to values which do not fit into a
+// float64. Same rules as in Swedish().
+func (m Money) swedishBig() Money {
+	var k int64
+	switch m.Interval {
+	case Interval005:
+		k = 20
+	case Interval010, Interval015:
+		k = 10
+	case Interval025:
+		k = 4
+	case Interval050:
+		k = 2
+	case Interval100:
+		k = 1
+	default:
+		return m
+	}
+	r := m.Rat()
+	if m.Interval == Interval015 {
+		i := m.Big()
+		if new(big.Int).Rem(i, big.NewInt(5)).Sign() == 0 {
+			r.SetFrac(i.Sub(i, big.NewInt(1)), big.NewInt(m.dp))
+		}
+	}
+	r.Mul(r, new(big.Rat).SetInt64(k))
+	return m.SetRat(new(big.Rat).SetFrac(roundHalfAway(r), big.NewInt(k)))
 }
 
 // rnd rounds int64 remainder rounded half towards plus infinity
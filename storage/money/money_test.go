@@ -21,6 +21,7 @@ import (
 
 	"github.com/corestoreio/csfw/i18n"
 	"github.com/corestoreio/csfw/storage/money"
+	"github.com/juju/errgo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -349,9 +350,9 @@ func TestMulNumber(t *testing.T) {
 		{100, 1300, -1300, "-169.000"},
 		{1000, 1300, -1300, "-1.690"},
 		{100, 13, 13, "0.020"},
-		{100, 45628734653, -45628734653, "250065429529630.200"}, // overflow of int64 ?
-		{100, 45628734653, -456287346, "-237307016244604.920"},
-		{100, math.MaxInt64, 2, "0.000"},
+		{100, 45628734653, -45628734653, "-208198142603388303.040"}, // exceeds int64
+		{100, 45628734653, -456287346, "-2081981423615560.090"},
+		{100, math.MaxInt64, 2, "1844674407370955.160"},
 	}
 
 	for _, test := range tests {
@@ -389,8 +390,8 @@ func TestMulf(t *testing.T) {
 		{100, 1300, -1300.01, "-16,900.130"},
 		{1000, 1300, -1300.01, "-1,690.013"},
 		{100, 13, 13.0, "1.690"},
-		{100, 45628734653, -45628734653.0, "-47,780,798,383.280"},
-		{100, math.MaxInt64, 2.01, "92,233,720,368.530"},
+		{100, 45628734653, -45628734653.0, "-20,819,814,260,338,830,304.090"}, // exceeds int64
+		{100, math.MaxInt64, 2.01, "185,389,777,940,780,993.720"},
 	}
 
 	for _, test := range tests {
@@ -409,26 +410,85 @@ func TestDiv(t *testing.T) {
 	tests := []struct {
 		have1 int64
 		have2 int64
-		want  int64
+		want  string
 	}{
-		{1300, 1300, 10000},
-		{13, -13, -10000},
-		{9000, -3000, -30000},
-		{13, 13, 10000},
-		{471100, 81500, 57804},
-		{45628734653, -45628734653, -10000},
-		{math.MaxInt64, 2, -9223372036854775807},
+		{1300, 1300, "10000"},
+		{13, -13, "-10000"},
+		{9000, -3000, "-30000"},
+		{13, 13, "10000"},
+		{471100, 81500, "57804"},
+		{45628734653, -45628734653, "-10000"},
+		{math.MaxInt64, 2, "46116860184273879035000"},
 	}
 
 	for _, test := range tests {
 		c := money.New().Set(test.have1)
 		c = c.Div(money.New().Set(test.have2))
-		have := c.Raw()
+		have := c.Big().String()
 		nob, err := c.Number()
 		assert.NoError(t, err)
 
 		if have != test.want {
-			t.Errorf("\nWant: %d\nHave: %d / %s\nIndex: %v\n", test.want, have, string(nob), test)
+			t.Errorf("\nWant: %s\nHave: %s / %s\nIndex: %v\n", test.want, have, string(nob), test)
+		}
+	}
+}
+
+func TestParseLocalized(t *testing.T) {
+	deFmtCur := i18n.NewCurrency(
+		i18n.SetCurrencyISO("EUR"),
+		i18n.SetCurrencySign([]byte("€")),
+		i18n.SetCurrencyFormat("#,##0.00 ¤;(#,##0.00 ¤)", i18n.Symbols{Decimal: ',', Group: '.'}),
+	)
+	tests := []struct {
+		fmtCur  i18n.CurrencyFormatter
+		have    string
+		want    string
+		wantErr bool
+	}{
+		{testFmtCur, "$ 1,234.56", "12345600", false},
+		{testFmtCur, "-1234.5", "-12345000", false},
+		{testFmtCur, "1234.56789", "12345679", false},
+		{testFmtCur, "1.234,56", "0", true},
+		{deFmtCur, "1.234,56 €", "12345600", false},
+		{deFmtCur, "(1.234,56 €)", "-12345600", false},
+		{deFmtCur, "EUR -0,05", "-500", false},
+		{deFmtCur, "1,234.56", "0", true},
+		{deFmtCur, "12.345.678.901.234.567.890,12 €", "123456789012345678901200", false}, // exceeds int64
+		{deFmtCur, "(12.345.678.901.234.567.890,12 €)", "-123456789012345678901200", false},
+	}
+	for i, test := range tests {
+		m := money.New()
+		m.FmtCur = test.fmtCur
+		err := m.ParseLocalized(test.have)
+		if test.wantErr {
+			assert.Error(t, err, "Index %d", i)
+			continue
 		}
+		assert.NoError(t, err, "Index %d", i)
+		assert.Exactly(t, test.want, m.Big().String(), "Index %d", i)
+		assert.True(t, m.Valid, "Index %d", i)
+
+		lm, err := m.Localize()
+		assert.NoError(t, err, "Index %d", i)
+		m2 := m.Set(0)
+		assert.NoError(t, m2.ParseLocalized(string(lm)), "Index %d", i)
+		lm2, err := m2.Localize()
+		assert.NoError(t, err, "Index %d", i)
+		assert.Exactly(t, string(lm), string(lm2), "Index %d", i)
 	}
+
+	m := money.New(money.WithPrecision(100))
+	assert.NoError(t, m.ParseNumber("1,234.56"))
+	assert.Exactly(t, int64(123456), m.Raw())
+
+	err := m.ParseNumber("1.234,56")
+	assert.True(t, errgo.Cause(err) == i18n.ErrNumberInvalid, "%s", err)
+	assert.NoError(t, m.ParseNumber("12345678901234567890"))
+	assert.Exactly(t, "1234567890123456789000", m.Big().String())
+	nb, err := m.Number()
+	assert.NoError(t, err)
+	assert.Exactly(t, "12,345,678,901,234,567,890.000", string(nb))
+	err = m.ParseNumber("1.1234567890123456789")
+	assert.True(t, errgo.Cause(err) == i18n.ErrNumberOverflow, "%s", err)
 }
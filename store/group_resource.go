@@ -26,6 +26,6 @@ import "github.com/corestoreio/csfw/storage/dbr"
 // @see app/code/Magento/Store/Model/Resource/Group/Collection.php::_beforeLoad()
 func (s *TableGroupSlice) SQLSelect(dbrSess dbr.SessionRunner, cbs ...dbr.SelectCb) (int, error) {
 	return s.parentSQLSelect(dbrSess, append(append([]dbr.SelectCb{nil}, func(sb *dbr.SelectBuilder) *dbr.SelectBuilder {
-		return sb.OrderBy("main_table.name ASC")
+		return sb.OrderBy(GroupColumns.Name.Asc())
 	}), cbs...)...)
 }
@@ -17,7 +17,7 @@ package store_test
 import (
 	"testing"
 
-	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
 	"github.com/corestoreio/csfw/storage/dbr"
 	"github.com/corestoreio/csfw/store"
 	"github.com/corestoreio/csfw/util"
@@ -25,7 +25,7 @@ import (
 )
 
 func init() {
-	dbc := csdb.MustConnectTest()
+	dbc := csdbtest.MustConnectTest("store_group_init")
 	defer func() {
 		if err := dbc.Close(); err != nil {
 			panic(err)
@@ -141,7 +141,7 @@ var testGroups = store.TableGroupSlice{
 }
 
 func TestTableGroupSliceLoad(t *testing.T) {
-	dbc := csdb.MustConnectTest()
+	dbc := csdbtest.MustConnectTest("TestTableGroupSliceLoad")
 	defer func() { assert.NoError(t, dbc.Close()) }()
 	dbrSess := dbc.NewSession()
 
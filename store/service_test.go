@@ -19,7 +19,7 @@ import (
 	std "log"
 	"testing"
 
-	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
 	"github.com/corestoreio/csfw/storage/dbr"
 	"github.com/corestoreio/csfw/store"
 	storemock "github.com/corestoreio/csfw/store/mock"
@@ -590,7 +590,7 @@ func TestNewServiceReInit(t *testing.T) {
 	t.Skip(TODO_Better_Test_Data)
 
 	// quick implement, use mock of dbr.SessionRunner and remove connection
-	dbc := csdb.MustConnectTest()
+	dbc := csdbtest.MustConnectTest("TestNewServiceReInit")
 	defer func() { assert.NoError(t, dbc.Close()) }()
 	dbrSess := dbc.NewSession()
 
@@ -17,7 +17,7 @@ package store_test
 import (
 	"testing"
 
-	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
 	"github.com/corestoreio/csfw/storage/dbr"
 	"github.com/corestoreio/csfw/store"
 	"github.com/corestoreio/csfw/store/scope"
@@ -448,7 +448,7 @@ func TestStorageReInitError(t *testing.T) {
 func TestStorageReInit(t *testing.T) {
 
 	// quick implement, use mock of dbr.SessionRunner and remove connection
-	dbc := csdb.MustConnectTest()
+	dbc := csdbtest.MustConnectTest("TestStorageReInit")
 	defer func() { assert.NoError(t, dbc.Close()) }()
 
 	nsg := store.MustNewStorage(nil, nil, nil)
@@ -32,9 +32,9 @@ func (s TableStore) IsDefault() bool {
 // regarding the sort order.
 func (s *TableStoreSlice) SQLSelect(dbrSess dbr.SessionRunner, cbs ...dbr.SelectCb) (int, error) {
 	return s.parentSQLSelect(dbrSess, append(append([]dbr.SelectCb{nil}, func(sb *dbr.SelectBuilder) *dbr.SelectBuilder {
-		sb.OrderBy("CASE WHEN main_table.store_id = 0 THEN 0 ELSE 1 END ASC")
-		sb.OrderBy("main_table.sort_order ASC")
-		return sb.OrderBy("main_table.name ASC")
+		sb.OrderBy("CASE WHEN " + StoreColumns.StoreID.Quote() + " = 0 THEN 0 ELSE 1 END ASC")
+		sb.OrderBy(StoreColumns.SortOrder.Asc())
+		return sb.OrderBy(StoreColumns.Name.Asc())
 	}), cbs...)...)
 }
 
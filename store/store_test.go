@@ -21,7 +21,7 @@ import (
 	"github.com/corestoreio/csfw/backend"
 	"github.com/corestoreio/csfw/config"
 	"github.com/corestoreio/csfw/config/model"
-	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
 	"github.com/corestoreio/csfw/storage/dbr"
 	"github.com/corestoreio/csfw/store"
 	"github.com/corestoreio/csfw/store/scope"
@@ -138,7 +138,7 @@ var testStores = store.TableStoreSlice{
 
 func TestTableStoreSliceLoad(t *testing.T) {
 	defer debugLogBuf.Reset()
-	dbc := csdb.MustConnectTest()
+	dbc := csdbtest.MustConnectTest("TestTableStoreSliceLoad")
 	defer func() { assert.NoError(t, dbc.Close()) }()
 	dbrSess := dbc.NewSession()
 
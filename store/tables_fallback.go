@@ -70,12 +70,42 @@ type TableStore struct {
 	IsActive  bool           `db:"is_active" json:",omitempty"`  // is_active smallint(5) unsigned NOT NULL MUL DEFAULT '0'
 }
 
+// StoreColumns contains the typed columns of DB table store
+// prefixed with the alias csdb.MainTable to be used in WHERE and ORDER BY.
+// Generated via tableToStruct.
+var StoreColumns = struct {
+	StoreID   dbr.ColumnInt64
+	Code      dbr.ColumnString
+	WebsiteID dbr.ColumnInt64
+	GroupID   dbr.ColumnInt64
+	Name      dbr.ColumnString
+	SortOrder dbr.ColumnInt64
+	IsActive  dbr.ColumnBool
+}{
+	StoreID:   dbr.NewColumnInt64(csdb.MainTable, "store_id"),
+	Code:      dbr.NewColumnString(csdb.MainTable, "code"),
+	WebsiteID: dbr.NewColumnInt64(csdb.MainTable, "website_id"),
+	GroupID:   dbr.NewColumnInt64(csdb.MainTable, "group_id"),
+	Name:      dbr.NewColumnString(csdb.MainTable, "name"),
+	SortOrder: dbr.NewColumnInt64(csdb.MainTable, "sort_order"),
+	IsActive:  dbr.NewColumnBool(csdb.MainTable, "is_active"),
+}
+
 // parentSQLSelect fills this slice with data from the database.
 // Generated via tableToStruct.
 func (s *TableStoreSlice) parentSQLSelect(dbrSess dbr.SessionRunner, cbs ...dbr.SelectCb) (int, error) {
 	return csdb.LoadSlice(dbrSess, TableCollection, TableIndexStore, &(*s), cbs...)
 }
 
+// SelectWhere fills this slice with all rows matching the
+// conditions. Use the typed columns of StoreColumns to create them.
+// Generated via tableToStruct.
+func (s *TableStoreSlice) SelectWhere(dbrSess dbr.SessionRunner, conds ...dbr.ConditionArg) (int, error) {
+	return s.SQLSelect(dbrSess, func(sb *dbr.SelectBuilder) *dbr.SelectBuilder {
+		return sb.Where(conds...)
+	})
+}
+
 // SQLInsert inserts all records into the database @todo.
 // Generated via tableToStruct.
 func (s *TableStoreSlice) SQLInsert(dbrSess dbr.SessionRunner, cbs ...dbr.InsertCb) (int, error) {
@@ -182,7 +212,7 @@ func (s TableStoreSlice) FilterNot(f func(*TableStore) bool) TableStoreSlice {
 	return sl
 }
 
-// parentMap will run function f on all items in TableStoreSlice.
+// Map will run function f on all items in TableStoreSlice.
 // Generated via tableToStruct.
 func (s TableStoreSlice) Map(f func(*TableStore)) TableStoreSlice {
 	for i := range s {
@@ -333,12 +363,38 @@ type TableGroup struct {
 	DefaultStoreID int64  `db:"default_store_id" json:",omitempty"` // default_store_id smallint(5) unsigned NOT NULL MUL DEFAULT '0'
 }
 
+// GroupColumns contains the typed columns of DB table store_group
+// prefixed with the alias csdb.MainTable to be used in WHERE and ORDER BY.
+// Generated via tableToStruct.
+var GroupColumns = struct {
+	GroupID        dbr.ColumnInt64
+	WebsiteID      dbr.ColumnInt64
+	Name           dbr.ColumnString
+	RootCategoryID dbr.ColumnInt64
+	DefaultStoreID dbr.ColumnInt64
+}{
+	GroupID:        dbr.NewColumnInt64(csdb.MainTable, "group_id"),
+	WebsiteID:      dbr.NewColumnInt64(csdb.MainTable, "website_id"),
+	Name:           dbr.NewColumnString(csdb.MainTable, "name"),
+	RootCategoryID: dbr.NewColumnInt64(csdb.MainTable, "root_category_id"),
+	DefaultStoreID: dbr.NewColumnInt64(csdb.MainTable, "default_store_id"),
+}
+
 // parentSQLSelect fills this slice with data from the database.
 // Generated via tableToStruct.
 func (s *TableGroupSlice) parentSQLSelect(dbrSess dbr.SessionRunner, cbs ...dbr.SelectCb) (int, error) {
 	return csdb.LoadSlice(dbrSess, TableCollection, TableIndexGroup, &(*s), cbs...)
 }
 
+// SelectWhere fills this slice with all rows matching the
+// conditions. Use the typed columns of GroupColumns to create them.
+// Generated via tableToStruct.
+func (s *TableGroupSlice) SelectWhere(dbrSess dbr.SessionRunner, conds ...dbr.ConditionArg) (int, error) {
+	return s.SQLSelect(dbrSess, func(sb *dbr.SelectBuilder) *dbr.SelectBuilder {
+		return sb.Where(conds...)
+	})
+}
+
 // SQLInsert inserts all records into the database @todo.
 // Generated via tableToStruct.
 func (s *TableGroupSlice) SQLInsert(dbrSess dbr.SessionRunner, cbs ...dbr.InsertCb) (int, error) {
@@ -433,7 +489,7 @@ func (s TableGroupSlice) FilterNot(f func(*TableGroup) bool) TableGroupSlice {
 	return sl
 }
 
-// parentMap will run function f on all items in TableGroupSlice.
+// Map will run function f on all items in TableGroupSlice.
 // Generated via tableToStruct.
 func (s TableGroupSlice) Map(f func(*TableGroup)) TableGroupSlice {
 	for i := range s {
@@ -565,12 +621,40 @@ type TableWebsite struct {
 	IsDefault      dbr.NullBool   `db:"is_default" json:",omitempty"`       // is_default smallint(5) unsigned NULL  DEFAULT '0'
 }
 
+// WebsiteColumns contains the typed columns of DB table store_website
+// prefixed with the alias csdb.MainTable to be used in WHERE and ORDER BY.
+// Generated via tableToStruct.
+var WebsiteColumns = struct {
+	WebsiteID      dbr.ColumnInt64
+	Code           dbr.ColumnString
+	Name           dbr.ColumnString
+	SortOrder      dbr.ColumnInt64
+	DefaultGroupID dbr.ColumnInt64
+	IsDefault      dbr.ColumnBool
+}{
+	WebsiteID:      dbr.NewColumnInt64(csdb.MainTable, "website_id"),
+	Code:           dbr.NewColumnString(csdb.MainTable, "code"),
+	Name:           dbr.NewColumnString(csdb.MainTable, "name"),
+	SortOrder:      dbr.NewColumnInt64(csdb.MainTable, "sort_order"),
+	DefaultGroupID: dbr.NewColumnInt64(csdb.MainTable, "default_group_id"),
+	IsDefault:      dbr.NewColumnBool(csdb.MainTable, "is_default"),
+}
+
 // parentSQLSelect fills this slice with data from the database.
 // Generated via tableToStruct.
 func (s *TableWebsiteSlice) parentSQLSelect(dbrSess dbr.SessionRunner, cbs ...dbr.SelectCb) (int, error) {
 	return csdb.LoadSlice(dbrSess, TableCollection, TableIndexWebsite, &(*s), cbs...)
 }
 
+// SelectWhere fills this slice with all rows matching the
+// conditions. Use the typed columns of WebsiteColumns to create them.
+// Generated via tableToStruct.
+func (s *TableWebsiteSlice) SelectWhere(dbrSess dbr.SessionRunner, conds ...dbr.ConditionArg) (int, error) {
+	return s.SQLSelect(dbrSess, func(sb *dbr.SelectBuilder) *dbr.SelectBuilder {
+		return sb.Where(conds...)
+	})
+}
+
 // SQLInsert inserts all records into the database @todo.
 // Generated via tableToStruct.
 func (s *TableWebsiteSlice) SQLInsert(dbrSess dbr.SessionRunner, cbs ...dbr.InsertCb) (int, error) {
@@ -677,7 +761,7 @@ func (s TableWebsiteSlice) FilterNot(f func(*TableWebsite) bool) TableWebsiteSli
 	return sl
 }
 
-// parentMap will run function f on all items in TableWebsiteSlice.
+// Map will run function f on all items in TableWebsiteSlice.
 // Generated via tableToStruct.
 func (s TableWebsiteSlice) Map(f func(*TableWebsite)) TableWebsiteSlice {
 	for i := range s {
@@ -26,7 +26,7 @@ import "github.com/corestoreio/csfw/storage/dbr"
 // @see app/code/Magento/Store/Model/Resource/Website/Collection.php::Load()
 func (s *TableWebsiteSlice) SQLSelect(dbrSess dbr.SessionRunner, cbs ...dbr.SelectCb) (int, error) {
 	return s.parentSQLSelect(dbrSess, append(append([]dbr.SelectCb{nil}, func(sb *dbr.SelectBuilder) *dbr.SelectBuilder {
-		return sb.OrderBy("main_table.sort_order ASC").OrderBy("main_table.name ASC")
+		return sb.OrderBy(WebsiteColumns.SortOrder.Asc()).OrderBy(WebsiteColumns.Name.Asc())
 	}), cbs...)...)
 }
 
@@ -15,9 +15,13 @@
 package php
 
 import (
-	"bufio"
-	"fmt"
+	"errors"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/juju/errgo"
 )
 
 //Day	---	---
@@ -65,37 +69,468 @@ import (
 //r	» RFC 2822 formatted date	Example: Thu, 21 Dec 2000 16:01:07 +0200
 //U	Seconds since the Unix Epoch (January 1 1970 00:00:00 GMT)	See also time()
 
-// DateToGoFormat maps the PHP date format to the Go format. TODO
+// ErrNoGoLayout gets returned when a date format cannot be expressed as a Go
+// time layout. Use DateFormat.Format in such cases.
+var ErrNoGoLayout = errors.New("Date format has no Go layout equivalent")
+
+// DateNames contains the names of months and days used for formatting.
+// Days start with Sunday.
+type DateNames struct {
+	Months, MonthsAbbr [12]string
+	Days, DaysAbbr     [7]string
+	AM, PM             string
+	// Ordinal returns the suffix of the day of the month for the letter S.
+	// Optional, defaults to the English st, nd, rd and th.
+	Ordinal func(day int) string
+}
+
+// EnglishDateNames are the names as used by the PHP function date().
+var EnglishDateNames = DateNames{
+	Months:     [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	MonthsAbbr: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	Days:       [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	DaysAbbr:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	AM:         "AM",
+	PM:         "PM",
+}
+
+// Internal verbs for ICU fields without a PHP letter. These letters are no
+// PHP format characters so they cannot clash with ParseDate.
+const (
+	verbDayOfYear1 = 'K' // ICU D, day of the year starting with 1
+	verbHour0To11  = 'J' // ICU K
+	verbHour1To24  = 'b' // ICU k
+	verbMinute     = 'k' // ICU m, without leading zero
+	verbSecond     = 'q' // ICU s, without leading zero
+	verbFraction   = 'f' // ICU S, width digits
+	verbQuarter    = 'Q' // ICU Q and q
+	verbEra        = 'R' // ICU G
+	verbLiteral    = 0
+)
+
+// DateFormat is a parsed PHP date() or ICU date format. Formatting happens
+// in Go, so also the letters S, N, z, W, L, B and others without a Go
+// layout equivalent are supported.
+type DateFormat []dateToken
+
+type dateToken struct {
+	verb  byte
+	width int
+	lit   string
+}
+
+// ParseDate parses a format of the PHP function date(). A backslash escapes
+// the next character and unknown characters are literals like in PHP.
+// http://php.net/manual/en/function.date.php
+func ParseDate(phpFormat string) DateFormat {
+	var df DateFormat
+	for i := 0; i < len(phpFormat); i++ {
+		c := phpFormat[i]
+		switch {
+		case c == '\\' && i+1 < len(phpFormat):
+			i++
+			_, size := utf8.DecodeRuneInString(phpFormat[i:])
+			df = df.appendLiteral(phpFormat[i : i+size])
+			i += size - 1
+		case strings.IndexByte("dDjlNSwzWFmMntLoYyaABgGhHisuveIOPpTZcrU", c) >= 0:
+			df = append(df, dateToken{verb: c})
+		default:
+			_, size := utf8.DecodeRuneInString(phpFormat[i:])
+			df = df.appendLiteral(phpFormat[i : i+size])
+			i += size - 1
+		}
+	}
+	return df
+}
+
+// ParseICU parses an ICU/CLDR date pattern like "EEEE, d. MMMM y" as used by
+// Magento. Text in single quotes is a literal and two single quotes are a
+// quote. The time zone fields z, Z, x and X and the week fields get mapped
+// to the nearest PHP equivalent.
+// http://userguide.icu-project.org/formatparse/datetime
+func ParseICU(pattern string) DateFormat {
+	var df DateFormat
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		switch {
+		case c == '\'':
+			if i+1 < len(pattern) && pattern[i+1] == '\'' {
+				df = df.appendLiteral("'")
+				i += 2
+				continue
+			}
+			var lit []byte
+			j := i + 1
+			for ; j < len(pattern); j++ {
+				if pattern[j] == '\'' {
+					if j+1 < len(pattern) && pattern[j+1] == '\'' {
+						lit = append(lit, '\'')
+						j++
+						continue
+					}
+					break
+				}
+				lit = append(lit, pattern[j])
+			}
+			df = df.appendLiteral(string(lit))
+			i = j + 1
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			n := 1
+			for i+n < len(pattern) && pattern[i+n] == c {
+				n++
+			}
+			df = append(df, icuToken(c, n))
+			i += n
+		default:
+			_, size := utf8.DecodeRuneInString(pattern[i:])
+			df = df.appendLiteral(pattern[i : i+size])
+			i += size
+		}
+	}
+	return df
+}
+
+func icuToken(c byte, n int) dateToken {
+	pick := func(verbs ...byte) dateToken {
+		if n > len(verbs) {
+			n = len(verbs)
+		}
+		return dateToken{verb: verbs[n-1]}
+	}
+	switch c {
+	case 'G':
+		return dateToken{verb: verbEra}
+	case 'y', 'u':
+		if n == 2 {
+			return dateToken{verb: 'y'}
+		}
+		return dateToken{verb: 'Y'}
+	case 'Y':
+		return dateToken{verb: 'o'}
+	case 'Q', 'q':
+		return dateToken{verb: verbQuarter, width: n}
+	case 'M', 'L':
+		return pick('n', 'm', 'M', 'F')
+	case 'w':
+		return dateToken{verb: 'W'}
+	case 'd':
+		return pick('j', 'd')
+	case 'D':
+		return dateToken{verb: verbDayOfYear1}
+	case 'E':
+		return pick('D', 'D', 'D', 'l')
+	case 'e', 'c':
+		return pick('N', 'N', 'D', 'l')
+	case 'a':
+		return dateToken{verb: 'A'}
+	case 'h':
+		return pick('g', 'h')
+	case 'H':
+		return pick('G', 'H')
+	case 'K':
+		return dateToken{verb: verbHour0To11}
+	case 'k':
+		return dateToken{verb: verbHour1To24}
+	case 'm':
+		return pick(verbMinute, 'i')
+	case 's':
+		return pick(verbSecond, 's')
+	case 'S':
+		return dateToken{verb: verbFraction, width: n}
+	case 'z':
+		return pick('T', 'T', 'T', 'e')
+	case 'Z':
+		return pick('O', 'O', 'O', 'P', 'P')
+	case 'x':
+		return pick('O', 'O', 'P')
+	case 'X':
+		return pick('p', 'p', 'p')
+	}
+	return dateToken{lit: strings.Repeat(string(c), n)}
+}
+
+func (df DateFormat) appendLiteral(s string) DateFormat {
+	if l := len(df); l > 0 && df[l-1].verb == verbLiteral {
+		df[l-1].lit += s
+		return df
+	}
+	return append(df, dateToken{lit: s})
+}
+
+// Format formats the time with the English names like the PHP function
+// date().
+func (df DateFormat) Format(t time.Time) string {
+	return string(df.AppendFormat(nil, t, nil))
+}
+
+// AppendFormat appends the formatted time to b. If names is nil the English
+// names get used.
+func (df DateFormat) AppendFormat(b []byte, t time.Time, names *DateNames) []byte {
+	if names == nil {
+		names = &EnglishDateNames
+	}
+	for _, tok := range df {
+		b = tok.appendFormat(b, t, names)
+	}
+	return b
+}
+
+func (tok dateToken) appendFormat(b []byte, t time.Time, n *DateNames) []byte {
+	switch tok.verb {
+	case verbLiteral:
+		return append(b, tok.lit...)
+	case 'd':
+		return appendInt(b, t.Day(), 2)
+	case 'D':
+		return append(b, n.DaysAbbr[t.Weekday()]...)
+	case 'j':
+		return appendInt(b, t.Day(), 0)
+	case 'l':
+		return append(b, n.Days[t.Weekday()]...)
+	case 'N':
+		wd := int(t.Weekday())
+		if wd == 0 {
+			wd = 7
+		}
+		return appendInt(b, wd, 0)
+	case 'S':
+		if n.Ordinal != nil {
+			return append(b, n.Ordinal(t.Day())...)
+		}
+		return append(b, englishOrdinal(t.Day())...)
+	case 'w':
+		return appendInt(b, int(t.Weekday()), 0)
+	case 'z':
+		return appendInt(b, t.YearDay()-1, 0)
+	case verbDayOfYear1:
+		return appendInt(b, t.YearDay(), 0)
+	case 'W':
+		_, w := t.ISOWeek()
+		return appendInt(b, w, 2)
+	case 'F':
+		return append(b, n.Months[t.Month()-1]...)
+	case 'm':
+		return appendInt(b, int(t.Month()), 2)
+	case 'M':
+		return append(b, n.MonthsAbbr[t.Month()-1]...)
+	case 'n':
+		return appendInt(b, int(t.Month()), 0)
+	case 't':
+		return appendInt(b, time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day(), 0)
+	case 'L':
+		if isLeap(t.Year()) {
+			return append(b, '1')
+		}
+		return append(b, '0')
+	case 'o':
+		y, _ := t.ISOWeek()
+		return appendInt(b, y, 4)
+	case 'Y':
+		return appendInt(b, t.Year(), 4)
+	case 'y':
+		return appendInt(b, t.Year()%100, 2)
+	case verbEra:
+		if t.Year() > 0 {
+			return append(b, "AD"...)
+		}
+		return append(b, "BC"...)
+	case verbQuarter:
+		q := (int(t.Month())-1)/3 + 1
+		if tok.width >= 3 {
+			b = append(b, 'Q')
+			return appendInt(b, q, 0)
+		}
+		return appendInt(b, q, tok.width)
+	case 'a':
+		if t.Hour() < 12 {
+			return append(b, strings.ToLower(n.AM)...)
+		}
+		return append(b, strings.ToLower(n.PM)...)
+	case 'A':
+		if t.Hour() < 12 {
+			return append(b, n.AM...)
+		}
+		return append(b, n.PM...)
+	case 'B':
+		sec := (t.Unix() + 3600) % 86400 // Biel Mean Time UTC+1
+		if sec < 0 {
+			sec += 86400
+		}
+		return appendInt(b, int(sec*10/864)%1000, 3)
+	case 'g':
+		return appendInt(b, hour12(t.Hour()), 0)
+	case 'G':
+		return appendInt(b, t.Hour(), 0)
+	case 'h':
+		return appendInt(b, hour12(t.Hour()), 2)
+	case 'H':
+		return appendInt(b, t.Hour(), 2)
+	case verbHour0To11:
+		return appendInt(b, t.Hour()%12, 0)
+	case verbHour1To24:
+		if t.Hour() == 0 {
+			return append(b, "24"...)
+		}
+		return appendInt(b, t.Hour(), 0)
+	case 'i':
+		return appendInt(b, t.Minute(), 2)
+	case verbMinute:
+		return appendInt(b, t.Minute(), 0)
+	case 's':
+		return appendInt(b, t.Second(), 2)
+	case verbSecond:
+		return appendInt(b, t.Second(), 0)
+	case 'u':
+		return appendInt(b, t.Nanosecond()/1e3, 6)
+	case 'v':
+		return appendInt(b, t.Nanosecond()/1e6, 3)
+	case verbFraction:
+		ns := appendInt(nil, t.Nanosecond(), 9)
+		for len(ns) < tok.width {
+			ns = append(ns, '0')
+		}
+		return append(b, ns[:tok.width]...)
+	case 'e':
+		return append(b, t.Location().String()...)
+	case 'I':
+		if isDST(t) {
+			return append(b, '1')
+		}
+		return append(b, '0')
+	case 'O':
+		return t.AppendFormat(b, "-0700")
+	case 'P':
+		return t.AppendFormat(b, "-07:00")
+	case 'p':
+		return t.AppendFormat(b, "Z07:00")
+	case 'T':
+		return t.AppendFormat(b, "MST")
+	case 'Z':
+		_, offset := t.Zone()
+		return appendInt(b, offset, 0)
+	case 'c':
+		return t.AppendFormat(b, "2006-01-02T15:04:05-07:00")
+	case 'r':
+		return ParseDate("D, d M Y H:i:s O").AppendFormat(b, t, n)
+	case 'U':
+		return strconv.AppendInt(b, t.Unix(), 10)
+	}
+	return b
+}
+
+// goLayouts maps the verbs to the Go layout.
+var goLayouts = map[byte]string{
+	'd': "02", 'D': "Mon", 'j': "2", 'l': "Monday",
+	'F': "January", 'm': "01", 'M': "Jan", 'n': "1",
+	'Y': "2006", 'y': "06",
+	'a': "pm", 'A': "PM", 'g': "3", 'h': "03", 'H': "15",
+	'i': "04", verbMinute: "4", 's': "05", verbSecond: "5",
+	'O': "-0700", 'P': "-07:00", 'p': "Z07:00", 'T': "MST",
+	'c': "2006-01-02T15:04:05-07:00", 'r': "Mon, 02 Jan 2006 15:04:05 -0700",
+}
+
+// GoLayout returns the Go time layout of the format. Returns an error
+// with the behaviour ErrNoGoLayout if a letter has no Go equivalent, like
+// S or N, or if literal text would be interpreted by the Go layout parser.
+// Only the English names can be formatted with a Go layout.
+func (df DateFormat) GoLayout() (string, error) {
+	var buf []byte
+	for _, tok := range df {
+		switch {
+		case tok.verb == verbLiteral:
+			buf = append(buf, tok.lit...)
+		case goLayouts[tok.verb] != "":
+			buf = append(buf, goLayouts[tok.verb]...)
+		case (tok.verb == 'u' || tok.verb == 'v' || tok.verb == verbFraction) && len(buf) > 0 && (buf[len(buf)-1] == '.' || buf[len(buf)-1] == ','):
+			digits := tok.width
+			switch tok.verb {
+			case 'u':
+				digits = 6
+			case 'v':
+				digits = 3
+			}
+			buf = append(buf, strings.Repeat("0", digits)...)
+		default:
+			return "", errgo.WithCausef(nil, ErrNoGoLayout, "%s: letter %q", ErrNoGoLayout, tok.verb)
+		}
+	}
+	layout := string(buf)
+	for _, probe := range probeTimes {
+		if probe.Format(layout) != df.Format(probe) {
+			return "", errgo.WithCausef(nil, ErrNoGoLayout, "%s: ambiguous layout %q", ErrNoGoLayout, layout)
+		}
+	}
+	return layout, nil
+}
+
+// probeTimes verify that a generated Go layout formats like the DateFormat.
+// All fields differ from the Go reference time.
+var probeTimes = [...]time.Time{
+	time.Date(1999, 11, 30, 22, 58, 59, 123456789, time.FixedZone("XYZ", 3*3600+1800)),
+	time.Date(2016, 2, 7, 9, 8, 3, 4000, time.FixedZone("WXY", -8*3600)),
+}
+
+// DateToGoFormat maps the PHP date format to the Go time layout. Returns an
+// error with the behaviour ErrNoGoLayout if there is no equivalent. Use
+// ParseDate(phpFormat).Format(t) to format any PHP date format.
 // http://php.net/manual/en/function.date.php
-func DateToGoFormat(phpFormat string) (goFormat string) {
-	// tutorial: http://jeremy.marzhillstudios.com/io/
-
-	s := bufio.NewScanner(strings.NewReader(phpFormat))
-	//split := func(data []byte, atEOF bool) (advance int, token []byte, err error) {
-	//	// Because our grammar is simple we can switch off the first
-	//	// character in the reader.
-	//	//switch data[0] {
-	//	//case '(', ')':
-	//	//	advance, token, err = 1, data[:1], nil
-	//	//case '"', '\'':
-	//	//	advance, token, err = consumeString(data)
-	//	//case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-	//	//	advance, token, err = consumeNum(data)
-	//	//case ' ', '\n', '\r', '\t':
-	//	//	advance, token, err = consumeWhitespace(data)
-	//	//default:
-	//	//	advance, token, err = consumeWord(data)
-	//	//}
-	//
-	//	println("data: ", string(data))
-	//
-	//	token = data
-	//	return
-	//}
-	//s.Split(split)
-	for s.Scan() {
-		tok := s.Bytes()
-		fmt.Println("Found token: ", string(tok))
-	}
-	return
+func DateToGoFormat(phpFormat string) (goFormat string, err error) {
+	return ParseDate(phpFormat).GoLayout()
+}
+
+// ICUToGoFormat maps an ICU date pattern to the Go time layout. Returns an
+// error with the behaviour ErrNoGoLayout if there is no equivalent.
+func ICUToGoFormat(pattern string) (goFormat string, err error) {
+	return ParseICU(pattern).GoLayout()
+}
+
+func appendInt(b []byte, i int, width int) []byte {
+	if i < 0 {
+		b = append(b, '-')
+		i = -i
+	}
+	s := strconv.Itoa(i)
+	for l := len(s); l < width; l++ {
+		b = append(b, '0')
+	}
+	return append(b, s...)
+}
+
+func englishOrdinal(day int) string {
+	if day%100 >= 11 && day%100 <= 13 {
+		return "th"
+	}
+	switch day % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+func hour12(h int) int {
+	if h %= 12; h == 0 {
+		return 12
+	}
+	return h
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// isDST reports if the offset is larger than the smaller offset of January
+// and July.
+func isDST(t time.Time) bool {
+	_, offset := t.Zone()
+	_, jan := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location()).Zone()
+	_, jul := time.Date(t.Year(), time.July, 1, 0, 0, 0, 0, t.Location()).Zone()
+	std := jan
+	if jul < std {
+		std = jul
+	}
+	return offset > std
 }
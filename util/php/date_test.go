@@ -15,23 +15,105 @@
 package php_test
 
 import (
+	"testing"
+	"time"
+
 	"github.com/corestoreio/csfw/util/php"
+	"github.com/juju/errgo"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
-func TestToGoFormat(t *testing.T) {
-
-	t.Skip("todo")
+var testDate = time.Date(2005, 8, 8, 15, 12, 46, 123456789, time.FixedZone("CEST", 2*3600))
 
+func TestDateFormat(t *testing.T) {
 	tests := []struct {
 		phpf string
-		gof  string
+		want string
+	}{
+		{`l jS \of F Y h:i:s A`, "Monday 8th of August 2005 03:12:46 PM"},
+		{`l \t\h\e jS`, "Monday the 8th"},
+		{`D, d M y g:i a`, "Mon, 08 Aug 05 3:12 pm"},
+		{`N w z W t L o`, "1 1 219 32 31 0 2005"},
+		{`n/j/Y G:i`, "8/8/2005 15:12"},
+		{`B u v`, "592 123456 123"},
+		{`e I O P p T Z`, "CEST 0 +0200 +02:00 +02:00 CEST 7200"},
+		{`c`, "2005-08-08T15:12:46+02:00"},
+		{`r`, "Mon, 08 Aug 2005 15:12:46 +0200"},
+		{`U`, "1123506766"},
+		{`Y-m-d ✓ \\ \ä`, "2005-08-08 ✓ \\ ä"},
+	}
+	for _, test := range tests {
+		assert.Exactly(t, test.want, php.ParseDate(test.phpf).Format(testDate), "%q", test.phpf)
+	}
+	for day, want := range map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th", 21: "21st", 22: "22nd", 23: "23rd"} {
+		assert.Exactly(t, want, php.ParseDate("jS").Format(time.Date(2016, 1, day, 0, 0, 0, 0, time.UTC)))
+	}
+}
+
+func TestICUFormat(t *testing.T) {
+	tests := []struct {
+		icu  string
+		want string
 	}{
-		{`l jS \of F Y h:i:s A`, `a b of `}, // e.g. Monday 8th of August 2005 03:12:46 PM
-		{`l \t\h\e jS`, `x the DATE`},       // e.g. Wednesday the 15th
+		{"EEEE, MMMM d, y", "Monday, August 8, 2005"},
+		{"MMM d, y h:mm:ss a", "Aug 8, 2005 3:12:46 PM"},
+		{"M/d/yy", "8/8/05"},
+		{"dd.MM.yyyy HH:mm", "08.08.2005 15:12"},
+		{"d. MMMM y 'um' H 'Uhr'", "8. August 2005 um 15 Uhr"},
+		{"h 'o''clock' a, zzzz", "3 o'clock PM, CEST"},
+		{"''yy''", "'05'"},
+		{"D K k m s SSS", "220 3 15 12 46 123"},
+		{"QQQ G xxx Z", "Q3 AD +02:00 +0200"},
 	}
 	for _, test := range tests {
-		assert.Exactly(t, test.gof, php.DateToGoFormat(test.phpf))
+		assert.Exactly(t, test.want, php.ParseICU(test.icu).Format(testDate), "%q", test.icu)
+	}
+}
+
+func TestDateToGoFormat(t *testing.T) {
+	tests := []struct {
+		phpf    string
+		gof     string
+		wantErr bool
+	}{
+		{`Y-m-d H:i:s`, "2006-01-02 15:04:05", false},
+		{`D, d M Y`, "Mon, 02 Jan 2006", false},
+		{`l, F j, Y g:i a`, "Monday, January 2, 2006 3:04 pm", false},
+		{`d.m.y H:i:s.u T`, "02.01.06 15:04:05.000000 MST", false},
+		{`c`, "2006-01-02T15:04:05-07:00", false},
+		{`l jS \of F Y`, "", true}, // S
+		{`N`, "", true},
+		{`z`, "", true},
+		{`Y \1`, "", true}, // literal 1 would be the month
+		{`Hu`, "", true},
+	}
+	for _, test := range tests {
+		gof, err := php.DateToGoFormat(test.phpf)
+		if test.wantErr {
+			assert.True(t, errgo.Cause(err) == php.ErrNoGoLayout, "%q: %v", test.phpf, err)
+			continue
+		}
+		assert.NoError(t, err, "%q", test.phpf)
+		assert.Exactly(t, test.gof, gof, "%q", test.phpf)
+		assert.Exactly(t, php.ParseDate(test.phpf).Format(testDate), testDate.Format(gof), "%q", test.phpf)
+	}
+
+	gof, err := php.ICUToGoFormat("EEEE, d. MMMM y HH:mm")
+	assert.NoError(t, err)
+	assert.Exactly(t, "Monday, 2. January 2006 15:04", gof)
+	_, err = php.ICUToGoFormat("H:mm")
+	assert.True(t, errgo.Cause(err) == php.ErrNoGoLayout)
+}
+
+func TestDateNames(t *testing.T) {
+	names := php.EnglishDateNames
+	names.Months[7] = "août"
+	names.Days[1] = "lundi"
+	names.Ordinal = func(day int) string {
+		if day == 1 {
+			return "er"
+		}
+		return ""
 	}
+	assert.Exactly(t, "lundi 8 août 2005", string(php.ParseDate("l jS F Y").AppendFormat(nil, testDate, &names)))
 }
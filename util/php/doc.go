@@ -13,4 +13,8 @@
 // limitations under the License.
 
 // Package php provides compatibility functions between Go and PHP.
+//
+// The date functions convert PHP date() formats and ICU date patterns, as
+// used by Magento, into Go layouts or format a time.Time with them directly,
+// optionally with localized month and day names.
 package php